@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/httpx"
+	"github.com/eunanio/sdk/pkg/keyring"
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/system"
+)
+
+func init() {
+	intervalUnit = time.Millisecond
+}
+
+// newServer returns an authorization server whose token endpoint answers
+// device code polls with responses in order, repeating the last one.
+func newServer(t *testing.T, responses ...string) (*httptest.Server, *Config) {
+	t.Helper()
+	var mu sync.Mutex
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("client_id") != "cli" || r.FormValue("scope") != "read write" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Code: "invalid_request"})
+			return
+		}
+		json.NewEncoder(w).Encode(DeviceCode{
+			DeviceCode:      "device-123",
+			UserCode:        "ABCD-EFGH",
+			VerificationURI: "https://example.com/device",
+			ExpiresIn:       1000,
+			Interval:        1,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		switch r.FormValue("grant_type") {
+		case "refresh_token":
+			if r.FormValue("refresh_token") != "refresh-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(Error{Code: "invalid_grant"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "access-2", "token_type": "bearer", "expires_in": 3600})
+		case deviceCodeGrant:
+			mu.Lock()
+			response := responses[0]
+			if len(responses) > 1 {
+				responses = responses[1:]
+			}
+			mu.Unlock()
+
+			if response == "ok" {
+				json.NewEncoder(w).Encode(map[string]any{"access_token": "access-1", "token_type": "bearer", "refresh_token": "refresh-1", "expires_in": 3600})
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Code: response})
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &Config{
+		ClientID:      "cli",
+		DeviceAuthURL: server.URL + "/device",
+		TokenURL:      server.URL + "/token",
+		Scopes:        []string{"read", "write"},
+		HTTP:          httpx.New(httpx.WithRetries(0)),
+	}
+}
+
+func TestLogin(t *testing.T) {
+	defer system.SetNonInteractive(system.IsNonInteractive())
+	system.SetNonInteractive(true)
+
+	tests := []struct {
+		name          string
+		responses     []string
+		expectedToken string
+		expectedErr   error
+	}{
+		{name: "Approved after polling", responses: []string{"authorization_pending", "slow_down", "ok"}, expectedToken: "access-1"},
+		{name: "Denied", responses: []string{"authorization_pending", "access_denied"}, expectedErr: ErrAccessDenied},
+		{name: "Expired", responses: []string{"expired_token"}, expectedErr: ErrCodeExpired},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			_, config := newServer(t, tt.responses...)
+			var out bytes.Buffer
+
+			token, err := config.Login(context.Background(), &out)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) || !log.IsCode(err, log.CodeUnauthorized) {
+					t.Fatalf("Expected %v, got: %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if token.AccessToken != tt.expectedToken || !token.Valid() {
+				t.Errorf("Expected a valid token %s, got: %+v", tt.expectedToken, token)
+			}
+			if !strings.Contains(out.String(), "https://example.com/device") || !strings.Contains(out.String(), "ABCD-EFGH") {
+				t.Errorf("Expected the verification link and code to be printed, got: %q", out.String())
+			}
+		})
+	}
+}
+
+func TestSession(t *testing.T) {
+	defer system.SetNonInteractive(system.IsNonInteractive())
+	system.SetNonInteractive(true)
+
+	t.Setenv("DEVKIT_KEYRING_PASSWORD", "test")
+	server, config := newServer(t, "ok")
+	k, err := keyring.New("devkit-test", keyring.WithFile(filepath.Join(t.TempDir(), "keyring.enc")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := NewSession(*config, k)
+	if _, err := session.Token(context.Background()); !errors.Is(err, ErrLoginRequired) {
+		t.Fatalf("Expected ErrLoginRequired before login, got: %v", err)
+	}
+
+	if _, err := session.Login(context.Background(), &bytes.Buffer{}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// A new session reads the stored token and refreshes it once expired.
+	restored := NewSession(*config, k)
+	token, err := restored.Token(context.Background())
+	if err != nil || token.AccessToken != "access-1" {
+		t.Fatalf("Expected the stored token, got: %+v %v", token, err)
+	}
+	restored.token.Expiry = time.Now()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err := restored.Authorize(req); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if req.Header.Get("Authorization") != "Bearer access-2" {
+		t.Errorf("Expected the refreshed token, got: %q", req.Header.Get("Authorization"))
+	}
+
+	stored, _ := k.Get(restored.key)
+	if !strings.Contains(stored.Password, "access-2") || !strings.Contains(stored.Password, "refresh-1") {
+		t.Errorf("Expected the refreshed token to be stored with the old refresh token, got: %s", stored.Password)
+	}
+
+	restored.token.Expiry = time.Now()
+	restored.token.RefreshToken = "revoked"
+	if _, err := restored.Token(context.Background()); !errors.Is(err, ErrLoginRequired) || !log.IsCode(err, log.CodeUnauthorized) {
+		t.Errorf("Expected a rejected refresh to require login, got: %v", err)
+	}
+
+	if err := restored.Logout(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, err := NewSession(*config, k).Token(context.Background()); !errors.Is(err, ErrLoginRequired) {
+		t.Errorf("Expected ErrLoginRequired after logout, got: %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/httpx"
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/system"
+	"github.com/eunanio/sdk/pkg/validate"
+)
+
+const deviceCodeGrant = "urn:ietf:params:oauth:grant-type:device_code"
+
+var (
+	ErrAccessDenied = errors.New("authorization was denied")
+	ErrCodeExpired  = errors.New("device code expired before authorization")
+)
+
+// intervalUnit scales polling intervals, which OAuth servers give in seconds.
+var intervalUnit = time.Second
+
+// Config describes an OAuth2 client using the device authorization grant
+// (RFC 8628). No client secret is needed.
+type Config struct {
+	ClientID      string `validate:"required"`
+	DeviceAuthURL string `validate:"required,url"`
+	TokenURL      string `validate:"required,url"`
+	Scopes        []string
+	HTTP          *httpx.Client
+}
+
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// expiryMargin treats tokens about to expire as expired, so they are not
+// rejected in flight.
+const expiryMargin = 30 * time.Second
+
+// Valid reports whether the access token is set and not about to expire.
+// Tokens without an expiry never expire.
+func (t *Token) Valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Until(t.Expiry) > expiryMargin)
+}
+
+// Authorize sets the Authorization header of req.
+func (t *Token) Authorize(req *http.Request) {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	req.Header.Set("Authorization", tokenType+" "+t.AccessToken)
+}
+
+// DeviceCode is the server's response to a device authorization request.
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// RequestDeviceCode starts the flow, returning the code the user enters at
+// the verification page.
+func (c *Config) RequestDeviceCode(ctx context.Context) (*DeviceCode, error) {
+	if err := validate.Struct(c); err != nil {
+		return nil, err
+	}
+
+	form := url.Values{"client_id": {c.ClientID}}
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+
+	code := &DeviceCode{}
+	if err := c.post(ctx, "device_code", c.DeviceAuthURL, form, code); err != nil {
+		return nil, err
+	}
+	if code.DeviceCode == "" || code.UserCode == "" || code.VerificationURI == "" {
+		return nil, log.Errorf(log.CodeRemote, "device_code", "incomplete device authorization response")
+	}
+
+	return code, nil
+}
+
+// PollToken polls the token endpoint at the interval the server asked for
+// until the user approves or denies the request, or the code expires.
+func (c *Config) PollToken(ctx context.Context, code *DeviceCode) (*Token, error) {
+	defer log.Timed("device_poll")()
+	interval := time.Duration(max(code.Interval, 5)) * intervalUnit
+	if code.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*intervalUnit)
+		defer cancel()
+	}
+
+	form := url.Values{
+		"grant_type":  {deviceCodeGrant},
+		"device_code": {code.DeviceCode},
+		"client_id":   {c.ClientID},
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, log.NewError(log.CodeUnauthorized, "device_poll", ErrCodeExpired)
+			}
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		token, err := c.requestToken(ctx, form)
+		var oauthErr *Error
+		if !errors.As(err, &oauthErr) {
+			return token, err
+		}
+
+		switch oauthErr.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * intervalUnit
+		case "access_denied":
+			return nil, log.NewError(log.CodeUnauthorized, "device_poll", ErrAccessDenied)
+		case "expired_token":
+			return nil, log.NewError(log.CodeUnauthorized, "device_poll", ErrCodeExpired)
+		default:
+			return nil, err
+		}
+	}
+}
+
+// Refresh exchanges a refresh token for a new token. Servers that do not
+// rotate refresh tokens omit it, in which case the old one is kept.
+func (c *Config) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	token, err := c.requestToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {c.ClientID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	return token, nil
+}
+
+// Login runs the whole flow: it prints the code and verification link to
+// w, opens the link in the browser when the session is interactive, and
+// waits for the user to approve.
+func (c *Config) Login(ctx context.Context, w io.Writer) (*Token, error) {
+	code, err := c.RequestDeviceCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(w, "To sign in, open %s and enter the code %s\n", code.VerificationURI, code.UserCode)
+	if !system.IsNonInteractive() {
+		link := code.VerificationURIComplete
+		if link == "" {
+			link = code.VerificationURI
+		}
+		if err := system.OpenURL(link); err != nil {
+			log.Component("auth").Debug("failed to open browser", "url", link, log.KeyError, err.Error())
+		}
+	}
+
+	return c.PollToken(ctx, code)
+}
+
+// Error is an OAuth2 error response.
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Description != "" {
+		return "oauth2: " + e.Code + ": " + e.Description
+	}
+	return "oauth2: " + e.Code
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+func (c *Config) requestToken(ctx context.Context, form url.Values) (*Token, error) {
+	resp := &tokenResponse{}
+	if err := c.post(ctx, "token", c.TokenURL, form, resp); err != nil {
+		return nil, err
+	}
+	if resp.AccessToken == "" {
+		return nil, log.Errorf(log.CodeRemote, "token", "token response has no access_token")
+	}
+
+	token := &Token{AccessToken: resp.AccessToken, TokenType: resp.TokenType, RefreshToken: resp.RefreshToken}
+	if resp.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// post sends form to endpoint and decodes a JSON response into out. OAuth2
+// error responses are returned as *Error.
+func (c *Config) post(ctx context.Context, op, endpoint string, form url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	client := c.HTTP
+	if client == nil {
+		client = httpx.New()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		oauthErr := &Error{}
+		if json.Unmarshal(body, oauthErr) == nil && oauthErr.Code != "" {
+			return oauthErr
+		}
+		return log.Errorf(log.CodeRemote, op, "%s returned %s", endpoint, resp.Status)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return log.Errorf(log.CodeRemote, op, "invalid response from %s: %v", endpoint, err)
+	}
+	return nil
+}
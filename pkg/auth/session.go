@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/eunanio/sdk/pkg/keyring"
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+var ErrLoginRequired = errors.New("not logged in")
+
+// tokenUsername marks keyring entries holding a token rather than a
+// username and password.
+const tokenUsername = "oauth2"
+
+// Session keeps the token of one OAuth2 client in a keyring, refreshing it
+// when it expires.
+type Session struct {
+	config  Config
+	keyring *keyring.Keyring
+	key     string
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// NewSession stores tokens for config in k under a key derived from the
+// token endpoint's host and the client ID.
+func NewSession(config Config, k *keyring.Keyring) *Session {
+	host := config.TokenURL
+	if u, err := url.Parse(config.TokenURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	return &Session{config: config, keyring: k, key: "oauth2/" + config.ClientID + "@" + host}
+}
+
+// Login runs the device flow and stores the resulting token.
+func (s *Session) Login(ctx context.Context, w io.Writer) (*Token, error) {
+	token, err := s.config.Login(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return token, s.save(token)
+}
+
+// Token returns a valid token, refreshing and storing it when it has
+// expired. Without a stored token, or when the refresh is rejected, it
+// returns ErrLoginRequired as a log.CodeUnauthorized error.
+func (s *Session) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil {
+		token, err := s.load()
+		if err != nil {
+			return nil, err
+		}
+		s.token = token
+	}
+	if s.token.Valid() {
+		return s.token, nil
+	}
+	if s.token.RefreshToken == "" {
+		return nil, log.NewError(log.CodeUnauthorized, "token", ErrLoginRequired)
+	}
+
+	token, err := s.config.Refresh(ctx, s.token.RefreshToken)
+	var oauthErr *Error
+	if errors.As(err, &oauthErr) {
+		return nil, log.NewError(log.CodeUnauthorized, "token", fmt.Errorf("%w: %w", ErrLoginRequired, err))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return token, s.save(token)
+}
+
+// Authorize sets the Authorization header of req from Token.
+func (s *Session) Authorize(req *http.Request) error {
+	token, err := s.Token(req.Context())
+	if err != nil {
+		return err
+	}
+
+	token.Authorize(req)
+	return nil
+}
+
+// Logout removes the stored token.
+func (s *Session) Logout() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = nil
+	return s.keyring.Delete(s.key)
+}
+
+func (s *Session) load() (*Token, error) {
+	cred, err := s.keyring.Get(s.key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, log.NewError(log.CodeUnauthorized, "token", ErrLoginRequired)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token := &Token{}
+	if cred.Username != tokenUsername || json.Unmarshal([]byte(cred.Password), token) != nil {
+		return nil, log.Errorf(log.CodeUnauthorized, "token", "stored token for %s is invalid, log in again", s.key)
+	}
+	return token, nil
+}
+
+func (s *Session) save(token *Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	if err := s.keyring.Set(s.key, keyring.Credential{Username: tokenUsername, Password: string(data)}); err != nil {
+		return err
+	}
+	s.token = token
+	return nil
+}
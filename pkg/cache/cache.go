@@ -0,0 +1,341 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/fs"
+	"github.com/eunanio/sdk/pkg/lockfile"
+	"github.com/opencontainers/go-digest"
+)
+
+var ErrNotFound = errors.New("cache entry not found")
+
+// lockTimeout bounds how long an index update waits for other processes.
+const lockTimeout = 30 * time.Second
+
+type Options struct {
+	// TTL expires entries this long after they were stored. Zero keeps
+	// entries until they are evicted for space.
+	TTL time.Duration
+	// MaxSize evicts the least recently used entries once the cache holds
+	// more than this many bytes. Zero means no limit.
+	MaxSize int64
+	// FS holds the cache instead of the disk, e.g. an fs.MemFS in tests.
+	// Other file systems are only locked within the process.
+	FS fs.FS
+}
+
+type Entry struct {
+	Key      string            `json:"key"`
+	Digest   digest.Digest     `json:"digest"`
+	Size     int64             `json:"size"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Created  time.Time         `json:"created"`
+	Accessed time.Time         `json:"accessed"`
+}
+
+// Cache is a content-addressable blob store under dir/blobs with an index
+// mapping keys to blobs in dir/index.json. Blobs shared by several keys are
+// stored once. Index updates hold a lock file so several processes can
+// share a cache directory.
+type Cache struct {
+	dir  string
+	opts Options
+	fsys fs.FS
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+func New(dir string, opts Options) (*Cache, error) {
+	fsys := opts.FS
+	if fsys == nil {
+		fsys = fs.OS
+	}
+	if err := fsys.MkdirAll(filepath.Join(dir, "blobs"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	return &Cache{dir: dir, opts: opts, fsys: fsys, now: time.Now}, nil
+}
+
+// Default returns a cache in the user cache directory, e.g.
+// ~/.cache/<name> on Linux.
+func Default(name string, opts Options) (*Cache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cache directory: %w", err)
+	}
+
+	return New(filepath.Join(dir, name), opts)
+}
+
+// Put stores the content of r under key, replacing any previous entry, and
+// evicts entries if the cache grows past MaxSize.
+func (c *Cache) Put(key string, r io.Reader, metadata map[string]string) (*Entry, error) {
+	d, size, err := c.PutBlob(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry *Entry
+	err = c.update(func(index map[string]*Entry) error {
+		now := c.now()
+		entry = &Entry{Key: key, Digest: d, Size: size, Metadata: metadata, Created: now, Accessed: now}
+		index[key] = entry
+		c.evict(index)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Open returns the content stored under key and marks it as recently used.
+// Expired entries are reported as ErrNotFound.
+func (c *Cache) Open(key string) (io.ReadCloser, *Entry, error) {
+	var entry *Entry
+	err := c.update(func(index map[string]*Entry) error {
+		e, ok := index[key]
+		if !ok || c.expired(e) {
+			return ErrNotFound
+		}
+		e.Accessed = c.now()
+		entry = e
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := c.OpenBlob(entry.Digest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, entry, nil
+}
+
+func (c *Cache) Get(key string) ([]byte, *Entry, error) {
+	f, entry, err := c.Open(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, entry, nil
+}
+
+func (c *Cache) Delete(key string) error {
+	return c.update(func(index map[string]*Entry) error {
+		delete(index, key)
+		return nil
+	})
+}
+
+// Entries returns the index sorted by key.
+func (c *Cache) Entries() ([]*Entry, error) {
+	var entries []*Entry
+	err := c.update(func(index map[string]*Entry) error {
+		for _, e := range index {
+			entries = append(entries, e)
+		}
+		return nil
+	})
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, err
+}
+
+// Prune drops expired entries, evicts down to MaxSize and removes blobs no
+// longer referenced by any key.
+func (c *Cache) Prune() error {
+	return c.update(func(index map[string]*Entry) error {
+		c.evict(index)
+		return nil
+	})
+}
+
+// PutBlob stores the content of r by digest without indexing it.
+func (c *Cache) PutBlob(r io.Reader) (digest.Digest, int64, error) {
+	tmp, err := fs.CreateTemp(c.fsys, filepath.Join(c.dir, "blobs"), ".tmp-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer c.fsys.Remove(tmp.Name())
+	defer tmp.Close()
+
+	digester := digest.Canonical.Digester()
+	size, err := io.Copy(io.MultiWriter(tmp, digester.Hash()), r)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, err
+	}
+
+	d := digester.Digest()
+	path := c.blobPath(d)
+	if err := c.fsys.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", 0, err
+	}
+	if err := c.fsys.Rename(tmp.Name(), path); err != nil {
+		return "", 0, fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	return d, size, nil
+}
+
+func (c *Cache) OpenBlob(d digest.Digest) (fs.File, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	f, err := c.fsys.Open(c.blobPath(d))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return f, err
+}
+
+func (c *Cache) HasBlob(d digest.Digest) bool {
+	if d.Validate() != nil {
+		return false
+	}
+
+	_, err := c.fsys.Stat(c.blobPath(d))
+	return err == nil
+}
+
+func (c *Cache) blobPath(d digest.Digest) string {
+	return filepath.Join(c.dir, "blobs", d.Algorithm().String(), d.Encoded())
+}
+
+func (c *Cache) expired(e *Entry) bool {
+	return c.opts.TTL > 0 && c.now().Sub(e.Created) > c.opts.TTL
+}
+
+// evict removes expired entries, then the least recently used ones until
+// the indexed size fits MaxSize, and finally deletes unreferenced blobs.
+func (c *Cache) evict(index map[string]*Entry) {
+	for key, e := range index {
+		if c.expired(e) {
+			delete(index, key)
+		}
+	}
+
+	if c.opts.MaxSize > 0 {
+		entries := make([]*Entry, 0, len(index))
+		sizes := map[digest.Digest]int64{}
+		for _, e := range index {
+			entries = append(entries, e)
+			sizes[e.Digest] = e.Size
+		}
+
+		var total int64
+		for _, size := range sizes {
+			total += size
+		}
+
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Accessed.Before(entries[j].Accessed) })
+		refs := map[digest.Digest]int{}
+		for _, e := range entries {
+			refs[e.Digest]++
+		}
+		for _, e := range entries {
+			if total <= c.opts.MaxSize {
+				break
+			}
+			delete(index, e.Key)
+			if refs[e.Digest]--; refs[e.Digest] == 0 {
+				total -= e.Size
+			}
+		}
+	}
+
+	c.removeUnreferenced(index)
+}
+
+// blobGrace protects blobs written by a concurrent Put that has not yet
+// added them to the index.
+const blobGrace = time.Minute
+
+func (c *Cache) removeUnreferenced(index map[string]*Entry) {
+	referenced := map[string]bool{}
+	for _, e := range index {
+		referenced[c.blobPath(e.Digest)] = true
+	}
+
+	root := filepath.Join(c.dir, "blobs")
+	_ = fs.Walk(c.fsys, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Base(path)[0] == '.' {
+			return nil
+		}
+		if referenced[path] {
+			return nil
+		}
+		if c.now().Sub(info.ModTime()) > blobGrace {
+			_ = c.fsys.Remove(path)
+		}
+		return nil
+	})
+}
+
+// update loads the index under the cache lock, applies fn and writes the
+// index back unless fn fails.
+func (c *Cache) update(fn func(index map[string]*Entry) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.fsys == fs.OS {
+		lock, err := lockfile.Acquire(context.Background(), filepath.Join(c.dir, ".lock"), lockfile.Options{Timeout: lockTimeout})
+		if err != nil {
+			return fmt.Errorf("failed to lock cache: %w", err)
+		}
+		defer lock.Release()
+	}
+
+	index := map[string]*Entry{}
+	indexPath := filepath.Join(c.dir, "index.json")
+	data, err := fs.ReadFile(c.fsys, indexPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &index); err != nil {
+			return fmt.Errorf("failed to decode cache index: %w", err)
+		}
+	}
+
+	if err := fn(index); err != nil {
+		return err
+	}
+
+	data, err = json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := indexPath + ".tmp"
+	if err := fs.WriteFile(c.fsys, tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return c.fsys.Rename(tmp, indexPath)
+}
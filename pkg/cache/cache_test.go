@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/fs"
+	"github.com/opencontainers/go-digest"
+)
+
+func newTestCache(t *testing.T, opts Options) (*Cache, *time.Time) {
+	t.Helper()
+	c, err := New(t.TempDir(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestPutGet(t *testing.T) {
+	c, _ := newTestCache(t, Options{})
+
+	entry, err := c.Put("chart/v1", strings.NewReader("content"), map[string]string{"source": "test"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if entry.Digest.String() != "sha256:ed7002b439e9ac845f22357d822bac1444730fbdb6016d3ec9432297b9ec9f73" || entry.Size != 7 {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+
+	data, got, err := c.Get("chart/v1")
+	if err != nil || string(data) != "content" || got.Metadata["source"] != "test" {
+		t.Errorf("Expected content with metadata, got: %q %+v %v", data, got, err)
+	}
+
+	if !c.HasBlob(entry.Digest) {
+		t.Error("Expected the blob to be addressable by digest")
+	}
+
+	if err := c.Delete("chart/v1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Get("chart/v1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	c, now := newTestCache(t, Options{TTL: time.Hour})
+
+	if _, err := c.Put("key", strings.NewReader("value"), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	*now = now.Add(30 * time.Minute)
+	if _, _, err := c.Get("key"); err != nil {
+		t.Errorf("Expected entry before TTL, got: %v", err)
+	}
+
+	*now = now.Add(time.Hour)
+	if _, _, err := c.Get("key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after TTL, got: %v", err)
+	}
+}
+
+func TestEviction(t *testing.T) {
+	c, now := newTestCache(t, Options{MaxSize: 10})
+
+	digests := map[string]digest.Digest{}
+	for _, key := range []string{"a", "b"} {
+		entry, err := c.Put(key, strings.NewReader(key+"1234"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digests[key] = entry.Digest
+		*now = now.Add(time.Second)
+	}
+
+	// Reading a makes b the least recently used entry.
+	if _, _, err := c.Get("a"); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(time.Second)
+
+	if _, err := c.Put("c", strings.NewReader("c1234"), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := c.Entries()
+	var keys []string
+	for _, e := range entries {
+		keys = append(keys, e.Key)
+	}
+	if strings.Join(keys, ",") != "a,c" {
+		t.Errorf("Expected b to be evicted, got: %v", keys)
+	}
+
+	*now = time.Now().Add(2 * blobGrace)
+	if err := c.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	if c.HasBlob(digests["b"]) || !c.HasBlob(digests["a"]) {
+		t.Error("Expected only the blob of the evicted entry to be removed")
+	}
+}
+
+func TestConcurrentPut(t *testing.T) {
+	c, _ := newTestCache(t, Options{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := c.Put(fmt.Sprintf("key-%d", i), strings.NewReader("shared"), nil); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	entries, err := c.Entries()
+	if err != nil || len(entries) != 20 {
+		t.Errorf("Expected 20 entries, got: %d %v", len(entries), err)
+	}
+}
+
+func TestMemFS(t *testing.T) {
+	memfs := fs.NewMemFS()
+	c, err := New("/cache", Options{FS: memfs, MaxSize: 10})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, err := c.Put("a", strings.NewReader("aaaaaa"), nil); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, err := c.Put("b", strings.NewReader("bbbbbb"), nil); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, _, err := c.Get("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a to be evicted, got: %v", err)
+	}
+	if data, _, err := c.Get("b"); err != nil || string(data) != "bbbbbb" {
+		t.Errorf("Expected b from memory, got: %q %v", data, err)
+	}
+	if _, err := memfs.Stat("/cache/index.json"); err != nil {
+		t.Errorf("Expected the index in the memory file system, got: %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package checksum
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/pool"
+	"github.com/eunanio/sdk/pkg/signing"
+)
+
+// DefaultFile is the conventional name of a checksum manifest.
+const DefaultFile = "SHA256SUMS"
+
+type Sum struct {
+	Name string
+	Hex  string
+}
+
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusMismatch Status = "mismatch"
+	StatusMissing  Status = "missing"
+)
+
+type FileResult struct {
+	Name     string `json:"name"`
+	Status   Status `json:"status"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+type Report struct {
+	Files []FileResult `json:"files"`
+}
+
+func (r Report) Passed() bool {
+	for _, file := range r.Files {
+		if file.Status != StatusOK {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r Report) Print(w io.Writer) {
+	for _, file := range r.Files {
+		switch file.Status {
+		case StatusMismatch:
+			fmt.Fprintf(w, "%s: FAILED (expected %s, got %s)\n", file.Name, file.Expected, file.Actual)
+		case StatusMissing:
+			fmt.Fprintf(w, "%s: FAILED (missing)\n", file.Name)
+		default:
+			fmt.Fprintf(w, "%s: OK\n", file.Name)
+		}
+	}
+}
+
+// Generate returns a sha256sum compatible manifest for files, given
+// relative to dir. With no files every regular file under dir is included
+// except existing manifests and signatures.
+func Generate(dir string, files ...string) ([]byte, error) {
+	if len(files) == 0 {
+		var err error
+		if files, err = listFiles(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(files)
+	sums, err := pool.Map(context.Background(), files, runtime.NumCPU(), func(ctx context.Context, name string) (string, error) {
+		sum, err := fileSum(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return "", fmt.Errorf("failed to checksum %s: %w", name, err)
+		}
+		return sum, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for i, name := range files {
+		fmt.Fprintf(&buf, "%s  %s\n", sums[i], filepath.ToSlash(name))
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteFile generates a manifest for files in dir and writes it to
+// dir/sumsFile.
+func WriteFile(dir, sumsFile string, files ...string) error {
+	data, err := Generate(dir, files...)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, sumsFile), data, 0644)
+}
+
+// Parse reads sha256sum output, accepting both the text ("  ") and binary
+// (" *") separators.
+func Parse(data []byte) ([]Sum, error) {
+	var sums []Sum
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		sum, name, ok := strings.Cut(text, " ")
+		name = strings.TrimPrefix(strings.TrimPrefix(name, " "), "*")
+		if !ok || len(sum) != sha256.Size*2 || name == "" {
+			return nil, log.Errorf(log.CodeInvalidArgument, "checksum_parse", "invalid checksum line %d: %q", line, text)
+		}
+		if _, err := hex.DecodeString(sum); err != nil {
+			return nil, log.Errorf(log.CodeInvalidArgument, "checksum_parse", "invalid checksum line %d: %q", line, text)
+		}
+
+		sums = append(sums, Sum{Name: name, Hex: strings.ToLower(sum)})
+	}
+
+	return sums, scanner.Err()
+}
+
+// Verify checks every file listed in dir/sumsFile and reports each result.
+// The returned error is non-nil when the manifest cannot be read or any
+// file is missing or does not match.
+func Verify(dir, sumsFile string) (Report, error) {
+	data, err := os.ReadFile(filepath.Join(dir, sumsFile))
+	if err != nil {
+		return Report{}, err
+	}
+
+	sums, err := Parse(data)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{}
+	failed := 0
+	for _, sum := range sums {
+		result := FileResult{Name: sum.Name, Expected: sum.Hex, Status: StatusOK}
+
+		actual, err := fileSum(filepath.Join(dir, filepath.FromSlash(sum.Name)))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			result.Status = StatusMissing
+		case err != nil:
+			return report, fmt.Errorf("failed to checksum %s: %w", sum.Name, err)
+		case actual != sum.Hex:
+			result.Status = StatusMismatch
+			result.Actual = actual
+		}
+
+		if result.Status != StatusOK {
+			failed++
+		}
+		report.Files = append(report.Files, result)
+	}
+
+	if failed > 0 {
+		return report, log.Errorf(log.CodeInvalidArgument, "checksum_verify", "%d of %d files failed verification", failed, len(report.Files))
+	}
+
+	return report, nil
+}
+
+// Sign writes a detached minisign signature of sumsFile to
+// sumsFile.minisig with signing.SignFile.
+func Sign(sumsFile string, key *signing.PrivateKey) error {
+	return signing.SignFile(sumsFile, key)
+}
+
+// VerifySignature checks sumsFile against its detached minisign signature.
+// Call it before Verify so a tampered manifest is never trusted.
+func VerifySignature(sumsFile string, key *signing.PublicKey) error {
+	_, err := signing.VerifyFile(sumsFile, key)
+	return err
+}
+
+func fileSum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func listFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+
+		name := d.Name()
+		if strings.HasSuffix(name, "SUMS") || strings.HasSuffix(name, signing.SignatureExt) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+
+	return files, err
+}
@@ -0,0 +1,124 @@
+package checksum
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/signing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"b.txt": "b", "sub/a.txt": "a", "SHA256SUMS": "old"})
+
+	data, err := Generate(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := "3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d  b.txt\n" +
+		"ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb  sub/a.txt\n"
+	if string(data) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expected    []Sum
+		expectError bool
+	}{
+		{
+			name:     "Text and binary mode",
+			data:     "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb  a.txt\r\n3E23E8160039594A33894F6564E1B1348BBD7A0088D42C4ACB73EEAED59C009D *b.bin\n",
+			expected: []Sum{{Name: "a.txt", Hex: "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"}, {Name: "b.bin", Hex: "3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d"}},
+		},
+		{name: "Short sum", data: "abc  a.txt\n", expectError: true},
+		{name: "Missing name", data: "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb\n", expectError: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			sums, err := Parse([]byte(tt.data))
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+			if len(sums) != len(tt.expected) {
+				t.Fatalf("Expected %v, got: %v", tt.expected, sums)
+			}
+			for i := range sums {
+				if sums[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got: %v", tt.expected[i], sums[i])
+				}
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"ok.txt": "ok", "changed.txt": "before", "removed.txt": "gone"})
+	if err := WriteFile(dir, DefaultFile); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, dir, map[string]string{"changed.txt": "after"})
+	os.Remove(filepath.Join(dir, "removed.txt"))
+
+	report, err := Verify(dir, DefaultFile)
+	if !log.IsCode(err, log.CodeInvalidArgument) {
+		t.Fatalf("Expected %s error, got: %v", log.CodeInvalidArgument, err)
+	}
+	if report.Passed() {
+		t.Error("Expected the report to fail")
+	}
+
+	expected := map[string]Status{"changed.txt": StatusMismatch, "ok.txt": StatusOK, "removed.txt": StatusMissing}
+	for _, file := range report.Files {
+		if file.Status != expected[file.Name] {
+			t.Errorf("Expected %s to be %s, got: %s", file.Name, expected[file.Name], file.Status)
+		}
+	}
+}
+
+func TestSignature(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"artifact.tgz": "data"})
+	sums := filepath.Join(dir, DefaultFile)
+	if err := WriteFile(dir, DefaultFile); err != nil {
+		t.Fatal(err)
+	}
+
+	public, private, err := signing.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Sign(sums, private); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySignature(sums, public); err != nil {
+		t.Errorf("Expected a valid signature, got: %v", err)
+	}
+
+	writeFiles(t, dir, map[string]string{DefaultFile: "tampered"})
+	if err := VerifySignature(sums, public); !log.IsCode(err, log.CodeUnauthorized) {
+		t.Errorf("Expected %s error, got: %v", log.CodeUnauthorized, err)
+	}
+}
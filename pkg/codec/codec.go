@@ -0,0 +1,211 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+var ErrUnknown = errors.New("unknown compression codec")
+
+// Codec compresses and decompresses streams. Name is also the media type
+// suffix, as in "application/vnd.oci.image.layer.v1.tar+gzip".
+type Codec interface {
+	Name() string
+	// Magic is the prefix of every compressed stream, used by Detect. It is
+	// empty for None.
+	Magic() []byte
+	NewReader(r io.Reader) (io.ReadCloser, error)
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+var (
+	mu     sync.RWMutex
+	codecs []Codec
+)
+
+func init() {
+	Register(Gzip)
+	Register(Zstd)
+	Register(Xz)
+}
+
+// Register adds c, replacing a registered codec with the same name.
+func Register(c Codec) {
+	mu.Lock()
+	defer mu.Unlock()
+	for i, existing := range codecs {
+		if existing.Name() == c.Name() {
+			codecs[i] = c
+			return
+		}
+	}
+	codecs = append(codecs, c)
+}
+
+// Get returns the codec registered as name. "" and "none" return None.
+func Get(name string) (Codec, error) {
+	if name == "" || name == None.Name() {
+		return None, nil
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, c := range codecs {
+		if c.Name() == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknown, name)
+}
+
+// dockerLayerTypes names the codec of the Docker image layer media types,
+// which predate the "+suffix" convention.
+var dockerLayerTypes = map[string]string{
+	"application/vnd.docker.image.rootfs.diff.tar.gzip":         "gzip",
+	"application/vnd.docker.image.rootfs.foreign.diff.tar.gzip": "gzip",
+	"application/vnd.docker.image.rootfs.diff.tar.zstd":         "zstd",
+}
+
+// ForMediaType returns the codec named by the "+suffix" of mediaType, or by
+// a Docker layer type such as "application/vnd.docker.image.rootfs.diff.tar.gzip".
+// Other types without a suffix, e.g. "application/vnd.oci.image.layer.v1.tar",
+// return None.
+func ForMediaType(mediaType string) (Codec, error) {
+	if name, ok := dockerLayerTypes[mediaType]; ok {
+		return Get(name)
+	}
+	_, suffix, ok := strings.Cut(mediaType, "+")
+	if !ok {
+		return None, nil
+	}
+	return Get(suffix)
+}
+
+// MediaType appends the suffix of c to base.
+func MediaType(base string, c Codec) string {
+	if c == nil || c.Name() == None.Name() {
+		return base
+	}
+	return base + "+" + c.Name()
+}
+
+// maxMagic is the longest prefix Detect reads.
+const maxMagic = 8
+
+// Detect identifies the codec of r from its leading bytes and returns a
+// reader that still yields them. Unrecognized streams are reported as None.
+func Detect(r io.Reader) (Codec, io.Reader, error) {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	head, err := br.Peek(maxMagic)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, err
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, c := range codecs {
+		if magic := c.Magic(); len(magic) > 0 && bytes.HasPrefix(head, magic) {
+			return c, br, nil
+		}
+	}
+	return None, br, nil
+}
+
+// NewReader decompresses r with the codec Detect finds, passing
+// uncompressed streams through.
+func NewReader(r io.Reader) (io.ReadCloser, error) {
+	c, br, err := Detect(r)
+	if err != nil {
+		return nil, err
+	}
+	return c.NewReader(br)
+}
+
+type gzipCodec struct{}
+
+// Gzip is the default codec for archives and layers.
+var Gzip Codec = gzipCodec{}
+
+func (gzipCodec) Name() string  { return "gzip" }
+func (gzipCodec) Magic() []byte { return []byte{0x1f, 0x8b} }
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+func (gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+type zstdCodec struct{}
+
+// Zstd is the codec of "+zstd" layers.
+var Zstd Codec = zstdCodec{}
+
+func (zstdCodec) Name() string  { return "zstd" }
+func (zstdCodec) Magic() []byte { return []byte{0x28, 0xb5, 0x2f, 0xfd} }
+
+func (zstdCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
+
+func (zstdCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w)
+}
+
+type xzCodec struct{}
+
+// Xz is the codec of .tar.xz archives.
+var Xz Codec = xzCodec{}
+
+func (xzCodec) Name() string  { return "xz" }
+func (xzCodec) Magic() []byte { return []byte{0xfd, '7', 'z', 'X', 'Z', 0x00} }
+
+func (xzCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	x, err := xz.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(x), nil
+}
+
+func (xzCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return xz.NewWriter(w)
+}
+
+type noneCodec struct{}
+
+// None passes data through unchanged.
+var None Codec = noneCodec{}
+
+func (noneCodec) Name() string  { return "none" }
+func (noneCodec) Magic() []byte { return nil }
+
+func (noneCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(r), nil
+}
+
+func (noneCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return nopWriteCloser{w}, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,156 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	var gzipped bytes.Buffer
+	w, _ := Gzip.NewWriter(&gzipped)
+	w.Write([]byte("hello"))
+	w.Close()
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{name: "Gzip", data: gzipped.Bytes(), expected: "gzip"},
+		{name: "Zstd", data: []byte{0x28, 0xb5, 0x2f, 0xfd, 0x00, 0x58}, expected: "zstd"},
+		{name: "Xz", data: []byte{0xfd, '7', 'z', 'X', 'Z', 0x00, 0x00, 0x04}, expected: "xz"},
+		{name: "Plain", data: []byte("plain text"), expected: "none"},
+		{name: "Short", data: []byte{0x1f}, expected: "none"},
+		{name: "Empty", data: nil, expected: "none"},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			c, r, err := Detect(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if c.Name() != tt.expected {
+				t.Errorf("Expected %s, got: %s", tt.expected, c.Name())
+			}
+			if rest, _ := io.ReadAll(r); !bytes.Equal(rest, tt.data) {
+				t.Errorf("Expected the reader to keep the peeked bytes, got: %v", rest)
+			}
+		})
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	var gzipped bytes.Buffer
+	w, _ := Gzip.NewWriter(&gzipped)
+	w.Write([]byte("hello"))
+	w.Close()
+
+	for _, data := range [][]byte{gzipped.Bytes(), []byte("hello")} {
+		r, err := NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if got, _ := io.ReadAll(r); string(got) != "hello" {
+			t.Errorf("Expected hello, got: %q", got)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, c := range []Codec{Gzip, Zstd, Xz, None} {
+		c := c // capture range variable
+		t.Run(c.Name(), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := c.NewWriter(&buf)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			w.Write([]byte("hello"))
+			if err := w.Close(); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			detected, _, _ := Detect(bytes.NewReader(buf.Bytes()))
+			if detected.Name() != c.Name() {
+				t.Errorf("Expected %s to be detected, got: %s", c.Name(), detected.Name())
+			}
+
+			r, err := NewReader(&buf)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			defer r.Close()
+			if got, _ := io.ReadAll(r); string(got) != "hello" {
+				t.Errorf("Expected hello, got: %q", got)
+			}
+		})
+	}
+}
+
+func TestForMediaType(t *testing.T) {
+	tests := []struct {
+		mediaType   string
+		expected    string
+		expectedErr error
+	}{
+		{mediaType: "application/vnd.oci.image.layer.v1.tar+gzip", expected: "gzip"},
+		{mediaType: "application/vnd.oci.image.layer.v1.tar+zstd", expected: "zstd"},
+		{mediaType: "application/vnd.oci.image.layer.v1.tar", expected: "none"},
+		{mediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip", expected: "gzip"},
+		{mediaType: "application/vnd.docker.image.rootfs.foreign.diff.tar.gzip", expected: "gzip"},
+		{mediaType: "application/vnd.oci.image.layer.v1.tar+lz4", expectedErr: ErrUnknown},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.mediaType, func(t *testing.T) {
+			c, err := ForMediaType(tt.mediaType)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected %v, got: %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil || c.Name() != tt.expected {
+				t.Fatalf("Expected %s, got: %v %v", tt.expected, c, err)
+			}
+			if strings.Contains(tt.mediaType, "+") && MediaType("application/vnd.oci.image.layer.v1.tar", c) != tt.mediaType {
+				t.Errorf("Expected MediaType to round trip %s", tt.mediaType)
+			}
+		})
+	}
+}
+
+// lowercase is a toy codec standing in for a third-party implementation.
+type lowercase struct{}
+
+func (lowercase) Name() string  { return "zstd" }
+func (lowercase) Magic() []byte { return []byte{0x28, 0xb5, 0x2f, 0xfd} }
+
+func (lowercase) NewReader(r io.Reader) (io.ReadCloser, error) {
+	data, err := io.ReadAll(r)
+	return io.NopCloser(strings.NewReader(strings.ToLower(string(data[4:])))), err
+}
+
+func (lowercase) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return nil, nil
+}
+
+func TestRegister(t *testing.T) {
+	previous, _ := Get("zstd")
+	defer Register(previous)
+
+	Register(lowercase{})
+	r, err := NewReader(bytes.NewReader(append([]byte{0x28, 0xb5, 0x2f, 0xfd}, "HELLO"...)))
+	if err != nil {
+		t.Fatalf("Expected the registered codec to replace the built-in one, got: %v", err)
+	}
+	if got, _ := io.ReadAll(r); string(got) != "hello" {
+		t.Errorf("Expected hello, got: %q", got)
+	}
+}
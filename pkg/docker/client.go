@@ -0,0 +1,291 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/fs"
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+// Client talks to the Docker Engine API, which Podman and other compatible
+// daemons also serve.
+type Client struct {
+	http    *http.Client
+	baseURL string
+}
+
+type Image struct {
+	ID          string   `json:"Id"`
+	RepoTags    []string `json:"RepoTags"`
+	RepoDigests []string `json:"RepoDigests"`
+	Size        int64    `json:"Size"`
+	Created     int64    `json:"Created"`
+}
+
+type BuildOptions struct {
+	// ContextDir is sent to the daemon as the build context. .dockerignore
+	// is not applied.
+	ContextDir string
+	// Dockerfile is relative to ContextDir, defaulting to "Dockerfile".
+	Dockerfile string
+	Tags       []string
+	BuildArgs  map[string]string
+	Platform   string
+	NoCache    bool
+	// Output receives the build log.
+	Output io.Writer
+}
+
+// NewClient connects to DOCKER_HOST, or the platform's default daemon socket
+// when it is unset.
+func NewClient() (*Client, error) {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = defaultHost
+	}
+
+	return NewClientWithHost(host)
+}
+
+// NewClientWithHost connects to a unix://, npipe://, tcp:// or http(s)://
+// daemon address.
+func NewClientWithHost(host string) (*Client, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, log.Errorf(log.CodeInvalidArgument, "docker_client", "invalid docker host %s: %s", host, err)
+	}
+
+	transport := &http.Transport{}
+	baseURL := "http://docker"
+
+	switch u.Scheme {
+	case "unix":
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", u.Path)
+		}
+	case "npipe":
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialPipe(ctx, u.Path)
+		}
+	case "tcp", "http":
+		baseURL = "http://" + u.Host
+	case "https":
+		baseURL = "https://" + u.Host
+	default:
+		return nil, log.Errorf(log.CodeInvalidArgument, "docker_client", "unsupported docker host %s", host)
+	}
+
+	return &Client{http: &http.Client{Transport: transport}, baseURL: baseURL}, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body io.Reader, header http.Header) (*http.Response, error) {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, log.Errorf(log.CodeRemote, "docker", "cannot connect to the docker daemon: %s", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, apiError(path, resp)
+	}
+
+	return resp, nil
+}
+
+func apiError(path string, resp *http.Response) error {
+	var msg struct {
+		Message string `json:"message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if json.Unmarshal(data, &msg) != nil || msg.Message == "" {
+		msg.Message = strings.TrimSpace(string(data))
+	}
+
+	code := log.CodeRemote
+	if resp.StatusCode == http.StatusNotFound {
+		code = log.CodeNotFound
+	}
+
+	return log.Errorf(code, "docker", "%s returned %d: %s", path, resp.StatusCode, msg.Message)
+}
+
+func (c *Client) Ping(ctx context.Context) error {
+	resp, err := c.do(ctx, http.MethodGet, "/_ping", nil, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// Images lists local images with their tags and registry digests.
+func (c *Client) Images(ctx context.Context) ([]Image, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/images/json", nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var images []Image
+	if err := json.NewDecoder(resp.Body).Decode(&images); err != nil {
+		return nil, fmt.Errorf("failed to decode image list: %w", err)
+	}
+
+	return images, nil
+}
+
+// Build builds an image and returns its ID.
+func (c *Client) Build(ctx context.Context, opts BuildOptions) (string, error) {
+	defer log.Timed("docker_build", "context", opts.ContextDir)()
+	buildContext, err := fs.CompressDir(opts.ContextDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to archive build context: %w", err)
+	}
+
+	query := url.Values{}
+	for _, tag := range opts.Tags {
+		query.Add("t", tag)
+	}
+	if opts.Dockerfile != "" {
+		query.Set("dockerfile", opts.Dockerfile)
+	}
+	if opts.Platform != "" {
+		query.Set("platform", opts.Platform)
+	}
+	if opts.NoCache {
+		query.Set("nocache", "1")
+	}
+	if len(opts.BuildArgs) > 0 {
+		args, err := json.Marshal(opts.BuildArgs)
+		if err != nil {
+			return "", err
+		}
+		query.Set("buildargs", string(args))
+	}
+
+	header := http.Header{"Content-Type": {"application/x-tar"}}
+	resp, err := c.do(ctx, http.MethodPost, "/build", query, bytes.NewReader(buildContext), header)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	return readBuildStream(resp.Body, opts.Output)
+}
+
+// readBuildStream follows the JSON messages of a build, copying the log to
+// out and returning the image ID from the final aux message.
+func readBuildStream(r io.Reader, out io.Writer) (string, error) {
+	if out == nil {
+		out = io.Discard
+	}
+
+	var id string
+	decoder := json.NewDecoder(r)
+	for {
+		var msg struct {
+			Stream string `json:"stream"`
+			Error  string `json:"error"`
+			Aux    struct {
+				ID string `json:"ID"`
+			} `json:"aux"`
+		}
+		if err := decoder.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			return "", fmt.Errorf("failed to read build output: %w", err)
+		}
+
+		if msg.Error != "" {
+			return "", log.Errorf(log.CodeRemote, "docker_build", "build failed: %s", msg.Error)
+		}
+		if msg.Stream != "" {
+			fmt.Fprint(out, msg.Stream)
+		}
+		if msg.Aux.ID != "" {
+			id = msg.Aux.ID
+		}
+	}
+
+	if id == "" {
+		return "", log.Errorf(log.CodeRemote, "docker_build", "build finished without an image ID")
+	}
+
+	return id, nil
+}
+
+// Save writes a tarball of images to w, as docker save does.
+func (c *Client) Save(ctx context.Context, w io.Writer, images ...string) error {
+	defer log.Timed("docker_save", "images", images)()
+	query := url.Values{"names": images}
+	resp, err := c.do(ctx, http.MethodGet, "/images/get", query, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to save images: %w", err)
+	}
+
+	return nil
+}
+
+// Load imports a tarball produced by Save and returns the loaded image
+// references.
+func (c *Client) Load(ctx context.Context, r io.Reader) ([]string, error) {
+	defer log.Timed("docker_load")()
+	header := http.Header{"Content-Type": {"application/x-tar"}}
+	resp, err := c.do(ctx, http.MethodPost, "/images/load", url.Values{"quiet": {"1"}}, r, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var loaded []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var msg struct {
+			Stream string `json:"stream"`
+			Error  string `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		if msg.Error != "" {
+			return nil, log.Errorf(log.CodeRemote, "docker_load", "load failed: %s", msg.Error)
+		}
+
+		for _, prefix := range []string{"Loaded image: ", "Loaded image ID: "} {
+			if ref, ok := strings.CutPrefix(strings.TrimSpace(msg.Stream), prefix); ok {
+				loaded = append(loaded, ref)
+			}
+		}
+	}
+
+	return loaded, scanner.Err()
+}
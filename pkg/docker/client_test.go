@@ -0,0 +1,118 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/log"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClientWithHost("tcp://" + strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestImages(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/images/json" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"page not found"}`))
+			return
+		}
+		w.Write([]byte(`[{"Id":"sha256:abc","RepoTags":["app:latest"],"RepoDigests":["registry.example.com/app@sha256:def"],"Size":42}]`))
+	})
+
+	images, err := client.Images(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(images) != 1 || images[0].ID != "sha256:abc" || images[0].RepoDigests[0] != "registry.example.com/app@sha256:def" {
+		t.Errorf("Unexpected images: %+v", images)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name         string
+		stream       string
+		expectedID   string
+		expectedCode log.Code
+	}{
+		{
+			name:       "Successful build",
+			stream:     `{"stream":"Step 1/1 : FROM scratch\n"}` + "\n" + `{"aux":{"ID":"sha256:abc"}}` + "\n" + `{"stream":"Successfully built abc\n"}`,
+			expectedID: "sha256:abc",
+		},
+		{
+			name:         "Build error",
+			stream:       `{"stream":"Step 1/1 : RUN false\n"}` + "\n" + `{"error":"The command '/bin/sh -c false' returned a non-zero code: 1"}`,
+			expectedCode: log.CodeRemote,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("t") != "app:latest" || r.Header.Get("Content-Type") != "application/x-tar" {
+					t.Errorf("Unexpected build request: %s", r.URL)
+				}
+				w.Write([]byte(tt.stream))
+			})
+
+			var out bytes.Buffer
+			id, err := client.Build(context.Background(), BuildOptions{ContextDir: t.TempDir(), Tags: []string{"app:latest"}, Output: &out})
+			if tt.expectedCode != "" {
+				if !log.IsCode(err, tt.expectedCode) {
+					t.Fatalf("Expected %s error, got: %v", tt.expectedCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if id != tt.expectedID {
+				t.Errorf("Expected ID %s, got: %s", tt.expectedID, id)
+			}
+			if !strings.Contains(out.String(), "Step 1/1") {
+				t.Errorf("Expected build output, got: %q", out.String())
+			}
+		})
+	}
+}
+
+func TestImageFromDockerArchive(t *testing.T) {
+	files := map[string][]byte{
+		"manifest.json": []byte(`[{"Config":"config.json","RepoTags":["app:latest"],"Layers":["abc/layer.tar"]}]`),
+		"config.json":   []byte(`{"architecture":"amd64"}`),
+		"abc/layer.tar": []byte("layer"),
+		"repositories":  []byte(`{}`),
+	}
+
+	manifest, blobs, err := imageFromArchive(files)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if manifest.Config.MediaType != spec.MediaTypeImageConfig || len(manifest.Layers) != 1 {
+		t.Fatalf("Unexpected manifest: %+v", manifest)
+	}
+	if string(blobs[manifest.Layers[0].Digest]) != "layer" {
+		t.Errorf("Expected layer blob, got: %q", blobs[manifest.Layers[0].Digest])
+	}
+	if manifest.Layers[0].Digest.Validate() != nil || manifest.Layers[0].Size != 5 {
+		t.Errorf("Unexpected layer descriptor: %+v", manifest.Layers[0])
+	}
+}
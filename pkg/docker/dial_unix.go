@@ -0,0 +1,15 @@
+//go:build !windows
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+const defaultHost = "unix:///var/run/docker.sock"
+
+func dialPipe(ctx context.Context, path string) (net.Conn, error) {
+	return nil, fmt.Errorf("named pipes are only supported on windows")
+}
@@ -0,0 +1,226 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/oci"
+	"github.com/eunanio/sdk/pkg/pool"
+	"github.com/eunanio/sdk/pkg/validate"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const maxParallelPushes = 4
+
+type PushOptions struct {
+	// Image is the local image name or ID.
+	Image    string `validate:"required"`
+	Tag      oci.Tag
+	Insecure bool
+}
+
+// Push exports a local image with Save and uploads its config, layers and
+// manifest through registry, so locally built images can join the OCI push
+// pipeline. Both the OCI layout written by Docker 25 and later and the older
+// docker save format are supported.
+func (c *Client) Push(ctx context.Context, registry *oci.OciClient, opts PushOptions) (*spec.Manifest, error) {
+	defer log.Timed("docker_push", "image", opts.Image, "tag", opts.Tag.String())()
+	if err := validate.Struct(opts); err != nil {
+		return nil, err
+	}
+
+	archive, err := os.CreateTemp("", "devkit-image-*.tar")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	if err := c.Save(ctx, archive, opts.Image); err != nil {
+		return nil, err
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	files, err := readArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, blobs, err := imageFromArchive(files)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptors := append([]spec.Descriptor{manifest.Config}, manifest.Layers...)
+	err = pool.ForEach(ctx, descriptors, maxParallelPushes, func(ctx context.Context, desc spec.Descriptor) error {
+		err := registry.PushBlob(oci.PushBlobOptions{
+			Digest:   desc,
+			File:     blobs[desc.Digest],
+			Name:     opts.Tag.Name,
+			Insecure: opts.Insecure,
+			Tag:      opts.Tag,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to push blob %s: %w", desc.Digest, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = registry.PushManifest(oci.PushManifestOptions{
+		Manifest: manifest,
+		Tag:      &opts.Tag,
+		Insecure: opts.Insecure,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func readArchive(r io.Reader) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image archive: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from image archive: %w", header.Name, err)
+		}
+		files[path.Clean(header.Name)] = data
+	}
+}
+
+// imageFromArchive returns the image manifest and its blobs by digest.
+func imageFromArchive(files map[string][]byte) (*spec.Manifest, map[digest.Digest][]byte, error) {
+	if _, ok := files[spec.ImageLayoutFile]; ok {
+		return imageFromLayout(files)
+	}
+
+	return imageFromDockerArchive(files)
+}
+
+func imageFromLayout(files map[string][]byte) (*spec.Manifest, map[digest.Digest][]byte, error) {
+	blob := func(d digest.Digest) ([]byte, error) {
+		data, ok := files[path.Join("blobs", d.Algorithm().String(), d.Encoded())]
+		if !ok {
+			return nil, fmt.Errorf("blob %s missing from image archive", d)
+		}
+		return data, nil
+	}
+
+	var index spec.Index
+	if err := json.Unmarshal(files["index.json"], &index); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode index.json: %w", err)
+	}
+
+	// docker save wraps the image manifest, or a platform index, in a
+	// single-entry index.
+	for len(index.Manifests) > 0 && index.Manifests[0].MediaType == spec.MediaTypeImageIndex {
+		data, err := blob(index.Manifests[0].Digest)
+		if err != nil {
+			return nil, nil, err
+		}
+		index = spec.Index{}
+		if err := json.Unmarshal(data, &index); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode image index: %w", err)
+		}
+	}
+	if len(index.Manifests) == 0 {
+		return nil, nil, fmt.Errorf("image archive contains no manifests")
+	}
+
+	data, err := blob(index.Manifests[0].Digest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manifest := &spec.Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode image manifest: %w", err)
+	}
+
+	blobs := map[digest.Digest][]byte{}
+	for _, desc := range append([]spec.Descriptor{manifest.Config}, manifest.Layers...) {
+		if blobs[desc.Digest], err = blob(desc.Digest); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return manifest, blobs, nil
+}
+
+// imageFromDockerArchive builds an OCI manifest from the manifest.json of
+// the legacy docker save format, whose layers are tarballs, usually
+// uncompressed.
+func imageFromDockerArchive(files map[string][]byte) (*spec.Manifest, map[digest.Digest][]byte, error) {
+	var entries []struct {
+		Config string
+		Layers []string
+	}
+	if err := json.Unmarshal(files["manifest.json"], &entries); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode manifest.json: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil, fmt.Errorf("image archive contains no images")
+	}
+
+	blobs := map[digest.Digest][]byte{}
+	describe := func(name, mediaType string) (spec.Descriptor, error) {
+		data, ok := files[path.Clean(name)]
+		if !ok {
+			return spec.Descriptor{}, fmt.Errorf("%s missing from image archive", name)
+		}
+
+		sum := sha256.Sum256(data)
+		d := digest.NewDigestFromBytes(digest.SHA256, sum[:])
+		blobs[d] = data
+		return spec.Descriptor{MediaType: mediaType, Digest: d, Size: int64(len(data))}, nil
+	}
+
+	config, err := describe(entries[0].Config, spec.MediaTypeImageConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manifest := &spec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: spec.MediaTypeImageManifest,
+		Config:    config,
+	}
+	for _, layer := range entries[0].Layers {
+		desc, err := describe(layer, spec.MediaTypeImageLayer)
+		if err != nil {
+			return nil, nil, err
+		}
+		desc.MediaType = oci.LayerMediaType(desc.MediaType, blobs[desc.Digest])
+		manifest.Layers = append(manifest.Layers, desc)
+	}
+
+	return manifest, blobs, nil
+}
@@ -0,0 +1,398 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/eunanio/sdk/pkg/fs"
+	"github.com/eunanio/sdk/pkg/httpx"
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/opencontainers/go-digest"
+	"golang.org/x/sync/errgroup"
+)
+
+// ProgressFunc is called as bytes arrive. total is -1 when the server does
+// not report a size.
+type ProgressFunc func(downloaded, total int64)
+
+type Options struct {
+	// Client defaults to httpx.New().
+	Client *httpx.Client
+	// Digest, such as "sha256:...", is verified once the download
+	// completes. A mismatch removes the file.
+	Digest digest.Digest
+	// Segments downloads the file in this many parallel ranges when the
+	// server supports them.
+	Segments int
+	Progress ProgressFunc
+	// ExtractTo unpacks a .tar.gz or .tgz download into this directory
+	// with fs.DecompressDir.
+	ExtractTo string
+}
+
+type Result struct {
+	Path    string
+	Size    int64
+	Digest  digest.Digest
+	Resumed bool
+}
+
+var ErrDigestMismatch = errors.New("digest mismatch")
+
+// File downloads url to dst. Data is written to dst.part first, so an
+// interrupted download is resumed with a range request on the next call.
+// A download is only resumed when the server identified the file with an
+// ETag or Last-Modified date, which guards the range request with If-Range
+// so a changed file is downloaded again from the start.
+func File(ctx context.Context, url, dst string, opts Options) (*Result, error) {
+	defer log.Timed("download", "url", url)()
+	algorithm := digest.Canonical
+	if opts.Digest != "" {
+		if err := opts.Digest.Validate(); err != nil {
+			return nil, log.NewError(log.CodeInvalidArgument, "download", err)
+		}
+		algorithm = opts.Digest.Algorithm()
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = httpx.New()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return nil, err
+	}
+
+	part := dst + ".part"
+	progress := newProgress(opts.Progress)
+
+	result := &Result{Path: dst}
+	offset, validator := partState(part)
+	var err error
+	if offset == 0 && opts.Segments > 1 {
+		result.Size, err = fetchSegments(ctx, client, url, part, opts.Segments, progress)
+		if errors.Is(err, errRangesUnsupported) {
+			result.Size, result.Resumed, err = fetch(ctx, client, url, part, 0, "", progress)
+		}
+	} else {
+		result.Size, result.Resumed, err = fetch(ctx, client, url, part, offset, validator, progress)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Digest, err = fileDigest(part, algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Digest != "" && result.Digest != opts.Digest {
+		os.Remove(part)
+		os.Remove(part + ".etag")
+		return nil, log.NewError(log.CodeInvalidArgument, "download", fmt.Errorf("%w for %s: expected %s, got %s", ErrDigestMismatch, url, opts.Digest, result.Digest))
+	}
+
+	if err := os.Rename(part, dst); err != nil {
+		return nil, err
+	}
+	os.Remove(part + ".etag")
+
+	if opts.ExtractTo != "" {
+		if err := extract(dst, opts.ExtractTo); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// partState returns the size of a partial download and the validator
+// recorded for it. A partial file without one cannot be resumed safely, so
+// its size is reported as 0.
+func partState(path string) (int64, string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, ""
+	}
+
+	validator, err := os.ReadFile(path + ".etag")
+	if err != nil || len(validator) == 0 {
+		return 0, ""
+	}
+
+	return info.Size(), string(validator)
+}
+
+// validatorOf returns the value to send in If-Range for resp: its strong
+// ETag, or its Last-Modified date when there is none.
+func validatorOf(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+
+	return resp.Header.Get("Last-Modified")
+}
+
+// completeSize returns the resource size in the Content-Range of a 416
+// response, "bytes */size".
+func completeSize(resp *http.Response) (int64, bool) {
+	size, ok := strings.CutPrefix(resp.Header.Get("Content-Range"), "bytes */")
+	if !ok {
+		return 0, false
+	}
+
+	n, err := strconv.ParseInt(size, 10, 64)
+	return n, err == nil
+}
+
+// fetch streams url into path starting at offset, resuming only if the
+// file still matches validator. A server that ignores the range request, or
+// reports the file changed, restarts the download from the beginning.
+func fetch(ctx context.Context, client *httpx.Client, url, path string, offset int64, validator string, progress *progress) (int64, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", validator)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	resumed := false
+	switch {
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+		flags |= os.O_APPEND
+		resumed = true
+	case offset > 0 && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		if size, ok := completeSize(resp); ok && size == offset {
+			// The partial file already holds the whole body.
+			progress.start(offset, offset)
+			return offset, true, nil
+		}
+		// The partial file does not fit the resource, so start over.
+		io.Copy(io.Discard, resp.Body)
+		return fetch(ctx, client, url, path, 0, "", progress)
+	case resp.StatusCode == http.StatusOK:
+		flags |= os.O_TRUNC
+		offset = 0
+		if err := os.WriteFile(path+".etag", []byte(validatorOf(resp)), 0644); err != nil {
+			return 0, false, err
+		}
+	default:
+		return 0, false, statusError(url, resp)
+	}
+
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	progress.start(offset, total)
+
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return 0, false, err
+	}
+	defer f.Close()
+
+	n, err := io.Copy(f, progress.reader(resp.Body))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+
+	return offset + n, resumed, f.Close()
+}
+
+var errRangesUnsupported = errors.New("server does not support range requests")
+
+// fetchSegments downloads count byte ranges concurrently into a temporary
+// file preallocated to the size reported by a HEAD request, and moves it to
+// path once every range has arrived. A crash never leaves a partial file
+// that looks complete.
+func fetchSegments(ctx context.Context, client *httpx.Client, url, path string, count int, progress *progress) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	resp.Body.Close()
+
+	size := resp.ContentLength
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Accept-Ranges") != "bytes" || size < int64(count) {
+		return 0, errRangesUnsupported
+	}
+	validator := validatorOf(resp)
+	progress.start(0, size)
+
+	tmp := path + ".segments"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	if err := f.Truncate(size); err != nil {
+		return 0, err
+	}
+
+	group, ctx := errgroup.WithContext(ctx)
+	chunk := size / int64(count)
+	for i := 0; i < count; i++ {
+		start := int64(i) * chunk
+		end := start + chunk - 1
+		if i == count-1 {
+			end = size - 1
+		}
+
+		group.Go(func() error {
+			return fetchRange(ctx, client, url, validator, f, start, end, progress)
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		// A partially written segmented file cannot be resumed.
+		f.Close()
+		os.Remove(tmp)
+		return 0, err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+
+	return size, os.Rename(tmp, path)
+}
+
+// fetchRange writes bytes start through end of url into f. With a
+// validator, a file that changed since the HEAD request fails the range
+// rather than mixing two versions.
+func fetchRange(ctx context.Context, client *httpx.Client, url, validator string, f *os.File, start, end int64, progress *progress) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", "bytes="+strconv.FormatInt(start, 10)+"-"+strconv.FormatInt(end, 10))
+	if validator != "" {
+		req.Header.Set("If-Range", validator)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return statusError(url, resp)
+	}
+
+	w := io.NewOffsetWriter(f, start)
+	n, err := io.Copy(w, progress.reader(io.LimitReader(resp.Body, end-start+1)))
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	if n != end-start+1 {
+		return fmt.Errorf("failed to download %s: short range %d-%d", url, start, end)
+	}
+
+	return nil
+}
+
+func statusError(url string, resp *http.Response) error {
+	code := log.CodeRemote
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		code = log.CodeNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		code = log.CodeUnauthorized
+	}
+
+	return log.Errorf(code, "download", "failed to download %s: %s", url, resp.Status)
+}
+
+func fileDigest(path string, algorithm digest.Algorithm) (digest.Digest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	return algorithm.FromReader(f)
+}
+
+func extract(path, dir string) error {
+	if !strings.HasSuffix(path, ".tar.gz") && !strings.HasSuffix(path, ".tgz") {
+		return log.Errorf(log.CodeInvalidArgument, "download", "cannot extract %s: only .tar.gz and .tgz archives are supported", filepath.Base(path))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return fs.DecompressDir(data, dir)
+}
+
+// progress aggregates bytes from concurrent segments for the callback.
+type progress struct {
+	fn         ProgressFunc
+	downloaded atomic.Int64
+	total      int64
+	mu         sync.Mutex
+}
+
+func newProgress(fn ProgressFunc) *progress {
+	return &progress{fn: fn}
+}
+
+func (p *progress) start(offset, total int64) {
+	p.downloaded.Store(offset)
+	p.total = total
+	if p.fn != nil {
+		p.fn(offset, total)
+	}
+}
+
+func (p *progress) reader(r io.Reader) io.Reader {
+	if p.fn == nil {
+		return r
+	}
+
+	return &progressReader{r: r, p: p}
+}
+
+type progressReader struct {
+	r io.Reader
+	p *progress
+}
+
+func (pr *progressReader) Read(b []byte) (int, error) {
+	n, err := pr.r.Read(b)
+	if n > 0 {
+		downloaded := pr.p.downloaded.Add(int64(n))
+		pr.p.mu.Lock()
+		pr.p.fn(downloaded, pr.p.total)
+		pr.p.mu.Unlock()
+	}
+
+	return n, err
+}
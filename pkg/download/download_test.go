@@ -0,0 +1,135 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/fs"
+	"github.com/eunanio/sdk/pkg/httpx"
+	"github.com/opencontainers/go-digest"
+)
+
+var content = []byte(strings.Repeat("0123456789", 1000))
+
+func newServer(t *testing.T, ranges *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			ranges.Add(1)
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		partial        []byte
+		etag           string
+		segments       int
+		digest         digest.Digest
+		expectResumed  bool
+		expectedRanges int32
+		expectError    error
+	}{
+		{name: "Single stream", digest: digest.FromBytes(content)},
+		{name: "Resume partial file", partial: content[:4000], etag: `"v1"`, expectResumed: true, expectedRanges: 1},
+		{name: "Partial file without validator", partial: content[:4000]},
+		{name: "Partial file of a changed file", partial: bytes.Repeat([]byte("x"), 4000), etag: `"v0"`, expectedRanges: 1, digest: digest.FromBytes(content)},
+		{name: "Complete partial file", partial: content, etag: `"v1"`, expectResumed: true, expectedRanges: 1},
+		{name: "Oversized partial file", partial: make([]byte, len(content)+10), etag: `"v1"`, expectedRanges: 1, digest: digest.FromBytes(content)},
+		{name: "Parallel segments", segments: 4, expectedRanges: 4, digest: digest.FromBytes(content)},
+		{name: "Digest mismatch", digest: digest.FromString("other"), expectError: ErrDigestMismatch},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			var ranges atomic.Int32
+			server := newServer(t, &ranges)
+			dst := filepath.Join(t.TempDir(), "tool")
+			if tt.partial != nil {
+				if err := os.WriteFile(dst+".part", tt.partial, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.etag != "" {
+				if err := os.WriteFile(dst+".part.etag", []byte(tt.etag), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var last int64
+			result, err := File(context.Background(), server.URL, dst, Options{
+				Client:   httpx.New(httpx.WithRetries(0)),
+				Digest:   tt.digest,
+				Segments: tt.segments,
+				Progress: func(downloaded, total int64) { last = downloaded },
+			})
+			if tt.expectError != nil {
+				if !errors.Is(err, tt.expectError) {
+					t.Fatalf("Expected %v, got: %v", tt.expectError, err)
+				}
+				if _, err := os.Stat(dst + ".part"); !os.IsNotExist(err) {
+					t.Error("Expected the partial file to be removed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			data, _ := os.ReadFile(dst)
+			if !bytes.Equal(data, content) {
+				t.Errorf("Expected downloaded content to match, got %d bytes", len(data))
+			}
+			if result.Resumed != tt.expectResumed || ranges.Load() != tt.expectedRanges {
+				t.Errorf("Expected resumed=%v with %d range requests, got: resumed=%v with %d", tt.expectResumed, tt.expectedRanges, result.Resumed, ranges.Load())
+			}
+			if last != int64(len(content)) {
+				t.Errorf("Expected final progress %d, got: %d", len(content), last)
+			}
+			if _, err := os.Stat(dst + ".part.etag"); !os.IsNotExist(err) {
+				t.Error("Expected the validator to be removed")
+			}
+		})
+	}
+}
+
+func TestFileExtract(t *testing.T) {
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "bin"), []byte("binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := fs.CompressDir(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "extracted")
+	if _, err := File(context.Background(), server.URL, filepath.Join(dir, "tool.tar.gz"), Options{ExtractTo: out}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(out, "bin")); err != nil || string(data) != "binary" {
+		t.Errorf("Expected extracted binary, got: %q %v", data, err)
+	}
+}
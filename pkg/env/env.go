@@ -0,0 +1,343 @@
+package env
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+// DefaultFiles are loaded by Load when no paths are given. Values in
+// .env.local override those in .env.
+var DefaultFiles = []string{".env", ".env.local"}
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+type line struct {
+	// text is the line as read, written back unchanged unless the entry is
+	// modified. It spans several lines for multi-line quoted values.
+	text string
+	key  string
+	// raw is the value as written, without its quotes; quote is ', " or 0.
+	raw    string
+	quote  byte
+	export bool
+}
+
+// File is a parsed .env file. Comments, blank lines and the formatting of
+// untouched entries are preserved when it is written back.
+type File struct {
+	lines []line
+}
+
+// Parse reads a .env file. Entries are KEY=VALUE lines, optionally prefixed
+// with "export". Values may be single quoted (literal), double quoted
+// (escapes and references, may span lines) or unquoted (trimmed, with " #"
+// starting a comment). ${VAR}, $VAR and ${VAR:-default} references are
+// expanded when values are read.
+func Parse(r io.Reader) (*File, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := parseLines(strings.ReplaceAll(string(data), "\r\n", "\n"))
+	if err != nil {
+		return nil, log.NewError(log.CodeInvalidArgument, "env_parse", err)
+	}
+
+	return &File{lines: lines}, nil
+}
+
+// Read parses the file at path.
+func Read(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	file, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return file, nil
+}
+
+func parseLines(data string) ([]line, error) {
+	var lines []line
+	lineNo := 1
+	for pos := 0; pos < len(data); {
+		start := pos
+		end := lineEnd(data, pos)
+		text := data[pos:end]
+
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			lines = append(lines, line{text: text})
+			pos = end + 1
+			lineNo++
+			continue
+		}
+
+		l := line{}
+		s := strings.TrimLeft(text, " \t")
+		if rest, ok := strings.CutPrefix(s, "export "); ok {
+			l.export = true
+			s = strings.TrimLeft(rest, " \t")
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNo)
+		}
+		l.key = strings.TrimSpace(s[:eq])
+		if !keyPattern.MatchString(l.key) {
+			return nil, fmt.Errorf("line %d: invalid key %q", lineNo, l.key)
+		}
+
+		valueStart := end - len(s) + eq + 1
+		for valueStart < end && (data[valueStart] == ' ' || data[valueStart] == '\t') {
+			valueStart++
+		}
+
+		if valueStart < end && (data[valueStart] == '"' || data[valueStart] == '\'') {
+			l.quote = data[valueStart]
+			closing := closingQuote(data, valueStart+1, l.quote)
+			if closing < 0 {
+				return nil, fmt.Errorf("line %d: unterminated %c quote", lineNo, l.quote)
+			}
+			l.raw = data[valueStart+1 : closing]
+
+			end = lineEnd(data, closing)
+			if rest := strings.TrimSpace(data[closing+1 : end]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("line %d: unexpected %q after quoted value", lineNo, rest)
+			}
+		} else {
+			raw := data[valueStart:end]
+			if i := inlineComment(raw); i >= 0 {
+				raw = raw[:i]
+			}
+			l.raw = strings.TrimSpace(raw)
+		}
+
+		l.text = data[start:end]
+		lines = append(lines, l)
+		lineNo += strings.Count(l.text, "\n") + 1
+		pos = end + 1
+	}
+
+	return lines, nil
+}
+
+func lineEnd(data string, pos int) int {
+	if i := strings.IndexByte(data[pos:], '\n'); i >= 0 {
+		return pos + i
+	}
+	return len(data)
+}
+
+// closingQuote returns the index of the quote ending a value that starts at
+// pos, skipping escaped double quotes.
+func closingQuote(data string, pos int, quote byte) int {
+	for i := pos; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func inlineComment(raw string) int {
+	for i := 1; i < len(raw); i++ {
+		if raw[i] == '#' && (raw[i-1] == ' ' || raw[i-1] == '\t') {
+			return i
+		}
+	}
+	return -1
+}
+
+// Keys returns the keys in the order they first appear.
+func (f *File) Keys() []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, l := range f.lines {
+		if l.key != "" && !seen[l.key] {
+			seen[l.key] = true
+			keys = append(keys, l.key)
+		}
+	}
+	return keys
+}
+
+// Map returns every entry with references expanded. A reference resolves
+// to an earlier entry of the file, or else to the process environment.
+func (f *File) Map() map[string]string {
+	values := map[string]string{}
+	f.resolve(values)
+	return values
+}
+
+// resolve evaluates the entries in order into values, which may already
+// hold entries of earlier layers.
+func (f *File) resolve(values map[string]string) {
+	for _, l := range f.lines {
+		if l.key != "" {
+			values[l.key] = evaluate(l.raw, l.quote, values)
+		}
+	}
+}
+
+// Get returns the expanded value of key.
+func (f *File) Get(key string) (string, bool) {
+	value, ok := f.Map()[key]
+	return value, ok
+}
+
+// Set stores value literally, quoting and escaping it as needed so it reads
+// back unchanged. An existing entry is updated in place, keeping its export
+// prefix; otherwise the entry is appended.
+func (f *File) Set(key, value string) error {
+	if !keyPattern.MatchString(key) {
+		return log.Errorf(log.CodeInvalidArgument, "env_set", "invalid key %q", key)
+	}
+
+	raw, quote := encode(value)
+	last := -1
+	for i, l := range f.lines {
+		if l.key == key {
+			last = i
+		}
+	}
+
+	if last < 0 {
+		f.lines = append(f.lines, line{key: key, raw: raw, quote: quote})
+		return nil
+	}
+
+	updated := line{key: key, raw: raw, quote: quote, export: f.lines[last].export}
+	f.lines[last] = updated
+	// Earlier duplicates would be shadowed anyway; drop them so the file
+	// has a single definition.
+	f.remove(func(i int, l line) bool { return l.key == key && i < last })
+	return nil
+}
+
+// Delete removes every entry for key.
+func (f *File) Delete(key string) {
+	f.remove(func(_ int, l line) bool { return l.key == key })
+}
+
+func (f *File) remove(match func(i int, l line) bool) {
+	kept := f.lines[:0]
+	for i, l := range f.lines {
+		if !match(i, l) {
+			kept = append(kept, l)
+		}
+	}
+	f.lines = kept
+}
+
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	for _, l := range f.lines {
+		if l.text == "" && l.key != "" {
+			l.text = format(l)
+		}
+		buf.WriteString(l.text)
+		buf.WriteByte('\n')
+	}
+
+	return buf.WriteTo(w)
+}
+
+// Save writes the file to path atomically with 0600 permissions, since .env
+// files usually hold secrets.
+func (f *File) Save(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := f.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func format(l line) string {
+	var b strings.Builder
+	if l.export {
+		b.WriteString("export ")
+	}
+	b.WriteString(l.key)
+	b.WriteByte('=')
+	if l.quote != 0 {
+		b.WriteByte(l.quote)
+	}
+	b.WriteString(l.raw)
+	if l.quote != 0 {
+		b.WriteByte(l.quote)
+	}
+	return b.String()
+}
+
+// Load reads the files in paths, defaulting to DefaultFiles, and merges
+// them: later files override earlier ones and may reference their values.
+// Missing files are skipped.
+func Load(paths ...string) (map[string]string, error) {
+	if len(paths) == 0 {
+		paths = DefaultFiles
+	}
+
+	values := map[string]string{}
+	for _, path := range paths {
+		file, err := Read(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		file.resolve(values)
+	}
+
+	return values, nil
+}
+
+// Export sets values in the process environment. Variables that are
+// already set are kept unless override is true, so the real environment
+// takes precedence over .env files.
+func Export(values map[string]string, override bool) error {
+	for key, value := range values {
+		if _, ok := os.LookupEnv(key); ok && !override {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package env
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Setenv("DEVKIT_ENV_HOME", "/home/dev")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "Unquoted values and comments",
+			input:    "# comment\n\nA=1\nexport B = two words # trailing\nC=\n",
+			expected: map[string]string{"A": "1", "B": "two words", "C": ""},
+		},
+		{
+			name:     "Quoted values",
+			input:    "A='literal $HOME'\nB=\"line\\nbreak \\\"q\\\"\"\nC=\"multi\nline\"\n",
+			expected: map[string]string{"A": "literal $HOME", "B": "line\nbreak \"q\"", "C": "multi\nline"},
+		},
+		{
+			name:     "Expansion",
+			input:    "A=x\nB=${A}-$A\nC=$DEVKIT_ENV_HOME/bin\nD=${DEVKIT_ENV_MISSING:-fallback}\nE=\\$A\n",
+			expected: map[string]string{"A": "x", "B": "x-x", "C": "/home/dev/bin", "D": "fallback", "E": "$A"},
+		},
+		{
+			name:    "Missing equals",
+			input:   "A\n",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid key",
+			input:   "1A=x\n",
+			wantErr: true,
+		},
+		{
+			name:    "Unterminated quote",
+			input:   "A=\"open\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := Parse(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			values := file.Map()
+			if len(values) != len(tt.expected) {
+				t.Errorf("Expected %d values, got: %v", len(tt.expected), values)
+			}
+			for key, expected := range tt.expected {
+				if values[key] != expected {
+					t.Errorf("Expected %s=%q, got: %q", key, expected, values[key])
+				}
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	input := "# settings\nexport A=1\nB='keep'  # note\nA=2\n"
+	file, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := file.WriteTo(&buf); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if buf.String() != input {
+		t.Errorf("Expected unchanged output, got: %q", buf.String())
+	}
+
+	value := "a \"b\" $c\nd"
+	if err := file.Set("A", value); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := file.Set("NEW", "plain"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	file.Delete("B")
+
+	buf.Reset()
+	file.WriteTo(&buf)
+	expected := "# settings\nA=\"a \\\"b\\\" \\$c\\nd\"\nNEW=plain\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+
+	reparsed, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got, _ := reparsed.Get("A"); got != value {
+		t.Errorf("Expected %q after round trip, got: %q", value, got)
+	}
+	if err := file.Set("bad key", "x"); err == nil {
+		t.Error("Expected an error for an invalid key")
+	}
+}
+
+func TestLoadAndExport(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("DEVKIT_ENV_A=base\nDEVKIT_ENV_B=b\n"), 0600)
+	os.WriteFile(filepath.Join(dir, ".env.local"), []byte("DEVKIT_ENV_A=${DEVKIT_ENV_A}-local\n"), 0600)
+
+	values, err := Load(filepath.Join(dir, ".env"), filepath.Join(dir, ".env.local"), filepath.Join(dir, "missing"))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if values["DEVKIT_ENV_A"] != "base-local" || values["DEVKIT_ENV_B"] != "b" {
+		t.Errorf("Expected layered values, got: %v", values)
+	}
+
+	t.Setenv("DEVKIT_ENV_B", "process")
+	t.Setenv("DEVKIT_ENV_A", "")
+	os.Unsetenv("DEVKIT_ENV_A")
+	if err := Export(values, false); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if os.Getenv("DEVKIT_ENV_A") != "base-local" || os.Getenv("DEVKIT_ENV_B") != "process" {
+		t.Errorf("Expected existing variables to be kept, got A=%q B=%q", os.Getenv("DEVKIT_ENV_A"), os.Getenv("DEVKIT_ENV_B"))
+	}
+}
+
+func TestSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	file := &File{}
+	file.Set("TOKEN", "secret")
+	if err := file.Save(path); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	loaded, err := Read(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got, _ := loaded.Get("TOKEN"); got != "secret" {
+		t.Errorf("Expected saved value, got: %q", got)
+	}
+}
@@ -0,0 +1,122 @@
+package env
+
+import (
+	"os"
+	"strings"
+)
+
+// evaluate decodes a raw value and expands its references. Single quoted
+// values are literal; double quoted values also decode \n, \t, \r, \", \\
+// and \$; unquoted values only decode \$.
+func evaluate(raw string, quote byte, values map[string]string) string {
+	if quote == '\'' {
+		return raw
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '\\' && i+1 < len(raw):
+			next := raw[i+1]
+			if decoded, ok := unescape(next, quote); ok {
+				b.WriteString(decoded)
+				i++
+				continue
+			}
+			b.WriteByte(c)
+		case c == '$':
+			value, n := expand(raw[i:], values)
+			b.WriteString(value)
+			i += n - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+func unescape(c byte, quote byte) (string, bool) {
+	if c == '$' {
+		return "$", true
+	}
+	if quote != '"' {
+		return "", false
+	}
+
+	switch c {
+	case 'n':
+		return "\n", true
+	case 't':
+		return "\t", true
+	case 'r':
+		return "\r", true
+	case '"', '\\':
+		return string(c), true
+	}
+	return "", false
+}
+
+// expand resolves the reference at the start of s and returns its value and
+// length. A $ that does not start a reference is kept.
+func expand(s string, values map[string]string) (string, int) {
+	if strings.HasPrefix(s, "${") {
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			return "$", 1
+		}
+
+		name, fallback, hasDefault := strings.Cut(s[2:end], ":-")
+		value, ok := lookup(name, values)
+		if hasDefault && (!ok || value == "") {
+			value = evaluate(fallback, '"', values)
+		}
+		return value, end + 1
+	}
+
+	n := 1
+	for n < len(s) && isNameChar(s[n], n == 1) {
+		n++
+	}
+	if n == 1 {
+		return "$", 1
+	}
+
+	value, _ := lookup(s[1:n], values)
+	return value, n
+}
+
+func isNameChar(c byte, first bool) bool {
+	if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	return !first && c >= '0' && c <= '9'
+}
+
+func lookup(name string, values map[string]string) (string, bool) {
+	if value, ok := values[name]; ok {
+		return value, true
+	}
+	return os.LookupEnv(name)
+}
+
+// encode returns the raw form and quote that evaluate back to value.
+func encode(value string) (string, byte) {
+	if value != "" && strings.IndexFunc(value, needsQuoting) < 0 {
+		return value, 0
+	}
+
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return replacer.Replace(value), '"'
+}
+
+func needsQuoting(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	case strings.ContainsRune("_-./:@,+=%", r):
+		return false
+	}
+	return true
+}
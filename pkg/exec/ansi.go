@@ -0,0 +1,16 @@
+package exec
+
+import "regexp"
+
+// ansiPattern matches CSI sequences (colors, cursor movement), OSC sequences
+// (titles, hyperlinks) and two-byte escapes.
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// forceColorEnv is understood by most CLIs that disable color when their
+// output is not a terminal.
+var forceColorEnv = []string{"FORCE_COLOR=1", "CLICOLOR_FORCE=1"}
+
+// StripANSI removes ANSI escape sequences from s.
+func StripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
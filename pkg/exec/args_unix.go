@@ -0,0 +1,9 @@
+//go:build !windows
+
+package exec
+
+import "os/exec"
+
+func platformCommand(name string, args []string) *exec.Cmd {
+	return exec.Command(name, args...)
+}
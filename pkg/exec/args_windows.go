@@ -0,0 +1,66 @@
+//go:build windows
+
+package exec
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+)
+
+// cmdMetaChars are interpreted by cmd.exe and must be caret-escaped when
+// arguments pass through it.
+var cmdMetaChars = regexp.MustCompile(`([()\][%!^"` + "`" + `<>&|;, *?])`)
+
+var (
+	trailingBackslashes = regexp.MustCompile(`(\\*)$`)
+	quoteBackslashes    = regexp.MustCompile(`(\\*)"`)
+)
+
+// platformCommand resolves name using PATH and PATHEXT. Batch files cannot be
+// started directly without cmd.exe re-parsing their arguments, so they are
+// run through cmd /d /s /c with every argument escaped for it.
+func platformCommand(name string, args []string) *exec.Cmd {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return exec.Command(name, args...)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".bat" && ext != ".cmd" {
+		return exec.Command(path, args...)
+	}
+
+	parts := []string{cmdMetaChars.ReplaceAllString(path, "^$1")}
+	for _, arg := range args {
+		parts = append(parts, quoteCmdArg(arg))
+	}
+
+	shell := comspec()
+	cmd := exec.Command(shell)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine: fmt.Sprintf(`%s /d /s /c "%s"`, syscall.EscapeArg(shell), strings.Join(parts, " ")),
+	}
+	return cmd
+}
+
+// quoteCmdArg quotes arg following the CommandLineToArgvW rules and then
+// escapes it for cmd.exe, so it reaches the batch file unchanged.
+func quoteCmdArg(arg string) string {
+	arg = quoteBackslashes.ReplaceAllString(arg, `$1$1\"`)
+	arg = trailingBackslashes.ReplaceAllString(arg, "$1$1")
+	arg = `"` + arg + `"`
+	return cmdMetaChars.ReplaceAllString(arg, "^$1")
+}
+
+func comspec() string {
+	if shell := os.Getenv("COMSPEC"); shell != "" {
+		return shell
+	}
+
+	return "cmd.exe"
+}
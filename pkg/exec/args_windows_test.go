@@ -0,0 +1,29 @@
+//go:build windows
+
+package exec
+
+import "testing"
+
+func TestQuoteCmdArg(t *testing.T) {
+	tests := []struct {
+		name     string
+		arg      string
+		expected string
+	}{
+		{name: "Plain word", arg: "build", expected: `^"build^"`},
+		{name: "Spaces", arg: "hello world", expected: `^"hello^ world^"`},
+		{name: "Embedded quote", arg: `say "hi"`, expected: `^"say^ \^"hi\^"^"`},
+		{name: "Trailing backslash", arg: `C:\dir\`, expected: `^"C:\dir\\^"`},
+		{name: "Metacharacters", arg: "a&b|c%PATH%", expected: `^"a^&b^|c^%PATH^%^"`},
+		{name: "Empty", arg: "", expected: `^"^"`},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteCmdArg(tt.arg); got != tt.expected {
+				t.Errorf("quoteCmdArg(%q) = %s, expected %s", tt.arg, got, tt.expected)
+			}
+		})
+	}
+}
@@ -2,22 +2,202 @@ package exec
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/trace"
+	"golang.org/x/term"
 )
 
-type Cmd struct{}
+const maxLineSize = 1024 * 1024
+
+type Cmd struct {
+	// Log records every execution in the devkit log with its arguments,
+	// directory, duration and exit code. Values of sensitive flags are
+	// redacted; SensitiveFlags names additional flags to redact.
+	Log            bool
+	SensitiveFlags []string
+}
 
 type CmdArgs struct {
 	Dir  string
 	Run  string
 	Args []string
+	// Env holds additional KEY=VALUE variables appended to the current
+	// environment.
+	Env []string
+	// Stdin is connected to the command's standard input. StdinString is a
+	// convenience used when Stdin is nil.
+	Stdin       io.Reader
+	StdinString string
+	// Stdout and Stderr receive the command's output line by line. They
+	// default to os.Stdout and os.Stderr.
+	Stdout io.Writer
+	Stderr io.Writer
+	// Shell runs Run as a script through sh -c, or cmd /C on Windows, so it
+	// may contain pipes and redirects. Args are passed to the script.
+	Shell bool
+	// Timeout bounds how long the command may run. When it elapses, or the
+	// context is cancelled, the process group receives Interrupt (SIGTERM by
+	// default, CTRL_BREAK on Windows) and is killed if it is still running
+	// after GracePeriod. A command whose Stdin is a terminal stays in the
+	// terminal's process group, so only the command itself is signalled.
+	Timeout     time.Duration
+	GracePeriod time.Duration
+	Interrupt   os.Signal
+	// Prefix is prepended to every line of output, e.g. "[build] ", so
+	// output from parallel commands stays readable.
+	Prefix      string
+	PrefixColor Color
+	// Credential runs the command as another user, letting processes
+	// started as root drop privileges. Umask sets the file mode creation
+	// mask of the command. Both are Unix only.
+	Credential *Credential
+	Umask      *os.FileMode
+	// ForceColor asks the command to emit color even though its output is
+	// not a terminal. StripANSI removes escape sequences from its output
+	// before it is written.
+	ForceColor bool
+	StripANSI  bool
 }
 
-func (c *Cmd) ExecuteWithStream(opts CmdArgs) error {
-	cmd := exec.Command(opts.Run, opts.Args...)
+func (opts CmdArgs) stdin() io.Reader {
+	if opts.Stdin != nil {
+		return opts.Stdin
+	}
+
+	if opts.StdinString != "" {
+		return strings.NewReader(opts.StdinString)
+	}
+
+	return nil
+}
+
+// ownProcessGroup reports whether the command may run in its own process
+// group. One reading the terminal must stay in the foreground group, or its
+// reads stop it with SIGTTIN and Ctrl+C never reaches it.
+func (opts CmdArgs) ownProcessGroup() bool {
+	f, ok := opts.Stdin.(*os.File)
+	return !ok || !term.IsTerminal(int(f.Fd()))
+}
+
+func (opts CmdArgs) gracePeriod() time.Duration {
+	if opts.GracePeriod > 0 {
+		return opts.GracePeriod
+	}
+
+	return defaultGracePeriod
+}
+
+func (opts CmdArgs) env() []string {
+	if len(opts.Env) == 0 && !opts.ForceColor {
+		return nil
+	}
+
+	env := append(os.Environ(), opts.Env...)
+	if opts.ForceColor {
+		env = append(env, forceColorEnv...)
+	}
+
+	return env
+}
+
+func (opts CmdArgs) formatLine(line string) string {
+	if opts.StripANSI {
+		line = StripANSI(line)
+	}
+
+	return opts.linePrefix() + line
+}
+
+func (opts CmdArgs) stdout() io.Writer {
+	if opts.Stdout != nil {
+		return opts.Stdout
+	}
+
+	return os.Stdout
+}
+
+func (opts CmdArgs) stderr() io.Writer {
+	if opts.Stderr != nil {
+		return opts.Stderr
+	}
+
+	return os.Stderr
+}
+
+func (c *Cmd) command(opts CmdArgs) (*exec.Cmd, error) {
+	var cmd *exec.Cmd
+	if opts.Shell {
+		cmd = shellCommand(opts.Run, opts.Args)
+	} else {
+		cmd = platformCommand(opts.Run, opts.Args)
+	}
+
+	cmd, err := withUmask(cmd, opts.Umask)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyCredential(cmd, opts.Credential); err != nil {
+		return nil, err
+	}
+
 	cmd.Dir = opts.Dir
+	cmd.Env = opts.env()
+	cmd.Stdin = opts.stdin()
+	return cmd, nil
+}
+
+// ExecuteShell runs script through the platform shell, streaming its output.
+func (c *Cmd) ExecuteShell(script string) error {
+	return c.ExecuteWithStream(CmdArgs{Run: script, Shell: true})
+}
+
+func (c *Cmd) ExecuteWithStream(opts CmdArgs) error {
+	return c.ExecuteWithStreamContext(context.Background(), opts)
+}
+
+// ExecuteWithStreamContext runs the command as a span of the trace in ctx
+// and passes the span to the child in TRACEPARENT, so tools that support
+// trace context join the same trace.
+func (c *Cmd) ExecuteWithStreamContext(ctx context.Context, opts CmdArgs) error {
+	ctx, span := trace.Start(ctx, "exec "+commandName(opts), trace.String("dir", opts.Dir))
+	if env := trace.Env(ctx); env != nil {
+		opts.Env = slices.Concat(opts.Env, env)
+	}
+
+	start := time.Now()
+	err := c.executeWithStream(ctx, opts)
+	c.logExecution(opts, time.Since(start), err)
+
+	if err != nil {
+		span.SetAttributes(trace.Int64("exit_code", int64(ExitCode(err))))
+	}
+	span.Finish(err)
+	return err
+}
+
+func (c *Cmd) executeWithStream(ctx context.Context, opts CmdArgs) error {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	cmd, err := c.command(opts)
+	if err != nil {
+		return err
+	}
+	group := newProcessGroup(cmd, opts.Interrupt, opts.ownProcessGroup())
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -32,27 +212,62 @@ func (c *Cmd) ExecuteWithStream(opts CmdArgs) error {
 	if err := cmd.Start(); err != nil {
 		return err
 	}
-	scanner := bufio.NewScanner(stdout)
-	scannerErr := bufio.NewScanner(stderr)
-	for scanner.Scan() {
-		fmt.Fprintln(os.Stdout, scanner.Text())
-	}
 
-	for scannerErr.Scan() {
-		fmt.Fprintln(os.Stderr, scannerErr.Text())
+	if err := group.started(); err != nil {
+		_ = group.kill()
+		_ = cmd.Wait()
+		return err
 	}
+	defer group.release()
 
-	if err := scanner.Err(); err != nil {
-		return err
+	stop := terminateOnDone(ctx, group, opts.gracePeriod())
+
+	// Both pipes are drained concurrently so a child filling one of them
+	// cannot block while we wait on the other.
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	streamErrs := make([]error, 2)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		streamErrs[0] = streamLines(stdout, opts.stdout(), opts.formatLine, &mu)
+	}()
+	go func() {
+		defer wg.Done()
+		streamErrs[1] = streamLines(stderr, opts.stderr(), opts.formatLine, &mu)
+	}()
+	wg.Wait()
+
+	waitErr := cmd.Wait()
+	if stop() {
+		return errors.Join(ctx.Err(), wrapExitError(opts, waitErr))
 	}
 
-	if err := scannerErr.Err(); err != nil {
+	if err := errors.Join(streamErrs...); err != nil {
 		return err
 	}
 
-	if err := cmd.Wait(); err != nil {
-		return err
+	if waitErr != nil {
+		return wrapExitError(opts, waitErr)
 	}
 
 	return nil
 }
+
+// streamLines copies r to w one line at a time, holding mu for each write so
+// lines from concurrent streams are never interleaved mid-line.
+func streamLines(r io.Reader, w io.Writer, format func(string) string, mu *sync.Mutex) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		mu.Lock()
+		_, err := fmt.Fprintln(w, format(scanner.Text()))
+		mu.Unlock()
+		if err != nil {
+			_, _ = io.Copy(io.Discard, r)
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,542 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+func TestExecuteWithStream(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	tests := []struct {
+		name           string
+		opts           CmdArgs
+		expectError    bool
+		expectedExit   int
+		expectedStdout []string
+		expectedStderr []string
+	}{
+		{
+			name:           "Stdout only",
+			opts:           CmdArgs{Run: "sh", Args: []string{"-c", "echo hello; echo world"}},
+			expectedStdout: []string{"hello", "world"},
+		},
+		{
+			name:           "Chatty stderr before stdout",
+			opts:           CmdArgs{Run: "sh", Args: []string{"-c", "i=0; while [ $i -lt 20000 ]; do echo \"error line $i\" >&2; i=$((i+1)); done; echo done"}},
+			expectedStdout: []string{"done"},
+			expectedStderr: []string{"error line 0", "error line 19999"},
+		},
+		{
+			name:           "Both streams",
+			opts:           CmdArgs{Run: "sh", Args: []string{"-c", "echo out; echo err >&2"}},
+			expectedStdout: []string{"out"},
+			expectedStderr: []string{"err"},
+		},
+		{
+			name:           "Shell pipeline",
+			opts:           CmdArgs{Run: "echo piped | tr a-z A-Z; echo \"$1\"", Args: []string{"first"}, Shell: true},
+			expectedStdout: []string{"PIPED", "first"},
+		},
+		{
+			name:           "Prefixed output",
+			opts:           CmdArgs{Run: "sh", Args: []string{"-c", "echo out; echo err >&2"}, Prefix: "[build] "},
+			expectedStdout: []string{"[build] out"},
+			expectedStderr: []string{"[build] err"},
+		},
+		{
+			name:           "Colored prefix",
+			opts:           CmdArgs{Run: "echo", Args: []string{"pushed"}, Prefix: "[push] ", PrefixColor: ColorCyan},
+			expectedStdout: []string{"\x1b[36m[push] \x1b[0mpushed"},
+		},
+		{
+			name:           "Umask",
+			opts:           CmdArgs{Run: "umask", Shell: true, Umask: fileMode(0027)},
+			expectedStdout: []string{"0027"},
+		},
+		{
+			name:           "Force color",
+			opts:           CmdArgs{Run: "echo \"$FORCE_COLOR $CLICOLOR_FORCE $EXTRA\"", Shell: true, ForceColor: true, Env: []string{"EXTRA=set"}},
+			expectedStdout: []string{"1 1 set"},
+		},
+		{
+			name:           "Strip ANSI",
+			opts:           CmdArgs{Run: "printf '\\033[1;32mok\\033[0m \\033]0;title\\007done\\n'", Shell: true, StripANSI: true},
+			expectedStdout: []string{"ok done"},
+		},
+		{
+			name:           "Stdin string",
+			opts:           CmdArgs{Run: "cat", StdinString: "piped input\n"},
+			expectedStdout: []string{"piped input"},
+		},
+		{
+			name:         "Non-zero exit",
+			opts:         CmdArgs{Run: "sh", Args: []string{"-c", "echo failing >&2; exit 3"}},
+			expectError:  true,
+			expectedExit: 3,
+		},
+		{
+			name:         "Killed by signal",
+			opts:         CmdArgs{Run: "sh", Args: []string{"-c", "kill -9 $$"}},
+			expectError:  true,
+			expectedExit: -1,
+		},
+		{
+			name:         "Missing binary",
+			opts:         CmdArgs{Run: "devkit-binary-does-not-exist"},
+			expectError:  true,
+			expectedExit: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			tt.opts.Stdout = &stdout
+			tt.opts.Stderr = &stderr
+
+			done := make(chan error, 1)
+			go func() {
+				c := &Cmd{}
+				done <- c.ExecuteWithStream(tt.opts)
+			}()
+
+			var err error
+			select {
+			case err = <-done:
+			case <-time.After(30 * time.Second):
+				t.Fatal("ExecuteWithStream() did not return")
+			}
+
+			if (err != nil) != tt.expectError {
+				t.Errorf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+			if tt.expectError && ExitCode(err) != tt.expectedExit {
+				t.Errorf("Expected exit code: %d, got: %d", tt.expectedExit, ExitCode(err))
+			}
+			for _, line := range tt.expectedStdout {
+				if !strings.Contains(stdout.String(), line+"\n") {
+					t.Errorf("Expected stdout to contain %q", line)
+				}
+			}
+			for _, line := range tt.expectedStderr {
+				if !strings.Contains(stderr.String(), line+"\n") {
+					t.Errorf("Expected stderr to contain %q", line)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteWithPTY(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("pseudo-terminals are not supported on windows")
+	}
+
+	var stdout bytes.Buffer
+	c := &Cmd{}
+	err := c.ExecuteWithPTY(CmdArgs{
+		Run:         "sh",
+		Args:        []string{"-c", "test -t 0 && test -t 1 && echo interactive"},
+		StdinString: "\n",
+		Stdout:      &stdout,
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWithPTY() error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "interactive") {
+		t.Errorf("Expected command to see a terminal, got output: %q", stdout.String())
+	}
+}
+
+func TestExecuteWithPTYReleasesStdin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("pseudo-terminals are not supported on windows")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	c := &Cmd{}
+	if err := c.ExecuteWithPTY(CmdArgs{Run: "true", Stdin: r, Stdout: io.Discard}); err != nil {
+		t.Fatalf("ExecuteWithPTY() error = %v", err)
+	}
+
+	// Input written after the command ended belongs to the next reader.
+	w.Write([]byte("after\n"))
+	read := make(chan string, 1)
+	go func() {
+		buf := make([]byte, 16)
+		n, _ := r.Read(buf)
+		read <- string(buf[:n])
+	}()
+
+	select {
+	case got := <-read:
+		if got != "after\n" {
+			t.Errorf("Expected %q, got: %q", "after\n", got)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected the passthrough to stop reading stdin when the command ended")
+	}
+}
+
+func TestExecuteWithStreamContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	tests := []struct {
+		name        string
+		opts        CmdArgs
+		maxDuration time.Duration
+		expectError error
+	}{
+		{
+			name:        "Completes before timeout",
+			opts:        CmdArgs{Run: "sh", Args: []string{"-c", "echo quick"}, Timeout: 5 * time.Second},
+			maxDuration: 5 * time.Second,
+		},
+		{
+			name:        "Interrupted on timeout",
+			opts:        CmdArgs{Run: "sh", Args: []string{"-c", "sleep 30"}, Timeout: 100 * time.Millisecond},
+			maxDuration: 3 * time.Second,
+			expectError: context.DeadlineExceeded,
+		},
+		{
+			name: "Killed after grace period",
+			opts: CmdArgs{
+				Run:         "sh",
+				Args:        []string{"-c", "trap '' TERM; sleep 30 & wait"},
+				Timeout:     100 * time.Millisecond,
+				GracePeriod: 200 * time.Millisecond,
+			},
+			maxDuration: 3 * time.Second,
+			expectError: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			tt.opts.Stdout = &stdout
+
+			start := time.Now()
+			c := &Cmd{}
+			err := c.ExecuteWithStreamContext(context.Background(), tt.opts)
+			if tt.expectError == nil && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+			if tt.expectError != nil && !errors.Is(err, tt.expectError) {
+				t.Errorf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+			if elapsed := time.Since(start); elapsed > tt.maxDuration {
+				t.Errorf("Command took %v, expected at most %v", elapsed, tt.maxDuration)
+			}
+		})
+	}
+}
+
+func TestOwnProcessGroup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a pseudo-terminal")
+	}
+
+	ptmx, tty, err := pty.Open()
+	if err != nil {
+		t.Skipf("no pseudo-terminal available: %v", err)
+	}
+	defer ptmx.Close()
+	defer tty.Close()
+
+	file, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	tests := []struct {
+		name     string
+		stdin    io.Reader
+		expected bool
+	}{
+		{name: "No stdin", expected: true},
+		{name: "Reader", stdin: strings.NewReader("input"), expected: true},
+		{name: "File", stdin: file, expected: true},
+		{name: "Terminal", stdin: tty, expected: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (CmdArgs{Stdin: tt.stdin}).ownProcessGroup(); got != tt.expected {
+				t.Errorf("Expected %v, got: %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestExecuteJSONStream(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	errStop := errors.New("stop")
+
+	tests := []struct {
+		name          string
+		script        string
+		callback      func(json.RawMessage) error
+		expectError   bool
+		expectedCalls int
+	}{
+		{
+			name:          "Decodes each line",
+			script:        `printf '{"id":1}\n\n{"id":2}\n[3]\n'`,
+			expectedCalls: 3,
+		},
+		{
+			name:          "Invalid JSON line",
+			script:        `printf '{"id":1}\nnot json\n'`,
+			expectError:   true,
+			expectedCalls: 1,
+		},
+		{
+			name:          "Callback error stops the command",
+			script:        `echo '{"id":1}'; sleep 30`,
+			callback:      func(json.RawMessage) error { return errStop },
+			expectError:   true,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			c := &Cmd{}
+			err := c.ExecuteJSONStream(CmdArgs{Run: tt.script, Shell: true, GracePeriod: time.Second}, func(msg json.RawMessage) error {
+				calls++
+				if tt.callback != nil {
+					return tt.callback(msg)
+				}
+				return nil
+			})
+			if (err != nil) != tt.expectError {
+				t.Errorf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("Expected %d callbacks, got: %d", tt.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestRunParallel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	cmds := []CmdArgs{
+		{Run: "echo first", Shell: true},
+		{Run: "sleep 0.1; echo failed >&2; exit 2", Shell: true},
+		{Run: "sleep 0.5; echo third", Shell: true},
+	}
+
+	tests := []struct {
+		name          string
+		mode          ParallelMode
+		expectedExits []int
+		maxDuration   time.Duration
+	}{
+		{
+			name:          "Continue on error",
+			mode:          ContinueOnError,
+			expectedExits: []int{0, 2, 0},
+			maxDuration:   5 * time.Second,
+		},
+		{
+			name:          "Fail fast",
+			mode:          FailFast,
+			expectedExits: []int{0, 2, -1},
+			maxDuration:   5 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Cmd{}
+			start := time.Now()
+			results, err := c.RunParallel(cmds, 2, tt.mode)
+			if err == nil || ExitCode(err) != 2 {
+				t.Errorf("Expected error with exit code 2, got: %v", err)
+			}
+			if time.Since(start) > tt.maxDuration {
+				t.Errorf("RunParallel() took %v", time.Since(start))
+			}
+			if len(results) != len(cmds) {
+				t.Fatalf("Expected %d results, got: %d", len(cmds), len(results))
+			}
+			for i, result := range results {
+				if result.ExitCode != tt.expectedExits[i] {
+					t.Errorf("Command %d: expected exit code %d, got: %d (%v)", i, tt.expectedExits[i], result.ExitCode, result.Err)
+				}
+			}
+			if string(results[0].Stdout) != "first\n" {
+				t.Errorf("Expected captured stdout %q, got: %q", "first\n", results[0].Stdout)
+			}
+			if string(results[1].Stderr) != "failed\n" {
+				t.Errorf("Expected captured stderr %q, got: %q", "failed\n", results[1].Stderr)
+			}
+		})
+	}
+}
+
+func TestExecuteScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	tests := []struct {
+		name           string
+		content        string
+		interpreter    string
+		args           []string
+		expectError    bool
+		expectedStdout string
+	}{
+		{
+			name:           "Default interpreter",
+			content:        "echo \"hello $1\"\n",
+			args:           []string{"world"},
+			expectedStdout: "hello world\n",
+		},
+		{
+			name:           "Shebang",
+			content:        "#!/bin/sh\nprintf 'from shebang\\n'\n",
+			expectedStdout: "from shebang\n",
+		},
+		{
+			name:           "CRLF line endings",
+			content:        "x=crlf\r\necho $x\r\n",
+			interpreter:    "sh",
+			expectedStdout: "crlf\n",
+		},
+		{
+			name:        "Failing script",
+			content:     "exit 4\n",
+			interpreter: "sh",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			c := &Cmd{}
+			err := c.ExecuteScript(tt.content, tt.interpreter, CmdArgs{Args: tt.args, Stdout: &stdout})
+			if (err != nil) != tt.expectError {
+				t.Errorf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+			if stdout.String() != tt.expectedStdout {
+				t.Errorf("Expected stdout %q, got: %q", tt.expectedStdout, stdout.String())
+			}
+		})
+	}
+}
+
+func TestExecuteAsUser(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() != 0 {
+		t.Skip("requires root on a Unix system")
+	}
+
+	var stdout bytes.Buffer
+	c := &Cmd{}
+	err := c.ExecuteWithStream(CmdArgs{
+		Run:        "id",
+		Args:       []string{"-u"},
+		Credential: &Credential{Uid: 65534, Gid: 65534},
+		Stdout:     &stdout,
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWithStream() error = %v", err)
+	}
+	if strings.TrimSpace(stdout.String()) != "65534" {
+		t.Errorf("Expected command to run as uid 65534, got: %q", stdout.String())
+	}
+}
+
+func fileMode(mode os.FileMode) *os.FileMode {
+	return &mode
+}
+
+func TestRedactArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		extra    []string
+		expected []string
+	}{
+		{
+			name:     "Flag with separate value",
+			args:     []string{"login", "--password", "hunter2", "registry.example.com"},
+			expected: []string{"login", "--password", "***", "registry.example.com"},
+		},
+		{
+			name:     "Flag with inline value",
+			args:     []string{"--auth-token=abc123", "--verbose"},
+			expected: []string{"--auth-token=***", "--verbose"},
+		},
+		{
+			name:     "Environment assignment",
+			args:     []string{"GITHUB_TOKEN=abc123", "PATH=/usr/bin"},
+			expected: []string{"GITHUB_TOKEN=***", "PATH=/usr/bin"},
+		},
+		{
+			name:     "Extra sensitive flag",
+			args:     []string{"login", "-u", "admin", "-p", "hunter2"},
+			extra:    []string{"-p"},
+			expected: []string{"login", "-u", "admin", "-p", "***"},
+		},
+		{
+			name:     "Whole words only",
+			args:     []string{"commit", "--author", "Jane", "--authority=ca", "NPM_AUTH=abc", "--api-key", "k"},
+			expected: []string{"commit", "--author", "Jane", "--authority=ca", "NPM_AUTH=***", "--api-key", "***"},
+		},
+		{
+			name:     "Nothing sensitive",
+			args:     []string{"build", "-t", "app:latest", "."},
+			expected: []string{"build", "-t", "app:latest", "."},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactArgs(tt.args, tt.extra)
+			if strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("redactArgs() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
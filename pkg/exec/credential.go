@@ -0,0 +1,49 @@
+package exec
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+)
+
+// Credential identifies the user and groups a command runs as.
+type Credential struct {
+	Uid    uint32
+	Gid    uint32
+	Groups []uint32
+}
+
+// LookupCredential resolves a user name to the credential used to run
+// commands as that user, including its supplementary groups.
+func LookupCredential(username string) (*Credential, error) {
+	u, err := user.Lookup(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up user %s: %w", username, err)
+	}
+
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported uid %s for user %s", u.Uid, username)
+	}
+
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported gid %s for user %s", u.Gid, username)
+	}
+
+	cred := &Credential{Uid: uint32(uid), Gid: uint32(gid)}
+
+	groupIds, err := u.GroupIds()
+	if err != nil {
+		return cred, nil
+	}
+
+	for _, id := range groupIds {
+		group, err := strconv.ParseUint(id, 10, 32)
+		if err == nil {
+			cred.Groups = append(cred.Groups, uint32(group))
+		}
+	}
+
+	return cred, nil
+}
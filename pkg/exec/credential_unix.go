@@ -0,0 +1,41 @@
+//go:build !windows
+
+package exec
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+func applyCredential(cmd *exec.Cmd, cred *Credential) error {
+	if cred == nil {
+		return nil
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid:         cred.Uid,
+		Gid:         cred.Gid,
+		Groups:      cred.Groups,
+		NoSetGroups: cred.Groups == nil,
+	}
+	return nil
+}
+
+// withUmask re-executes cmd through sh after setting the umask, since the
+// umask is process wide and cannot be changed for the child alone.
+func withUmask(cmd *exec.Cmd, mask *os.FileMode) (*exec.Cmd, error) {
+	if mask == nil {
+		return cmd, nil
+	}
+
+	script := fmt.Sprintf(`umask %04o && exec "$0" "$@"`, *mask&os.ModePerm)
+	wrapped := exec.Command("sh", append([]string{"-c", script}, cmd.Args...)...)
+	wrapped.SysProcAttr = cmd.SysProcAttr
+	return wrapped, nil
+}
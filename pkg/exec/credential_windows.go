@@ -0,0 +1,25 @@
+//go:build windows
+
+package exec
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+func applyCredential(cmd *exec.Cmd, cred *Credential) error {
+	if cred == nil {
+		return nil
+	}
+
+	return fmt.Errorf("running commands as another user is not supported on windows")
+}
+
+func withUmask(cmd *exec.Cmd, mask *os.FileMode) (*exec.Cmd, error) {
+	if mask == nil {
+		return cmd, nil
+	}
+
+	return nil, fmt.Errorf("umask is not supported on windows")
+}
@@ -0,0 +1,68 @@
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// ExitError reports a command that ran but did not exit successfully.
+type ExitError struct {
+	Command string
+	// ExitCode is the process exit status, or -1 when it was terminated by
+	// a signal.
+	ExitCode int
+	Signal   syscall.Signal
+	// Killed is true when the process was terminated by a signal rather
+	// than exiting on its own.
+	Killed bool
+	Err    *exec.ExitError
+}
+
+func (e *ExitError) Error() string {
+	if e.Killed {
+		return fmt.Sprintf("%s: killed by signal: %s", e.Command, e.Signal)
+	}
+
+	return fmt.Sprintf("%s: exit status %d", e.Command, e.ExitCode)
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code carried by err, or -1 if err did not come
+// from a command exiting.
+func ExitCode(err error) int {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode
+	}
+
+	return -1
+}
+
+func wrapExitError(opts CmdArgs, err error) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+
+	result := &ExitError{
+		Command:  strings.TrimSpace(opts.Run + " " + strings.Join(opts.Args, " ")),
+		ExitCode: exitErr.ExitCode(),
+		Err:      exitErr,
+	}
+
+	if status, ok := exitErr.Sys().(interface {
+		Signaled() bool
+		Signal() syscall.Signal
+	}); ok && status.Signaled() {
+		result.Killed = true
+		result.Signal = status.Signal()
+	}
+
+	return result
+}
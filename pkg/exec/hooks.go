@@ -0,0 +1,51 @@
+package exec
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CommandHook observes every finished command, e.g. to export metrics.
+// command is the base name of the executable, or "shell" for scripts, and
+// exitCode is -1 when the command could not be run.
+type CommandHook func(command string, exitCode int, elapsed time.Duration)
+
+var (
+	hooksMu      sync.RWMutex
+	commandHooks []CommandHook
+)
+
+// OnCommand registers hook for the commands run by every Cmd.
+func OnCommand(hook CommandHook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	commandHooks = append(commandHooks, hook)
+}
+
+func runCommandHooks(opts CmdArgs, duration time.Duration, err error) {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	if len(commandHooks) == 0 {
+		return
+	}
+
+	command := commandName(opts)
+	exitCode := 0
+	if err != nil {
+		exitCode = ExitCode(err)
+	}
+
+	for _, hook := range commandHooks {
+		hook(command, exitCode, duration)
+	}
+}
+
+// commandName labels opts in hooks and spans without its arguments.
+func commandName(opts CmdArgs) string {
+	if opts.Shell {
+		return "shell"
+	}
+	return strings.TrimSuffix(filepath.Base(opts.Run), ".exe")
+}
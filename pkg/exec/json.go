@@ -0,0 +1,69 @@
+package exec
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ExecuteJSONStream runs the command and calls fn with each line of stdout
+// decoded as JSON, for tools emitting machine readable streams such as
+// docker --format json or kubectl -o json --watch. Blank lines are skipped.
+// If fn returns an error the command is terminated and that error returned.
+func (c *Cmd) ExecuteJSONStream(opts CmdArgs, fn func(json.RawMessage) error) error {
+	return c.ExecuteJSONStreamContext(context.Background(), opts, fn)
+}
+
+func (c *Cmd) ExecuteJSONStreamContext(ctx context.Context, opts CmdArgs, fn func(json.RawMessage) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	opts.Stdout = pw
+	// A prefix would corrupt every JSON line.
+	opts.Prefix = ""
+
+	decodeErr := make(chan error, 1)
+	go func() {
+		err := decodeJSONLines(pr, fn)
+		if err != nil {
+			cancel()
+		}
+		_, _ = io.Copy(io.Discard, pr)
+		decodeErr <- err
+	}()
+
+	err := c.ExecuteWithStreamContext(ctx, opts)
+	pw.Close()
+
+	if err := <-decodeErr; err != nil {
+		return err
+	}
+
+	return err
+}
+
+func decodeJSONLines(r io.Reader, fn func(json.RawMessage) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		if !json.Valid(line) {
+			return fmt.Errorf("invalid JSON on line %d: %s", lineNo, line)
+		}
+
+		if err := fn(json.RawMessage(bytes.Clone(line))); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
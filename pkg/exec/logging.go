@@ -0,0 +1,89 @@
+package exec
+
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+const redacted = "***"
+
+// sensitiveWords mark flags and KEY=VALUE arguments whose values are
+// redacted from execution logs. They match whole words of a name, so
+// --auth-token and NPM_AUTH are sensitive but --author is not.
+var sensitiveWords = []string{"password", "passwd", "token", "secret", "apikey", "credential", "credentials", "auth", "authorization"}
+
+func (c *Cmd) logExecution(opts CmdArgs, duration time.Duration, err error) {
+	runCommandHooks(opts, duration, err)
+	if !c.Log {
+		return
+	}
+
+	attrs := []any{
+		"command", opts.Run,
+		"args", redactArgs(opts.Args, c.SensitiveFlags),
+		"dir", opts.Dir,
+		"duration_ms", float64(duration.Microseconds()) / 1000,
+	}
+
+	logger := log.Component("exec")
+	if err != nil {
+		logger.Error("command failed", append(attrs, "exit_code", ExitCode(err), log.KeyError, err.Error())...)
+		return
+	}
+
+	logger.Info("command finished", append(attrs, "exit_code", 0)...)
+}
+
+// redactArgs replaces the values of sensitive flags ("--token abc",
+// "--password=abc") and KEY=VALUE arguments with a placeholder. extra lists
+// additional flags, such as "-p", whose following value is sensitive.
+func redactArgs(args []string, extra []string) []string {
+	result := make([]string, len(args))
+	redactNext := false
+
+	for i, arg := range args {
+		if redactNext {
+			result[i] = redacted
+			redactNext = false
+			continue
+		}
+
+		name, _, hasValue := strings.Cut(arg, "=")
+		switch {
+		case hasValue && isSensitive(name, extra):
+			result[i] = name + "=" + redacted
+		case !hasValue && strings.HasPrefix(arg, "-") && isSensitive(arg, extra):
+			result[i] = arg
+			redactNext = true
+		default:
+			result[i] = arg
+		}
+	}
+
+	return result
+}
+
+func isSensitive(name string, extra []string) bool {
+	for _, flag := range extra {
+		if name == flag {
+			return true
+		}
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		// Adjacent words are also tried joined, so api-key and API_KEY
+		// match apikey.
+		if slices.Contains(sensitiveWords, word) || i > 0 && slices.Contains(sensitiveWords, words[i-1]+word) {
+			return true
+		}
+	}
+
+	return false
+}
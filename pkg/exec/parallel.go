@@ -0,0 +1,102 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/pool"
+)
+
+type ParallelMode int
+
+const (
+	// ContinueOnError runs every command regardless of failures.
+	ContinueOnError ParallelMode = iota
+	// FailFast cancels running commands and skips pending ones after the
+	// first failure.
+	FailFast
+)
+
+type Result struct {
+	Args     CmdArgs
+	Duration time.Duration
+	ExitCode int
+	Stdout   []byte
+	Stderr   []byte
+	Err      error
+}
+
+// RunParallel runs cmds with at most maxConcurrency executing at once. Output
+// is captured into each Result; it is also streamed when a command sets
+// Stdout or Stderr. Results are returned in the order of cmds.
+func (c *Cmd) RunParallel(cmds []CmdArgs, maxConcurrency int, mode ParallelMode) ([]Result, error) {
+	return c.RunParallelContext(context.Background(), cmds, maxConcurrency, mode)
+}
+
+func (c *Cmd) RunParallelContext(ctx context.Context, cmds []CmdArgs, maxConcurrency int, mode ParallelMode) ([]Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var once sync.Once
+	var firstErr error
+	results, errs := pool.MapAll(ctx, cmds, maxConcurrency, func(ctx context.Context, opts CmdArgs) (Result, error) {
+		result := c.runCaptured(ctx, opts)
+		if result.Err != nil && mode == FailFast {
+			once.Do(func() { firstErr = result.Err })
+			cancel()
+		}
+		return result, nil
+	})
+
+	// Commands skipped after a fail-fast cancellation only have an error.
+	for i, err := range errs {
+		if err != nil {
+			results[i] = Result{Args: cmds[i], ExitCode: -1, Err: err}
+		}
+	}
+
+	if mode == FailFast {
+		return results, firstErr
+	}
+
+	var joined []error
+	for _, result := range results {
+		if result.Err != nil {
+			joined = append(joined, fmt.Errorf("%s: %w", result.Args.Run, result.Err))
+		}
+	}
+
+	return results, errors.Join(joined...)
+}
+
+func (c *Cmd) runCaptured(ctx context.Context, opts CmdArgs) Result {
+	result := Result{Args: opts}
+
+	var stdout, stderr bytes.Buffer
+	opts.Stdout = teeWriter(opts.Stdout, &stdout)
+	opts.Stderr = teeWriter(opts.Stderr, &stderr)
+
+	start := time.Now()
+	result.Err = c.ExecuteWithStreamContext(ctx, opts)
+	result.Duration = time.Since(start)
+	result.Stdout = stdout.Bytes()
+	result.Stderr = stderr.Bytes()
+	if result.Err != nil {
+		result.ExitCode = ExitCode(result.Err)
+	}
+
+	return result
+}
+
+func teeWriter(w io.Writer, buf *bytes.Buffer) io.Writer {
+	if w == nil {
+		return buf
+	}
+
+	return io.MultiWriter(w, buf)
+}
@@ -0,0 +1,26 @@
+package exec
+
+// Color is an ANSI foreground color used for line prefixes.
+type Color string
+
+const (
+	ColorNone    Color = ""
+	ColorRed     Color = "31"
+	ColorGreen   Color = "32"
+	ColorYellow  Color = "33"
+	ColorBlue    Color = "34"
+	ColorMagenta Color = "35"
+	ColorCyan    Color = "36"
+)
+
+// PrefixColors is a palette for assigning distinct colors to commands run
+// side by side.
+var PrefixColors = []Color{ColorCyan, ColorMagenta, ColorYellow, ColorGreen, ColorBlue, ColorRed}
+
+func (opts CmdArgs) linePrefix() string {
+	if opts.Prefix == "" || opts.PrefixColor == ColorNone {
+		return opts.Prefix
+	}
+
+	return "\x1b[" + string(opts.PrefixColor) + "m" + opts.Prefix + "\x1b[0m"
+}
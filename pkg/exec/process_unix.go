@@ -0,0 +1,55 @@
+//go:build !windows
+
+package exec
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// processGroup signals the command, or with own set places it in its own
+// process group so signals reach every process it spawns.
+type processGroup struct {
+	cmd             *exec.Cmd
+	interruptSignal os.Signal
+	own             bool
+}
+
+func newProcessGroup(cmd *exec.Cmd, interrupt os.Signal, own bool) *processGroup {
+	if own {
+		if cmd.SysProcAttr == nil {
+			cmd.SysProcAttr = &syscall.SysProcAttr{}
+		}
+		cmd.SysProcAttr.Setpgid = true
+	}
+
+	if interrupt == nil {
+		interrupt = syscall.SIGTERM
+	}
+
+	return &processGroup{cmd: cmd, interruptSignal: interrupt, own: own}
+}
+
+func (g *processGroup) started() error {
+	return nil
+}
+
+func (g *processGroup) interrupt() error {
+	sig, ok := g.interruptSignal.(syscall.Signal)
+	if !ok || !g.own {
+		return g.cmd.Process.Signal(g.interruptSignal)
+	}
+
+	return syscall.Kill(-g.cmd.Process.Pid, sig)
+}
+
+func (g *processGroup) kill() error {
+	if !g.own {
+		return g.cmd.Process.Kill()
+	}
+
+	return syscall.Kill(-g.cmd.Process.Pid, syscall.SIGKILL)
+}
+
+func (g *processGroup) release() {}
@@ -0,0 +1,76 @@
+//go:build windows
+
+package exec
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// processGroup assigns the command to a job object so the whole tree can be
+// terminated. With own set it also starts in a new console process group,
+// which CTRL_BREAK can be sent to.
+type processGroup struct {
+	cmd *exec.Cmd
+	job windows.Handle
+	own bool
+}
+
+func newProcessGroup(cmd *exec.Cmd, _ os.Signal, own bool) *processGroup {
+	if own {
+		if cmd.SysProcAttr == nil {
+			cmd.SysProcAttr = &syscall.SysProcAttr{}
+		}
+		cmd.SysProcAttr.CreationFlags |= windows.CREATE_NEW_PROCESS_GROUP
+	}
+
+	return &processGroup{cmd: cmd, own: own}
+}
+
+func (g *processGroup) started() error {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return err
+	}
+
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(g.cmd.Process.Pid))
+	if err != nil {
+		windows.CloseHandle(job)
+		return err
+	}
+	defer windows.CloseHandle(process)
+
+	if err := windows.AssignProcessToJobObject(job, process); err != nil {
+		windows.CloseHandle(job)
+		return err
+	}
+
+	g.job = job
+	return nil
+}
+
+func (g *processGroup) interrupt() error {
+	if !g.own {
+		// Console events go to a whole group, which would include us.
+		return g.cmd.Process.Signal(os.Interrupt)
+	}
+
+	return windows.GenerateConsoleCtrlEvent(windows.CTRL_BREAK_EVENT, uint32(g.cmd.Process.Pid))
+}
+
+func (g *processGroup) kill() error {
+	if g.job == 0 {
+		return g.cmd.Process.Kill()
+	}
+
+	return windows.TerminateJobObject(g.job, 1)
+}
+
+func (g *processGroup) release() {
+	if g.job != 0 {
+		windows.CloseHandle(g.job)
+	}
+}
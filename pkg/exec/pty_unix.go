@@ -0,0 +1,149 @@
+//go:build !windows
+
+package exec
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// ExecuteWithPTY runs the command attached to a pseudo-terminal so programs
+// that check isatty prompt and colorize as they would interactively. When no
+// stdin is given, the terminal is switched to raw mode and keystrokes are
+// passed through until the command exits; window size changes are
+// propagated to the child. The
+// passthrough reads os.Stdin directly, so it must not overlap a pending
+// system.ReadLineWithTimeout read.
+func (c *Cmd) ExecuteWithPTY(opts CmdArgs) error {
+	start := time.Now()
+	err := c.executeWithPTY(opts)
+	c.logExecution(opts, time.Since(start), err)
+	return err
+}
+
+func (c *Cmd) executeWithPTY(opts CmdArgs) error {
+	cmd, err := c.command(opts)
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = nil
+
+	ptmx, err := pty.Start(cmd)
+	if err != nil {
+		return err
+	}
+	defer ptmx.Close()
+
+	stdinFd := int(os.Stdin.Fd())
+	interactive := term.IsTerminal(stdinFd)
+
+	if interactive {
+		resize := make(chan os.Signal, 1)
+		signal.Notify(resize, syscall.SIGWINCH)
+		defer func() {
+			signal.Stop(resize)
+			close(resize)
+		}()
+
+		go func() {
+			for range resize {
+				_ = pty.InheritSize(os.Stdin, ptmx)
+			}
+		}()
+		resize <- syscall.SIGWINCH
+	}
+
+	stdin := opts.stdin()
+	if stdin == nil {
+		stdin = os.Stdin
+		if interactive {
+			state, err := term.MakeRaw(stdinFd)
+			if err != nil {
+				return err
+			}
+			defer term.Restore(stdinFd, state)
+		}
+	}
+
+	// The passthrough has to stop with the command, or it would take the
+	// next keystroke from whoever reads stdin after us.
+	stop := make(chan struct{})
+	copied := make(chan struct{})
+	input, cancelable := cancelableStdin(stdin, stop)
+	go func() {
+		defer close(copied)
+		_, _ = io.Copy(ptmx, input)
+	}()
+
+	// Reading the pty fails with EIO once the child exits, so the copy
+	// error is not meaningful here.
+	_, _ = io.Copy(opts.stdout(), ptmx)
+
+	err = cmd.Wait()
+	close(stop)
+	ptmx.Close()
+	if cancelable {
+		<-copied
+	}
+
+	if err != nil {
+		return wrapExitError(opts, err)
+	}
+
+	return nil
+}
+
+// pollInterval bounds how long the stdin passthrough takes to notice that
+// the command has ended.
+const pollInterval = 100 * time.Millisecond
+
+// cancelableStdin wraps a file so reads of it end when stop is closed. Other
+// readers are returned as is and read until they end.
+func cancelableStdin(r io.Reader, stop <-chan struct{}) (io.Reader, bool) {
+	f, ok := r.(*os.File)
+	if !ok || int(f.Fd()) >= unix.FD_SETSIZE {
+		return r, false
+	}
+
+	return &cancelableReader{f: f, fd: int(f.Fd()), stop: stop}, true
+}
+
+// cancelableReader only reads f once select reports it readable, so a read
+// can be abandoned when stop is closed rather than blocking until input
+// arrives.
+type cancelableReader struct {
+	f    *os.File
+	fd   int
+	stop <-chan struct{}
+}
+
+func (r *cancelableReader) Read(p []byte) (int, error) {
+	for {
+		select {
+		case <-r.stop:
+			return 0, io.EOF
+		default:
+		}
+
+		var fds unix.FdSet
+		fds.Set(r.fd)
+		timeout := unix.NsecToTimeval(pollInterval.Nanoseconds())
+		n, err := unix.Select(r.fd+1, &fds, nil, nil, &timeout)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if n > 0 {
+			return r.f.Read(p)
+		}
+	}
+}
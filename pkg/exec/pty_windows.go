@@ -0,0 +1,9 @@
+//go:build windows
+
+package exec
+
+import "fmt"
+
+func (c *Cmd) ExecuteWithPTY(opts CmdArgs) error {
+	return fmt.Errorf("pseudo-terminals are not supported on windows")
+}
@@ -0,0 +1,161 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VersionArgs holds the arguments used to query a binary's version when it
+// does not support --version.
+var VersionArgs = map[string][]string{
+	"go":      {"version"},
+	"kubectl": {"version", "--client"},
+	"helm":    {"version", "--short"},
+}
+
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// RequirementError reports a binary that is missing or does not satisfy a
+// version constraint.
+type RequirementError struct {
+	Binary     string
+	Constraint string
+	// Path is empty when the binary could not be found.
+	Path    string
+	Version string
+	Reason  string
+}
+
+func (e *RequirementError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%s is required but was not found in PATH", e.Binary)
+	}
+
+	if e.Version == "" {
+		return fmt.Sprintf("%s: %s", e.Binary, e.Reason)
+	}
+
+	return fmt.Sprintf("%s %s is installed but %s is required", e.Binary, e.Version, e.Constraint)
+}
+
+// Require checks that binary is on PATH and, when constraint is not empty,
+// that its reported version satisfies it. Constraints take the form
+// ">=24.0", ">1.2.3", "<=2", "=1.30" or a bare version meaning ">=".
+func Require(binary, constraint string) error {
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return &RequirementError{Binary: binary, Constraint: constraint, Reason: "not found"}
+	}
+
+	if constraint == "" {
+		return nil
+	}
+
+	args, ok := VersionArgs[binary]
+	if !ok {
+		args = []string{"--version"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, args...).CombinedOutput()
+	if err != nil {
+		return &RequirementError{Binary: binary, Constraint: constraint, Path: path, Reason: fmt.Sprintf("failed to get version: %s", err.Error())}
+	}
+
+	version := versionPattern.Find(bytes.TrimSpace(out))
+	if version == nil {
+		return &RequirementError{Binary: binary, Constraint: constraint, Path: path, Reason: "could not parse version output"}
+	}
+
+	ok, err = satisfies(string(version), constraint)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return &RequirementError{Binary: binary, Constraint: constraint, Path: path, Version: string(version), Reason: "version does not satisfy constraint"}
+	}
+
+	return nil
+}
+
+func satisfies(version, constraint string) (bool, error) {
+	constraint = strings.TrimSpace(constraint)
+	op := ">="
+	for _, candidate := range []string{">=", "<=", "==", ">", "<", "="} {
+		if strings.HasPrefix(constraint, candidate) {
+			op = candidate
+			constraint = strings.TrimSpace(strings.TrimPrefix(constraint, candidate))
+			break
+		}
+	}
+
+	want, err := parseVersion(strings.TrimPrefix(constraint, "v"))
+	if err != nil {
+		return false, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+	}
+
+	have, err := parseVersion(version)
+	if err != nil {
+		return false, err
+	}
+
+	cmp := compareVersions(have, want)
+	switch op {
+	case ">=":
+		return cmp >= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case "<":
+		return cmp < 0, nil
+	default:
+		return cmp == 0, nil
+	}
+}
+
+func parseVersion(version string) ([]int, error) {
+	parts := strings.Split(version, ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q", version)
+		}
+		numbers[i] = n
+	}
+
+	return numbers, nil
+}
+
+// compareVersions compares dotted versions numerically, treating missing
+// components as zero.
+func compareVersions(a, b []int) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,52 @@
+package exec
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		constraint  string
+		expected    bool
+		expectError bool
+	}{
+		{name: "Greater or equal", version: "24.0.7", constraint: ">=24.0", expected: true},
+		{name: "Older major", version: "23.0.1", constraint: ">=24.0", expected: false},
+		{name: "Bare version", version: "1.30.2", constraint: "1.30", expected: true},
+		{name: "Strictly greater", version: "2.0", constraint: ">2.0.0", expected: false},
+		{name: "Less than", version: "1.9.9", constraint: "<1.10", expected: true},
+		{name: "Less or equal", version: "1.10", constraint: "<=1.10.0", expected: true},
+		{name: "Equal", version: "3.1.0", constraint: "=3.1", expected: true},
+		{name: "Double equal", version: "3.1.1", constraint: "==3.1", expected: false},
+		{name: "Leading v", version: "1.2.3", constraint: ">=v1.2", expected: true},
+		{name: "Invalid constraint", version: "1.2.3", constraint: ">=latest", expectError: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := satisfies(tt.version, tt.constraint)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+			if got != tt.expected {
+				t.Errorf("satisfies(%q, %q) = %v, expected %v", tt.version, tt.constraint, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRequireMissingBinary(t *testing.T) {
+	err := Require("devkit-binary-does-not-exist", ">=1.0")
+
+	var reqErr *RequirementError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("Expected RequirementError, got: %v", err)
+	}
+	if reqErr.Path != "" {
+		t.Errorf("Expected empty path, got: %s", reqErr.Path)
+	}
+}
@@ -0,0 +1,109 @@
+package exec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ExecuteScript writes content to a private temporary file and runs it with
+// interpreter, removing the file afterwards. When interpreter is empty the
+// script's shebang is used, falling back to sh (cmd on Windows). Line endings
+// are normalized for the target platform. opts.Run is ignored and opts.Args
+// are passed to the script.
+func (c *Cmd) ExecuteScript(content string, interpreter string, opts CmdArgs) error {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	shebang := parseShebang(content)
+
+	if interpreter == "" && (runtime.GOOS == "windows" || shebang == "") {
+		interpreter = shebang
+		if interpreter == "" {
+			interpreter = defaultInterpreter()
+		}
+	}
+
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(interpreter)), ".exe")
+	if name == "cmd" {
+		content = strings.ReplaceAll(content, "\n", "\r\n")
+	}
+
+	dir, err := os.MkdirTemp("", "devkit-script-")
+	if err != nil {
+		return fmt.Errorf("failed to create script directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "script"+scriptExtension(name))
+	if err := os.WriteFile(path, []byte(content), 0700); err != nil {
+		return fmt.Errorf("failed to write script: %w", err)
+	}
+
+	opts.Shell = false
+	opts.Args = append(interpreterArgs(name, path), opts.Args...)
+	if interpreter == "" {
+		opts.Run = path
+		opts.Args = opts.Args[1:]
+	} else {
+		opts.Run = interpreter
+	}
+
+	return c.ExecuteWithStream(opts)
+}
+
+// parseShebang returns the interpreter named by a #! line, resolving
+// "/usr/bin/env python3" style lines to the program name.
+func parseShebang(content string) string {
+	if !strings.HasPrefix(content, "#!") {
+		return ""
+	}
+
+	line, _, _ := strings.Cut(content[2:], "\n")
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	if filepath.Base(fields[0]) == "env" && len(fields) > 1 {
+		return fields[1]
+	}
+
+	if runtime.GOOS == "windows" {
+		return filepath.Base(fields[0])
+	}
+
+	return fields[0]
+}
+
+func defaultInterpreter() string {
+	if runtime.GOOS == "windows" {
+		return "cmd"
+	}
+
+	return "sh"
+}
+
+func scriptExtension(interpreter string) string {
+	switch interpreter {
+	case "cmd":
+		return ".cmd"
+	case "powershell", "pwsh":
+		return ".ps1"
+	case "python", "python3":
+		return ".py"
+	default:
+		return ""
+	}
+}
+
+func interpreterArgs(interpreter, path string) []string {
+	switch interpreter {
+	case "cmd":
+		return []string{"/C", path}
+	case "powershell", "pwsh":
+		return []string{"-NoProfile", "-ExecutionPolicy", "Bypass", "-File", path}
+	default:
+		return []string{path}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package exec
+
+import "os/exec"
+
+// shellCommand runs script with sh -c. Any args are available to the script
+// as positional parameters $1, $2, ...
+func shellCommand(script string, args []string) *exec.Cmd {
+	return exec.Command("sh", append([]string{"-c", script, "sh"}, args...)...)
+}
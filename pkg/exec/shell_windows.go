@@ -0,0 +1,27 @@
+//go:build windows
+
+package exec
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// shellCommand runs script with cmd /C. The command line is passed verbatim
+// since cmd.exe does not follow the usual argument quoting rules; args are
+// escaped so they reach the script as single arguments.
+func shellCommand(script string, args []string) *exec.Cmd {
+	parts := []string{script}
+	for _, arg := range args {
+		parts = append(parts, quoteCmdArg(arg))
+	}
+
+	shell := comspec()
+	cmd := exec.Command(shell)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine: fmt.Sprintf(`%s /d /s /c "%s"`, syscall.EscapeArg(shell), strings.Join(parts, " ")),
+	}
+	return cmd
+}
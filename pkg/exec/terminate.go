@@ -0,0 +1,47 @@
+package exec
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+const defaultGracePeriod = 5 * time.Second
+
+// terminateOnDone watches ctx while the command runs. When ctx ends, the
+// process group is interrupted and, if still alive after grace, killed. The
+// returned function stops the watcher and reports whether it fired.
+func terminateOnDone(ctx context.Context, group *processGroup, grace time.Duration) func() bool {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	var fired atomic.Bool
+
+	go func() {
+		defer close(finished)
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+		}
+
+		fired.Store(true)
+		if err := group.interrupt(); err != nil {
+			_ = group.kill()
+			return
+		}
+
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-done:
+		case <-timer.C:
+			_ = group.kill()
+		}
+	}()
+
+	return func() bool {
+		close(done)
+		<-finished
+		return fired.Load()
+	}
+}
@@ -4,41 +4,139 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/codec"
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/trace"
 )
 
+type CompressOptions struct {
+	// Prefix is prepended to every entry name, e.g. "mychart".
+	Prefix string
+	// Exclude skips files, and directories with everything below them, for
+	// which it returns true. rel is relative to the source directory and
+	// uses forward slashes.
+	Exclude func(rel string, isDir bool) bool
+	// Reproducible clears timestamps and ownership and normalizes modes to
+	// 0644 or 0755, so the same tree always produces the same archive.
+	Reproducible bool
+	// Codec compresses the archive. The default is codec.Gzip.
+	Codec codec.Codec
+	// FS is read instead of the disk, e.g. a MemFS in tests.
+	FS FS
+	// Transform rewrites the content of regular files before they are
+	// archived, e.g. to redact secrets. Returning nil leaves the file out.
+	Transform func(rel string, data []byte) ([]byte, error)
+}
+
 func CompressDir(src string) ([]byte, error) {
+	return CompressDirWithOptions(src, CompressOptions{})
+}
+
+func CompressDirWithOptions(src string, opts CompressOptions) ([]byte, error) {
+	return CompressDirContext(context.Background(), src, opts)
+}
+
+// CompressDirContext is CompressDirWithOptions recorded as a span of the
+// trace in ctx.
+func CompressDirContext(ctx context.Context, src string, opts CompressOptions) ([]byte, error) {
+	_, span := trace.Start(ctx, "fs.compress_dir", trace.String("src", src))
+	data, err := compressDir(src, opts)
+	span.SetAttributes(trace.Int64("bytes", int64(len(data))))
+	span.Finish(err)
+	return data, err
+}
+
+func compressDir(src string, opts CompressOptions) ([]byte, error) {
+	defer log.Timed("compress_dir", "src", src)()
+	fsys := orOS(opts.FS)
+	compressor := opts.Codec
+	if compressor == nil {
+		compressor = codec.Gzip
+	}
+
 	var buf bytes.Buffer
-	gw := gzip.NewWriter(&buf)
-	defer gw.Close()
-	tw := tar.NewWriter(gw)
+	cw, err := compressor.NewWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+	defer cw.Close()
+	tw := tar.NewWriter(cw)
 	defer tw.Close()
 
-	err := filepath.Walk(src, func(file string, fi os.FileInfo, err error) error {
+	err = Walk(fsys, src, func(file string, fi os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		header, err := tar.FileInfoHeader(fi, fi.Name())
+		relativePath, err := filepath.Rel(src, file)
 		if err != nil {
-			return fmt.Errorf("failed to create tar header: %w", err)
+			return fmt.Errorf("failed to get relative path: %w", err)
 		}
 
-		relativePath, err := filepath.Rel(src, file)
+		if opts.Exclude != nil && relativePath != "." && opts.Exclude(filepath.ToSlash(relativePath), fi.IsDir()) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var link string
+		if fi.Mode()&os.ModeSymlink != 0 {
+			link, err = fsys.Readlink(file)
+			if err != nil {
+				return fmt.Errorf("failed to read symlink: %w", err)
+			}
+		}
+
+		var content []byte
+		if opts.Transform != nil && fi.Mode().IsRegular() {
+			data, err := ReadFile(fsys, file)
+			if err != nil {
+				return fmt.Errorf("failed to read file: %w", err)
+			}
+			content, err = opts.Transform(filepath.ToSlash(relativePath), data)
+			if err != nil {
+				return fmt.Errorf("failed to transform %s: %w", relativePath, err)
+			}
+			if content == nil {
+				return nil
+			}
+		}
+
+		header, err := tar.FileInfoHeader(fi, link)
 		if err != nil {
-			return fmt.Errorf("failed to get relative path: %w", err)
+			return fmt.Errorf("failed to create tar header: %w", err)
+		}
+		if content != nil {
+			header.Size = int64(len(content))
 		}
 
 		header.Name = filepath.Join(relativePath)
+		if opts.Prefix != "" {
+			header.Name = path.Join(opts.Prefix, filepath.ToSlash(relativePath))
+		}
+		if opts.Reproducible {
+			normalizeHeader(header)
+		}
 		if err := tw.WriteHeader(header); err != nil {
 			return fmt.Errorf("failed to write tar header: %w", err)
 		}
 
-		if !fi.Mode().IsDir() {
-			data, err := os.Open(file)
+		if content != nil {
+			if _, err := tw.Write(content); err != nil {
+				return fmt.Errorf("failed to write file data: %w", err)
+			}
+		} else if fi.Mode().IsRegular() {
+			data, err := fsys.Open(file)
 			if err != nil {
 				return fmt.Errorf("failed to open file: %w", err)
 			}
@@ -61,21 +159,42 @@ func CompressDir(src string) ([]byte, error) {
 		return nil, fmt.Errorf("failed to close tar writer: %w", err)
 	}
 
-	if err := gw.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close gzip writer: %w", err)
+	if err := cw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close %s writer: %w", compressor.Name(), err)
 	}
 
 	return buf.Bytes(), nil
 }
 
+func normalizeHeader(header *tar.Header) {
+	header.ModTime = time.Unix(0, 0)
+	header.AccessTime = time.Time{}
+	header.ChangeTime = time.Time{}
+	header.Uid, header.Gid = 0, 0
+	header.Uname, header.Gname = "", ""
+
+	mode := int64(0644)
+	if header.Typeflag == tar.TypeDir || header.Mode&0111 != 0 {
+		mode = 0755
+	}
+	header.Mode = mode
+}
+
+// DecompressDir extracts a tar archive, plain or compressed with any
+// registered codec, into dst.
 func DecompressDir(tarBytes []byte, dst string) error {
-	byteReader := bytes.NewReader(tarBytes)
-	gzipReader, err := gzip.NewReader(byteReader)
+	return DecompressDirFS(OS, tarBytes, dst)
+}
+
+// DecompressDirFS is DecompressDir writing to fsys.
+func DecompressDirFS(fsys FS, tarBytes []byte, dst string) error {
+	defer log.Timed("decompress_dir", "dst", dst)()
+	reader, err := codec.NewReader(bytes.NewReader(tarBytes))
 	if err != nil {
-		return fmt.Errorf("error creating gzip reader: %w", err)
+		return fmt.Errorf("error creating decompressor: %w", err)
 	}
-	defer gzipReader.Close()
-	tarReader := tar.NewReader(gzipReader)
+	defer reader.Close()
+	tarReader := tar.NewReader(reader)
 
 	for {
 		header, err := tarReader.Next()
@@ -87,14 +206,39 @@ func DecompressDir(tarBytes []byte, dst string) error {
 		}
 
 		target := filepath.Join(dst, header.Name)
+		if !within(dst, target) {
+			return fmt.Errorf("invalid file path in archive: %s", header.Name)
+		}
+
+		// Earlier entries may have created links, so the path is resolved
+		// through them rather than trusted lexically.
+		rel, err := filepath.Rel(dst, target)
+		if err != nil {
+			return fmt.Errorf("invalid file path in archive: %s", header.Name)
+		}
+		dir, err := resolve(fsys, dst, dst, filepath.Dir(rel))
+		if err != nil {
+			return fmt.Errorf("invalid file path in archive: %s: %w", header.Name, err)
+		}
+		target = filepath.Join(dir, filepath.Base(rel))
 
 		switch header.Typeflag {
 		case tar.TypeDir:
-			if err := os.MkdirAll(target, os.FileMode(header.Mode)); err != nil {
+			target, err = resolve(fsys, dst, dir, filepath.Base(rel))
+			if err != nil {
+				return fmt.Errorf("invalid file path in archive: %s: %w", header.Name, err)
+			}
+			if err := fsys.MkdirAll(target, os.FileMode(header.Mode)); err != nil {
 				return fmt.Errorf("error creating directory: %w", err)
 			}
 		case tar.TypeReg:
-			file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(header.Mode))
+			// Replace a link rather than write through it.
+			if fi, err := fsys.Lstat(target); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+				if err := fsys.Remove(target); err != nil {
+					return fmt.Errorf("error replacing symlink: %w", err)
+				}
+			}
+			file, err := fsys.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(header.Mode))
 			if err != nil {
 				return fmt.Errorf("error creating file: %w", err)
 			}
@@ -104,6 +248,17 @@ func DecompressDir(tarBytes []byte, dst string) error {
 				return fmt.Errorf("error writing file content: %w", err)
 			}
 			file.Close()
+		case tar.TypeSymlink:
+			// Links may only point at other entries of the archive.
+			if _, err := resolve(fsys, dst, dir, header.Linkname); err != nil {
+				return fmt.Errorf("invalid symlink in archive: %s -> %s: %w", header.Name, header.Linkname, err)
+			}
+			if err := fsys.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return fmt.Errorf("error creating directory: %w", err)
+			}
+			if err := fsys.Symlink(header.Linkname, target); err != nil {
+				return fmt.Errorf("error creating symlink: %w", err)
+			}
 		default:
 			return fmt.Errorf("unsupported file type: %v", header.Typeflag)
 		}
@@ -112,6 +267,74 @@ func DecompressDir(tarBytes []byte, dst string) error {
 	return nil
 }
 
+// within reports whether target is dst or below it.
+func within(dst, target string) bool {
+	dst = filepath.Clean(dst)
+	return target == dst || strings.HasPrefix(target, dst+string(os.PathSeparator))
+}
+
+// maxLinks bounds how many symlinks resolve follows, like ELOOP.
+const maxLinks = 40
+
+// resolve walks name from the directory from, which must be inside dst, one
+// component at a time, following symlinks that already exist in fsys the way
+// the OS would, and returns the real path. It fails if any step, including
+// a ".." after a link, leaves dst.
+func resolve(fsys FS, dst, from, name string) (string, error) {
+	dst = filepath.Clean(dst)
+	if filepath.IsAbs(name) || path.IsAbs(name) {
+		return "", fmt.Errorf("absolute path %s", name)
+	}
+
+	cur := from
+	rest := splitPath(name)
+	links := 0
+	for len(rest) > 0 {
+		part := rest[0]
+		rest = rest[1:]
+
+		switch part {
+		case ".":
+			continue
+		case "..":
+			if cur == dst {
+				return "", fmt.Errorf("%s leaves %s", name, dst)
+			}
+			cur = filepath.Dir(cur)
+			continue
+		}
+
+		next := filepath.Join(cur, part)
+		fi, err := fsys.Lstat(next)
+		if err != nil || fi.Mode()&os.ModeSymlink == 0 {
+			cur = next
+			continue
+		}
+
+		if links++; links > maxLinks {
+			return "", fmt.Errorf("too many links in %s", name)
+		}
+		link, err := fsys.Readlink(next)
+		if err != nil {
+			return "", fmt.Errorf("failed to read symlink: %w", err)
+		}
+		if filepath.IsAbs(link) || path.IsAbs(link) {
+			return "", fmt.Errorf("%s links to absolute path %s", next, link)
+		}
+		rest = append(splitPath(link), rest...)
+	}
+
+	return cur, nil
+}
+
+// splitPath splits p on both slash styles without cleaning it, since ".."
+// after a symlink means the link target's parent.
+func splitPath(p string) []string {
+	return strings.FieldsFunc(p, func(r rune) bool {
+		return r == '/' || r == os.PathSeparator
+	})
+}
+
 func CompressFile(data []byte, filename string) ([]byte, error) {
 	var buf bytes.Buffer
 	gw := gzip.NewWriter(&buf)
@@ -11,6 +11,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/codec"
 )
 
 func TestCompressDir(t *testing.T) {
@@ -254,15 +257,15 @@ func TestDecompressDir(t *testing.T) {
 		{
 			name: "Decompress archive with unsupported file type",
 			setup: func() ([]byte, func(), error) {
-				// Create a tar.gz archive with a symbolic link
+				// Create a tar.gz archive with a hard link
 				var buf bytes.Buffer
 				gw := gzip.NewWriter(&buf)
 				tw := tar.NewWriter(gw)
 				hdr := &tar.Header{
-					Name:     "symlink",
-					Mode:     0777,
+					Name:     "hardlink",
+					Mode:     0644,
 					Linkname: "somefile",
-					Typeflag: tar.TypeSymlink,
+					Typeflag: tar.TypeLink,
 				}
 				if err := tw.WriteHeader(hdr); err != nil {
 					return nil, nil, err
@@ -348,3 +351,158 @@ func verifyDecompressedContent(data []byte, dstDir string) error {
 	})
 	return err
 }
+
+func TestSymlinkRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "file.txt"), []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("sub", "file.txt"), filepath.Join(src, "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	data, err := CompressDir(src)
+	if err != nil {
+		t.Fatalf("CompressDir failed: %v", err)
+	}
+
+	dst := t.TempDir()
+	if err := DecompressDir(data, dst); err != nil {
+		t.Fatalf("DecompressDir failed: %v", err)
+	}
+	if target, err := os.Readlink(filepath.Join(dst, "link")); err != nil || target != filepath.Join("sub", "file.txt") {
+		t.Errorf("Expected link to sub/file.txt, got: %q, %v", target, err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dst, "link")); err != nil || string(content) != "content" {
+		t.Errorf("Expected the link to resolve, got: %q, %v", content, err)
+	}
+
+	link := func(name, target string) *tar.Header {
+		return &tar.Header{Name: name, Typeflag: tar.TypeSymlink, Linkname: target}
+	}
+
+	tests := []struct {
+		name    string
+		entries []*tar.Header
+	}{
+		{"absolute", []*tar.Header{link("sub/link", "/etc/passwd")}},
+		{"escaping", []*tar.Header{link("sub/link", "../../outside")}},
+		{"chained", []*tar.Header{
+			link("a", "."),
+			link("a/b", ".."),
+			{Name: "b/evil.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+		}},
+		{"parent of link", []*tar.Header{
+			link("a", "."),
+			link("p", "a/.."),
+		}},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			gw := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(gw)
+			for _, h := range tt.entries {
+				tw.WriteHeader(h)
+				if h.Size > 0 {
+					tw.Write(make([]byte, h.Size))
+				}
+			}
+			tw.Close()
+			gw.Close()
+
+			parent := t.TempDir()
+			dst := filepath.Join(parent, "dst")
+			if err := DecompressDir(buf.Bytes(), dst); err == nil || !strings.Contains(err.Error(), "invalid") {
+				t.Errorf("Expected the archive to be rejected, got: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(parent, "evil.txt")); err == nil {
+				t.Error("Expected nothing to be written outside the destination")
+			}
+		})
+	}
+}
+
+func TestCompressDirWithOptions(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "templates"), 0755)
+	os.MkdirAll(filepath.Join(dir, ".git"), 0755)
+	os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte("name: app"), 0600)
+	os.WriteFile(filepath.Join(dir, "templates", "deploy.yaml"), []byte("kind: Deployment"), 0644)
+	os.WriteFile(filepath.Join(dir, "templates", "skip.txt"), []byte("skip"), 0644)
+	os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref"), 0644)
+
+	opts := CompressOptions{
+		Prefix: "app",
+		Exclude: func(rel string, isDir bool) bool {
+			return (isDir && rel == ".git") || rel == "templates/skip.txt"
+		},
+		Reproducible: true,
+	}
+
+	first, err := CompressDirWithOptions(dir, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	now := time.Now().Add(time.Hour)
+	os.Chtimes(filepath.Join(dir, "Chart.yaml"), now, now)
+	second, err := CompressDirWithOptions(dir, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("Expected reproducible archives to ignore modification times")
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	var entries []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, fmt.Sprintf("%s %o", header.Name, header.Mode))
+		if header.Uid != 0 || header.Uname != "" || !header.ModTime.Equal(time.Unix(0, 0)) {
+			t.Errorf("Expected normalized metadata for %s, got: %+v", header.Name, header)
+		}
+	}
+
+	expected := []string{"app 755", "app/Chart.yaml 644", "app/templates 755", "app/templates/deploy.yaml 644"}
+	if strings.Join(entries, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got: %v", expected, entries)
+	}
+}
+
+func TestCompressDirCodec(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "file.txt"), []byte("content"), 0644)
+
+	data, err := CompressDirWithOptions(dir, CompressOptions{Codec: codec.None})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		t.Fatal("Expected an uncompressed tar")
+	}
+
+	dst := t.TempDir()
+	if err := DecompressDir(data, dst); err != nil {
+		t.Fatalf("Expected plain tar archives to extract, got: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dst, "file.txt")); string(content) != "content" {
+		t.Errorf("Expected content, got: %q", content)
+	}
+}
@@ -0,0 +1,206 @@
+package fs
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/codec"
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// Fields reported in ArchiveChange.Fields.
+const (
+	FieldType    = "type"
+	FieldSize    = "size"
+	FieldMode    = "mode"
+	FieldContent = "content"
+	FieldLink    = "link"
+	FieldModTime = "mtime"
+	FieldOwner   = "owner"
+)
+
+type ArchiveEntry struct {
+	Name     string
+	Type     byte
+	Size     int64
+	Mode     int64
+	Linkname string
+	ModTime  time.Time
+	Uid, Gid int
+	// Digest is the sha256 of the content of regular files.
+	Digest string
+}
+
+type ArchiveChange struct {
+	Kind ChangeKind
+	Name string
+	// Before is nil for added entries and After for removed ones.
+	Before, After *ArchiveEntry
+	// Fields lists what differs for modified entries.
+	Fields []string
+}
+
+type ArchiveDiff struct {
+	Changes []ArchiveChange
+	// Reordered is set when both archives hold the same entries in a
+	// different order, which alone changes the archive digest.
+	Reordered bool
+}
+
+// Empty reports whether the archives have identical entries in the same
+// order. Their compressed bytes may still differ, e.g. by gzip level.
+func (d *ArchiveDiff) Empty() bool {
+	return len(d.Changes) == 0 && !d.Reordered
+}
+
+// String lists the changes one per line: "+ name", "- name" and
+// "~ name (size, content)".
+func (d *ArchiveDiff) String() string {
+	var b strings.Builder
+	for _, change := range d.Changes {
+		switch change.Kind {
+		case ChangeAdded:
+			fmt.Fprintf(&b, "+ %s\n", change.Name)
+		case ChangeRemoved:
+			fmt.Fprintf(&b, "- %s\n", change.Name)
+		case ChangeModified:
+			fmt.Fprintf(&b, "~ %s (%s)\n", change.Name, strings.Join(change.Fields, ", "))
+		}
+	}
+	if d.Reordered {
+		b.WriteString("entries are in a different order\n")
+	}
+	return b.String()
+}
+
+// DiffArchives compares two tar archives, gzip compressed or not, entry by
+// entry. Changes are sorted by name.
+func DiffArchives(a, b io.Reader) (*ArchiveDiff, error) {
+	defer log.Timed("diff_archives")()
+	before, beforeOrder, err := readArchiveEntries(a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read first archive: %w", err)
+	}
+	after, afterOrder, err := readArchiveEntries(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read second archive: %w", err)
+	}
+
+	diff := &ArchiveDiff{}
+	for name, old := range before {
+		current, ok := after[name]
+		if !ok {
+			diff.Changes = append(diff.Changes, ArchiveChange{Kind: ChangeRemoved, Name: name, Before: old})
+			continue
+		}
+		if fields := compareEntries(old, current); len(fields) > 0 {
+			diff.Changes = append(diff.Changes, ArchiveChange{Kind: ChangeModified, Name: name, Before: old, After: current, Fields: fields})
+		}
+	}
+	for name, current := range after {
+		if _, ok := before[name]; !ok {
+			diff.Changes = append(diff.Changes, ArchiveChange{Kind: ChangeAdded, Name: name, After: current})
+		}
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool { return diff.Changes[i].Name < diff.Changes[j].Name })
+
+	if len(diff.Changes) == 0 {
+		diff.Reordered = strings.Join(beforeOrder, "\x00") != strings.Join(afterOrder, "\x00")
+	}
+
+	return diff, nil
+}
+
+func readArchiveEntries(r io.Reader) (map[string]*ArchiveEntry, []string, error) {
+	src, err := codec.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating decompressor: %w", err)
+	}
+	defer src.Close()
+
+	entries := map[string]*ArchiveEntry{}
+	var order []string
+	tr := tar.NewReader(src)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading tar archive: %w", err)
+		}
+
+		entry := &ArchiveEntry{
+			Name:     normalizeEntryName(header.Name),
+			Type:     header.Typeflag,
+			Size:     header.Size,
+			Mode:     header.Mode,
+			Linkname: header.Linkname,
+			ModTime:  header.ModTime,
+			Uid:      header.Uid,
+			Gid:      header.Gid,
+		}
+		if header.Typeflag == tar.TypeReg {
+			hash := sha256.New()
+			if _, err := io.Copy(hash, tr); err != nil {
+				return nil, nil, fmt.Errorf("error reading %s: %w", header.Name, err)
+			}
+			entry.Digest = "sha256:" + hex.EncodeToString(hash.Sum(nil))
+		}
+
+		// Later entries replace earlier ones, as they would on extraction.
+		entries[entry.Name] = entry
+		order = append(order, entry.Name)
+	}
+
+	return entries, order, nil
+}
+
+func normalizeEntryName(name string) string {
+	name = path.Clean(strings.TrimPrefix(name, "./"))
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+func compareEntries(a, b *ArchiveEntry) []string {
+	var fields []string
+	if a.Type != b.Type {
+		fields = append(fields, FieldType)
+	}
+	if a.Size != b.Size {
+		fields = append(fields, FieldSize)
+	}
+	if a.Mode != b.Mode {
+		fields = append(fields, FieldMode)
+	}
+	if a.Digest != b.Digest {
+		fields = append(fields, FieldContent)
+	}
+	if a.Linkname != b.Linkname {
+		fields = append(fields, FieldLink)
+	}
+	if !a.ModTime.Equal(b.ModTime) {
+		fields = append(fields, FieldModTime)
+	}
+	if a.Uid != b.Uid || a.Gid != b.Gid {
+		fields = append(fields, FieldOwner)
+	}
+	return fields
+}
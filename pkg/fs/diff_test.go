@@ -0,0 +1,127 @@
+package fs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testEntry struct {
+	name    string
+	content string
+	mode    int64
+	modTime time.Time
+}
+
+func buildArchive(t *testing.T, entries []testEntry, compress bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var gw *gzip.Writer
+	tw := tar.NewWriter(&buf)
+	if compress {
+		gw = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gw)
+	}
+
+	for _, e := range entries {
+		mode := e.mode
+		if mode == 0 {
+			mode = 0644
+		}
+		header := &tar.Header{Name: e.name, Mode: mode, Size: int64(len(e.content)), ModTime: e.modTime, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(e.content))
+	}
+	tw.Close()
+	if gw != nil {
+		gw.Close()
+	}
+	return buf.Bytes()
+}
+
+func TestDiffArchives(t *testing.T) {
+	epoch := time.Unix(0, 0)
+	base := []testEntry{{name: "a.txt", content: "a"}, {name: "b.txt", content: "b"}}
+
+	tests := []struct {
+		name      string
+		before    []testEntry
+		after     []testEntry
+		compress  bool
+		expected  string
+		reordered bool
+	}{
+		{
+			name:     "Identical",
+			before:   base,
+			after:    base,
+			compress: true,
+			expected: "",
+		},
+		{
+			name:     "Added, removed and modified",
+			before:   []testEntry{{name: "a.txt", content: "a"}, {name: "./b.txt", content: "b"}},
+			after:    []testEntry{{name: "a.txt", content: "aa", mode: 0755}, {name: "c.txt", content: "c"}},
+			compress: true,
+			expected: "~ a.txt (size, mode, content)\n- b.txt\n+ c.txt\n",
+		},
+		{
+			name:     "Metadata only",
+			before:   base,
+			after:    []testEntry{{name: "a.txt", content: "a", modTime: epoch.Add(time.Hour)}, {name: "b.txt", content: "b"}},
+			expected: "~ a.txt (mtime)\n",
+		},
+		{
+			name:      "Reordered",
+			before:    base,
+			after:     []testEntry{base[1], base[0]},
+			expected:  "entries are in a different order\n",
+			reordered: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range tt.before {
+				if tt.before[i].modTime.IsZero() {
+					tt.before[i].modTime = epoch
+				}
+			}
+			for i := range tt.after {
+				if tt.after[i].modTime.IsZero() {
+					tt.after[i].modTime = epoch
+				}
+			}
+
+			a := buildArchive(t, tt.before, tt.compress)
+			b := buildArchive(t, tt.after, tt.compress)
+			diff, err := DiffArchives(bytes.NewReader(a), bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if diff.String() != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, diff.String())
+			}
+			if diff.Reordered != tt.reordered {
+				t.Errorf("Expected Reordered=%v, got: %v", tt.reordered, diff.Reordered)
+			}
+			if diff.Empty() != (tt.expected == "") {
+				t.Errorf("Expected Empty()=%v", tt.expected == "")
+			}
+		})
+	}
+}
+
+func TestDiffArchivesInvalid(t *testing.T) {
+	valid := buildArchive(t, []testEntry{{name: "a.txt", content: "a"}}, true)
+	_, err := DiffArchives(bytes.NewReader(valid), strings.NewReader("\x1f\x8bnot gzip"))
+	if err == nil {
+		t.Error("Expected an error for a corrupt archive")
+	}
+}
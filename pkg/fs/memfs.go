@@ -0,0 +1,340 @@
+package fs
+
+import (
+	"bytes"
+	"errors"
+	iofs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemFS is an FS held in memory, for tests. It is safe for concurrent use.
+type MemFS struct {
+	mu    sync.RWMutex
+	nodes map[string]*memNode
+}
+
+type memNode struct {
+	data    []byte
+	mode    os.FileMode
+	modTime time.Time
+	target  string
+}
+
+func NewMemFS() *MemFS {
+	return &MemFS{nodes: map[string]*memNode{
+		"/": {mode: os.ModeDir | 0755, modTime: time.Now()},
+	}}
+}
+
+// key maps name to an absolute slash-separated path. Relative names are
+// resolved against the root.
+func key(name string) string {
+	return path.Clean("/" + filepath.ToSlash(name))
+}
+
+func pathError(op, name string, err error) error {
+	return &iofs.PathError{Op: op, Path: name, Err: err}
+}
+
+// resolve follows symbolic links in the last element of name.
+func (m *MemFS) resolve(name string) (string, *memNode, bool) {
+	k := key(name)
+	for range 40 {
+		node, ok := m.nodes[k]
+		if !ok || node.mode&os.ModeSymlink == 0 {
+			return k, node, ok
+		}
+		target := filepath.ToSlash(node.target)
+		if !path.IsAbs(target) {
+			target = path.Join(path.Dir(k), target)
+		}
+		k = path.Clean(target)
+	}
+	return k, nil, false
+}
+
+func (m *MemFS) Open(name string) (File, error) {
+	return m.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (m *MemFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	k, node, ok := m.resolve(name)
+	if ok && flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0 {
+		return nil, pathError("open", name, iofs.ErrExist)
+	}
+	if !ok {
+		if flag&os.O_CREATE == 0 {
+			return nil, pathError("open", name, iofs.ErrNotExist)
+		}
+		if parent, ok := m.nodes[path.Dir(k)]; !ok || !parent.mode.IsDir() {
+			return nil, pathError("open", name, iofs.ErrNotExist)
+		}
+		node = &memNode{mode: perm.Perm(), modTime: time.Now()}
+		m.nodes[k] = node
+	}
+	if node.mode.IsDir() && flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+		return nil, pathError("open", name, errIsDirectory)
+	}
+
+	writable := flag&(os.O_WRONLY|os.O_RDWR) != 0
+	if writable && flag&os.O_TRUNC != 0 {
+		node.data = nil
+		node.modTime = time.Now()
+	}
+
+	f := &memFile{fs: m, name: name, key: k, writable: writable}
+	if !node.mode.IsDir() {
+		f.reader = bytes.NewReader(bytes.Clone(node.data))
+	}
+	if flag&os.O_APPEND != 0 {
+		f.offset = int64(len(node.data))
+	}
+	return f, nil
+}
+
+var errIsDirectory = errors.New("is a directory")
+
+func (m *MemFS) Stat(name string) (os.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	k, node, ok := m.resolve(name)
+	if !ok {
+		return nil, pathError("stat", name, iofs.ErrNotExist)
+	}
+	return memInfo{name: path.Base(k), node: *node}, nil
+}
+
+func (m *MemFS) Lstat(name string) (os.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	k := key(name)
+	node, ok := m.nodes[k]
+	if !ok {
+		return nil, pathError("lstat", name, iofs.ErrNotExist)
+	}
+	return memInfo{name: path.Base(k), node: *node}, nil
+}
+
+func (m *MemFS) ReadDir(name string) ([]os.DirEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	k, node, ok := m.resolve(name)
+	if !ok {
+		return nil, pathError("readdir", name, iofs.ErrNotExist)
+	}
+	if !node.mode.IsDir() {
+		return nil, pathError("readdir", name, iofs.ErrInvalid)
+	}
+
+	prefix := strings.TrimSuffix(k, "/") + "/"
+	var entries []os.DirEntry
+	for p, child := range m.nodes {
+		if p == k || !strings.HasPrefix(p, prefix) || strings.Contains(p[len(prefix):], "/") {
+			continue
+		}
+		entries = append(entries, iofs.FileInfoToDirEntry(memInfo{name: path.Base(p), node: *child}))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (m *MemFS) Readlink(name string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, ok := m.nodes[key(name)]
+	if !ok {
+		return "", pathError("readlink", name, iofs.ErrNotExist)
+	}
+	if node.mode&os.ModeSymlink == 0 {
+		return "", pathError("readlink", name, iofs.ErrInvalid)
+	}
+	return node.target, nil
+}
+
+func (m *MemFS) Symlink(target, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	k := key(name)
+	if _, ok := m.nodes[k]; ok {
+		return pathError("symlink", name, iofs.ErrExist)
+	}
+	if _, ok := m.nodes[path.Dir(k)]; !ok {
+		return pathError("symlink", name, iofs.ErrNotExist)
+	}
+	m.nodes[k] = &memNode{mode: os.ModeSymlink | 0777, modTime: time.Now(), target: target}
+	return nil
+}
+
+func (m *MemFS) MkdirAll(name string, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	k := key(name)
+	for dir := k; ; dir = path.Dir(dir) {
+		if node, ok := m.nodes[dir]; ok {
+			if !node.mode.IsDir() {
+				return pathError("mkdir", name, iofs.ErrExist)
+			}
+			break
+		}
+		if dir == "/" {
+			break
+		}
+	}
+
+	var parts []string
+	for dir := k; dir != "/"; dir = path.Dir(dir) {
+		parts = append(parts, dir)
+	}
+	for i := len(parts) - 1; i >= 0; i-- {
+		if _, ok := m.nodes[parts[i]]; !ok {
+			m.nodes[parts[i]] = &memNode{mode: os.ModeDir | perm.Perm(), modTime: time.Now()}
+		}
+	}
+	return nil
+}
+
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	k := key(name)
+	node, ok := m.nodes[k]
+	if !ok {
+		return pathError("remove", name, iofs.ErrNotExist)
+	}
+	if node.mode.IsDir() {
+		for p := range m.nodes {
+			if strings.HasPrefix(p, k+"/") {
+				return pathError("remove", name, iofs.ErrInvalid)
+			}
+		}
+	}
+	delete(m.nodes, k)
+	return nil
+}
+
+func (m *MemFS) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	from, to := key(oldpath), key(newpath)
+	node, ok := m.nodes[from]
+	if !ok {
+		return pathError("rename", oldpath, iofs.ErrNotExist)
+	}
+	if _, ok := m.nodes[path.Dir(to)]; !ok {
+		return pathError("rename", newpath, iofs.ErrNotExist)
+	}
+
+	delete(m.nodes, from)
+	m.nodes[to] = node
+	if node.mode.IsDir() {
+		for p, child := range m.nodes {
+			if strings.HasPrefix(p, from+"/") {
+				delete(m.nodes, p)
+				m.nodes[to+p[len(from):]] = child
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MemFS) Chtimes(name string, _, mtime time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, node, ok := m.resolve(name)
+	if !ok {
+		return pathError("chtimes", name, iofs.ErrNotExist)
+	}
+	node.modTime = mtime
+	return nil
+}
+
+// memFile reads a snapshot taken when it was opened and writes through to
+// the node.
+type memFile struct {
+	fs       *MemFS
+	name     string
+	key      string
+	reader   *bytes.Reader
+	writable bool
+	offset   int64
+	closed   bool
+}
+
+func (f *memFile) Name() string { return f.name }
+
+func (f *memFile) Read(p []byte) (int, error) {
+	if f.closed {
+		return 0, iofs.ErrClosed
+	}
+	if f.reader == nil {
+		return 0, pathError("read", f.name, iofs.ErrInvalid)
+	}
+	return f.reader.Read(p)
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if f.closed {
+		return 0, iofs.ErrClosed
+	}
+	if !f.writable {
+		return 0, pathError("write", f.name, iofs.ErrPermission)
+	}
+
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	node, ok := f.fs.nodes[f.key]
+	if !ok {
+		return 0, pathError("write", f.name, iofs.ErrNotExist)
+	}
+
+	end := f.offset + int64(len(p))
+	if end > int64(len(node.data)) {
+		node.data = append(node.data, make([]byte, end-int64(len(node.data)))...)
+	}
+	copy(node.data[f.offset:], p)
+	f.offset = end
+	node.modTime = time.Now()
+	return len(p), nil
+}
+
+func (f *memFile) Close() error {
+	if f.closed {
+		return iofs.ErrClosed
+	}
+	f.closed = true
+	return nil
+}
+
+func (f *memFile) Stat() (os.FileInfo, error) {
+	return f.fs.Stat(f.key)
+}
+
+type memInfo struct {
+	name string
+	node memNode
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return int64(len(i.node.data)) }
+func (i memInfo) Mode() os.FileMode  { return i.node.mode }
+func (i memInfo) ModTime() time.Time { return i.node.modTime }
+func (i memInfo) IsDir() bool        { return i.node.mode.IsDir() }
+func (i memInfo) Sys() any           { return nil }
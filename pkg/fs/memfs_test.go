@@ -0,0 +1,82 @@
+package fs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMemFS(t *testing.T) {
+	m := NewMemFS()
+	if err := m.MkdirAll("/src/templates", 0755); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	WriteFile(m, "/src/Chart.yaml", []byte("name: app"), 0644)
+	WriteFile(m, "/src/templates/deploy.yaml", []byte("kind: Deployment"), 0644)
+	m.Symlink("Chart.yaml", "/src/link.yaml")
+
+	if _, err := m.Open("/src/missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected ErrNotExist, got: %v", err)
+	}
+	if err := WriteFile(m, "/nodir/file", nil, 0644); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a missing parent to fail, got: %v", err)
+	}
+	if data, err := ReadFile(m, "/src/link.yaml"); err != nil || string(data) != "name: app" {
+		t.Errorf("Expected symlinks to be followed, got: %q %v", data, err)
+	}
+	if info, _ := m.Lstat("/src/link.yaml"); info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Expected Lstat to report the link, got: %v", info.Mode())
+	}
+
+	f, _ := m.OpenFile("/src/Chart.yaml", os.O_WRONLY|os.O_APPEND, 0)
+	f.Write([]byte("\nversion: 1"))
+	f.Close()
+	if data, _ := ReadFile(m, "/src/Chart.yaml"); string(data) != "name: app\nversion: 1" {
+		t.Errorf("Expected appended content, got: %q", data)
+	}
+
+	var walked []string
+	Walk(m, "/src", func(path string, info os.FileInfo, err error) error {
+		walked = append(walked, filepath.ToSlash(path))
+		return err
+	})
+	expected := "/src,/src/Chart.yaml,/src/link.yaml,/src/templates,/src/templates/deploy.yaml"
+	if strings.Join(walked, ",") != expected {
+		t.Errorf("Expected %s, got: %v", expected, walked)
+	}
+
+	tmp, err := CreateTemp(m, "/src", ".tmp-*")
+	if err != nil || !strings.HasPrefix(filepath.Base(tmp.Name()), ".tmp-") {
+		t.Fatalf("Expected a temp file, got: %v %v", tmp, err)
+	}
+	tmp.Close()
+	if err := m.Rename(tmp.Name(), "/src/renamed"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := m.Remove("/src/templates"); err == nil {
+		t.Error("Expected removing a non-empty directory to fail")
+	}
+}
+
+func TestCompressMemFS(t *testing.T) {
+	src := NewMemFS()
+	src.MkdirAll("/chart/templates", 0755)
+	WriteFile(src, "/chart/Chart.yaml", []byte("name: app"), 0644)
+	WriteFile(src, "/chart/templates/deploy.yaml", []byte("kind: Deployment"), 0644)
+
+	data, err := CompressDirWithOptions("/chart", CompressOptions{FS: src, Reproducible: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	dst := NewMemFS()
+	dst.MkdirAll("/out", 0755)
+	if err := DecompressDirFS(dst, data, "/out"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if content, err := ReadFile(dst, "/out/templates/deploy.yaml"); err != nil || string(content) != "kind: Deployment" {
+		t.Errorf("Expected the extracted file, got: %q %v", content, err)
+	}
+}
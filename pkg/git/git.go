@@ -0,0 +1,237 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type Repository struct {
+	repo *gogit.Repository
+}
+
+type CloneOptions struct {
+	// Ref is a branch name, or a full reference such as refs/tags/v1.0.0,
+	// to check out instead of the remote HEAD.
+	Ref      string
+	Depth    int
+	Username string
+	Password string
+}
+
+// Open opens the repository containing path, searching parent directories
+// for the .git directory.
+func Open(path string) (*Repository, error) {
+	repo, err := gogit.PlainOpenWithOptions(path, &gogit.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository %s: %w", path, err)
+	}
+
+	return &Repository{repo: repo}, nil
+}
+
+func Clone(ctx context.Context, rawURL, dst string, opts CloneOptions) (*Repository, error) {
+	cloneOpts := &gogit.CloneOptions{
+		URL:   rawURL,
+		Depth: opts.Depth,
+	}
+	if opts.Ref != "" {
+		cloneOpts.ReferenceName = plumbing.ReferenceName(opts.Ref)
+		if !strings.HasPrefix(opts.Ref, "refs/") {
+			cloneOpts.ReferenceName = plumbing.NewBranchReferenceName(opts.Ref)
+		}
+		cloneOpts.SingleBranch = true
+	}
+	if opts.Username != "" || opts.Password != "" {
+		cloneOpts.Auth = &http.BasicAuth{Username: opts.Username, Password: opts.Password}
+	}
+
+	repo, err := gogit.PlainCloneContext(ctx, dst, false, cloneOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone %s: %w", redactURL(rawURL), err)
+	}
+
+	return &Repository{repo: repo}, nil
+}
+
+// CurrentCommit returns the full hash of HEAD.
+func (r *Repository) CurrentCommit() (string, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	return head.Hash().String(), nil
+}
+
+// CurrentBranch returns the checked out branch, or "" when HEAD is detached.
+func (r *Repository) CurrentBranch() (string, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	if !head.Name().IsBranch() {
+		return "", nil
+	}
+
+	return head.Name().Short(), nil
+}
+
+// IsDirty reports whether the worktree has uncommitted or untracked changes.
+func (r *Repository) IsDirty() (bool, error) {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return false, err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return false, fmt.Errorf("failed to get worktree status: %w", err)
+	}
+
+	return !status.IsClean(), nil
+}
+
+// Tags returns the tag names in the repository, sorted.
+func (r *Repository) Tags() ([]string, error) {
+	iter, err := r.repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		tags = append(tags, ref.Name().Short())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// CreateTag tags HEAD. A non-empty message creates an annotated tag signed
+// off with user.name and user.email from the git config.
+func (r *Repository) CreateTag(name, message string) error {
+	head, err := r.repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	var opts *gogit.CreateTagOptions
+	if message != "" {
+		tagger, err := r.signature()
+		if err != nil {
+			return err
+		}
+		opts = &gogit.CreateTagOptions{Tagger: tagger, Message: message}
+	}
+
+	if _, err := r.repo.CreateTag(name, head.Hash(), opts); err != nil {
+		return fmt.Errorf("failed to create tag %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// RemoteURL returns the first URL of the named remote.
+func (r *Repository) RemoteURL(name string) (string, error) {
+	remote, err := r.repo.Remote(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to find remote %s: %w", name, err)
+	}
+
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", fmt.Errorf("remote %s has no URL", name)
+	}
+
+	return urls[0], nil
+}
+
+// Annotations returns the org.opencontainers.image.revision and
+// org.opencontainers.image.source annotations for the repository. The source
+// is taken from the origin remote and omitted when there is none.
+func (r *Repository) Annotations() (map[string]string, error) {
+	commit, err := r.CurrentCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	annotations := map[string]string{spec.AnnotationRevision: commit}
+
+	remote, err := r.RemoteURL("origin")
+	if errors.Is(err, gogit.ErrRemoteNotFound) {
+		return annotations, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	annotations[spec.AnnotationSource] = SourceURL(remote)
+
+	return annotations, nil
+}
+
+// SourceURL converts a remote URL into a browsable https URL, rewriting scp
+// style SSH remotes and dropping credentials and the .git suffix:
+// git@github.com:org/repo.git becomes https://github.com/org/repo.
+func SourceURL(remote string) string {
+	if !strings.Contains(remote, "://") {
+		if host, path, ok := strings.Cut(remote, ":"); ok {
+			if _, h, ok := strings.Cut(host, "@"); ok {
+				host = h
+			}
+			remote = "https://" + host + "/" + strings.TrimPrefix(path, "/")
+		}
+	}
+
+	u, err := url.Parse(remote)
+	if err != nil {
+		return remote
+	}
+
+	if u.Scheme == "ssh" || u.Scheme == "git" {
+		u.Scheme = "https"
+		u.Host = u.Hostname()
+	}
+	u.User = nil
+	u.Path = strings.TrimSuffix(u.Path, ".git")
+
+	return u.String()
+}
+
+func (r *Repository) signature() (*object.Signature, error) {
+	cfg, err := r.repo.ConfigScoped(config.SystemScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git config: %w", err)
+	}
+
+	if cfg.User.Name == "" || cfg.User.Email == "" {
+		return nil, fmt.Errorf("user.name and user.email must be set to create an annotated tag")
+	}
+
+	return &object.Signature{Name: cfg.User.Name, Email: cfg.User.Email, When: time.Now()}, nil
+}
+
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	return u.Redacted()
+}
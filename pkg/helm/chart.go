@@ -0,0 +1,112 @@
+package helm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/validate"
+	"gopkg.in/yaml.v3"
+)
+
+const ChartFile = "Chart.yaml"
+
+var chartName = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// Chart is the metadata in Chart.yaml. The json names match the config
+// blob Helm pushes to OCI registries.
+type Chart struct {
+	APIVersion   string            `json:"apiVersion" yaml:"apiVersion" validate:"required,oneof=v1 v2"`
+	Name         string            `json:"name" yaml:"name" validate:"required"`
+	Version      string            `json:"version" yaml:"version" validate:"required,semver"`
+	KubeVersion  string            `json:"kubeVersion,omitempty" yaml:"kubeVersion,omitempty"`
+	Description  string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Type         string            `json:"type,omitempty" yaml:"type,omitempty" validate:"oneof=application library"`
+	Keywords     []string          `json:"keywords,omitempty" yaml:"keywords,omitempty"`
+	Home         string            `json:"home,omitempty" yaml:"home,omitempty" validate:"url"`
+	Sources      []string          `json:"sources,omitempty" yaml:"sources,omitempty"`
+	Dependencies []Dependency      `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	Maintainers  []Maintainer      `json:"maintainers,omitempty" yaml:"maintainers,omitempty"`
+	Icon         string            `json:"icon,omitempty" yaml:"icon,omitempty" validate:"url"`
+	AppVersion   string            `json:"appVersion,omitempty" yaml:"appVersion,omitempty"`
+	Deprecated   bool              `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+type Dependency struct {
+	Name       string `json:"name" yaml:"name" validate:"required"`
+	Version    string `json:"version,omitempty" yaml:"version,omitempty" validate:"required"`
+	Repository string `json:"repository,omitempty" yaml:"repository,omitempty"`
+	Condition  string `json:"condition,omitempty" yaml:"condition,omitempty"`
+	Alias      string `json:"alias,omitempty" yaml:"alias,omitempty"`
+}
+
+type Maintainer struct {
+	Name  string `json:"name" yaml:"name" validate:"required"`
+	Email string `json:"email,omitempty" yaml:"email,omitempty"`
+	URL   string `json:"url,omitempty" yaml:"url,omitempty" validate:"url"`
+}
+
+// Load reads Chart.yaml from the chart directory dir without linting it.
+func Load(dir string) (*Chart, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ChartFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, log.Errorf(log.CodeNotFound, "helm_load", "%s is not a chart: %s not found", dir, ChartFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	chart := &Chart{}
+	if err := yaml.Unmarshal(data, chart); err != nil {
+		return nil, log.Errorf(log.CodeInvalidArgument, "helm_load", "invalid %s: %s", ChartFile, err)
+	}
+
+	return chart, nil
+}
+
+// Lint loads the chart in dir and checks it the way helm lint does before
+// packaging: required fields, a SemVer 2 version, a name matching the
+// directory and a parseable values.yaml. All problems are reported
+// together as validate.Errors.
+func Lint(dir string) (*Chart, error) {
+	chart, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs validate.Errors
+	if err := validate.Struct(chart); err != nil {
+		if !errors.As(err, &errs) {
+			return nil, err
+		}
+	}
+
+	if chart.Name != "" && !chartName.MatchString(chart.Name) {
+		errs = append(errs, validate.FieldError{Field: "name", Rule: "chart-name", Message: fmt.Sprintf("%q may only contain letters, digits, '.', '_' and '-'", chart.Name)})
+	}
+	if abs, err := filepath.Abs(dir); err == nil && chart.Name != "" && filepath.Base(abs) != chart.Name {
+		errs = append(errs, validate.FieldError{Field: "name", Rule: "chart-name", Message: fmt.Sprintf("%q must match the chart directory %q", chart.Name, filepath.Base(abs))})
+	}
+	if chart.APIVersion == "v1" && len(chart.Dependencies) > 0 {
+		errs = append(errs, validate.FieldError{Field: "dependencies", Rule: "api-version", Message: "require apiVersion v2"})
+	}
+
+	values, err := os.ReadFile(filepath.Join(dir, "values.yaml"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	var parsed map[string]any
+	if err := yaml.Unmarshal(values, &parsed); err != nil {
+		errs = append(errs, validate.FieldError{Field: "values.yaml", Rule: "yaml", Message: "is not a valid YAML map: " + err.Error()})
+	}
+
+	if len(errs) > 0 {
+		return nil, log.NewError(log.CodeInvalidArgument, "helm_lint", errs)
+	}
+
+	return chart, nil
+}
@@ -0,0 +1,291 @@
+package helm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/oci"
+	"github.com/eunanio/sdk/pkg/validate"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const validChart = `apiVersion: v2
+name: app
+version: 1.2.3+build.4
+description: An example chart
+home: https://example.com/app
+sources: [https://github.com/example/app]
+maintainers:
+- {name: Jo, email: jo@example.com}
+- {name: Sam}
+`
+
+// writeChart creates a chart named name with files, relative paths mapped
+// to contents.
+func writeChart(t *testing.T, name string, files map[string]string) string {
+	dir := filepath.Join(t.TempDir(), name)
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		dir      string
+		chart    string
+		values   string
+		expected []string
+	}{
+		{name: "valid", dir: "app", chart: validChart, values: "replicas: 1\n"},
+		{name: "missing fields", dir: "app", chart: "name: app\n", expected: []string{"apiVersion is required", "version is required"}},
+		{name: "invalid values", dir: "app", chart: "apiVersion: v3\nname: app\nversion: \"1.0\"\ntype: service\nhome: example.com\n", expected: []string{
+			`apiVersion must be one of v1, v2, got "v3"`,
+			`version must be a semantic version such as 1.2.3, got "1.0"`,
+			`type must be one of application, library, got "service"`,
+			`home must be a valid URL, got "example.com"`,
+		}},
+		{name: "directory mismatch", dir: "other", chart: validChart, expected: []string{`name "app" must match the chart directory "other"`}},
+		{name: "invalid name", dir: "my app", chart: "apiVersion: v2\nname: my app\nversion: 1.0.0\n", expected: []string{`name "my app" may only contain letters, digits, '.', '_' and '-'`}},
+		{name: "v1 dependencies", dir: "app", chart: "apiVersion: v1\nname: app\nversion: 1.0.0\ndependencies:\n- name: db\n", expected: []string{"dependencies[0].version is required", "dependencies require apiVersion v2"}},
+		{name: "values not a map", dir: "app", chart: validChart, values: "- a\n- b\n", expected: []string{"values.yaml is not a valid YAML map"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{ChartFile: tt.chart}
+			if tt.values != "" {
+				files["values.yaml"] = tt.values
+			}
+			dir := writeChart(t, tt.dir, files)
+
+			chart, err := Lint(dir)
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Fatalf("Expected no error, got: %v", err)
+				}
+				if chart.Name != "app" || len(chart.Maintainers) != 2 {
+					t.Errorf("Expected chart metadata, got: %+v", chart)
+				}
+				return
+			}
+
+			var errs validate.Errors
+			if !errors.As(err, &errs) || !log.IsCode(err, log.CodeInvalidArgument) {
+				t.Fatalf("Expected validation errors, got: %v", err)
+			}
+			if len(errs) != len(tt.expected) {
+				t.Fatalf("Expected %d errors, got: %v", len(tt.expected), err)
+			}
+			for i, expected := range tt.expected {
+				if !strings.HasPrefix(errs[i].Error(), expected) {
+					t.Errorf("Expected %q, got: %q", expected, errs[i].Error())
+				}
+			}
+		})
+	}
+
+	if _, err := Lint(t.TempDir()); !log.IsCode(err, log.CodeNotFound) {
+		t.Errorf("Expected not found for a directory without Chart.yaml, got: %v", err)
+	}
+}
+
+func TestIgnore(t *testing.T) {
+	rules := append(parseIgnore(defaultIgnore), parseIgnore([]string{
+		"# comment",
+		"*.tgz",
+		"ci/",
+		"/docs/*.md",
+		"!docs/README.md",
+		".git",
+	})...)
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{path: "app-1.0.0.tgz", expected: true},
+		{path: "charts/db-1.0.0.tgz", expected: true},
+		{path: "ci", isDir: true, expected: true},
+		{path: "ci", isDir: false, expected: false},
+		{path: "docs/usage.md", expected: true},
+		{path: "docs/README.md", expected: false},
+		{path: ".git", isDir: true, expected: true},
+		{path: "templates/.hidden.yaml", expected: true},
+		{path: "templates/deployment.yaml", expected: false},
+		{path: "values.yaml", expected: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.path, func(t *testing.T) {
+			if got := rules.ignored(tt.path, tt.isDir); got != tt.expected {
+				t.Errorf("Expected ignored to be %v, got: %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPackage(t *testing.T) {
+	dir := writeChart(t, "app", map[string]string{
+		ChartFile:                   validChart,
+		"values.yaml":               "replicas: 1\n",
+		IgnoreFile:                  "*.tgz\nci/\n",
+		"templates/deployment.yaml": "kind: Deployment\n",
+		"templates/.scratch":        "notes",
+		"ci/values.yaml":            "replicas: 2\n",
+		"app-0.9.0.tgz":             "old",
+	})
+
+	path, err := Package(dir, t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if filepath.Base(path) != "app-1.2.3+build.4.tgz" {
+		t.Errorf("Expected helm's archive name, got: %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := archiveEntries(t, data)
+	expected := []string{"app", "app/.helmignore", "app/Chart.yaml", "app/templates", "app/templates/deployment.yaml", "app/values.yaml"}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected %v, got: %v", expected, entries)
+	}
+
+	again, _, err := Archive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Error("Expected packaging to be reproducible")
+	}
+}
+
+func archiveEntries(t *testing.T, data []byte) []string {
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []string
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, header.Name)
+	}
+}
+
+func TestPush(t *testing.T) {
+	var mu sync.Mutex
+	blobs := map[string]int64{}
+	var manifest spec.Manifest
+	var manifestPath string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/charts/app/blobs/uploads/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/upload")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		blobs[r.URL.Query().Get("digest")] = int64(len(data))
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("/v2/charts/app/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		manifestPath = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&manifest)
+		w.WriteHeader(http.StatusCreated)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	dir := writeChart(t, "app", map[string]string{ChartFile: validChart, "LICENSE": "SPDX-License-Identifier: MIT\n"})
+	prov := filepath.Join(t.TempDir(), "app.prov")
+	os.WriteFile(prov, []byte("-----BEGIN PGP SIGNED MESSAGE-----"), 0644)
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	pushed, err := Push(oci.NewOciClient(), dir, PushOptions{
+		Host:        host,
+		Namespace:   "charts",
+		Insecure:    true,
+		Provenance:  prov,
+		Annotations: map[string]string{"com.example.team": "platform"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if manifestPath != "/v2/charts/app/manifests/1.2.3_build.4" {
+		t.Errorf("Expected the version as tag with + replaced, got: %s", manifestPath)
+	}
+	if manifest.Config.MediaType != ConfigMediaType || len(manifest.Layers) != 2 ||
+		manifest.Layers[0].MediaType != ChartMediaType || manifest.Layers[1].MediaType != ProvenanceMediaType {
+		t.Errorf("Expected helm media types, got: %+v", manifest)
+	}
+	for _, desc := range append([]spec.Descriptor{manifest.Config}, manifest.Layers...) {
+		if blobs[desc.Digest.String()] != desc.Size {
+			t.Errorf("Expected blob %s to be uploaded, got: %v", desc.Digest, blobs)
+		}
+	}
+
+	expected := map[string]string{
+		spec.AnnotationTitle:       "app",
+		spec.AnnotationVersion:     "1.2.3+build.4",
+		spec.AnnotationDescription: "An example chart",
+		spec.AnnotationURL:         "https://example.com/app",
+		spec.AnnotationSource:      "https://github.com/example/app",
+		spec.AnnotationAuthors:     "Jo (jo@example.com), Sam",
+		spec.AnnotationLicenses:    "MIT",
+		"com.example.team":         "platform",
+	}
+	for key, value := range expected {
+		if pushed.Annotations[key] != value {
+			t.Errorf("Expected annotation %s=%q, got: %q", key, value, pushed.Annotations[key])
+		}
+	}
+	if pushed.Annotations[spec.AnnotationCreated] == "" {
+		t.Error("Expected a created annotation")
+	}
+
+	if _, err := Push(oci.NewOciClient(), dir, PushOptions{Provenance: "/missing.prov"}); !log.IsCode(err, log.CodeInvalidArgument) {
+		t.Errorf("Expected invalid options to fail before pushing, got: %v", err)
+	}
+}
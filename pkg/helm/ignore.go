@@ -0,0 +1,94 @@
+package helm
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const IgnoreFile = ".helmignore"
+
+// defaultIgnore matches the rule Helm always adds: hidden files in
+// templates are never packaged.
+var defaultIgnore = []string{"templates/.?*"}
+
+type ignoreRule struct {
+	pattern string
+	negate  bool
+	dirOnly bool
+}
+
+type ignoreRules []ignoreRule
+
+// loadIgnore reads .helmignore from dir. Patterns follow Helm: a pattern
+// with a slash matches the path relative to the chart, one without matches
+// the base name, a trailing slash matches only directories, a leading !
+// negates and the last matching pattern wins. ** is not supported.
+func loadIgnore(dir string) (ignoreRules, error) {
+	rules := parseIgnore(defaultIgnore)
+
+	data, err := os.ReadFile(filepath.Join(dir, IgnoreFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return rules, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	return append(rules, parseIgnore(lines)...), scanner.Err()
+}
+
+func parseIgnore(lines []string) ignoreRules {
+	var rules ignoreRules
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		rule.pattern = strings.TrimPrefix(line, "/")
+		if rule.pattern != "" {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+func (r ignoreRules) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, rule := range r {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		name := path.Base(rel)
+		if strings.Contains(rule.pattern, "/") {
+			name = rel
+		}
+		if ok, _ := path.Match(rule.pattern, name); ok {
+			ignored = !rule.negate
+		}
+	}
+
+	return ignored
+}
@@ -0,0 +1,58 @@
+package helm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/eunanio/sdk/pkg/fs"
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+// Archive lints the chart in dir and packages it as a gzipped tarball with
+// every file under <name>/, skipping paths matched by .helmignore. The
+// archive is reproducible: packaging the same files twice gives the same
+// digest.
+func Archive(dir string) ([]byte, *Chart, error) {
+	defer log.Timed("helm_archive", "dir", dir)()
+	chart, err := Lint(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rules, err := loadIgnore(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", IgnoreFile, err)
+	}
+
+	data, err := fs.CompressDirWithOptions(dir, fs.CompressOptions{
+		Prefix:       chart.Name,
+		Exclude:      rules.ignored,
+		Reproducible: true,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to package chart: %w", err)
+	}
+
+	return data, chart, nil
+}
+
+// Package writes the chart in dir to dst/<name>-<version>.tgz, as helm
+// package does, and returns the path of the archive.
+func Package(dir, dst string) (string, error) {
+	data, chart, err := Archive(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dst, fmt.Sprintf("%s-%s.tgz", chart.Name, chart.Version))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write chart archive: %w", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,175 @@
+package helm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/git"
+	"github.com/eunanio/sdk/pkg/license"
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/oci"
+	"github.com/eunanio/sdk/pkg/validate"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Media types used by Helm for charts stored in OCI registries.
+const (
+	ConfigMediaType     = "application/vnd.cncf.helm.config.v1+json"
+	ChartMediaType      = ChartContentMediaType + "+gzip"
+	ProvenanceMediaType = "application/vnd.cncf.helm.chart.provenance.v1.prov"
+	// ChartContentMediaType is the chart layer media type without the codec
+	// suffix, which Push takes from the archive.
+	ChartContentMediaType = "application/vnd.cncf.helm.chart.content.v1.tar"
+)
+
+type PushOptions struct {
+	// Host and Namespace locate the chart repository: a chart is pushed to
+	// <host>/<namespace>/<name>:<version>.
+	Host      string `validate:"required"`
+	Namespace string
+	Insecure  bool
+	// Provenance is a .prov file created by helm package --sign, pushed
+	// alongside the chart.
+	Provenance string `validate:"path-exists"`
+	// Annotations are added to the manifest, overriding generated ones.
+	Annotations map[string]string
+}
+
+// Push packages the chart in dir and publishes it so helm pull and helm
+// install can use it with an oci:// reference. The manifest carries the
+// standard OCI annotations from Chart.yaml, plus the revision and source
+// of the git repository containing dir when there is one and the licenses
+// detected in dir.
+func Push(registry *oci.OciClient, dir string, opts PushOptions) (*spec.Manifest, error) {
+	if err := validate.Struct(opts); err != nil {
+		return nil, err
+	}
+
+	archive, chart, err := Archive(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer log.Timed("helm_push", "chart", chart.Name, "version", chart.Version)()
+
+	config, err := json.Marshal(chart)
+	if err != nil {
+		return nil, err
+	}
+
+	blobs := [][]byte{config, archive}
+	manifest := &spec.Manifest{
+		Versioned:   specs.Versioned{SchemaVersion: 2},
+		MediaType:   spec.MediaTypeImageManifest,
+		Config:      descriptor(ConfigMediaType, config),
+		Layers:      []spec.Descriptor{descriptor(oci.LayerMediaType(ChartContentMediaType, archive), archive)},
+		Annotations: annotations(dir, chart, opts.Annotations),
+	}
+
+	if opts.Provenance != "" {
+		prov, err := os.ReadFile(opts.Provenance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read provenance file: %w", err)
+		}
+		blobs = append(blobs, prov)
+		manifest.Layers = append(manifest.Layers, descriptor(ProvenanceMediaType, prov))
+	}
+
+	// OCI tags cannot contain "+", so Helm replaces it in build metadata.
+	tag := oci.Tag{
+		Host:      opts.Host,
+		Namespace: opts.Namespace,
+		Name:      chart.Name,
+		Version:   strings.ReplaceAll(chart.Version, "+", "_"),
+	}
+
+	descriptors := append([]spec.Descriptor{manifest.Config}, manifest.Layers...)
+	for i, desc := range descriptors {
+		err := registry.PushBlob(oci.PushBlobOptions{
+			Digest:   desc,
+			File:     blobs[i],
+			Name:     tag.Name,
+			Insecure: opts.Insecure,
+			Tag:      tag,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to push blob %s: %w", desc.Digest, err)
+		}
+	}
+
+	err = registry.PushManifest(oci.PushManifestOptions{
+		Manifest: manifest,
+		Tag:      &tag,
+		Insecure: opts.Insecure,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func descriptor(mediaType string, data []byte) spec.Descriptor {
+	return spec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(data),
+		Size:      int64(len(data)),
+	}
+}
+
+func annotations(dir string, chart *Chart, extra map[string]string) map[string]string {
+	annotations := map[string]string{
+		spec.AnnotationTitle:   chart.Name,
+		spec.AnnotationVersion: chart.Version,
+		spec.AnnotationCreated: time.Now().UTC().Format(time.RFC3339),
+	}
+	if chart.Description != "" {
+		annotations[spec.AnnotationDescription] = chart.Description
+	}
+	if chart.Home != "" {
+		annotations[spec.AnnotationURL] = chart.Home
+	}
+	if len(chart.Sources) > 0 {
+		annotations[spec.AnnotationSource] = chart.Sources[0]
+	}
+
+	var authors []string
+	for _, maintainer := range chart.Maintainers {
+		if maintainer.Email != "" {
+			authors = append(authors, fmt.Sprintf("%s (%s)", maintainer.Name, maintainer.Email))
+		} else {
+			authors = append(authors, maintainer.Name)
+		}
+	}
+	if len(authors) > 0 {
+		annotations[spec.AnnotationAuthors] = strings.Join(authors, ", ")
+	}
+
+	if repo, err := git.Open(dir); err == nil {
+		if vcs, err := repo.Annotations(); err == nil {
+			for key, value := range vcs {
+				if _, ok := annotations[key]; !ok {
+					annotations[key] = value
+				}
+			}
+		}
+	}
+
+	if licenses, err := license.Annotations(dir); err == nil {
+		for key, value := range licenses {
+			if _, ok := annotations[key]; !ok {
+				annotations[key] = value
+			}
+		}
+	}
+
+	for key, value := range extra {
+		annotations[key] = value
+	}
+
+	return annotations
+}
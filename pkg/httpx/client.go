@@ -0,0 +1,319 @@
+package httpx
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/system"
+)
+
+const (
+	defaultRetries    = 3
+	defaultMinBackoff = 200 * time.Millisecond
+	defaultMaxBackoff = 5 * time.Second
+)
+
+// RequestHook is called before every attempt of a request.
+type RequestHook func(req *http.Request, attempt int)
+
+// ResponseHook is called after every attempt with either the response or the
+// transport error.
+type ResponseHook func(req *http.Request, resp *http.Response, err error, elapsed time.Duration)
+
+type Client struct {
+	http        *http.Client
+	retries     int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	maxBodySize int64
+	onRequest   []RequestHook
+	onResponse  []ResponseHook
+}
+
+type Option func(*options)
+
+type options struct {
+	timeout     time.Duration
+	retries     int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	maxBodySize int64
+	proxy       *system.ProxyConfig
+	tls         *tls.Config
+	transport   http.RoundTripper
+	onRequest   []RequestHook
+	onResponse  []ResponseHook
+}
+
+// WithTimeout limits each attempt, including reading the response body.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithRetries sets how many times a failed request is retried. Zero
+// disables retries.
+func WithRetries(retries int) Option {
+	return func(o *options) {
+		o.retries = retries
+	}
+}
+
+// WithBackoff sets the bounds of the exponential backoff between retries.
+func WithBackoff(min, max time.Duration) Option {
+	return func(o *options) {
+		o.minBackoff = min
+		o.maxBackoff = max
+	}
+}
+
+// WithMaxBodySize makes reading a response body fail with ErrBodyTooLarge
+// after limit bytes.
+func WithMaxBodySize(limit int64) Option {
+	return func(o *options) {
+		o.maxBodySize = limit
+	}
+}
+
+// WithProxy overrides the proxy settings detected by system.ProxySettings.
+func WithProxy(proxy *system.ProxyConfig) Option {
+	return func(o *options) {
+		o.proxy = proxy
+	}
+}
+
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) {
+		o.tls = config
+	}
+}
+
+// WithTransport replaces the transport, ignoring the proxy and TLS options.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+func WithRequestHook(hook RequestHook) Option {
+	return func(o *options) {
+		o.onRequest = append(o.onRequest, hook)
+	}
+}
+
+func WithResponseHook(hook ResponseHook) Option {
+	return func(o *options) {
+		o.onResponse = append(o.onResponse, hook)
+	}
+}
+
+// New returns a client that uses the system proxy settings and retries
+// failed requests three times with exponential backoff. Every attempt is
+// logged at debug level.
+func New(opts ...Option) *Client {
+	o := &options{
+		retries:    defaultRetries,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+		onResponse: []ResponseHook{logResponse},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	transport := o.transport
+	if transport == nil {
+		transport = newTransport(o)
+	}
+
+	return &Client{
+		http:        &http.Client{Transport: transport, Timeout: o.timeout},
+		retries:     o.retries,
+		minBackoff:  o.minBackoff,
+		maxBackoff:  o.maxBackoff,
+		maxBodySize: o.maxBodySize,
+		onRequest:   o.onRequest,
+		onResponse:  o.onResponse,
+	}
+}
+
+func newTransport(o *options) http.RoundTripper {
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return http.DefaultTransport
+	}
+
+	proxy := o.proxy
+	if proxy == nil {
+		proxy = system.ProxySettings()
+	}
+
+	transport := base.Clone()
+	transport.Proxy = proxy.ProxyFunc()
+	if o.tls != nil {
+		transport.TLSClientConfig = o.tls
+	}
+
+	return transport
+}
+
+// Do sends req, retrying network errors, 429 and 5xx responses when the
+// method is idempotent and the body can be replayed. Retry-After is honoured
+// up to the maximum backoff.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			req.Body = body
+		}
+
+		for _, hook := range c.onRequest {
+			hook(req, attempt)
+		}
+
+		start := time.Now()
+		resp, err := c.http.Do(req)
+		for _, hook := range c.onResponse {
+			hook(req, resp, err, time.Since(start))
+		}
+
+		if attempt >= c.retries || !c.retryable(req, resp, err) {
+			if err != nil {
+				return nil, err
+			}
+			c.limitBody(resp)
+			return resp, nil
+		}
+
+		wait := c.backoff(attempt, resp)
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req)
+}
+
+// HTTPClient returns the underlying client without retries or body limits,
+// for APIs that need an *http.Client.
+func (c *Client) HTTPClient() *http.Client {
+	return c.http
+}
+
+func (c *Client) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+	default:
+		return false
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented
+}
+
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, c.maxBackoff)
+		}
+	}
+
+	wait := c.minBackoff << attempt
+	if wait <= 0 || wait > c.maxBackoff {
+		wait = c.maxBackoff
+	}
+
+	// Full jitter between half and all of the computed wait.
+	return wait/2 + rand.N(wait/2+1)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func logResponse(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+	logger := log.Component("httpx")
+	if err != nil {
+		logger.Debug("request failed", "method", req.Method, "url", req.URL.Redacted(), log.KeyError, err.Error(), "duration_ms", elapsed.Milliseconds())
+		return
+	}
+
+	logger.Debug("request finished", "method", req.Method, "url", req.URL.Redacted(), "status", resp.StatusCode, "duration_ms", elapsed.Milliseconds())
+}
+
+var ErrBodyTooLarge = errors.New("response body too large")
+
+func (c *Client) limitBody(resp *http.Response) {
+	if c.maxBodySize <= 0 {
+		return
+	}
+
+	resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: c.maxBodySize}
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrBodyTooLarge
+	}
+
+	// Read one byte past the limit to tell an exact fit from an overflow.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), ErrBodyTooLarge
+	}
+
+	return n, err
+}
@@ -0,0 +1,127 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDoRetries(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		failures         int32
+		status           int
+		expectedStatus   int
+		expectedAttempts int32
+	}{
+		{name: "Retries server errors", method: http.MethodGet, failures: 2, status: http.StatusServiceUnavailable, expectedStatus: http.StatusOK, expectedAttempts: 3},
+		{name: "Retries rate limiting", method: http.MethodPut, failures: 1, status: http.StatusTooManyRequests, expectedStatus: http.StatusOK, expectedAttempts: 2},
+		{name: "Gives up after retries", method: http.MethodGet, failures: 10, status: http.StatusBadGateway, expectedStatus: http.StatusBadGateway, expectedAttempts: 4},
+		{name: "Does not retry POST", method: http.MethodPost, failures: 1, status: http.StatusInternalServerError, expectedStatus: http.StatusInternalServerError, expectedAttempts: 1},
+		{name: "Does not retry client errors", method: http.MethodGet, failures: 1, status: http.StatusNotFound, expectedStatus: http.StatusNotFound, expectedAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if r.Method != http.MethodGet && string(body) != "payload" {
+					t.Errorf("Expected the body to be replayed, got: %q", body)
+				}
+				if attempts.Add(1) <= tt.failures {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client := New(WithBackoff(time.Millisecond, 5*time.Millisecond))
+			req, _ := http.NewRequest(tt.method, server.URL, strings.NewReader("payload"))
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got: %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if got := attempts.Load(); got != tt.expectedAttempts {
+				t.Errorf("Expected %d attempts, got: %d", tt.expectedAttempts, got)
+			}
+		})
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Query().Get("body")))
+	}))
+	defer server.Close()
+
+	client := New(WithMaxBodySize(5))
+
+	tests := []struct {
+		body        string
+		expectError bool
+	}{
+		{body: "12345", expectError: false},
+		{body: "123456", expectError: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.body, func(t *testing.T) {
+			resp, err := client.Get(context.Background(), server.URL+"?body="+tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			_, err = io.ReadAll(resp.Body)
+			if errors.Is(err, ErrBodyTooLarge) != tt.expectError {
+				t.Errorf("Expected body too large: %v, got: %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestHooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Request-Id") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	var status int
+	client := New(
+		WithRequestHook(func(req *http.Request, attempt int) {
+			req.Header.Set("X-Request-Id", "abc")
+		}),
+		WithResponseHook(func(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+			status = resp.StatusCode
+		}),
+	)
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if status != http.StatusOK {
+		t.Errorf("Expected response hook to see status 200, got: %d", status)
+	}
+}
@@ -0,0 +1,223 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLToJSON converts a YAML document to JSON, keeping the order of
+// mapping keys. Timestamps and other tagged scalars become strings, and
+// merge keys (<<) are expanded.
+func YAMLToJSON(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to parse yaml: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, &node); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// JSONToYAML converts a JSON document to block-style YAML, keeping the
+// order of object keys.
+func JSONToYAML(data []byte) ([]byte, error) {
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("failed to parse json: invalid document")
+	}
+
+	// JSON is valid YAML, so decoding into a node keeps key order and the
+	// exact scalar types.
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to parse json: %w", err)
+	}
+	blockStyle(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+func writeJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case 0:
+		buf.WriteString("null")
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeJSON(buf, node.Content[0])
+	case yaml.AliasNode:
+		return writeJSON(buf, node.Alias)
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.MappingNode:
+		pairs, err := mappingPairs(node)
+		if err != nil {
+			return err
+		}
+		buf.WriteByte('{')
+		for i, pair := range pairs {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := marshal(pair.key)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJSON(buf, pair.value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.ScalarNode:
+		value, err := scalarValue(node)
+		if err != nil {
+			return err
+		}
+		data, err := marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	}
+
+	return nil
+}
+
+type pair struct {
+	key   string
+	value *yaml.Node
+}
+
+// mappingPairs returns the entries of a mapping in order. Entries pulled in
+// by merge keys come first and are overridden by explicit keys.
+func mappingPairs(node *yaml.Node) ([]pair, error) {
+	var pairs []pair
+	index := map[string]int{}
+	set := func(key string, value *yaml.Node) {
+		if i, ok := index[key]; ok {
+			pairs[i].value = value
+			return
+		}
+		index[key] = len(pairs)
+		pairs = append(pairs, pair{key: key, value: value})
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := resolveAlias(node.Content[i]), node.Content[i+1]
+		if key.Kind == yaml.ScalarNode && key.ShortTag() == "!!merge" {
+			merged, err := mergedPairs(resolveAlias(value))
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range merged {
+				if _, ok := index[p.key]; !ok {
+					set(p.key, p.value)
+				}
+			}
+			continue
+		}
+
+		if key.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("unsupported yaml key at line %d: keys must be scalars", key.Line)
+		}
+		set(key.Value, value)
+	}
+
+	return pairs, nil
+}
+
+func mergedPairs(value *yaml.Node) ([]pair, error) {
+	switch value.Kind {
+	case yaml.MappingNode:
+		return mappingPairs(value)
+	case yaml.SequenceNode:
+		// Earlier mappings in the list take precedence.
+		var pairs []pair
+		seen := map[string]bool{}
+		for _, item := range value.Content {
+			merged, err := mergedPairs(resolveAlias(item))
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range merged {
+				if !seen[p.key] {
+					seen[p.key] = true
+					pairs = append(pairs, p)
+				}
+			}
+		}
+		return pairs, nil
+	default:
+		return nil, fmt.Errorf("invalid merge at line %d: expected a mapping", value.Line)
+	}
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+func scalarValue(node *yaml.Node) (any, error) {
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var b bool
+		err := node.Decode(&b)
+		return b, err
+	case "!!int":
+		var i any
+		err := node.Decode(&i)
+		return i, err
+	case "!!float":
+		var f float64
+		if err := node.Decode(&f); err != nil {
+			return nil, err
+		}
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, fmt.Errorf("unsupported value %q at line %d: not representable in json", node.Value, node.Line)
+		}
+		return f, nil
+	default:
+		return node.Value, nil
+	}
+}
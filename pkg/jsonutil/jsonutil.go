@@ -0,0 +1,62 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Pretty re-indents a JSON document with two spaces.
+func Pretty(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bytes.TrimSpace(data), "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to parse json: %w", err)
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+// Canonical marshals v as compact JSON with object keys sorted and without
+// HTML escaping, so equal values always produce identical bytes and
+// therefore identical digests. Numbers keep their original precision.
+func Canonical(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshal(value)
+}
+
+// decode parses a JSON document into maps, slices and json.Number values.
+func decode(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to parse json: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("failed to parse json: unexpected data after document")
+	}
+
+	return value, nil
+}
+
+func marshal(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
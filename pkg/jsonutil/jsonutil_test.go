@@ -0,0 +1,196 @@
+package jsonutil
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestYAMLToJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  string
+		expectErr bool
+	}{
+		{name: "keeps order", input: "b: 1\na: [x, true, null, 1.5]\n", expected: `{"b":1,"a":["x",true,null,1.5]}`},
+		{name: "strings", input: "date: 2024-01-01\nversion: \"1.0\"\nhtml: <b>\n", expected: `{"date":"2024-01-01","version":"1.0","html":"<b>"}`},
+		{name: "non-string keys", input: "1: one\ntrue: yes\n", expected: `{"1":"one","true":"yes"}`},
+		{name: "anchors and merge keys", input: "base: &base {a: 1, b: 2}\nchild:\n  <<: *base\n  b: 3\n", expected: `{"base":{"a":1,"b":2},"child":{"a":1,"b":3}}`},
+		{name: "empty", input: "", expected: `null`},
+		{name: "infinity", input: "value: .inf\n", expectErr: true},
+		{name: "invalid", input: "a: [\n", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := YAMLToJSON([]byte(tt.input))
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected an error, got: %s", out)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if string(out) != tt.expected {
+				t.Errorf("Expected %s, got: %s", tt.expected, out)
+			}
+		})
+	}
+}
+
+func TestJSONToYAML(t *testing.T) {
+	out, err := JSONToYAML([]byte(`{"name":"app","replicas":2,"version":"1.0","enabled":"true","ports":[80,443],"labels":{}}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := "name: app\nreplicas: 2\nversion: \"1.0\"\nenabled: \"true\"\nports:\n  - 80\n  - 443\nlabels: {}\n"
+	if string(out) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	back, err := YAMLToJSON(out)
+	if err != nil {
+		t.Fatalf("Expected no error converting back, got: %v", err)
+	}
+	if string(back) != `{"name":"app","replicas":2,"version":"1.0","enabled":"true","ports":[80,443],"labels":{}}` {
+		t.Errorf("Expected round trip to keep the document, got: %s", back)
+	}
+
+	if _, err := JSONToYAML([]byte(`{"a":`)); err == nil {
+		t.Error("Expected an error for invalid json")
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// Examples from RFC 7386, appendix A.
+	tests := []struct {
+		doc      string
+		patch    string
+		expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.patch, func(t *testing.T) {
+			out, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if string(out) != tt.expected {
+				t.Errorf("Expected %s, got: %s", tt.expected, out)
+			}
+		})
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	// Mostly examples from RFC 6902, appendix A.
+	tests := []struct {
+		name      string
+		doc       string
+		patch     string
+		expected  string
+		expectErr error
+	}{
+		{name: "add member", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux"}]`, expected: `{"baz":"qux","foo":"bar"}`},
+		{name: "add array element", doc: `{"foo":["bar","baz"]}`, patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`, expected: `{"foo":["bar","qux","baz"]}`},
+		{name: "append", doc: `{"foo":[1]}`, patch: `[{"op":"add","path":"/foo/-","value":[2]}]`, expected: `{"foo":[1,[2]]}`},
+		{name: "add null", doc: `{}`, patch: `[{"op":"add","path":"/foo","value":null}]`, expected: `{"foo":null}`},
+		{name: "remove member", doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`, expected: `{"foo":"bar"}`},
+		{name: "remove element", doc: `{"foo":["bar","qux","baz"]}`, patch: `[{"op":"remove","path":"/foo/1"}]`, expected: `{"foo":["bar","baz"]}`},
+		{name: "replace", doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":"boo"}]`, expected: `{"baz":"boo","foo":"bar"}`},
+		{name: "move", doc: `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, expected: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{name: "move element", doc: `{"foo":["all","grass","cows","eat"]}`, patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, expected: `{"foo":["all","cows","eat","grass"]}`},
+		{name: "copy is independent", doc: `{"a":{"b":1}}`, patch: `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, expected: `{"a":{"b":1},"c":{"b":2}}`},
+		{name: "test passes", doc: `{"baz":"qux","foo":["a",2,"c"]}`, patch: `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, expected: `{"baz":"qux","foo":["a",2,"c"]}`},
+		{name: "escaped pointer", doc: `{"/":9,"~1":10}`, patch: `[{"op":"test","path":"/~01","value":10},{"op":"remove","path":"/~1"}]`, expected: `{"~1":10}`},
+		{name: "replace root", doc: `{"a":1}`, patch: `[{"op":"replace","path":"","value":[1]}]`, expected: `[1]`},
+		{name: "keeps precision", doc: `{"n":12345678901234567890}`, patch: `[]`, expected: `{"n":12345678901234567890}`},
+		{name: "test fails", doc: `{"baz":"qux"}`, patch: `[{"op":"test","path":"/baz","value":"bar"}]`, expectErr: ErrTestFailed},
+		{name: "missing path", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz/bat","value":"qux"}]`, expectErr: errAny},
+		{name: "remove missing", doc: `{"foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`, expectErr: errAny},
+		{name: "index out of range", doc: `{"foo":[1]}`, patch: `[{"op":"add","path":"/foo/3","value":2}]`, expectErr: errAny},
+		{name: "leading zero", doc: `{"foo":[1,2]}`, patch: `[{"op":"remove","path":"/foo/01"}]`, expectErr: errAny},
+		{name: "move into child", doc: `{"a":{"b":{}}}`, patch: `[{"op":"move","from":"/a","path":"/a/b/c"}]`, expectErr: errAny},
+		{name: "missing value", doc: `{}`, patch: `[{"op":"add","path":"/a"}]`, expectErr: errAny},
+		{name: "unknown op", doc: `{}`, patch: `[{"op":"merge","path":"/a","value":1}]`, expectErr: errAny},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := ApplyPatch([]byte(tt.doc), []byte(tt.patch))
+			if tt.expectErr != nil {
+				if err == nil {
+					t.Fatalf("Expected an error, got: %s", out)
+				}
+				if tt.expectErr != errAny && !errors.Is(err, tt.expectErr) {
+					t.Errorf("Expected %v, got: %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if string(out) != tt.expected {
+				t.Errorf("Expected %s, got: %s", tt.expected, out)
+			}
+		})
+	}
+}
+
+var errAny = errors.New("any error")
+
+func TestCanonical(t *testing.T) {
+	type config struct {
+		Zeta  string         `json:"zeta"`
+		Alpha map[string]int `json:"alpha"`
+		HTML  string         `json:"html"`
+	}
+
+	out, err := Canonical(config{Zeta: "z", Alpha: map[string]int{"b": 2, "a": 1}, HTML: "<a&b>"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := `{"alpha":{"a":1,"b":2},"html":"<a&b>","zeta":"z"}`
+	if string(out) != expected {
+		t.Errorf("Expected %s, got: %s", expected, out)
+	}
+}
+
+func TestPretty(t *testing.T) {
+	out, err := Pretty([]byte(` {"a":[1,2],"b":{}} `))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": {}\n}\n"
+	if string(out) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	if _, err := Pretty([]byte(`{`)); err == nil {
+		t.Error("Expected an error for invalid json")
+	}
+}
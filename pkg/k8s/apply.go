@@ -0,0 +1,191 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/jsonutil"
+	"github.com/eunanio/sdk/pkg/log"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFieldManager owns the fields applied by Apply.
+const DefaultFieldManager = "devkit"
+
+// ObjectRef identifies an object in the cluster.
+type ObjectRef struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+}
+
+// String formats the reference like kubectl, e.g. "deployment/web".
+func (r ObjectRef) String() string {
+	return strings.ToLower(r.Kind) + "/" + r.Name
+}
+
+type ApplyOptions struct {
+	// FieldManager names the owner of the applied fields, defaulting to
+	// DefaultFieldManager.
+	FieldManager string
+	// Force takes ownership of fields managed by someone else instead of
+	// failing with a conflict.
+	Force bool
+	// DryRun validates the objects on the server without persisting them.
+	DryRun bool
+}
+
+type object struct {
+	ref  ObjectRef
+	data []byte
+}
+
+// Apply server-side applies every object in manifests, a YAML or JSON
+// stream that may contain several documents and List objects. Namespaces
+// and CustomResourceDefinitions are applied first. It returns the applied
+// objects with their namespaces resolved.
+func (c *Client) Apply(ctx context.Context, manifests []byte, opts ApplyOptions) ([]ObjectRef, error) {
+	defer log.Timed("k8s_apply")()
+	objects, err := parseManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		return applyPriority(objects[i].ref.Kind) < applyPriority(objects[j].ref.Kind)
+	})
+
+	refs := make([]ObjectRef, 0, len(objects))
+	for _, obj := range objects {
+		ref, err := c.applyObject(ctx, obj, opts)
+		if err != nil {
+			return refs, err
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs, nil
+}
+
+func (c *Client) applyObject(ctx context.Context, obj object, opts ApplyOptions) (ObjectRef, error) {
+	path, ref, err := c.objectPath(ctx, obj.ref)
+	if err != nil {
+		return obj.ref, err
+	}
+
+	query := url.Values{}
+	query.Set("fieldManager", opts.FieldManager)
+	if opts.FieldManager == "" {
+		query.Set("fieldManager", DefaultFieldManager)
+	}
+	if opts.Force {
+		query.Set("force", "true")
+	}
+	if opts.DryRun {
+		query.Set("dryRun", "All")
+	}
+
+	resp, err := c.do(ctx, http.MethodPatch, path, query, obj.data, "application/apply-patch+yaml")
+	if err != nil {
+		return ref, fmt.Errorf("failed to apply %s: %w", ref, err)
+	}
+	resp.Body.Close()
+
+	log.Component("k8s").Debug("applied object", "object", ref.String(), "namespace", ref.Namespace)
+	return ref, nil
+}
+
+// parseManifests splits a YAML stream into JSON objects, expanding Lists.
+func parseManifests(manifests []byte) ([]object, error) {
+	var objects []object
+	decoder := yaml.NewDecoder(bytes.NewReader(manifests))
+	for i := 0; ; i++ {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, log.Errorf(log.CodeInvalidArgument, "k8s_apply", "invalid manifest document %d: %s", i, err)
+		}
+		if len(node.Content) == 0 || node.Content[0].ShortTag() == "!!null" {
+			continue
+		}
+
+		doc, err := yaml.Marshal(&node)
+		if err != nil {
+			return nil, err
+		}
+		data, err := jsonutil.YAMLToJSON(doc)
+		if err != nil {
+			return nil, log.Errorf(log.CodeInvalidArgument, "k8s_apply", "invalid manifest document %d: %s", i, err)
+		}
+
+		parsed, err := parseObject(data)
+		if err != nil {
+			return nil, log.Errorf(log.CodeInvalidArgument, "k8s_apply", "invalid manifest document %d: %s", i, err)
+		}
+		objects = append(objects, parsed...)
+	}
+
+	return objects, nil
+}
+
+func parseObject(data []byte) ([]object, error) {
+	var header struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("expected an object: %w", err)
+	}
+
+	if strings.HasSuffix(header.Kind, "List") && header.Items != nil {
+		var objects []object
+		for _, item := range header.Items {
+			parsed, err := parseObject(item)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, parsed...)
+		}
+		return objects, nil
+	}
+
+	if header.APIVersion == "" || header.Kind == "" || header.Metadata.Name == "" {
+		return nil, fmt.Errorf("apiVersion, kind and metadata.name are required")
+	}
+
+	ref := ObjectRef{
+		APIVersion: header.APIVersion,
+		Kind:       header.Kind,
+		Namespace:  header.Metadata.Namespace,
+		Name:       header.Metadata.Name,
+	}
+	return []object{{ref: ref, data: data}}, nil
+}
+
+func applyPriority(kind string) int {
+	switch kind {
+	case "Namespace":
+		return 0
+	case "CustomResourceDefinition":
+		return 1
+	default:
+		return 2
+	}
+}
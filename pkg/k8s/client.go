@@ -0,0 +1,344 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/httpx"
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/system"
+)
+
+// Client talks to the Kubernetes API server of one kubeconfig context.
+type Client struct {
+	// Namespace is used for namespaced objects that do not set one. It
+	// defaults to the context namespace, or "default".
+	Namespace string
+
+	server string
+	http   *httpx.Client
+	user   User
+
+	mu          sync.Mutex
+	resources   map[string][]apiResource
+	execToken   string
+	execExpires time.Time
+}
+
+type apiResource struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	Namespaced bool   `json:"namespaced"`
+}
+
+// NewClient connects to the cluster of the named context, or the current
+// context when name is empty.
+func NewClient(config *Config, name string) (*Client, error) {
+	kubeContext, cluster, user, err := config.resolved(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := clusterTLS(cluster, user)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []httpx.Option{httpx.WithTLSConfig(tlsConfig)}
+	if cluster.ProxyURL != "" {
+		opts = append(opts, httpx.WithProxy(&system.ProxyConfig{HTTPProxy: cluster.ProxyURL, HTTPSProxy: cluster.ProxyURL}))
+	}
+
+	namespace := kubeContext.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	return &Client{
+		Namespace: namespace,
+		server:    strings.TrimSuffix(cluster.Server, "/"),
+		http:      httpx.New(opts...),
+		user:      user,
+		resources: map[string][]apiResource{},
+	}, nil
+}
+
+// LoadClient loads the default kubeconfig and connects to its current
+// context.
+func LoadClient() (*Client, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(config, "")
+}
+
+func clusterTLS(cluster Cluster, user User) (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: cluster.InsecureSkipTLSVerify,
+		ServerName:         cluster.TLSServerName,
+	}
+
+	ca, err := readData(cluster.CertificateAuthorityData, cluster.CertificateAuthority)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster certificate authority: %w", err)
+	}
+	if len(ca) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, log.Errorf(log.CodeInvalidArgument, "k8s_client", "cluster certificate authority contains no PEM certificates")
+		}
+		config.RootCAs = pool
+	}
+
+	cert, err := readData(user.ClientCertificateData, user.ClientCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client certificate: %w", err)
+	}
+	key, err := readData(user.ClientKeyData, user.ClientKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client key: %w", err)
+	}
+	if len(cert) > 0 || len(key) > 0 {
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, log.Errorf(log.CodeInvalidArgument, "k8s_client", "invalid client certificate: %s", err)
+		}
+		config.Certificates = []tls.Certificate{pair}
+	}
+
+	return config, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body []byte, contentType string) (*http.Response, error) {
+	endpoint := c.server + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if err := c.authorize(ctx, req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, log.Errorf(log.CodeRemote, "k8s", "cannot connect to %s: %s", c.server, err)
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, apiError(method, path, resp)
+	}
+
+	return resp, nil
+}
+
+// getJSON decodes the object at path into v.
+func (c *Client) getJSON(ctx context.Context, path string, v any) error {
+	resp, err := c.do(ctx, http.MethodGet, path, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// apiError turns a failed response, usually a Status object, into an error.
+func apiError(method, path string, resp *http.Response) error {
+	var status struct {
+		Message string `json:"message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if json.Unmarshal(data, &status) != nil || status.Message == "" {
+		status.Message = strings.TrimSpace(string(data))
+	}
+
+	code := log.CodeRemote
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		code = log.CodeNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		code = log.CodeUnauthorized
+	case http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusConflict:
+		code = log.CodeInvalidArgument
+	}
+
+	return log.Errorf(code, "k8s", "%s %s returned %d: %s", method, path, resp.StatusCode, status.Message)
+}
+
+func (c *Client) authorize(ctx context.Context, req *http.Request) error {
+	switch {
+	case c.user.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.user.Token)
+	case c.user.TokenFile != "":
+		// Projected service account tokens are rotated, so the file is read
+		// for every request.
+		token, err := os.ReadFile(c.user.TokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read token file: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	case c.user.Exec != nil:
+		token, err := c.execCredential(ctx)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case c.user.Username != "":
+		req.SetBasicAuth(c.user.Username, c.user.Password)
+	}
+
+	return nil
+}
+
+// execCredential runs the exec credential plugin, caching its token until
+// it expires.
+func (c *Client) execCredential(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.execToken != "" && (c.execExpires.IsZero() || time.Now().Add(time.Minute).Before(c.execExpires)) {
+		return c.execToken, nil
+	}
+
+	plugin := c.user.Exec
+	apiVersion := plugin.APIVersion
+	if apiVersion == "" {
+		apiVersion = "client.authentication.k8s.io/v1"
+	}
+	info, err := json.Marshal(map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       "ExecCredential",
+		"spec":       map[string]any{"interactive": false},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.CommandContext(ctx, plugin.Command, plugin.Args...)
+	cmd.Env = append(os.Environ(), "KUBERNETES_EXEC_INFO="+string(info))
+	for _, env := range plugin.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", log.Errorf(log.CodeUnauthorized, "k8s_auth", "credential plugin %s failed: %s %s", plugin.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var credential struct {
+		Status struct {
+			Token               string    `json:"token"`
+			ExpirationTimestamp time.Time `json:"expirationTimestamp"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(out, &credential); err != nil || credential.Status.Token == "" {
+		return "", log.Errorf(log.CodeUnauthorized, "k8s_auth", "credential plugin %s returned no token", plugin.Command)
+	}
+
+	c.execToken = credential.Status.Token
+	c.execExpires = credential.Status.ExpirationTimestamp
+	return c.execToken, nil
+}
+
+// resource finds the API resource serving kind in apiVersion through the
+// discovery API.
+func (c *Client) resource(ctx context.Context, apiVersion, kind string) (apiResource, error) {
+	c.mu.Lock()
+	resources, ok := c.resources[apiVersion]
+	c.mu.Unlock()
+
+	if !ok {
+		var list struct {
+			Resources []apiResource `json:"resources"`
+		}
+		if err := c.getJSON(ctx, groupVersionPath(apiVersion), &list); err != nil {
+			return apiResource{}, fmt.Errorf("failed to discover %s: %w", apiVersion, err)
+		}
+
+		// Subresources such as deployments/status are not addressable
+		// objects.
+		for _, r := range list.Resources {
+			if !strings.Contains(r.Name, "/") {
+				resources = append(resources, r)
+			}
+		}
+
+		c.mu.Lock()
+		c.resources[apiVersion] = resources
+		c.mu.Unlock()
+	}
+
+	for _, r := range resources {
+		if r.Kind == kind {
+			return r, nil
+		}
+	}
+
+	return apiResource{}, log.Errorf(log.CodeNotFound, "k8s", "kind %s is not served by %s", kind, apiVersion)
+}
+
+// objectPath returns the URL path of the object, or of its collection when
+// ref has no name, along with ref with its namespace resolved.
+func (c *Client) objectPath(ctx context.Context, ref ObjectRef) (string, ObjectRef, error) {
+	r, err := c.resource(ctx, ref.APIVersion, ref.Kind)
+	if err != nil {
+		return "", ref, err
+	}
+
+	path := groupVersionPath(ref.APIVersion)
+	if r.Namespaced {
+		if ref.Namespace == "" {
+			ref.Namespace = c.Namespace
+		}
+		path += "/namespaces/" + url.PathEscape(ref.Namespace)
+	} else {
+		ref.Namespace = ""
+	}
+	path += "/" + r.Name
+	if ref.Name != "" {
+		path += "/" + url.PathEscape(ref.Name)
+	}
+
+	return path, ref, nil
+}
+
+func groupVersionPath(apiVersion string) string {
+	if !strings.Contains(apiVersion, "/") {
+		return "/api/" + apiVersion
+	}
+
+	return "/apis/" + apiVersion
+}
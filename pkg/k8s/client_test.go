@@ -0,0 +1,315 @@
+package k8s
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/oci"
+)
+
+type applied struct {
+	path  string
+	query string
+	body  map[string]any
+}
+
+// fakeAPI serves discovery for core/v1 and apps/v1, records applies and
+// reports deployments as ready after readyAfter status reads.
+type fakeAPI struct {
+	mu         sync.Mutex
+	applied    []applied
+	auth       []string
+	reads      int
+	readyAfter int
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+
+	switch {
+	case r.URL.Path == "/api/v1":
+		json.NewEncoder(w).Encode(map[string]any{"resources": []map[string]any{
+			{"name": "namespaces", "kind": "Namespace", "namespaced": false},
+			{"name": "secrets", "kind": "Secret", "namespaced": true},
+			{"name": "configmaps", "kind": "ConfigMap", "namespaced": true},
+		}})
+	case r.URL.Path == "/apis/apps/v1":
+		json.NewEncoder(w).Encode(map[string]any{"resources": []map[string]any{
+			{"name": "deployments", "kind": "Deployment", "namespaced": true},
+			{"name": "deployments/status", "kind": "Deployment", "namespaced": true},
+		}})
+	case r.Method == http.MethodPatch:
+		var body map[string]any
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &body); err != nil || r.Header.Get("Content-Type") != "application/apply-patch+yaml" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		if body["kind"] == "ConfigMap" && body["metadata"].(map[string]any)["name"] == "conflict" {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]any{"kind": "Status", "message": `Apply failed with 1 conflict: conflict with "kubectl"`})
+			return
+		}
+		f.applied = append(f.applied, applied{path: r.URL.Path, query: r.URL.RawQuery, body: body})
+		w.Write(data)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/apis/apps/v1/namespaces/team/deployments/"):
+		f.reads++
+		updated := 1
+		if f.reads >= f.readyAfter {
+			updated = 3
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"metadata": map[string]any{"generation": 2},
+			"spec":     map[string]any{"replicas": 3},
+			"status": map[string]any{
+				"observedGeneration": 2,
+				"replicas":           3,
+				"updatedReplicas":    updated,
+				"availableReplicas":  updated,
+			},
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"kind": "Status", "message": "the server could not find the requested resource"})
+	}
+}
+
+// newTestClient starts api over TLS and connects to it through a
+// kubeconfig trusting its certificate.
+func newTestClient(t *testing.T, api http.Handler, user string) *Client {
+	server := httptest.NewTLSServer(api)
+	t.Cleanup(server.Close)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	kubeconfig := `
+current-context: test
+contexts:
+- name: test
+  context: {cluster: test, user: test, namespace: team}
+clusters:
+- name: test
+  cluster:
+    server: ` + server.URL + `
+    certificate-authority-data: ` + base64.StdEncoding.EncodeToString(ca) + `
+users:
+- name: test
+  user:
+` + user
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(kubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(config, "")
+	if err != nil {
+		t.Fatalf("Expected no error creating the client, got: %v", err)
+	}
+
+	return client
+}
+
+const testManifests = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+---
+# comments and empty documents are skipped
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata: {name: settings, namespace: other}
+  data: {port: "8080"}
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: team
+`
+
+func TestApply(t *testing.T) {
+	api := &fakeAPI{}
+	client := newTestClient(t, api, "    token: secret-token\n")
+
+	refs, err := client.Apply(context.Background(), []byte(testManifests), ApplyOptions{Force: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []ObjectRef{
+		{APIVersion: "v1", Kind: "Namespace", Name: "team"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "team", Name: "web"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "other", Name: "settings"},
+	}
+	if !reflect.DeepEqual(refs, expected) {
+		t.Errorf("Expected %+v, got: %+v", expected, refs)
+	}
+
+	paths := []string{"/api/v1/namespaces/team", "/apis/apps/v1/namespaces/team/deployments/web", "/api/v1/namespaces/other/configmaps/settings"}
+	for i, path := range paths {
+		if api.applied[i].path != path {
+			t.Errorf("Expected apply %d to %s, got: %s", i, path, api.applied[i].path)
+		}
+		if api.applied[i].query != "fieldManager=devkit&force=true" {
+			t.Errorf("Expected field manager and force, got: %s", api.applied[i].query)
+		}
+	}
+	if data := api.applied[2].body["data"].(map[string]any); data["port"] != "8080" {
+		t.Errorf("Expected the object to be sent as JSON, got: %v", api.applied[2].body)
+	}
+
+	for _, auth := range api.auth {
+		if auth != "Bearer secret-token" {
+			t.Errorf("Expected the bearer token on every request, got: %q", auth)
+		}
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	client := newTestClient(t, &fakeAPI{}, "    token: secret-token\n")
+
+	tests := []struct {
+		name       string
+		manifests  string
+		expectCode log.Code
+	}{
+		{name: "missing name", manifests: "apiVersion: v1\nkind: ConfigMap\n", expectCode: log.CodeInvalidArgument},
+		{name: "invalid yaml", manifests: "kind: [\n", expectCode: log.CodeInvalidArgument},
+		{name: "unknown kind", manifests: "apiVersion: v1\nkind: Widget\nmetadata: {name: w}\n", expectCode: log.CodeNotFound},
+		{name: "unknown group", manifests: "apiVersion: example.com/v1\nkind: Widget\nmetadata: {name: w}\n", expectCode: log.CodeNotFound},
+		{name: "conflict", manifests: "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: conflict}\n", expectCode: log.CodeInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Apply(context.Background(), []byte(tt.manifests), ApplyOptions{})
+			if !log.IsCode(err, tt.expectCode) {
+				t.Errorf("Expected %s, got: %v", tt.expectCode, err)
+			}
+		})
+	}
+}
+
+func TestWaitForRollout(t *testing.T) {
+	rolloutPollInterval = 10 * time.Millisecond
+	defer func() { rolloutPollInterval = 2 * time.Second }()
+
+	api := &fakeAPI{readyAfter: 3}
+	client := newTestClient(t, api, "    token: secret-token\n")
+
+	refs := []ObjectRef{
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "settings"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+	}
+	if err := client.WaitForRollout(context.Background(), refs...); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if api.reads != 3 {
+		t.Errorf("Expected to poll until ready, got %d reads", api.reads)
+	}
+
+	api.reads, api.readyAfter = 0, 1000
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := client.WaitForRollout(ctx, refs...)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "1 of 3 updated replicas") {
+		t.Errorf("Expected a deadline error with the rollout state, got: %v", err)
+	}
+}
+
+func TestPullSecret(t *testing.T) {
+	api := &fakeAPI{}
+	client := newTestClient(t, api, "    username: admin\n    password: hunter2\n")
+
+	ref, err := client.ApplyPullSecret(context.Background(), "registry", "", "docker.io", oci.OciCredentials{Username: "user", Password: "pass"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if ref != (ObjectRef{APIVersion: "v1", Kind: "Secret", Namespace: "team", Name: "registry"}) {
+		t.Errorf("Unexpected ref: %+v", ref)
+	}
+
+	secret := api.applied[0].body
+	if secret["type"] != "kubernetes.io/dockerconfigjson" {
+		t.Errorf("Expected a dockerconfigjson secret, got: %v", secret["type"])
+	}
+	encoded := secret["data"].(map[string]any)[".dockerconfigjson"].(string)
+	decoded, _ := base64.StdEncoding.DecodeString(encoded)
+
+	var dockerConfig struct {
+		Auths map[string]struct {
+			Username string `json:"username"`
+			Auth     string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(decoded, &dockerConfig); err != nil {
+		t.Fatalf("Expected a docker config, got: %s", decoded)
+	}
+	entry, ok := dockerConfig.Auths[dockerHubConfigKey]
+	if !ok || entry.Username != "user" || entry.Auth != base64.StdEncoding.EncodeToString([]byte("user:pass")) {
+		t.Errorf("Expected Docker Hub credentials, got: %s", decoded)
+	}
+
+	if api.auth[0] != "Basic "+base64.StdEncoding.EncodeToString([]byte("admin:hunter2")) {
+		t.Errorf("Expected basic auth from the kubeconfig user, got: %q", api.auth[0])
+	}
+}
+
+func TestExecCredential(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential plugin is a shell script")
+	}
+
+	dir := t.TempDir()
+	plugin := filepath.Join(dir, "credential")
+	script := "#!/bin/sh\necho \"$@\" >> \"${0%/*}/calls\"\n" +
+		`echo '{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"exec-token","expirationTimestamp":"2999-01-01T00:00:00Z"}}'` + "\n"
+	if err := os.WriteFile(plugin, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	api := &fakeAPI{}
+	client := newTestClient(t, api, "    exec:\n      command: "+plugin+"\n      args: [--cluster, test]\n")
+	if _, err := client.Apply(context.Background(), []byte(testManifests), ApplyOptions{}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	for _, auth := range api.auth {
+		if auth != "Bearer exec-token" {
+			t.Errorf("Expected the exec token on every request, got: %q", auth)
+		}
+	}
+
+	calls, _ := os.ReadFile(filepath.Join(dir, "calls"))
+	if string(calls) != "--cluster test\n" {
+		t.Errorf("Expected the plugin to run once and its token to be cached, got: %q", calls)
+	}
+}
@@ -0,0 +1,234 @@
+package k8s
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a merged kubeconfig. Paths inside it are absolute.
+type Config struct {
+	CurrentContext string
+	Contexts       map[string]Context
+	Clusters       map[string]Cluster
+	Users          map[string]User
+}
+
+type Context struct {
+	Cluster   string `yaml:"cluster"`
+	User      string `yaml:"user"`
+	Namespace string `yaml:"namespace"`
+}
+
+type Cluster struct {
+	Server                   string `yaml:"server"`
+	CertificateAuthority     string `yaml:"certificate-authority"`
+	CertificateAuthorityData string `yaml:"certificate-authority-data"`
+	InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+	TLSServerName            string `yaml:"tls-server-name"`
+	ProxyURL                 string `yaml:"proxy-url"`
+}
+
+type User struct {
+	ClientCertificate     string      `yaml:"client-certificate"`
+	ClientCertificateData string      `yaml:"client-certificate-data"`
+	ClientKey             string      `yaml:"client-key"`
+	ClientKeyData         string      `yaml:"client-key-data"`
+	Token                 string      `yaml:"token"`
+	TokenFile             string      `yaml:"tokenFile"`
+	Username              string      `yaml:"username"`
+	Password              string      `yaml:"password"`
+	Exec                  *ExecConfig `yaml:"exec"`
+}
+
+// ExecConfig runs a credential plugin, such as aws or gke-gcloud-auth-plugin,
+// that prints an ExecCredential with a token.
+type ExecConfig struct {
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args"`
+	Env     []struct {
+		Name  string `yaml:"name"`
+		Value string `yaml:"value"`
+	} `yaml:"env"`
+	APIVersion string `yaml:"apiVersion"`
+}
+
+type kubeconfigFile struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string  `yaml:"name"`
+		Context Context `yaml:"context"`
+	} `yaml:"contexts"`
+	Clusters []struct {
+		Name    string  `yaml:"name"`
+		Cluster Cluster `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User User   `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// DefaultPaths returns the files listed in $KUBECONFIG, or ~/.kube/config.
+func DefaultPaths() []string {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		var paths []string
+		for _, path := range filepath.SplitList(env) {
+			if path != "" {
+				paths = append(paths, path)
+			}
+		}
+		return paths
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(home, ".kube", "config")}
+}
+
+// LoadConfig reads and merges kubeconfig files, defaulting to DefaultPaths.
+// As with kubectl, the first file to define a name or the current context
+// wins and missing files are skipped.
+func LoadConfig(paths ...string) (*Config, error) {
+	if len(paths) == 0 {
+		paths = DefaultPaths()
+	}
+
+	config := &Config{
+		Contexts: map[string]Context{},
+		Clusters: map[string]Cluster{},
+		Users:    map[string]User{},
+	}
+
+	loaded := 0
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+		}
+
+		var file kubeconfigFile
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, log.Errorf(log.CodeInvalidArgument, "k8s_config", "invalid kubeconfig %s: %s", path, err)
+		}
+		loaded++
+
+		dir := filepath.Dir(path)
+		if config.CurrentContext == "" {
+			config.CurrentContext = file.CurrentContext
+		}
+		for _, entry := range file.Contexts {
+			if _, ok := config.Contexts[entry.Name]; !ok {
+				config.Contexts[entry.Name] = entry.Context
+			}
+		}
+		for _, entry := range file.Clusters {
+			if _, ok := config.Clusters[entry.Name]; !ok {
+				cluster := entry.Cluster
+				cluster.CertificateAuthority = resolvePath(dir, cluster.CertificateAuthority)
+				config.Clusters[entry.Name] = cluster
+			}
+		}
+		for _, entry := range file.Users {
+			if _, ok := config.Users[entry.Name]; !ok {
+				user := entry.User
+				user.ClientCertificate = resolvePath(dir, user.ClientCertificate)
+				user.ClientKey = resolvePath(dir, user.ClientKey)
+				user.TokenFile = resolvePath(dir, user.TokenFile)
+				if user.Exec != nil && strings.ContainsRune(user.Exec.Command, filepath.Separator) {
+					user.Exec.Command = resolvePath(dir, user.Exec.Command)
+				}
+				config.Users[entry.Name] = user
+			}
+		}
+	}
+
+	if loaded == 0 {
+		return nil, log.Errorf(log.CodeNotFound, "k8s_config", "no kubeconfig found in %s", strings.Join(paths, ", "))
+	}
+
+	return config, nil
+}
+
+// ContextNames returns the names of all contexts, sorted.
+func (c *Config) ContextNames() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// resolved returns the context, cluster and user for name, or for the
+// current context when name is empty.
+func (c *Config) resolved(name string) (Context, Cluster, User, error) {
+	if name == "" {
+		name = c.CurrentContext
+	}
+	if name == "" {
+		return Context{}, Cluster{}, User{}, log.Errorf(log.CodeInvalidArgument, "k8s_config", "no context selected and current-context is not set")
+	}
+
+	kubeContext, ok := c.Contexts[name]
+	if !ok {
+		return Context{}, Cluster{}, User{}, log.Errorf(log.CodeNotFound, "k8s_config", "context %s not found", name)
+	}
+
+	cluster, ok := c.Clusters[kubeContext.Cluster]
+	if !ok {
+		return Context{}, Cluster{}, User{}, log.Errorf(log.CodeNotFound, "k8s_config", "cluster %s of context %s not found", kubeContext.Cluster, name)
+	}
+	if cluster.Server == "" {
+		return Context{}, Cluster{}, User{}, log.Errorf(log.CodeInvalidArgument, "k8s_config", "cluster %s has no server", kubeContext.Cluster)
+	}
+
+	// A context without a user is valid and connects anonymously.
+	user := c.Users[kubeContext.User]
+	return kubeContext, cluster, user, nil
+}
+
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	if strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+
+	return filepath.Join(dir, path)
+}
+
+// readData returns the base64 data, or the content of file when data is
+// empty.
+func readData(data, file string) ([]byte, error) {
+	if data != "" {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 data: %w", err)
+		}
+		return decoded, nil
+	}
+
+	if file == "" {
+		return nil, nil
+	}
+
+	return os.ReadFile(file)
+}
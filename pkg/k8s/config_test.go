@@ -0,0 +1,127 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+const primaryConfig = `
+current-context: dev
+contexts:
+- name: dev
+  context: {cluster: dev, user: dev, namespace: team}
+- name: shared
+  context: {cluster: dev, user: dev}
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com
+    certificate-authority: certs/ca.pem
+users:
+- name: dev
+  user:
+    client-certificate: certs/client.pem
+    client-key: /abs/client-key.pem
+`
+
+const secondaryConfig = `
+current-context: prod
+contexts:
+- name: shared
+  context: {cluster: prod, user: prod}
+- name: prod
+  context: {cluster: prod}
+- name: broken
+  context: {cluster: missing}
+clusters:
+- name: prod
+  cluster: {server: https://prod.example.com}
+- name: dev
+  cluster: {server: https://ignored.example.com}
+`
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	primary := filepath.Join(dir, "primary")
+	secondary := filepath.Join(dir, "secondary")
+	os.WriteFile(primary, []byte(primaryConfig), 0600)
+	os.WriteFile(secondary, []byte(secondaryConfig), 0600)
+
+	config, err := LoadConfig(primary, filepath.Join(dir, "missing"), secondary)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if config.CurrentContext != "dev" {
+		t.Errorf("Expected the first current-context to win, got: %s", config.CurrentContext)
+	}
+	if names := config.ContextNames(); !reflect.DeepEqual(names, []string{"broken", "dev", "prod", "shared"}) {
+		t.Errorf("Expected merged contexts, got: %v", names)
+	}
+	if config.Contexts["shared"].Cluster != "dev" {
+		t.Errorf("Expected the first definition of a context to win, got: %+v", config.Contexts["shared"])
+	}
+	if server := config.Clusters["dev"].Server; server != "https://dev.example.com" {
+		t.Errorf("Expected the first definition of a cluster to win, got: %s", server)
+	}
+	if ca := config.Clusters["dev"].CertificateAuthority; ca != filepath.Join(dir, "certs", "ca.pem") {
+		t.Errorf("Expected relative paths to be resolved against the kubeconfig, got: %s", ca)
+	}
+	if key := config.Users["dev"].ClientKey; key != "/abs/client-key.pem" {
+		t.Errorf("Expected absolute paths to be kept, got: %s", key)
+	}
+
+	tests := []struct {
+		context      string
+		expectServer string
+		expectCode   log.Code
+	}{
+		{context: "", expectServer: "https://dev.example.com"},
+		{context: "prod", expectServer: "https://prod.example.com"},
+		{context: "unknown", expectCode: log.CodeNotFound},
+		{context: "broken", expectCode: log.CodeNotFound},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.context, func(t *testing.T) {
+			_, cluster, _, err := config.resolved(tt.context)
+			if tt.expectCode != "" {
+				if !log.IsCode(err, tt.expectCode) {
+					t.Errorf("Expected %s, got: %v", tt.expectCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if cluster.Server != tt.expectServer {
+				t.Errorf("Expected %s, got: %s", tt.expectServer, cluster.Server)
+			}
+		})
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadConfig(filepath.Join(dir, "missing")); !log.IsCode(err, log.CodeNotFound) {
+		t.Errorf("Expected not found when no kubeconfig exists, got: %v", err)
+	}
+
+	invalid := filepath.Join(dir, "invalid")
+	os.WriteFile(invalid, []byte("contexts: {"), 0600)
+	if _, err := LoadConfig(invalid); !log.IsCode(err, log.CodeInvalidArgument) {
+		t.Errorf("Expected invalid argument for a malformed kubeconfig, got: %v", err)
+	}
+}
+
+func TestDefaultPaths(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/a/config"+string(os.PathListSeparator)+string(os.PathListSeparator)+"/b/config")
+	if paths := DefaultPaths(); !reflect.DeepEqual(paths, []string{"/a/config", "/b/config"}) {
+		t.Errorf("Expected KUBECONFIG entries, got: %v", paths)
+	}
+}
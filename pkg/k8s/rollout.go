@@ -0,0 +1,151 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+// rolloutPollInterval is how often WaitForRollout checks workload status.
+var rolloutPollInterval = 2 * time.Second
+
+type workload struct {
+	Metadata struct {
+		Generation int64 `json:"generation"`
+	} `json:"metadata"`
+	Spec struct {
+		Replicas       *int32 `json:"replicas"`
+		UpdateStrategy struct {
+			Type string `json:"type"`
+		} `json:"updateStrategy"`
+	} `json:"spec"`
+	Status struct {
+		ObservedGeneration     int64  `json:"observedGeneration"`
+		Replicas               int32  `json:"replicas"`
+		UpdatedReplicas        int32  `json:"updatedReplicas"`
+		ReadyReplicas          int32  `json:"readyReplicas"`
+		AvailableReplicas      int32  `json:"availableReplicas"`
+		CurrentRevision        string `json:"currentRevision"`
+		UpdateRevision         string `json:"updateRevision"`
+		DesiredNumberScheduled int32  `json:"desiredNumberScheduled"`
+		UpdatedNumberScheduled int32  `json:"updatedNumberScheduled"`
+		NumberAvailable        int32  `json:"numberAvailable"`
+		Conditions             []struct {
+			Type    string `json:"type"`
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// WaitForRollout waits until the Deployments, StatefulSets and DaemonSets
+// among refs have rolled out, using the same rules as kubectl rollout
+// status. Other kinds are ignored, so the result of Apply can be passed
+// directly. Bound the wait with a context deadline.
+func (c *Client) WaitForRollout(ctx context.Context, refs ...ObjectRef) error {
+	defer log.Timed("k8s_rollout")()
+	var pending []ObjectRef
+	for _, ref := range refs {
+		switch ref.Kind {
+		case "Deployment", "StatefulSet", "DaemonSet":
+			pending = append(pending, ref)
+		}
+	}
+
+	for {
+		var waiting []ObjectRef
+		var reason string
+		for _, ref := range pending {
+			done, msg, err := c.rolloutStatus(ctx, ref)
+			if err != nil {
+				return err
+			}
+			if !done {
+				waiting = append(waiting, ref)
+				reason = msg
+			}
+		}
+
+		if len(waiting) == 0 {
+			return nil
+		}
+		pending = waiting
+
+		timer := time.NewTimer(rolloutPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("rollout of %s did not finish: %s: %w", pending[len(pending)-1], reason, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// rolloutStatus reports whether ref has rolled out, and what it is waiting
+// for if not.
+func (c *Client) rolloutStatus(ctx context.Context, ref ObjectRef) (bool, string, error) {
+	path, ref, err := c.objectPath(ctx, ref)
+	if err != nil {
+		return false, "", err
+	}
+
+	var w workload
+	if err := c.getJSON(ctx, path, &w); err != nil {
+		return false, "", err
+	}
+
+	if w.Metadata.Generation > w.Status.ObservedGeneration {
+		return false, "waiting for the update to be observed", nil
+	}
+
+	switch ref.Kind {
+	case "Deployment":
+		for _, condition := range w.Status.Conditions {
+			if condition.Type == "Progressing" && condition.Reason == "ProgressDeadlineExceeded" {
+				return false, "", log.Errorf(log.CodeRemote, "k8s_rollout", "%s exceeded its progress deadline: %s", ref, condition.Message)
+			}
+		}
+
+		replicas := desiredReplicas(w)
+		switch {
+		case w.Status.UpdatedReplicas < replicas:
+			return false, fmt.Sprintf("%d of %d updated replicas", w.Status.UpdatedReplicas, replicas), nil
+		case w.Status.Replicas > w.Status.UpdatedReplicas:
+			return false, fmt.Sprintf("%d old replicas pending termination", w.Status.Replicas-w.Status.UpdatedReplicas), nil
+		case w.Status.AvailableReplicas < w.Status.UpdatedReplicas:
+			return false, fmt.Sprintf("%d of %d updated replicas available", w.Status.AvailableReplicas, w.Status.UpdatedReplicas), nil
+		}
+	case "StatefulSet":
+		if w.Spec.UpdateStrategy.Type == "OnDelete" {
+			return true, "", nil
+		}
+
+		replicas := desiredReplicas(w)
+		switch {
+		case w.Status.ReadyReplicas < replicas:
+			return false, fmt.Sprintf("%d of %d replicas ready", w.Status.ReadyReplicas, replicas), nil
+		case w.Status.UpdateRevision != w.Status.CurrentRevision:
+			return false, fmt.Sprintf("%d of %d replicas updated", w.Status.UpdatedReplicas, replicas), nil
+		}
+	case "DaemonSet":
+		desired := w.Status.DesiredNumberScheduled
+		switch {
+		case w.Status.UpdatedNumberScheduled < desired:
+			return false, fmt.Sprintf("%d of %d updated pods scheduled", w.Status.UpdatedNumberScheduled, desired), nil
+		case w.Status.NumberAvailable < desired:
+			return false, fmt.Sprintf("%d of %d updated pods available", w.Status.NumberAvailable, desired), nil
+		}
+	}
+
+	return true, "", nil
+}
+
+func desiredReplicas(w workload) int32 {
+	if w.Spec.Replicas == nil {
+		return 1
+	}
+
+	return *w.Spec.Replicas
+}
@@ -0,0 +1,65 @@
+package k8s
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/eunanio/sdk/pkg/oci"
+)
+
+// dockerHubConfigKey is the registry key Docker and the kubelet use for
+// Docker Hub credentials.
+const dockerHubConfigKey = "https://index.docker.io/v1/"
+
+// PullSecret returns a kubernetes.io/dockerconfigjson Secret granting access
+// to the registry at host with creds, for use in imagePullSecrets. An empty
+// namespace uses the namespace of the client it is applied with.
+func PullSecret(name, namespace, host string, creds oci.OciCredentials) ([]byte, error) {
+	if host == "docker.io" || host == "index.docker.io" || host == "registry-1.docker.io" {
+		host = dockerHubConfigKey
+	}
+
+	auth := base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))
+	dockerConfig, err := json.Marshal(map[string]any{
+		"auths": map[string]any{
+			host: map[string]string{
+				"username": creds.Username,
+				"password": creds.Password,
+				"auth":     auth,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]string{"name": name}
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+
+	return json.Marshal(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   metadata,
+		"type":       "kubernetes.io/dockerconfigjson",
+		"data":       map[string][]byte{".dockerconfigjson": dockerConfig},
+	})
+}
+
+// ApplyPullSecret creates or updates the image pull secret returned by
+// PullSecret.
+func (c *Client) ApplyPullSecret(ctx context.Context, name, namespace, host string, creds oci.OciCredentials) (ObjectRef, error) {
+	secret, err := PullSecret(name, namespace, host, creds)
+	if err != nil {
+		return ObjectRef{}, err
+	}
+
+	refs, err := c.Apply(ctx, secret, ApplyOptions{Force: true})
+	if err != nil {
+		return ObjectRef{}, err
+	}
+
+	return refs[0], nil
+}
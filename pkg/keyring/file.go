@@ -0,0 +1,243 @@
+package keyring
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/lockfile"
+	"github.com/eunanio/sdk/pkg/system"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	saltSize = 16
+	keySize  = 32
+	// lockTimeout bounds how long a read or write waits for other
+	// processes.
+	lockTimeout = 30 * time.Second
+)
+
+var ErrNoPassphrase = errors.New("keyring file needs DEVKIT_KEYRING_PASSWORD or a passphrase entered at a terminal")
+
+// promptPassphrase asks for the passphrase of the keyring file at path.
+var promptPassphrase = func(path string) (string, error) {
+	if system.IsNonInteractive() {
+		return "", system.ErrNonInteractive
+	}
+	return system.PromptPassword(fmt.Sprintf("Passphrase for %s: ", path))
+}
+
+// fileBackend keeps every secret in one JSON document sealed with AES-GCM.
+// The file holds the scrypt salt, the nonce and the ciphertext. Reads and
+// writes hold a lock file so several processes can share it.
+type fileBackend struct {
+	mu         sync.Mutex
+	path       string
+	passphrase []byte
+}
+
+func newFileBackend(path string) *fileBackend {
+	return &fileBackend{path: path}
+}
+
+func (f *fileBackend) get(host string) ([]byte, error) {
+	var secret []byte
+	err := f.locked(func() error {
+		secrets, _, err := f.load()
+		if err != nil {
+			return err
+		}
+
+		var ok bool
+		if secret, ok = secrets[host]; !ok {
+			return ErrNotFound
+		}
+		return nil
+	})
+
+	return secret, err
+}
+
+func (f *fileBackend) set(host string, secret []byte) error {
+	return f.locked(func() error {
+		secrets, salt, err := f.load()
+		if err != nil {
+			return err
+		}
+
+		secrets[host] = secret
+		return f.save(secrets, salt)
+	})
+}
+
+func (f *fileBackend) delete(host string) error {
+	return f.locked(func() error {
+		secrets, salt, err := f.load()
+		if err != nil {
+			return err
+		}
+
+		if _, ok := secrets[host]; !ok {
+			return ErrNotFound
+		}
+
+		delete(secrets, host)
+		return f.save(secrets, salt)
+	})
+}
+
+func (f *fileBackend) locked(fn func() error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return err
+	}
+
+	lock, err := lockfile.Acquire(context.Background(), f.path+".lock", lockfile.Options{Timeout: lockTimeout})
+	if err != nil {
+		return fmt.Errorf("failed to lock keyring file: %w", err)
+	}
+	defer lock.Release()
+
+	return fn()
+}
+
+func (f *fileBackend) load() (map[string][]byte, []byte, error) {
+	secrets := map[string][]byte{}
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		salt := make([]byte, saltSize)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return nil, nil, err
+		}
+		return secrets, salt, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(data) < saltSize {
+		return nil, nil, fmt.Errorf("keyring file %s is corrupt", f.path)
+	}
+	salt, data := data[:saltSize], data[saltSize:]
+
+	gcm, err := f.cipher(salt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, nil, fmt.Errorf("keyring file %s is corrupt", f.path)
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		// Ask again next time rather than keep a mistyped passphrase.
+		f.passphrase = nil
+		return nil, nil, fmt.Errorf("failed to decrypt keyring file %s: %w", f.path, err)
+	}
+
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode keyring file %s: %w", f.path, err)
+	}
+
+	return secrets, salt, nil
+}
+
+func (f *fileBackend) save(secrets map[string][]byte, salt []byte) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	gcm, err := f.cipher(salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	data := make([]byte, 0, len(salt)+len(nonce)+len(plaintext)+gcm.Overhead())
+	data = append(data, salt...)
+	data = append(data, nonce...)
+	data = gcm.Seal(data, nonce, plaintext, nil)
+
+	// A unique temporary file keeps concurrent writers from truncating each
+	// other's data before the rename.
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path)
+}
+
+func (f *fileBackend) cipher(salt []byte) (cipher.AEAD, error) {
+	password, err := f.password()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := scrypt.Key(password, salt, 1<<15, 8, 1, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// password returns DEVKIT_KEYRING_PASSWORD, or else a passphrase prompted
+// for once per process. Without either there is no key to derive, so
+// ErrNoPassphrase is returned rather than keeping a key on disk.
+func (f *fileBackend) password() ([]byte, error) {
+	if password := os.Getenv("DEVKIT_KEYRING_PASSWORD"); password != "" {
+		return []byte(password), nil
+	}
+	if f.passphrase != nil {
+		return f.passphrase, nil
+	}
+
+	passphrase, err := promptPassphrase(f.path)
+	if errors.Is(err, system.ErrNonInteractive) {
+		return nil, ErrNoPassphrase
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring passphrase: %w", err)
+	}
+	if passphrase == "" {
+		return nil, ErrNoPassphrase
+	}
+
+	f.passphrase = []byte(passphrase)
+	return f.passphrase, nil
+}
@@ -0,0 +1,122 @@
+package keyring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var ErrNotFound = errors.New("credential not found")
+
+type Credential struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// backend stores opaque secrets by host.
+type backend interface {
+	get(host string) ([]byte, error)
+	set(host string, secret []byte) error
+	delete(host string) error
+}
+
+type Keyring struct {
+	service string
+	backend backend
+}
+
+type Option func(*options)
+
+type options struct {
+	file string
+}
+
+// WithFile stores credentials in an encrypted file at path instead of the
+// operating system's credential store.
+func WithFile(path string) Option {
+	return func(o *options) {
+		o.file = path
+	}
+}
+
+// New returns a keyring that stores credentials under service in the macOS
+// Keychain, Windows Credential Manager or the Secret Service on Linux. When
+// no native store is available credentials are kept in an AES-GCM encrypted
+// file in the user config directory. Its key is derived from
+// DEVKIT_KEYRING_PASSWORD when set, otherwise from a passphrase prompted for
+// on the terminal; ErrNoPassphrase is returned when neither is available.
+func New(service string, opts ...Option) (*Keyring, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.file == "" {
+		if native := nativeBackend(service); native != nil {
+			return &Keyring{service: service, backend: native}, nil
+		}
+
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find config directory: %w", err)
+		}
+		o.file = filepath.Join(dir, service, "keyring.enc")
+	}
+
+	return &Keyring{service: service, backend: newFileBackend(o.file)}, nil
+}
+
+// Get returns the credential stored for host, or ErrNotFound.
+func (k *Keyring) Get(host string) (*Credential, error) {
+	secret, err := k.backend.get(host)
+	if err != nil {
+		return nil, err
+	}
+
+	cred := &Credential{}
+	if err := json.Unmarshal(secret, cred); err != nil {
+		return nil, fmt.Errorf("failed to decode credential for %s: %w", host, err)
+	}
+
+	return cred, nil
+}
+
+func (k *Keyring) Set(host string, cred Credential) error {
+	secret, err := json.Marshal(cred)
+	if err != nil {
+		return err
+	}
+
+	if err := k.backend.set(host, secret); err != nil {
+		return fmt.Errorf("failed to store credential for %s: %w", host, err)
+	}
+
+	return nil
+}
+
+// Delete removes the credential for host. Deleting a missing credential is
+// not an error.
+func (k *Keyring) Delete(host string) error {
+	err := k.backend.delete(host)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to delete credential for %s: %w", host, err)
+	}
+
+	return nil
+}
+
+// Lookup implements oci.Keychain, returning empty credentials when none are
+// stored for host.
+func (k *Keyring) Lookup(host string) (username, password string, err error) {
+	cred, err := k.Get(host)
+	if errors.Is(err, ErrNotFound) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return cred.Username, cred.Password, nil
+}
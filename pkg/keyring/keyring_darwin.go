@@ -0,0 +1,73 @@
+package keyring
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychain stores secrets as generic passwords through the security tool.
+// Commands are passed on stdin with -i so secrets never appear in the
+// process list, and secrets are base64 encoded so security never prints
+// them as hex.
+type keychain struct {
+	service string
+}
+
+func nativeBackend(service string) backend {
+	if _, err := exec.LookPath("security"); err != nil {
+		return nil
+	}
+
+	return &keychain{service: service}
+}
+
+func (k *keychain) get(host string) ([]byte, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", k.service, "-a", host, "-w").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// 44 is errSecItemNotFound.
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("security find-generic-password: %w", err)
+	}
+
+	return base64.StdEncoding.DecodeString(string(bytes.TrimSpace(out)))
+}
+
+func (k *keychain) set(host string, secret []byte) error {
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", quote(k.service), quote(host), base64.StdEncoding.EncodeToString(secret))
+	return k.interactive(command)
+}
+
+func (k *keychain) delete(host string) error {
+	_, err := exec.Command("security", "delete-generic-password", "-s", k.service, "-a", host).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+			return ErrNotFound
+		}
+		return fmt.Errorf("security delete-generic-password: %w", err)
+	}
+
+	return nil
+}
+
+func (k *keychain) interactive(command string) error {
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(command)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("security: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
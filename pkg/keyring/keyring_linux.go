@@ -0,0 +1,68 @@
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// secretService talks to the freedesktop Secret Service (GNOME Keyring,
+// KWallet) through libsecret's secret-tool.
+type secretService struct {
+	service string
+}
+
+// nativeBackend requires secret-tool and a session bus; headless machines
+// use the encrypted file instead.
+func nativeBackend(service string) backend {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return nil
+	}
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return nil
+	}
+
+	return &secretService{service: service}
+}
+
+func (s *secretService) get(host string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "lookup", "service", s.service, "host", host)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// secret-tool exits 1 with no output when nothing matches.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() == 0 {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("secret-tool lookup: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
+
+func (s *secretService) set(host string, secret []byte) error {
+	cmd := exec.Command("secret-tool", "store", "--label", s.service+": "+host, "service", s.service, "host", host)
+	cmd.Stdin = bytes.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool store: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+func (s *secretService) delete(host string) error {
+	if _, err := s.get(host); err != nil {
+		return err
+	}
+
+	if out, err := exec.Command("secret-tool", "clear", "service", s.service, "host", host).CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool clear: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
@@ -0,0 +1,7 @@
+//go:build !linux && !darwin && !windows
+
+package keyring
+
+func nativeBackend(service string) backend {
+	return nil
+}
@@ -0,0 +1,115 @@
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/system"
+)
+
+func TestFileKeyring(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		prompt   string
+	}{
+		{name: "Prompted passphrase", prompt: "correct horse battery staple"},
+		{name: "Password from env", password: "correct horse battery staple"},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DEVKIT_KEYRING_PASSWORD", tt.password)
+			prompts := 0
+			withPrompt(t, func(string) (string, error) {
+				prompts++
+				return tt.prompt, nil
+			})
+			path := filepath.Join(t.TempDir(), "keyring.enc")
+
+			k, err := New("devkit-test", WithFile(path))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if _, err := k.Get("registry.example.com"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound, got: %v", err)
+			}
+
+			cred := Credential{Username: "user", Password: "s3cret"}
+			if err := k.Set("registry.example.com", cred); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(data, []byte("s3cret")) {
+				t.Error("Expected the keyring file to be encrypted")
+			}
+
+			username, password, err := k.Lookup("registry.example.com")
+			if err != nil || username != cred.Username || password != cred.Password {
+				t.Errorf("Expected %+v, got: %s %s %v", cred, username, password, err)
+			}
+
+			if err := k.Delete("registry.example.com"); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if err := k.Delete("registry.example.com"); err != nil {
+				t.Errorf("Expected deleting a missing credential to succeed, got: %v", err)
+			}
+
+			username, _, err = k.Lookup("registry.example.com")
+			if err != nil || username != "" {
+				t.Errorf("Expected no credential, got: %q %v", username, err)
+			}
+
+			if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+				t.Errorf("Expected only the keyring file on disk, got: %v", entries)
+			}
+			if tt.prompt != "" && prompts != 1 {
+				t.Errorf("Expected to be prompted once, got: %d", prompts)
+			}
+		})
+	}
+}
+
+func TestFileKeyringWrongPassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.enc")
+
+	t.Setenv("DEVKIT_KEYRING_PASSWORD", "first")
+	k, _ := New("devkit-test", WithFile(path))
+	if err := k.Set("registry.example.com", Credential{Username: "user"}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DEVKIT_KEYRING_PASSWORD", "second")
+	if _, err := k.Get("registry.example.com"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a decryption error, got: %v", err)
+	}
+}
+
+func TestFileKeyringNoPassphrase(t *testing.T) {
+	t.Setenv("DEVKIT_KEYRING_PASSWORD", "")
+	withPrompt(t, func(string) (string, error) {
+		return "", system.ErrNonInteractive
+	})
+
+	k, _ := New("devkit-test", WithFile(filepath.Join(t.TempDir(), "keyring.enc")))
+	if err := k.Set("registry.example.com", Credential{Username: "user"}); !errors.Is(err, ErrNoPassphrase) {
+		t.Errorf("Expected ErrNoPassphrase, got: %v", err)
+	}
+}
+
+func withPrompt(t *testing.T, prompt func(path string) (string, error)) {
+	t.Helper()
+	previous := promptPassphrase
+	promptPassphrase = prompt
+	t.Cleanup(func() { promptPassphrase = previous })
+}
@@ -0,0 +1,108 @@
+package license
+
+import (
+	"strings"
+	"unicode"
+)
+
+// matcher recognizes a license text by phrases it must contain. Matchers
+// are tried in order, so a license whose text mentions another one, like
+// the LGPL quoting the GPL, comes before it.
+type matcher struct {
+	id       string
+	requires []string
+	excludes []string
+}
+
+var matchers = []matcher{
+	{id: "AGPL-3.0", requires: []string{"gnu affero general public license", "version 3"}},
+	{id: "LGPL-2.1", requires: []string{"gnu lesser general public license", "version 2.1"}},
+	{id: "LGPL-3.0", requires: []string{"gnu lesser general public license", "version 3"}},
+	{id: "GPL-2.0", requires: []string{"gnu general public license", "version 2"}, excludes: []string{"version 3"}},
+	{id: "GPL-3.0", requires: []string{"gnu general public license", "version 3"}},
+	{id: "MPL-2.0", requires: []string{"mozilla public license version 2.0"}},
+	{id: "EPL-2.0", requires: []string{"eclipse public license v 2.0"}},
+	{id: "Apache-2.0", requires: []string{"apache license", "version 2.0"}},
+	{id: "BSD-3-Clause", requires: []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{id: "BSD-2-Clause", requires: []string{"redistribution and use in source and binary forms"}},
+	{id: "MIT", requires: []string{"permission is hereby granted free of charge to any person obtaining a copy", "the above copyright notice and this permission notice shall be included"}},
+	{id: "ISC", requires: []string{"distribute this software for any purpose with or without fee is hereby granted"}},
+	{id: "Unlicense", requires: []string{"this is free and unencumbered software released into the public domain"}},
+	{id: "CC0-1.0", requires: []string{"cc0 1.0 universal"}},
+}
+
+// Detect returns the SPDX identifier of a license text, such as the content
+// of a LICENSE file, or "" when it is not recognized.
+func Detect(text string) string {
+	normalized := normalize(text)
+	for _, m := range matchers {
+		if m.matches(normalized) {
+			return m.id
+		}
+	}
+
+	return ""
+}
+
+func (m matcher) matches(text string) bool {
+	for _, phrase := range m.requires {
+		if !strings.Contains(text, phrase) {
+			return false
+		}
+	}
+	for _, phrase := range m.excludes {
+		if strings.Contains(text, phrase) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// normalize lowercases text and reduces punctuation and line breaks to
+// single spaces, keeping dots so version numbers survive.
+func normalize(text string) string {
+	var sb strings.Builder
+	space := true
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' {
+			sb.WriteRune(r)
+			space = false
+			continue
+		}
+		if !space {
+			sb.WriteByte(' ')
+			space = true
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+const spdxTag = "SPDX-License-Identifier:"
+
+// headerIdentifier returns the expression of the first
+// SPDX-License-Identifier tag in a file header.
+func headerIdentifier(header string) string {
+	_, rest, ok := strings.Cut(header, spdxTag)
+	if !ok {
+		return ""
+	}
+	line, _, _ := strings.Cut(rest, "\n")
+	line = strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+
+	// Drop the end of block comments in languages that need one.
+	for _, suffix := range []string{"*/", "-->", "--}}", "#}"} {
+		line = strings.TrimSpace(strings.TrimSuffix(line, suffix))
+	}
+
+	// Anything else, like the tag quoted in a string, is not an expression.
+	if strings.IndexFunc(line, func(r rune) bool { return !isExpressionRune(r) }) >= 0 {
+		return ""
+	}
+	return line
+}
+
+func isExpressionRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(".-+:() ", r))
+}
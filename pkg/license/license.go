@@ -0,0 +1,175 @@
+package license
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/log"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// maxLicenseSize bounds how much of a license file is read.
+const maxLicenseSize = 1 << 20
+
+// headerSize is how much of a source file is searched for an
+// SPDX-License-Identifier tag.
+const headerSize = 4096
+
+type Source string
+
+const (
+	// SourceFile is a LICENSE, LICENCE, COPYING or UNLICENSE file.
+	SourceFile Source = "file"
+	// SourceHeader is an SPDX-License-Identifier tag in a source file.
+	SourceHeader Source = "header"
+)
+
+// Finding is a license found in a file.
+type Finding struct {
+	// Path is relative to the scanned directory and uses forward slashes.
+	Path string
+	// License is an SPDX license expression, or "" for a license file
+	// whose text was not recognized.
+	License string
+	Source  Source
+}
+
+// skipDirs hold third-party code or metadata whose licenses are not those
+// of the scanned project.
+var skipDirs = map[string]bool{".git": true, "node_modules": true, "vendor": true}
+
+// licenseNames are the base names, without extension or suffix such as
+// "-MIT", that mark a license file.
+var licenseNames = []string{"LICENSE", "LICENCE", "COPYING", "UNLICENSE"}
+
+// Scan walks dir for license files and SPDX-License-Identifier headers and
+// returns what it found sorted by path.
+func Scan(dir string) ([]Finding, error) {
+	defer log.Timed("license_scan", "dir", dir)()
+	var findings []Finding
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if file != dir && skipDirs[entry.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if isLicenseFile(entry.Name()) {
+			data, err := readHead(file, maxLicenseSize)
+			if err != nil {
+				return err
+			}
+			id := headerIdentifier(data)
+			if id == "" {
+				id = Detect(data)
+			}
+			findings = append(findings, Finding{Path: rel, License: id, Source: SourceFile})
+			return nil
+		}
+
+		header, err := readHead(file, headerSize)
+		if err != nil {
+			return err
+		}
+		if id := headerIdentifier(header); id != "" {
+			findings = append(findings, Finding{Path: rel, License: id, Source: SourceHeader})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Path < findings[j].Path })
+	return findings, nil
+}
+
+func isLicenseFile(name string) bool {
+	name = strings.ToUpper(name)
+	for _, ext := range []string{".MD", ".TXT", ".RST"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+
+	for _, prefix := range licenseNames {
+		if name == prefix || strings.HasPrefix(name, prefix+"-") || strings.HasPrefix(name, prefix+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func readHead(file string, size int64) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, size))
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// Expression combines the licenses of findings into one SPDX expression,
+// e.g. "Apache-2.0 AND MIT". Unrecognized license files are left out, and
+// "" is returned when no license was recognized.
+func Expression(findings []Finding) string {
+	seen := map[string]bool{}
+	var ids []string
+	for _, f := range findings {
+		if f.License == "" || seen[f.License] {
+			continue
+		}
+		seen[f.License] = true
+		ids = append(ids, f.License)
+	}
+	sort.Strings(ids)
+
+	if len(ids) > 1 {
+		for i, id := range ids {
+			if strings.Contains(id, " ") {
+				ids[i] = "(" + id + ")"
+			}
+		}
+	}
+
+	return strings.Join(ids, " AND ")
+}
+
+// Annotations returns the org.opencontainers.image.licenses annotation for
+// the project in dir, or an empty map when no license was recognized.
+func Annotations(dir string) (map[string]string, error) {
+	findings, err := Scan(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations := map[string]string{}
+	if expr := Expression(findings); expr != "" {
+		annotations[spec.AnnotationLicenses] = expr
+	}
+
+	return annotations, nil
+}
@@ -0,0 +1,129 @@
+package license
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const mitText = `MIT License
+
+Copyright (c) 2024 Example
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction.
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+`
+
+const bsd3Text = `Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+3. Neither the name of the copyright holder nor the names of its
+   contributors may be used to endorse or promote products.
+`
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"mit", mitText, "MIT"},
+		{"apache", "                                 Apache License\n                           Version 2.0, January 2004\n", "Apache-2.0"},
+		{"bsd-3", bsd3Text, "BSD-3-Clause"},
+		{"bsd-2", "Redistribution and use in source and binary forms, with or without\nmodification, are permitted", "BSD-2-Clause"},
+		{"gpl-2", "GNU GENERAL PUBLIC LICENSE\nVersion 2, June 1991", "GPL-2.0"},
+		{"gpl-3", "GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007", "GPL-3.0"},
+		{"lgpl-3", "GNU LESSER GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n\nversion 3 of the GNU General Public License", "LGPL-3.0"},
+		{"agpl", "GNU AFFERO GENERAL PUBLIC LICENSE\nVersion 3, 19 November 2007", "AGPL-3.0"},
+		{"isc", "Permission to use, copy, modify, and/or distribute this software for any\npurpose with or without fee is hereby granted", "ISC"},
+		{"unknown", "All rights reserved.", ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.text); got != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHeaderIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{"line comment", "// SPDX-License-Identifier: Apache-2.0\npackage main\n", "Apache-2.0"},
+		{"block comment", "/* SPDX-License-Identifier: MIT OR Apache-2.0 */\n", "MIT OR Apache-2.0"},
+		{"html", "<!-- SPDX-License-Identifier: CC-BY-4.0 -->", "CC-BY-4.0"},
+		{"quoted", "const tag = \"SPDX-License-Identifier:\"\n", ""},
+		{"none", "package main\n", ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			if got := headerIdentifier(tt.header); got != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"LICENSE":                     mitText,
+		"third_party/lib/COPYING.txt": bsd3Text,
+		"NOTICE.md":                   "Copyright Example",
+		"LICENSE-CUSTOM":              "All rights reserved.",
+		"cmd/main.go":                 "// SPDX-License-Identifier: MIT OR Apache-2.0\n\npackage main\n",
+		"vendor/x/LICENSE":            "GNU GENERAL PUBLIC LICENSE\nVersion 3",
+		"node_modules/y/index.js":     "// SPDX-License-Identifier: GPL-2.0\n",
+	}
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	findings, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []Finding{
+		{Path: "LICENSE", License: "MIT", Source: SourceFile},
+		{Path: "LICENSE-CUSTOM", License: "", Source: SourceFile},
+		{Path: "cmd/main.go", License: "MIT OR Apache-2.0", Source: SourceHeader},
+		{Path: "third_party/lib/COPYING.txt", License: "BSD-3-Clause", Source: SourceFile},
+	}
+	if !reflect.DeepEqual(findings, expected) {
+		t.Errorf("Expected %+v, got: %+v", expected, findings)
+	}
+
+	annotations, err := Annotations(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got := annotations[spec.AnnotationLicenses]; got != "BSD-3-Clause AND MIT AND (MIT OR Apache-2.0)" {
+		t.Errorf("Expected combined expression, got: %q", got)
+	}
+
+	if annotations, _ := Annotations(t.TempDir()); len(annotations) != 0 {
+		t.Errorf("Expected no annotations without licenses, got: %v", annotations)
+	}
+}
@@ -0,0 +1,201 @@
+package lockfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrLocked = errors.New("lock is held by another process")
+
+const (
+	minPoll = 10 * time.Millisecond
+	maxPoll = 500 * time.Millisecond
+)
+
+type Options struct {
+	// Timeout bounds how long Acquire waits for the lock. Zero waits until
+	// the context is done.
+	Timeout time.Duration
+	// StaleAfter treats a lock older than this as abandoned even if its
+	// owner cannot be checked, e.g. when it was taken on another host. Zero
+	// only breaks locks whose owning process has exited.
+	StaleAfter time.Duration
+}
+
+// Lock is a lock file holding the pid and hostname of its owner. It is
+// created exclusively, so it works across processes and on any filesystem
+// that honours O_EXCL.
+type Lock struct {
+	path  string
+	owner string
+}
+
+// Acquire creates the lock file at path, waiting while another live process
+// holds it. Locks left behind by processes that have exited are removed.
+func Acquire(ctx context.Context, path string, opts Options) (*Lock, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	poll := minPoll
+	for {
+		lock, err := TryAcquire(path, opts)
+		if !errors.Is(err, ErrLocked) {
+			return lock, err
+		}
+
+		timer := time.NewTimer(poll)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("failed to acquire %s: %w", path, errors.Join(ErrLocked, ctx.Err()))
+		case <-timer.C:
+		}
+
+		poll = min(poll*2, maxPoll)
+	}
+}
+
+// TryAcquire takes the lock without waiting, returning ErrLocked if a live
+// process holds it.
+func TryAcquire(path string, opts Options) (*Lock, error) {
+	owner := currentOwner()
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = file.WriteString(owner)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("failed to write lock file: %w", err)
+			}
+			return &Lock{path: path, owner: owner}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		if !breakStale(path, opts.StaleAfter) {
+			break
+		}
+	}
+
+	return nil, ErrLocked
+}
+
+// Release removes the lock file if it is still owned by this lock.
+func (l *Lock) Release() error {
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	if string(data) != l.owner {
+		return nil
+	}
+
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove lock file: %w", err)
+	}
+	return nil
+}
+
+func (l *Lock) Path() string {
+	return l.path
+}
+
+// Owner returns the pid and hostname recorded in the lock file at path.
+func Owner(path string) (pid int, hostname string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, "", err
+	}
+
+	pid, hostname, ok := parseOwner(string(data))
+	if !ok {
+		return 0, "", fmt.Errorf("invalid lock file %s", path)
+	}
+	return pid, hostname, nil
+}
+
+// breakStale removes the lock file at path if its owner has exited or it
+// is older than staleAfter, and reports whether it did.
+func breakStale(path string, staleAfter time.Duration) bool {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Is(err, os.ErrNotExist)
+	}
+
+	if !isStale(string(data), info.ModTime(), staleAfter) {
+		return false
+	}
+
+	// Only remove the file if it has not been replaced since it was read,
+	// so a lock taken by another process in the meantime is kept.
+	current, err := os.ReadFile(path)
+	if err != nil || string(current) != string(data) {
+		return errors.Is(err, os.ErrNotExist)
+	}
+
+	return os.Remove(path) == nil
+}
+
+func isStale(content string, modified time.Time, staleAfter time.Duration) bool {
+	if staleAfter > 0 && time.Since(modified) > staleAfter {
+		return true
+	}
+
+	pid, hostname, ok := parseOwner(content)
+	if !ok {
+		// The owner may still be writing the file.
+		return time.Since(modified) > time.Second
+	}
+
+	if hostname != currentHostname() {
+		return false
+	}
+	return !processAlive(pid)
+}
+
+func currentOwner() string {
+	return fmt.Sprintf("%d\n%s\n", os.Getpid(), currentHostname())
+}
+
+func parseOwner(content string) (int, string, bool) {
+	lines := strings.SplitN(strings.TrimSpace(content), "\n", 2)
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil || pid <= 0 {
+		return 0, "", false
+	}
+
+	var hostname string
+	if len(lines) == 2 {
+		hostname = strings.TrimSpace(lines[1])
+	}
+	return pid, hostname, true
+}
+
+func currentHostname() string {
+	hostname, _ := os.Hostname()
+	return hostname
+}
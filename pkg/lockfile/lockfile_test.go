@@ -0,0 +1,151 @@
+package lockfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTryAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+
+	lock, err := TryAcquire(path, Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	pid, hostname, err := Owner(path)
+	if err != nil {
+		t.Fatalf("Expected no error reading owner, got: %v", err)
+	}
+	if pid != os.Getpid() || hostname != currentHostname() {
+		t.Errorf("Expected owner %d@%s, got: %d@%s", os.Getpid(), currentHostname(), pid, hostname)
+	}
+
+	if _, err := TryAcquire(path, Options{}); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked, got: %v", err)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Expected no error releasing, got: %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected lock file to be removed, got: %v", err)
+	}
+}
+
+func TestStaleLocks(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		age        time.Duration
+		staleAfter time.Duration
+		expectErr  error
+	}{
+		{name: "exited process", content: fmt.Sprintf("99999999\n%s\n", currentHostname())},
+		{name: "live process", content: currentOwner(), expectErr: ErrLocked},
+		{name: "other host", content: "1\nother-host\n", expectErr: ErrLocked},
+		{name: "other host expired", content: "1\nother-host\n", age: time.Hour, staleAfter: time.Minute},
+		{name: "garbage", content: "not a pid", age: time.Minute},
+		{name: "being written", content: "", expectErr: ErrLocked},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.lock")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			modified := time.Now().Add(-tt.age)
+			if err := os.Chtimes(path, modified, modified); err != nil {
+				t.Fatal(err)
+			}
+
+			lock, err := TryAcquire(path, Options{StaleAfter: tt.staleAfter})
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("Expected %v, got: %v", tt.expectErr, err)
+			}
+			if lock != nil {
+				lock.Release()
+			}
+		})
+	}
+}
+
+func TestAcquireTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+	lock, err := TryAcquire(path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Release()
+
+	start := time.Now()
+	_, err = Acquire(context.Background(), path, Options{Timeout: 100 * time.Millisecond})
+	if !errors.Is(err, ErrLocked) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected ErrLocked after deadline, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected Acquire to wait for the timeout, returned after %s", elapsed)
+	}
+}
+
+func TestAcquireWaits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+
+	var mu sync.Mutex
+	holders, peak := 0, 0
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock, err := Acquire(context.Background(), path, Options{Timeout: 5 * time.Second})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			mu.Lock()
+			holders++
+			peak = max(peak, holders)
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			holders--
+			mu.Unlock()
+			lock.Release()
+		}()
+	}
+	wg.Wait()
+
+	if peak != 1 {
+		t.Errorf("Expected one holder at a time, got: %d", peak)
+	}
+}
+
+func TestReleaseKeepsReplacedLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+	lock, err := TryAcquire(path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte("1\nother-host\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected lock owned by another process to be kept, got: %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package lockfile
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+func processAlive(pid int) bool {
+	err := unix.Kill(pid, 0)
+	return err == nil || errors.Is(err, unix.EPERM)
+}
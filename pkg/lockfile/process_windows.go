@@ -0,0 +1,24 @@
+//go:build windows
+
+package lockfile
+
+import "golang.org/x/sys/windows"
+
+// stillActive is the exit code reported for a running process.
+const stillActive = 259
+
+func processAlive(pid int) bool {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// Access is denied for processes owned by other users, which are
+		// still running.
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+	defer windows.CloseHandle(handle)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil {
+		return true
+	}
+	return code == stillActive
+}
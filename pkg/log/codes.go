@@ -0,0 +1,84 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+type Code string
+
+const (
+	CodeUnknown         Code = "unknown"
+	CodeInvalidArgument Code = "invalid_argument"
+	CodeNotFound        Code = "not_found"
+	CodeUnauthorized    Code = "unauthorized"
+	CodeRemote          Code = "remote_error"
+	CodeIO              Code = "io_error"
+)
+
+// DevkitError annotates an error with a stable code and the operation that
+// produced it, so callers can branch on Code instead of the message text.
+type DevkitError struct {
+	Code Code
+	Op   string
+	Err  error
+}
+
+func (e *DevkitError) Error() string {
+	if e.Op == "" {
+		return e.Err.Error()
+	}
+
+	return fmt.Sprintf("%s: %s", e.Op, e.Err.Error())
+}
+
+func (e *DevkitError) Unwrap() error {
+	return e.Err
+}
+
+func NewError(code Code, op string, err error) error {
+	return &DevkitError{Code: code, Op: op, Err: err}
+}
+
+func Errorf(code Code, op string, format string, args ...any) error {
+	return &DevkitError{Code: code, Op: op, Err: fmt.Errorf(format, args...)}
+}
+
+// ErrorCode returns the code of the first DevkitError in err's chain, or
+// CodeUnknown if there is none.
+func ErrorCode(err error) Code {
+	var devkitErr *DevkitError
+	if errors.As(err, &devkitErr) {
+		return devkitErr.Code
+	}
+
+	return CodeUnknown
+}
+
+func IsCode(err error, code Code) bool {
+	return err != nil && ErrorCode(err) == code
+}
+
+// Error logs msg at error level with err, and its code and operation when err
+// is a DevkitError.
+func Error(msg string, err error) {
+	logCaller(slog.LevelError, msg, errorAttrs(err)...)
+}
+
+func errorAttrs(err error) []slog.Attr {
+	if err == nil {
+		return nil
+	}
+
+	attrs := []slog.Attr{slog.String(KeyError, err.Error())}
+	var devkitErr *DevkitError
+	if errors.As(err, &devkitErr) {
+		attrs = append(attrs, slog.String(KeyCode, string(devkitErr.Code)))
+		if devkitErr.Op != "" {
+			attrs = append(attrs, slog.String(KeyOp, devkitErr.Op))
+		}
+	}
+
+	return attrs
+}
@@ -1,12 +1,16 @@
 package log
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"log/slog"
 	"os"
+	"runtime"
+	"time"
 )
 
+var logFile *os.File
+
 func init() {
 	path, ok := os.LookupEnv("LOG_PATH")
 	if !ok {
@@ -19,32 +23,37 @@ func init() {
 		path = fmt.Sprintf("%s/devkit.log", homePath)
 	}
 
-	logFile, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	var err error
+	logFile, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "failed to open log file")
 		os.Exit(1)
 	}
 
-	if isDebug() {
-		mw := io.MultiWriter(os.Stdout, logFile)
-		logger := slog.New(slog.NewJSONHandler(mw, nil))
-		slog.SetDefault(logger)
-		return
-	}
-
-	logger := slog.New(slog.NewJSONHandler(logFile, nil))
-	slog.SetDefault(logger)
+	initLevels()
+	Configure()
 }
 
 func NoError(err error, msg string) {
 	if err != nil {
-		slog.Error(err.Error())
+		logCaller(slog.LevelError, msg, errorAttrs(err)...)
 		fmt.Fprintln(os.Stderr, msg)
+		_ = Flush()
 		os.Exit(1)
 	}
 }
 
-func isDebug() bool {
-	_, ok := os.LookupEnv("DEBUG")
-	return ok
+// logCaller logs a record attributed to the caller of the exported function
+// invoking it, rather than to this package.
+func logCaller(level slog.Level, msg string, attrs ...slog.Attr) {
+	logger := slog.Default()
+	if !logger.Enabled(context.Background(), level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.AddAttrs(attrs...)
+	_ = logger.Handler().Handle(context.Background(), r)
 }
@@ -0,0 +1,127 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// levelOff is above every level in use, disabling a handler entirely.
+const levelOff = slog.Level(1 << 20)
+
+var (
+	levelMu     sync.Mutex
+	fileLevel   = new(slog.LevelVar)
+	stdoutLevel = new(slog.LevelVar)
+	baseLevel   = slog.LevelInfo
+	verbosity   int
+)
+
+// initLevels reads LOG_LEVEL for the log file level. The legacy DEBUG
+// variable is still honoured and behaves like SetVerbosity(2).
+func initLevels() {
+	if value, ok := os.LookupEnv("LOG_LEVEL"); ok {
+		level, err := ParseLevel(value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ignoring LOG_LEVEL: %s\n", err.Error())
+		} else {
+			SetLevel(level)
+		}
+	}
+
+	if _, ok := os.LookupEnv("DEBUG"); ok {
+		SetVerbosity(2)
+		return
+	}
+
+	SetVerbosity(0)
+}
+
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", s)
+	}
+
+	return level, nil
+}
+
+// SetLevel sets the minimum level written to the log file.
+func SetLevel(level slog.Level) {
+	levelMu.Lock()
+	baseLevel = level
+	levelMu.Unlock()
+
+	SetVerbosity(verbosity)
+}
+
+// SetVerbosity controls how much is mirrored to stdout, following the usual
+// -v/-vv conventions: 0 writes only to the log file, 1 also prints info and
+// above to stdout, and 2 or more prints debug records to both.
+func SetVerbosity(n int) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	verbosity = n
+	switch {
+	case n <= 0:
+		fileLevel.Set(baseLevel)
+		stdoutLevel.Set(levelOff)
+	case n == 1:
+		fileLevel.Set(baseLevel)
+		stdoutLevel.Set(slog.LevelInfo)
+	default:
+		fileLevel.Set(slog.LevelDebug)
+		stdoutLevel.Set(slog.LevelDebug)
+	}
+}
+
+// teeHandler sends each record to every handler that accepts its level.
+type teeHandler struct {
+	handlers []slog.Handler
+}
+
+func (h *teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var firstErr error
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, r.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+func (h *teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+
+	return &teeHandler{handlers: handlers}
+}
+
+func (h *teeHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+
+	return &teeHandler{handlers: handlers}
+}
@@ -0,0 +1,98 @@
+package log
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+type Option func(*options)
+
+type options struct {
+	source        bool
+	flushInterval time.Duration
+}
+
+var (
+	mu     sync.Mutex
+	writer *asyncWriter
+)
+
+// WithSource adds the file:line and function of the call site to warn and
+// error records.
+func WithSource() Option {
+	return func(o *options) {
+		o.source = true
+	}
+}
+
+// WithAsyncWrites buffers records destined for the log file and writes them
+// in the background every interval. Call Close before the program exits so
+// buffered records are not lost.
+func WithAsyncWrites(interval time.Duration) Option {
+	return func(o *options) {
+		o.flushInterval = interval
+	}
+}
+
+// Configure replaces the default logger with one built from opts.
+func Configure(opts ...Option) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if writer != nil {
+		_ = writer.Close()
+		writer = nil
+	}
+
+	var file io.Writer = logFile
+	if o.flushInterval > 0 {
+		writer = newAsyncWriter(logFile, o.flushInterval)
+		file = writer
+	}
+
+	var handler slog.Handler = &teeHandler{handlers: []slog.Handler{
+		newJSONHandler(file, fileLevel),
+		newJSONHandler(os.Stdout, stdoutLevel),
+	}}
+	if o.source {
+		handler = &sourceHandler{Handler: handler}
+	}
+
+	slog.SetDefault(slog.New(handler))
+}
+
+// Flush writes any buffered records to the log file.
+func Flush() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if writer == nil {
+		return nil
+	}
+
+	return writer.Flush()
+}
+
+// Close flushes buffered records and closes the log file. It should be
+// deferred in main; records logged afterwards are discarded.
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if writer != nil {
+		if err := writer.Close(); err != nil {
+			return err
+		}
+		writer = nil
+	}
+
+	return logFile.Close()
+}
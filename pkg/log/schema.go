@@ -0,0 +1,95 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// SchemaVersion is incremented whenever a key in Record is renamed, removed
+// or changes type. Adding new optional keys does not change the version.
+const SchemaVersion = 1
+
+const (
+	KeySchemaVersion = "schema_version"
+	KeyTime          = "ts"
+	KeyLevel         = "level"
+	KeyMessage       = "msg"
+	KeyComponent     = "component"
+	KeyError         = "err"
+	KeyCode          = "code"
+	KeyOp            = "op"
+)
+
+// Record is the documented shape of a single JSON log line. Any attributes
+// beyond the guaranteed keys are collected in Attrs.
+type Record struct {
+	SchemaVersion int            `json:"schema_version"`
+	Time          time.Time      `json:"ts"`
+	Level         string         `json:"level"`
+	Message       string         `json:"msg"`
+	Component     string         `json:"component,omitempty"`
+	Err           string         `json:"err,omitempty"`
+	Code          string         `json:"code,omitempty"`
+	Op            string         `json:"op,omitempty"`
+	Attrs         map[string]any `json:"-"`
+}
+
+// ParseRecord decodes one JSON log line into a Record. Lines without a
+// schema version, or written by a newer schema, are rejected.
+func ParseRecord(line []byte) (*Record, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode log record: %w", err)
+	}
+
+	record := &Record{}
+	if err := json.Unmarshal(line, record); err != nil {
+		return nil, fmt.Errorf("failed to decode log record: %w", err)
+	}
+
+	if record.SchemaVersion == 0 {
+		return nil, fmt.Errorf("log record has no %s", KeySchemaVersion)
+	}
+
+	if record.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("unsupported log schema version: %d", record.SchemaVersion)
+	}
+
+	for _, key := range []string{KeySchemaVersion, KeyTime, KeyLevel, KeyMessage, KeyComponent, KeyError, KeyCode, KeyOp} {
+		delete(fields, key)
+	}
+
+	record.Attrs = make(map[string]any, len(fields))
+	for key, raw := range fields {
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("failed to decode attribute %s: %w", key, err)
+		}
+		record.Attrs[key] = value
+	}
+
+	return record, nil
+}
+
+// Component returns a logger whose records are tagged with the given
+// component name.
+func Component(name string) *slog.Logger {
+	return slog.Default().With(KeyComponent, name)
+}
+
+func newJSONHandler(w io.Writer, level slog.Leveler) slog.Handler {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				a.Key = KeyTime
+			}
+			return a
+		},
+	})
+
+	return handler.WithAttrs([]slog.Attr{slog.Int(KeySchemaVersion, SchemaVersion)})
+}
@@ -0,0 +1,81 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestParseRecord(t *testing.T) {
+	tests := []struct {
+		name        string
+		line        func() []byte
+		expected    Record
+		expectError bool
+	}{
+		{
+			name: "Record written by the default handler",
+			line: func() []byte {
+				var buf bytes.Buffer
+				logger := slog.New(newJSONHandler(&buf, slog.LevelInfo))
+				logger.With(KeyComponent, "oci").Error("failed to push blob", KeyError, "unauthorized", "digest", "sha256:1234")
+				return buf.Bytes()
+			},
+			expected: Record{
+				SchemaVersion: SchemaVersion,
+				Level:         "ERROR",
+				Message:       "failed to push blob",
+				Component:     "oci",
+				Err:           "unauthorized",
+				Attrs:         map[string]any{"digest": "sha256:1234"},
+			},
+		},
+		{
+			name: "Missing schema version",
+			line: func() []byte {
+				return []byte(`{"ts":"2024-01-01T00:00:00Z","level":"INFO","msg":"hello"}`)
+			},
+			expectError: true,
+		},
+		{
+			name: "Newer schema version",
+			line: func() []byte {
+				return []byte(`{"schema_version":99,"ts":"2024-01-01T00:00:00Z","level":"INFO","msg":"hello"}`)
+			},
+			expectError: true,
+		},
+		{
+			name: "Invalid JSON",
+			line: func() []byte {
+				return []byte("not json")
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := ParseRecord(tt.line())
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+			if tt.expectError {
+				return
+			}
+
+			if record.Time.IsZero() {
+				t.Errorf("Expected timestamp to be set")
+			}
+			if record.SchemaVersion != tt.expected.SchemaVersion || record.Level != tt.expected.Level ||
+				record.Message != tt.expected.Message || record.Component != tt.expected.Component || record.Err != tt.expected.Err {
+				t.Errorf("ParseRecord() = %+v, expected %+v", record, tt.expected)
+			}
+			for key, value := range tt.expected.Attrs {
+				if record.Attrs[key] != value {
+					t.Errorf("Expected attribute %s = %v, got: %v", key, value, record.Attrs[key])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+type sourceHandler struct {
+	slog.Handler
+}
+
+func (h *sourceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelWarn && r.PC != 0 {
+		frames := runtime.CallersFrames([]uintptr{r.PC})
+		frame, _ := frames.Next()
+		r.AddAttrs(
+			slog.String("source", fmt.Sprintf("%s:%d", relativeSource(frame.Function, frame.File), frame.Line)),
+			slog.String("func", path.Base(frame.Function)),
+		)
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *sourceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sourceHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *sourceHandler) WithGroup(name string) slog.Handler {
+	return &sourceHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+var buildModules = sync.OnceValue(func() []string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	modules := []string{info.Main.Path}
+	for _, dep := range info.Deps {
+		modules = append(modules, dep.Path)
+	}
+
+	return modules
+})
+
+// relativeSource returns file relative to the root of the module that owns
+// function, e.g. "pkg/oci/opts.go". Files outside any known module keep their
+// parent directory for context.
+func relativeSource(function, file string) string {
+	pkg := packagePath(function)
+	name := path.Base(file)
+
+	var module string
+	for _, m := range buildModules() {
+		if m == "" || len(m) <= len(module) {
+			continue
+		}
+		if pkg == m || strings.HasPrefix(pkg, m+"/") {
+			module = m
+		}
+	}
+
+	if module == "" {
+		return path.Join(path.Base(path.Dir(file)), name)
+	}
+
+	if pkg == module {
+		return name
+	}
+
+	return path.Join(strings.TrimPrefix(pkg, module+"/"), name)
+}
+
+// packagePath extracts the import path from a fully qualified function name
+// such as "github.com/eunanio/sdk/pkg/oci.(*OciClient).PushBlob".
+func packagePath(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+
+	return function
+}
@@ -0,0 +1,98 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRelativeSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		function string
+		file     string
+		expected string
+	}{
+		{
+			name:     "Method in module package",
+			function: "github.com/eunanio/sdk/pkg/oci.(*OciClient).PushBlob",
+			file:     "/home/user/src/devkit/pkg/oci/opts.go",
+			expected: "pkg/oci/opts.go",
+		},
+		{
+			name:     "Function in module root",
+			function: "github.com/eunanio/sdk.main",
+			file:     "/home/user/src/devkit/main.go",
+			expected: "main.go",
+		},
+		{
+			name:     "Trimmed build path",
+			function: "github.com/eunanio/sdk/pkg/fs.CompressDir",
+			file:     "github.com/eunanio/sdk/pkg/fs/compression.go",
+			expected: "pkg/fs/compression.go",
+		},
+		{
+			name:     "Package outside known modules",
+			function: "os.ReadFile",
+			file:     "/usr/local/go/src/os/file.go",
+			expected: "os/file.go",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			if got := relativeSource(tt.function, tt.file); got != tt.expected {
+				t.Errorf("relativeSource() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSourceHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		log          func(*slog.Logger)
+		expectSource bool
+	}{
+		{
+			name:         "Error record",
+			log:          func(l *slog.Logger) { l.Error("failed") },
+			expectSource: true,
+		},
+		{
+			name:         "Warn record",
+			log:          func(l *slog.Logger) { l.Warn("careful") },
+			expectSource: true,
+		},
+		{
+			name:         "Info record",
+			log:          func(l *slog.Logger) { l.Info("hello") },
+			expectSource: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(&sourceHandler{Handler: slog.NewJSONHandler(&buf, nil)})
+			tt.log(logger)
+
+			record := map[string]any{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("Failed to decode record: %v", err)
+			}
+
+			source, ok := record["source"].(string)
+			if ok != tt.expectSource {
+				t.Fatalf("Expected source: %v, got: %v", tt.expectSource, record["source"])
+			}
+			if tt.expectSource && !strings.HasPrefix(source, "pkg/log/source_test.go:") {
+				t.Errorf("Unexpected source: %s", source)
+			}
+		})
+	}
+}
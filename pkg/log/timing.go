@@ -0,0 +1,20 @@
+package log
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Timed logs the start of the operation name and returns a function that logs
+// its completion along with the elapsed duration. Typical use:
+//
+//	defer log.Timed("push_blob", "digest", digest)()
+func Timed(name string, args ...any) func() {
+	start := time.Now()
+	logger := slog.Default().With(append([]any{KeyOp, name}, args...)...)
+	logger.Debug("operation started")
+
+	return func() {
+		logger.Info("operation finished", "duration_ms", float64(time.Since(start).Microseconds())/1000)
+	}
+}
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"bufio"
+	"io"
+	"sync"
+	"time"
+)
+
+const defaultBufferSize = 64 * 1024
+
+// asyncWriter buffers log records in memory and writes them to the
+// underlying writer when the buffer fills up or the flush interval elapses.
+type asyncWriter struct {
+	mu   sync.Mutex
+	buf  *bufio.Writer
+	done chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+func newAsyncWriter(w io.Writer, interval time.Duration) *asyncWriter {
+	aw := &asyncWriter{
+		buf:  bufio.NewWriterSize(w, defaultBufferSize),
+		done: make(chan struct{}),
+	}
+
+	aw.wg.Add(1)
+	go aw.flushEvery(interval)
+	return aw
+}
+
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *asyncWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Flush()
+}
+
+func (w *asyncWriter) Close() error {
+	w.once.Do(func() {
+		close(w.done)
+		w.wg.Wait()
+	})
+	return w.Flush()
+}
+
+func (w *asyncWriter) flushEvery(interval time.Duration) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = w.Flush()
+		case <-w.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/exec"
+	"github.com/eunanio/sdk/pkg/oci"
+)
+
+// Client metrics, recorded once Instrument has been called.
+var (
+	OCIRequests = Default.NewCounterVec("oci_requests_total",
+		"Registry requests made by the OCI client.", "op", "method", "code")
+	OCIRequestDuration = Default.NewHistogramVec("oci_request_duration_seconds",
+		"Duration of registry requests made by the OCI client.", nil, "op", "method")
+	BlobBytes = Default.NewCounterVec("blob_bytes_transferred",
+		"Bytes of blob content pushed or pulled.", "direction")
+	ExecDuration = Default.NewHistogramVec("exec_command_duration",
+		"Duration in seconds of commands run through the exec package.", nil, "command", "exit_code")
+)
+
+var instrumentOnce sync.Once
+
+// Instrument records the client metrics through the oci and exec hooks. It
+// is safe to call more than once.
+func Instrument() {
+	instrumentOnce.Do(func() {
+		oci.OnRequest(func(op, method string, status int, elapsed time.Duration) {
+			code := "error"
+			if status > 0 {
+				code = strconv.Itoa(status)
+			}
+			OCIRequests.Inc(op, method, code)
+			OCIRequestDuration.Observe(elapsed.Seconds(), op, method)
+		})
+		oci.OnTransfer(func(op string, bytes int64) {
+			direction := "pull"
+			if strings.HasPrefix(op, "push") {
+				direction = "push"
+			}
+			BlobBytes.Add(float64(bytes), direction)
+		})
+		exec.OnCommand(func(command string, exitCode int, elapsed time.Duration) {
+			ExecDuration.Observe(elapsed.Seconds(), command, strconv.Itoa(exitCode))
+		})
+	})
+}
+
+// Serve exposes Default on addr at /metrics until ctx is done.
+func Serve(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return serve(ctx, listener)
+}
+
+func serve(ctx context.Context, listener net.Listener) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Default.Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
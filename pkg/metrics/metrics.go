@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// CounterVec is a monotonically increasing value per label combination.
+type CounterVec struct {
+	family family[counter]
+}
+
+type counter struct {
+	bits atomic.Uint64
+}
+
+func (c *counter) add(v float64) {
+	for {
+		old := c.bits.Load()
+		if c.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{family: family[counter]{
+		name: name, help: help, kind: "counter", labels: labels,
+		series: map[string]*counter{}, values: map[string][]string{},
+		newSeries: func() *counter { return &counter{} },
+	}}
+	r.register(name, c)
+	return c
+}
+
+// Add increases the counter for the label values, given in the order the
+// labels were declared. Negative values are ignored.
+func (c *CounterVec) Add(v float64, values ...string) {
+	if v < 0 {
+		return
+	}
+	c.family.with(values).add(v)
+}
+
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Value returns the current value for the label values.
+func (c *CounterVec) Value(values ...string) float64 {
+	return math.Float64frombits(c.family.with(values).bits.Load())
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.family.each(w, func(labels string, s *counter) {
+		fmt.Fprintf(w, "%s%s %s\n", c.family.name, braces(labels), formatFloat(math.Float64frombits(s.bits.Load())))
+	})
+}
+
+// HistogramVec counts observations into cumulative buckets per label
+// combination.
+type HistogramVec struct {
+	family  family[histogram]
+	buckets []float64
+}
+
+type histogram struct {
+	mu     sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec uses DefaultBuckets when buckets is nil.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	h := &HistogramVec{buckets: buckets}
+	h.family = family[histogram]{
+		name: name, help: help, kind: "histogram", labels: labels,
+		series: map[string]*histogram{}, values: map[string][]string{},
+		newSeries: func() *histogram { return &histogram{counts: make([]uint64, len(buckets))} },
+	}
+	r.register(name, h)
+	return h
+}
+
+func (h *HistogramVec) Observe(v float64, values ...string) {
+	s := h.family.with(values)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, bound := range h.buckets {
+		if v <= bound {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.family.each(w, func(labels string, s *histogram) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.family.name, withLabel(labels, `le="`+formatFloat(bound)+`"`), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.family.name, withLabel(labels, `le="+Inf"`), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.family.name, braces(labels), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.family.name, braces(labels), s.count)
+	})
+}
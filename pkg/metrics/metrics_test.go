@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/exec"
+	"github.com/eunanio/sdk/pkg/oci"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestWriteTo(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("requests_total", "Requests.\nSecond line.", "code")
+	duration := r.NewHistogramVec("duration_seconds", "Durations.", []float64{1, 0.1})
+
+	requests.Inc("500")
+	requests.Add(2, `2"00`)
+	requests.Add(-1, "500")
+	duration.Observe(0.05)
+	duration.Observe(0.5)
+	duration.Observe(3)
+
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := `# HELP requests_total Requests.\nSecond line.
+# TYPE requests_total counter
+requests_total{code="2\"00"} 2
+requests_total{code="500"} 1
+# HELP duration_seconds Durations.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{le="0.1"} 1
+duration_seconds_bucket{le="1"} 2
+duration_seconds_bucket{le="+Inf"} 3
+duration_seconds_sum 3.55
+duration_seconds_count 3
+`
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a name twice to panic")
+		}
+	}()
+
+	r := NewRegistry()
+	r.NewCounterVec("requests_total", "Requests.")
+	r.NewCounterVec("requests_total", "Requests.")
+}
+
+func TestInstrument(t *testing.T) {
+	Instrument()
+	Instrument()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/app/blobs/uploads/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/upload/location")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/upload/location", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	pushed := BlobBytes.Value("push")
+	created := OCIRequests.Value("push_blob", "PUT", "201")
+	err := oci.NewOciClient().PushBlob(oci.PushBlobOptions{
+		Digest:   spec.Descriptor{Digest: "sha256:1234567890abcdef"},
+		File:     []byte("content"),
+		Name:     "app",
+		Insecure: true,
+		Tag:      oci.Tag{Host: server.Listener.Addr().String(), Name: "app", Version: "v1"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if got := BlobBytes.Value("push") - pushed; got != 7 {
+		t.Errorf("Expected 7 pushed bytes, got: %v", got)
+	}
+	if got := OCIRequests.Value("push_blob", "PUT", "201") - created; got != 1 {
+		t.Errorf("Expected one upload request, got: %v", got)
+	}
+
+	cmd := &exec.Cmd{}
+	cmd.ExecuteWithStream(exec.CmdArgs{Run: "go", Args: []string{"version"}, Stdout: io.Discard})
+
+	var buf bytes.Buffer
+	Default.WriteTo(&buf)
+	if !strings.Contains(buf.String(), `exec_command_duration_count{command="go",exit_code="0"} `) {
+		t.Errorf("Expected the command duration to be recorded, got:\n%s", buf.String())
+	}
+}
+
+func TestServe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, listener) }()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/metrics")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "# TYPE oci_requests_total counter") {
+		t.Errorf("Expected client metrics, got:\n%s", body)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Expected a clean shutdown, got: %v", err)
+	}
+}
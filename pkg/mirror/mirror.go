@@ -0,0 +1,312 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/oci"
+	"github.com/eunanio/sdk/pkg/pool"
+	"github.com/eunanio/sdk/pkg/schedule"
+	"github.com/eunanio/sdk/pkg/validate"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// DefaultConcurrency is the number of blobs copied at once.
+const DefaultConcurrency = 4
+
+type Source struct {
+	// Repository locates the source repository; its Version is ignored.
+	Repository oci.Tag
+	// Tags selects tags by name or path.Match pattern, e.g. "v1.*". Empty
+	// selects every tag.
+	Tags     []string
+	Insecure bool
+}
+
+// Destination receives every source repository below Namespace, e.g.
+// docker.io/library/alpine is mirrored to <host>/<namespace>/library/alpine.
+type Destination struct {
+	Host      string `validate:"required"`
+	Namespace string
+	Insecure  bool
+}
+
+type Mirror struct {
+	Sources     []Source
+	Destination Destination
+	// Client reads the sources and DestinationClient writes to the
+	// destination. DestinationClient defaults to Client, which defaults to
+	// a new client.
+	Client            *oci.OciClient
+	DestinationClient *oci.OciClient
+	// Concurrency bounds the blobs copied at once, DefaultConcurrency when
+	// zero.
+	Concurrency int
+	// DryRun compares sources and destination without copying anything.
+	DryRun bool
+	// OnReport receives the report of every sync, e.g. to log the outcome
+	// of scheduled runs.
+	OnReport func(*Report)
+}
+
+// Sync copies every selected tag whose digest differs from the destination
+// and reports the outcome per tag. Tags that fail do not stop the others;
+// the returned error summarizes them.
+func (m *Mirror) Sync(ctx context.Context) (*Report, error) {
+	if err := validate.Struct(m); err != nil {
+		return nil, err
+	}
+	defer log.Timed("mirror_sync", "sources", len(m.Sources), "destination", m.Destination.Host)()
+
+	report := &Report{Started: time.Now()}
+	for _, source := range m.Sources {
+		report.Results = append(report.Results, m.syncSource(ctx, source)...)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	report.Finished = time.Now()
+
+	if m.OnReport != nil {
+		m.OnReport(report)
+	}
+	if failed := report.Count(StatusFailed); failed > 0 {
+		return report, log.Errorf(log.CodeRemote, "mirror_sync", "%d of %d tags failed to sync", failed, len(report.Results))
+	}
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// Task runs Sync on schedule s. Failures are returned to the scheduler,
+// which logs them; use OnReport to inspect each run.
+func (m *Mirror) Task(name string, s schedule.Schedule) schedule.Task {
+	return schedule.Task{
+		Name:     name,
+		Schedule: s,
+		Run: func(ctx context.Context) error {
+			_, err := m.Sync(ctx)
+			return err
+		},
+	}
+}
+
+func (m *Mirror) source() *oci.OciClient {
+	if m.Client == nil {
+		m.Client = oci.NewOciClient()
+	}
+	return m.Client
+}
+
+func (m *Mirror) destination() *oci.OciClient {
+	if m.DestinationClient == nil {
+		return m.source()
+	}
+	return m.DestinationClient
+}
+
+func (m *Mirror) concurrency() int {
+	if m.Concurrency <= 0 {
+		return DefaultConcurrency
+	}
+	return m.Concurrency
+}
+
+func (m *Mirror) syncSource(ctx context.Context, source Source) []Result {
+	tags, err := m.selectTags(ctx, source)
+	if err != nil {
+		repo := source.Repository.Host + "/" + source.Repository.NamespacedName()
+		return []Result{{Source: repo, Status: StatusFailed, Error: err.Error()}}
+	}
+
+	dst := m.target(source.Repository)
+	var results []Result
+	for _, tag := range tags {
+		if ctx.Err() != nil {
+			break
+		}
+		results = append(results, m.syncTag(ctx, source, dst, tag))
+	}
+
+	return results
+}
+
+// selectTags lists the repository only when a filter is a pattern.
+func (m *Mirror) selectTags(ctx context.Context, source Source) ([]string, error) {
+	literal := len(source.Tags) > 0
+	for _, filter := range source.Tags {
+		if strings.ContainsAny(filter, `*?[\`) {
+			literal = false
+		}
+	}
+	if literal {
+		return slices.Compact(slices.Sorted(slices.Values(source.Tags))), nil
+	}
+
+	all, err := m.source().ListTags(ctx, oci.ListTagsOptions{Tag: source.Repository, Insecure: source.Insecure})
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for _, tag := range all {
+		if matches(source.Tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+
+	return tags, nil
+}
+
+func matches(filters []string, tag string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, filter := range filters {
+		if ok, _ := path.Match(filter, tag); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *Mirror) target(repo oci.Tag) oci.Tag {
+	namespace := repo.Namespace
+	if m.Destination.Namespace != "" {
+		namespace = strings.Trim(path.Join(m.Destination.Namespace, repo.Namespace), "/")
+	}
+
+	return oci.Tag{Host: m.Destination.Host, Namespace: namespace, Name: repo.Name}
+}
+
+// copyStats counts what a tag sync transferred; blobs are copied
+// concurrently.
+type copyStats struct {
+	blobs atomic.Int64
+	bytes atomic.Int64
+}
+
+func (m *Mirror) syncTag(ctx context.Context, source Source, dst oci.Tag, tag string) Result {
+	src := source.Repository
+	src.Version, dst.Version = tag, tag
+	result := Result{Source: src.String(), Destination: dst.String()}
+	fail := func(err error) Result {
+		result.Status = StatusFailed
+		result.Error = err.Error()
+		log.Component("mirror").Error("sync failed", "source", result.Source, log.KeyError, err.Error())
+		return result
+	}
+
+	desc, err := m.source().ResolveManifest(ctx, oci.ManifestOptions{Tag: src, Insecure: source.Insecure})
+	if err != nil {
+		return fail(err)
+	}
+	result.Digest = desc.Digest
+
+	current, err := m.destination().ResolveManifest(ctx, oci.ManifestOptions{Tag: dst, Insecure: m.Destination.Insecure})
+	switch {
+	case err == nil && current.Digest == desc.Digest:
+		result.Status = StatusUnchanged
+		return result
+	case err != nil && !log.IsCode(err, log.CodeNotFound):
+		return fail(err)
+	}
+
+	if m.DryRun {
+		result.Status = StatusOutdated
+		return result
+	}
+
+	var stats copyStats
+	if err := m.copyManifest(ctx, source, src, dst, &stats); err != nil {
+		result.Blobs, result.Bytes = int(stats.blobs.Load()), stats.bytes.Load()
+		return fail(err)
+	}
+
+	result.Status = StatusCopied
+	result.Blobs, result.Bytes = int(stats.blobs.Load()), stats.bytes.Load()
+	log.Component("mirror").Info("synced", "source", result.Source, "digest", result.Digest.String(), "blobs", result.Blobs, "bytes", result.Bytes)
+	return result
+}
+
+// copyManifest copies the manifest src refers to, its blobs and, for an
+// index, the manifests it lists, then stores it as dst.
+func (m *Mirror) copyManifest(ctx context.Context, source Source, src, dst oci.Tag, stats *copyStats) error {
+	data, desc, err := m.source().FetchManifest(ctx, oci.ManifestOptions{Tag: src, Insecure: source.Insecure})
+	if err != nil {
+		return err
+	}
+	if desc.MediaType == "" {
+		var probe struct {
+			MediaType string `json:"mediaType"`
+		}
+		json.Unmarshal(data, &probe)
+		desc.MediaType = probe.MediaType
+	}
+
+	if oci.IsIndex(desc.MediaType) {
+		var index spec.Index
+		if err := json.Unmarshal(data, &index); err != nil {
+			return fmt.Errorf("failed to decode index %s: %w", src.String(), err)
+		}
+		for _, child := range index.Manifests {
+			childSrc, childDst := src, dst
+			childSrc.Version, childDst.Version = child.Digest.String(), child.Digest.String()
+			if _, err := m.destination().ResolveManifest(ctx, oci.ManifestOptions{Tag: childDst, Insecure: m.Destination.Insecure}); err == nil {
+				continue
+			}
+			if err := m.copyManifest(ctx, source, childSrc, childDst, stats); err != nil {
+				return err
+			}
+		}
+	} else {
+		var manifest spec.Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return fmt.Errorf("failed to decode manifest %s: %w", src.String(), err)
+		}
+		blobs := append([]spec.Descriptor{manifest.Config}, manifest.Layers...)
+		err := pool.ForEach(ctx, blobs, m.concurrency(), func(ctx context.Context, blob spec.Descriptor) error {
+			return m.copyBlob(ctx, source, src, dst, blob, stats)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return m.destination().PutManifest(ctx, oci.PutManifestOptions{Tag: dst, Data: data, MediaType: desc.MediaType, Insecure: m.Destination.Insecure})
+}
+
+func (m *Mirror) copyBlob(ctx context.Context, source Source, src, dst oci.Tag, blob spec.Descriptor, stats *copyStats) error {
+	exists, err := m.destination().BlobExists(ctx, oci.BlobExistsOptions{Tag: dst, Digest: blob.Digest, Insecure: m.Destination.Insecure})
+	if err != nil || exists {
+		return err
+	}
+
+	data, err := m.source().PullBlobContext(ctx, oci.PullBlobOptions{Digest: blob, Name: src.Name, Tag: &src, Insecure: source.Insecure})
+	if err != nil {
+		return fmt.Errorf("failed to pull blob %s: %w", blob.Digest, err)
+	}
+	if got := blob.Digest.Algorithm().FromBytes(data); got != blob.Digest {
+		return log.Errorf(log.CodeRemote, "mirror_copy_blob", "blob digest mismatch: expected %s, got %s", blob.Digest, got)
+	}
+
+	err = m.destination().PushBlobContext(ctx, oci.PushBlobOptions{Digest: blob, File: data, Name: dst.Name, Insecure: m.Destination.Insecure, Tag: dst})
+	if err != nil {
+		return fmt.Errorf("failed to push blob %s: %w", blob.Digest, err)
+	}
+
+	stats.blobs.Add(1)
+	stats.bytes.Add(int64(len(data)))
+	return nil
+}
@@ -0,0 +1,248 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/oci"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// registry is an in-memory OCI distribution API, enough for the calls a
+// mirror makes.
+type registry struct {
+	mu        sync.Mutex
+	tags      map[string]map[string]digest.Digest
+	manifests map[digest.Digest][]byte
+	types     map[digest.Digest]string
+	blobs     map[digest.Digest][]byte
+	uploads   int
+}
+
+func newRegistry(t *testing.T) (*registry, string) {
+	r := &registry{
+		tags:      map[string]map[string]digest.Digest{},
+		manifests: map[digest.Digest][]byte{},
+		types:     map[digest.Digest]string{},
+		blobs:     map[digest.Digest][]byte{},
+	}
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return r, strings.TrimPrefix(server.URL, "http://")
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p := strings.TrimPrefix(req.URL.Path, "/v2/")
+	switch {
+	case strings.HasSuffix(p, "/tags/list"):
+		repo := strings.TrimSuffix(p, "/tags/list")
+		var tags []string
+		for tag := range r.tags[repo] {
+			tags = append(tags, tag)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"name": repo, "tags": tags})
+	case strings.Contains(p, "/manifests/"):
+		repo, ref, _ := strings.Cut(p, "/manifests/")
+		if req.Method == http.MethodPut {
+			data, _ := io.ReadAll(req.Body)
+			d := digest.FromBytes(data)
+			r.manifests[d], r.types[d] = data, req.Header.Get("Content-Type")
+			if _, err := digest.Parse(ref); err != nil {
+				r.tag(repo, ref, d)
+			}
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		d, ok := r.tags[repo][ref]
+		if !ok {
+			d = digest.Digest(ref)
+		}
+		data, ok := r.manifests[d]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", r.types[d])
+		w.Header().Set("Docker-Content-Digest", d.String())
+		w.Write(data)
+	case strings.Contains(p, "/blobs/uploads/"):
+		if req.Method == http.MethodPut {
+			data, _ := io.ReadAll(req.Body)
+			r.blobs[digest.Digest(req.URL.Query().Get("digest"))] = data
+			r.uploads++
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.Header().Set("Location", req.URL.Path+"session")
+		w.WriteHeader(http.StatusAccepted)
+	case strings.Contains(p, "/blobs/"):
+		_, ref, _ := strings.Cut(p, "/blobs/")
+		data, ok := r.blobs[digest.Digest(ref)]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (r *registry) tag(repo, tag string, d digest.Digest) {
+	if r.tags[repo] == nil {
+		r.tags[repo] = map[string]digest.Digest{}
+	}
+	r.tags[repo][tag] = d
+}
+
+func (r *registry) blob(mediaType, content string) spec.Descriptor {
+	d := digest.FromString(content)
+	r.blobs[d] = []byte(content)
+	return spec.Descriptor{MediaType: mediaType, Digest: d, Size: int64(len(content))}
+}
+
+func (r *registry) manifest(repo, tag string, layers ...string) spec.Descriptor {
+	manifest := spec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: spec.MediaTypeImageManifest,
+		Config:    r.blob(spec.MediaTypeImageConfig, "config "+tag),
+	}
+	for _, layer := range layers {
+		manifest.Layers = append(manifest.Layers, r.blob(spec.MediaTypeImageLayerGzip, layer))
+	}
+	return r.put(repo, tag, spec.MediaTypeImageManifest, manifest)
+}
+
+func (r *registry) put(repo, tag, mediaType string, v any) spec.Descriptor {
+	data, _ := json.Marshal(v)
+	d := digest.FromBytes(data)
+	r.manifests[d], r.types[d] = data, mediaType
+	if tag != "" {
+		r.tag(repo, tag, d)
+	}
+	return spec.Descriptor{MediaType: mediaType, Digest: d, Size: int64(len(data))}
+}
+
+func TestSync(t *testing.T) {
+	src, srcHost := newRegistry(t)
+	dst, dstHost := newRegistry(t)
+
+	src.manifest("library/app", "v1.0", "base", "app 1.0")
+	src.manifest("library/app", "v1.1", "base", "app 1.1")
+	src.manifest("library/app", "dev", "base", "app dev")
+	amd64 := src.manifest("library/app", "", "base", "amd64")
+	arm64 := src.manifest("library/app", "", "base", "arm64")
+	src.put("library/app", "latest", spec.MediaTypeImageIndex, spec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: spec.MediaTypeImageIndex,
+		Manifests: []spec.Descriptor{amd64, arm64},
+	})
+
+	var reports []*Report
+	mirror := &Mirror{
+		Sources: []Source{{
+			Repository: oci.Tag{Host: srcHost, Namespace: "library", Name: "app"},
+			Tags:       []string{"v1.*", "latest"},
+			Insecure:   true,
+		}},
+		Destination: Destination{Host: dstHost, Namespace: "mirror", Insecure: true},
+		OnReport:    func(r *Report) { reports = append(reports, r) },
+	}
+
+	report, err := mirror.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var synced []string
+	for _, result := range report.Results {
+		if result.Status != StatusCopied {
+			t.Errorf("Expected %s to be copied, got: %s %s", result.Source, result.Status, result.Error)
+		}
+		synced = append(synced, result.Source[strings.LastIndex(result.Source, ":")+1:])
+	}
+	if strings.Join(synced, ",") != "latest,v1.0,v1.1" {
+		t.Errorf("Expected latest, v1.0 and v1.1, got: %v", synced)
+	}
+
+	// Configs of v1.0, v1.1 and the platforms, which share one, the base
+	// layer and four app layers.
+	if dst.uploads != 8 {
+		t.Errorf("Expected 8 blob uploads, got: %d", dst.uploads)
+	}
+	for _, tag := range []string{"latest", "v1.0", "v1.1"} {
+		if got, want := dst.tags["mirror/library/app"][tag], src.tags["library/app"][tag]; got != want {
+			t.Errorf("Expected %s to keep digest %s, got: %s", tag, want, got)
+		}
+	}
+	if _, ok := dst.manifests[amd64.Digest]; !ok {
+		t.Error("Expected the platform manifests of the index to be copied")
+	}
+
+	report, err = mirror.Sync(context.Background())
+	if err != nil || report.Count(StatusUnchanged) != 3 || dst.uploads != 8 {
+		t.Errorf("Expected a second sync to change nothing, got: %+v, %v", report.Results, err)
+	}
+
+	src.manifest("library/app", "v1.1", "base", "app 1.1.1")
+	mirror.DryRun = true
+	report, _ = mirror.Sync(context.Background())
+	if report.Count(StatusOutdated) != 1 || report.Count(StatusUnchanged) != 2 || dst.uploads != 8 {
+		t.Errorf("Expected a dry run to only report v1.1, got: %+v", report.Results)
+	}
+
+	mirror.DryRun = false
+	report, _ = mirror.Sync(context.Background())
+	if report.Count(StatusCopied) != 1 || report.Results[2].Blobs != 1 || dst.uploads != 9 {
+		t.Errorf("Expected only the new v1.1 layer to be copied, got: %+v", report.Results)
+	}
+
+	if len(reports) != 4 {
+		t.Errorf("Expected every sync to be reported, got: %d", len(reports))
+	}
+}
+
+func TestSyncFailure(t *testing.T) {
+	src, srcHost := newRegistry(t)
+	_, dstHost := newRegistry(t)
+	src.manifest("app", "v1", "layer")
+
+	mirror := &Mirror{
+		Sources: []Source{{
+			Repository: oci.Tag{Host: srcHost, Name: "app"},
+			Tags:       []string{"v1", "missing"},
+			Insecure:   true,
+		}},
+		Destination: Destination{Host: dstHost, Insecure: true},
+	}
+
+	report, err := mirror.Sync(context.Background())
+	if !log.IsCode(err, log.CodeRemote) {
+		t.Errorf("Expected a remote error, got: %v", err)
+	}
+	if report.Count(StatusCopied) != 1 || report.Count(StatusFailed) != 1 {
+		t.Errorf("Expected v1 copied and missing failed, got: %+v", report.Results)
+	}
+
+	var out strings.Builder
+	report.Print(&out)
+	if !strings.Contains(out.String(), ":missing: FAILED") || !strings.Contains(out.String(), "1 copied, 0 unchanged, 1 failed") {
+		t.Errorf("Expected failures in the printed report, got:\n%s", out.String())
+	}
+
+	if _, err := (&Mirror{}).Sync(context.Background()); !log.IsCode(err, log.CodeInvalidArgument) {
+		t.Errorf("Expected a missing destination to be rejected, got: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package mirror
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+type Status string
+
+const (
+	StatusCopied    Status = "copied"
+	StatusUnchanged Status = "unchanged"
+	// StatusOutdated marks a tag a dry run would copy.
+	StatusOutdated Status = "outdated"
+	StatusFailed   Status = "failed"
+)
+
+// Result is the outcome of syncing one tag. A source whose tags could not
+// be listed has a single failed result without a tag.
+type Result struct {
+	Source      string        `json:"source"`
+	Destination string        `json:"destination,omitempty"`
+	Digest      digest.Digest `json:"digest,omitempty"`
+	Status      Status        `json:"status"`
+	Blobs       int           `json:"blobs_copied"`
+	Bytes       int64         `json:"bytes_copied"`
+	Error       string        `json:"error,omitempty"`
+}
+
+type Report struct {
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Results  []Result  `json:"results"`
+}
+
+// Count returns the number of results with status.
+func (r Report) Count(status Status) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func (r Report) Print(w io.Writer) {
+	for _, result := range r.Results {
+		switch result.Status {
+		case StatusCopied:
+			fmt.Fprintf(w, "%s: copied to %s (%d blobs, %d bytes)\n", result.Source, result.Destination, result.Blobs, result.Bytes)
+		case StatusFailed:
+			fmt.Fprintf(w, "%s: FAILED (%s)\n", result.Source, result.Error)
+		default:
+			fmt.Fprintf(w, "%s: %s\n", result.Source, result.Status)
+		}
+	}
+	fmt.Fprintf(w, "%d copied, %d unchanged, %d failed in %s\n",
+		r.Count(StatusCopied), r.Count(StatusUnchanged), r.Count(StatusFailed), r.Finished.Sub(r.Started).Round(time.Millisecond))
+}
@@ -0,0 +1,126 @@
+package netcheck
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitForHTTP(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		expectStatus int
+		expectError  bool
+	}{
+		{name: "Any success", expectStatus: 0},
+		{name: "Expected status never returned", expectStatus: http.StatusTeapot, expectError: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			err := WaitForHTTP(ctx, server.URL, tt.expectStatus)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), "status 204") {
+				t.Errorf("Expected the last status in the error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestWaitForTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		l, err := net.Listen("tcp", address)
+		if err != nil {
+			return
+		}
+		defer l.Close()
+		conn, err := l.Accept()
+		if err == nil {
+			conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := WaitForTCP(ctx, address); err != nil {
+		t.Errorf("Expected the port to open, got: %v", err)
+	}
+}
+
+func TestReachability(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer registry.Close()
+
+	untrusted := httptest.NewTLSServer(http.NotFoundHandler())
+	defer untrusted.Close()
+
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedAddress := closed.Addr().String()
+	closed.Close()
+
+	reports := Reachability(context.Background(),
+		"http://"+registry.Listener.Addr().String(),
+		untrusted.Listener.Addr().String(),
+		"http://"+closedAddress,
+	)
+
+	tests := []struct {
+		name      string
+		ok        bool
+		lastStage Stage
+		detail    string
+	}{
+		{name: "Plain registry", ok: true, lastStage: StagePing, detail: "authentication required"},
+		{name: "Untrusted certificate", lastStage: StageTLS},
+		{name: "Closed port", lastStage: StageTCP},
+	}
+
+	for i, tt := range tests {
+		tt := tt // capture range variable
+		report := reports[i]
+		t.Run(tt.name, func(t *testing.T) {
+			last := report.Stages[len(report.Stages)-1]
+			if report.OK() != tt.ok || last.Stage != tt.lastStage {
+				t.Errorf("Expected ok=%v ending at %s, got: %s", tt.ok, tt.lastStage, report)
+			}
+			if !strings.Contains(last.Detail, tt.detail) {
+				t.Errorf("Expected detail %q, got: %q", tt.detail, last.Detail)
+			}
+		})
+	}
+}
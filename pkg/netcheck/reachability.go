@@ -0,0 +1,180 @@
+package netcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/httpx"
+	"github.com/eunanio/sdk/pkg/system"
+)
+
+type Stage string
+
+const (
+	StageDNS  Stage = "dns"
+	StageTCP  Stage = "tcp"
+	StageTLS  Stage = "tls"
+	StagePing Stage = "ping"
+)
+
+type StageResult struct {
+	Stage    Stage         `json:"stage"`
+	OK       bool          `json:"ok"`
+	Duration time.Duration `json:"duration"`
+	Detail   string        `json:"detail,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Report is the reachability of one registry host. Stages stop at the first
+// failure, so the last stage explains what is wrong.
+type Report struct {
+	Host   string        `json:"host"`
+	Stages []StageResult `json:"stages"`
+}
+
+func (r Report) OK() bool {
+	return len(r.Stages) > 0 && r.Stages[len(r.Stages)-1].OK && r.Stages[len(r.Stages)-1].Stage == StagePing
+}
+
+func (r Report) String() string {
+	parts := make([]string, len(r.Stages))
+	for i, stage := range r.Stages {
+		if stage.OK {
+			parts[i] = fmt.Sprintf("%s ok (%s)", stage.Stage, stage.Duration.Round(time.Millisecond))
+		} else {
+			parts[i] = fmt.Sprintf("%s failed: %s", stage.Stage, stage.Error)
+		}
+	}
+	return r.Host + ": " + strings.Join(parts, ", ")
+}
+
+// Reachability checks registry hosts concurrently: DNS resolution, a TCP
+// connection, the TLS handshake and a GET /v2/ ping. Hosts are given as
+// host[:port]; an "http://" prefix marks a plain HTTP registry, which skips
+// the TLS stage.
+func Reachability(ctx context.Context, hosts ...string) []Report {
+	reports := make([]Report, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			reports[i] = CheckRegistry(ctx, host)
+		}(i, host)
+	}
+	wg.Wait()
+
+	return reports
+}
+
+// CheckRegistry checks a single registry host, see Reachability.
+func CheckRegistry(ctx context.Context, host string) Report {
+	report := Report{Host: host}
+	insecure := strings.HasPrefix(host, "http://")
+	address := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(host, "http://"), "https://"), "/")
+
+	hostname, port, err := net.SplitHostPort(address)
+	if err != nil {
+		hostname, port = address, "443"
+		if insecure {
+			port = "80"
+		}
+	}
+
+	run := func(stage Stage, check func() (string, error)) bool {
+		start := time.Now()
+		detail, err := check()
+		result := StageResult{Stage: stage, OK: err == nil, Duration: time.Since(start), Detail: detail}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		report.Stages = append(report.Stages, result)
+		return result.OK
+	}
+
+	if !run(StageDNS, func() (string, error) {
+		addrs, err := net.DefaultResolver.LookupHost(ctx, hostname)
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(addrs, ", "), nil
+	}) {
+		return report
+	}
+
+	target := net.JoinHostPort(hostname, port)
+	if !run(StageTCP, func() (string, error) {
+		dialer := &net.Dialer{Timeout: attemptTimeout}
+		conn, err := dialer.DialContext(ctx, "tcp", target)
+		if err != nil {
+			return "", err
+		}
+		defer conn.Close()
+		return conn.RemoteAddr().String(), nil
+	}) {
+		return report
+	}
+
+	if !insecure && !run(StageTLS, func() (string, error) {
+		dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: attemptTimeout}, Config: &tls.Config{ServerName: hostname}}
+		conn, err := dialer.DialContext(ctx, "tcp", target)
+		if err != nil {
+			return "", err
+		}
+		defer conn.Close()
+
+		state := conn.(*tls.Conn).ConnectionState()
+		detail := tls.VersionName(state.Version)
+		if len(state.PeerCertificates) > 0 {
+			detail += ", certificate expires " + state.PeerCertificates[0].NotAfter.Format(time.DateOnly)
+		}
+		return detail, nil
+	}) {
+		return report
+	}
+
+	run(StagePing, func() (string, error) {
+		scheme := "https"
+		if insecure {
+			scheme = "http"
+		}
+
+		client := httpx.New(httpx.WithRetries(0), httpx.WithTimeout(attemptTimeout))
+		resp, err := client.Get(ctx, fmt.Sprintf("%s://%s/v2/", scheme, target))
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			return "registry API v2", nil
+		case http.StatusUnauthorized:
+			return "registry API v2, authentication required", nil
+		}
+		return "", fmt.Errorf("unexpected status %d from /v2/", resp.StatusCode)
+	})
+
+	return report
+}
+
+// RegistryCheck adapts CheckRegistry to a system.Check for doctor commands.
+func RegistryCheck(host string) system.Check {
+	return system.Check{
+		Name: host + " registry",
+		Run: func(ctx context.Context) (string, error) {
+			report := CheckRegistry(ctx, host)
+			last := report.Stages[len(report.Stages)-1]
+			if !report.OK() {
+				return "", fmt.Errorf("%s check failed: %s", last.Stage, last.Error)
+			}
+			return last.Detail, nil
+		},
+	}
+}
@@ -0,0 +1,73 @@
+package netcheck
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/httpx"
+)
+
+const (
+	minPollInterval = 100 * time.Millisecond
+	maxPollInterval = 2 * time.Second
+	attemptTimeout  = 5 * time.Second
+)
+
+// WaitForHTTP polls url until it answers with expectStatus, or any 2xx
+// status when expectStatus is zero, or ctx is done. Each attempt is made
+// once without retries; opts configure the client, e.g. its TLS settings.
+func WaitForHTTP(ctx context.Context, url string, expectStatus int, opts ...httpx.Option) error {
+	opts = append([]httpx.Option{httpx.WithRetries(0), httpx.WithTimeout(attemptTimeout)}, opts...)
+	client := httpx.New(opts...)
+
+	return poll(ctx, url, func() error {
+		resp, err := client.Get(ctx, url)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if expectStatus == 0 && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		if resp.StatusCode == expectStatus {
+			return nil
+		}
+		return fmt.Errorf("status %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	})
+}
+
+// WaitForTCP polls until a TCP connection to address (host:port) succeeds
+// or ctx is done.
+func WaitForTCP(ctx context.Context, address string) error {
+	dialer := &net.Dialer{Timeout: attemptTimeout}
+	return poll(ctx, address, func() error {
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+}
+
+// poll runs attempt with a growing interval until it succeeds. On timeout
+// the last failure is included in the error.
+func poll(ctx context.Context, target string, attempt func() error) error {
+	interval := minPollInterval
+	for {
+		err := attempt()
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s (last error: %v): %w", target, err, ctx.Err())
+		case <-time.After(interval):
+		}
+		interval = min(interval*2, maxPollInterval)
+	}
+}
@@ -0,0 +1,18 @@
+package oci
+
+import (
+	"net/http"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+func statusError(op string, resp *http.Response, msg string) error {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return log.Errorf(log.CodeUnauthorized, op, "unauthorized, please use nori login to authenticate")
+	case http.StatusNotFound:
+		return log.Errorf(log.CodeNotFound, op, "%s: %s", msg, resp.Status)
+	default:
+		return log.Errorf(log.CodeRemote, op, "%s: %s", msg, resp.Status)
+	}
+}
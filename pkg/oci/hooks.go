@@ -0,0 +1,112 @@
+package oci
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/trace"
+	"github.com/opencontainers/go-digest"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// RequestHook observes every registry request, e.g. to export metrics. op
+// is the client operation, such as "push_blob", and status is 0 when no
+// response was received.
+type RequestHook func(op, method string, status int, elapsed time.Duration)
+
+// TransferHook receives the size of each blob pushed or pulled.
+type TransferHook func(op string, bytes int64)
+
+// ArtifactHook receives each manifest pushed or pulled with its digest,
+// e.g. to keep a local history of artifacts.
+type ArtifactHook func(op string, tag Tag, digest digest.Digest, manifest *spec.Manifest)
+
+var (
+	hooksMu       sync.RWMutex
+	requestHooks  []RequestHook
+	transferHooks []TransferHook
+	artifactHooks []ArtifactHook
+)
+
+// OnRequest registers hook for the requests of every OciClient.
+func OnRequest(hook RequestHook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	requestHooks = append(requestHooks, hook)
+}
+
+// OnTransfer registers hook for the blobs of every OciClient.
+func OnTransfer(hook TransferHook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	transferHooks = append(transferHooks, hook)
+}
+
+// OnArtifact registers hook for the manifests of every OciClient.
+func OnArtifact(hook ArtifactHook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	artifactHooks = append(artifactHooks, hook)
+}
+
+// do sends req with the client's HTTP client, as a span of the trace in its
+// context, after waiting for the rate limiter, and reports it to the
+// request hooks.
+func (c *OciClient) do(op string, req *http.Request) (*http.Response, error) {
+	ctx, span := trace.StartKind(req.Context(), "HTTP "+req.Method, trace.KindClient,
+		trace.String("http.method", req.Method), trace.String("server.address", req.URL.Host))
+	req = req.WithContext(ctx)
+	trace.Inject(ctx, req.Header)
+
+	if c.RateLimiter != nil {
+		if err := c.RateLimiter.Wait(ctx, req.URL.Host); err != nil {
+			span.Finish(err)
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient().Do(req)
+	if resp != nil {
+		span.SetAttributes(trace.Int64("http.status_code", int64(resp.StatusCode)))
+		if resp.StatusCode >= 400 {
+			span.RecordError(fmt.Errorf("%s", resp.Status))
+		}
+	}
+	span.Finish(err)
+
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	var status int
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	for _, hook := range requestHooks {
+		hook(op, req.Method, status, time.Since(start))
+	}
+
+	return resp, err
+}
+
+func transferred(op string, bytes int64) {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	for _, hook := range transferHooks {
+		hook(op, bytes)
+	}
+}
+
+func artifact(op string, tag Tag, manifestBytes []byte, manifest *spec.Manifest) {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	if len(artifactHooks) == 0 {
+		return
+	}
+
+	d := digest.FromBytes(manifestBytes)
+	for _, hook := range artifactHooks {
+		hook(op, tag, d, manifest)
+	}
+}
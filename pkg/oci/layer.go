@@ -0,0 +1,34 @@
+package oci
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/eunanio/sdk/pkg/codec"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// LayerMediaType returns base with the "+suffix" of the codec data is
+// compressed with, e.g. spec.MediaTypeImageLayerGzip for a gzipped tarball
+// and spec.MediaTypeImageLayer for a plain one.
+func LayerMediaType(base string, data []byte) string {
+	c, _, err := codec.Detect(bytes.NewReader(data))
+	if err != nil {
+		return base
+	}
+	return codec.MediaType(base, c)
+}
+
+// OpenLayer decompresses a layer blob with the codec named by the media type
+// of desc. When the type names no compression the codec is detected from
+// the data, since not every producer labels its layers accurately.
+func OpenLayer(desc spec.Descriptor, r io.Reader) (io.ReadCloser, error) {
+	c, err := codec.ForMediaType(desc.MediaType)
+	if err != nil {
+		return nil, err
+	}
+	if c == codec.None {
+		return codec.NewReader(r)
+	}
+	return c.NewReader(r)
+}
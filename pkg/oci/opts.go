@@ -2,12 +2,18 @@ package oci
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 
+	"github.com/eunanio/sdk/pkg/httpx"
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/trace"
+	"github.com/eunanio/sdk/pkg/validate"
 	spec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -17,22 +23,59 @@ type PushBlobOptions struct {
 	Name     string
 	Insecure bool
 	Tag      Tag
+	// Progress is called as the blob is uploaded.
+	Progress ProgressFunc
 }
 
 type PullBlobOptions struct {
-	Digest spec.Descriptor
-	Name   string
-	Tag    *Tag
+	Digest   spec.Descriptor
+	Name     string
+	Tag      *Tag
+	Insecure bool
+	// Progress is called as the blob is downloaded.
+	Progress ProgressFunc
 }
 
 type PushManifestOptions struct {
-	Manifest *spec.Manifest
-	Tag      *Tag
+	Manifest *spec.Manifest `validate:"required"`
+	Tag      *Tag           `validate:"required"`
 	Insecure bool
 }
 
 type OciClient struct {
 	Credentials *OciCredentials
+	// Keychain supplies credentials per registry host when Credentials is
+	// not set.
+	Keychain Keychain
+	// RateLimiter, when set, is consulted before every request.
+	RateLimiter RateLimiter
+
+	transport  http.RoundTripper
+	clientOnce sync.Once
+	client     *httpx.Client
+}
+
+type Option func(*OciClient)
+
+// WithTransport sends the client's requests through transport instead of the
+// shared registry client's, e.g. the client of an httptest.Server. Requests
+// are still retried and logged.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *OciClient) {
+		c.transport = transport
+	}
+}
+
+// RateLimiter delays requests to host, returning an error only when ctx
+// ends the wait. ratelimit.Hosts implements it.
+type RateLimiter interface {
+	Wait(ctx context.Context, host string) error
+}
+
+// Keychain looks up registry credentials by host, returning an empty
+// username when it has none. keyring.Keyring implements it.
+type Keychain interface {
+	Lookup(host string) (username, password string, err error)
 }
 
 type OciCredentials struct {
@@ -42,6 +85,24 @@ type OciCredentials struct {
 }
 
 func (c *OciClient) PushBlob(opts PushBlobOptions) error {
+	return c.PushBlobContext(context.Background(), opts)
+}
+
+// PushBlobContext is PushBlob recorded as a span of the trace in ctx, which
+// also cancels the upload.
+func (c *OciClient) PushBlobContext(ctx context.Context, opts PushBlobOptions) error {
+	ctx, span := trace.Start(ctx, "oci.push_blob", trace.String("digest", opts.Digest.Digest.String()), trace.String("repository", opts.Tag.NamespacedName()))
+	err := c.pushBlob(ctx, opts)
+	span.Finish(err)
+	return err
+}
+
+func (c *OciClient) pushBlob(ctx context.Context, opts PushBlobOptions) error {
+	defer log.Timed("push_blob", "digest", opts.Digest.Digest.String())()
+	if err := validate.Struct(opts); err != nil {
+		return err
+	}
+
 	var protocol string
 	if opts.Insecure {
 		protocol = "http"
@@ -56,98 +117,131 @@ func (c *OciClient) PushBlob(opts PushBlobOptions) error {
 		endpoint = fmt.Sprintf("%s://%s/v2/%s/blobs/uploads/", protocol, opts.Tag.Host, opts.Tag.Name)
 	}
 
-	req, err := http.NewRequest("GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return fmt.Errorf("error creating request: %s", err.Error())
 	}
 
-	if c.Credentials != nil {
-		req.Header.Add("Authorization", c.Credentials.encoded)
+	if err := c.authorize(req); err != nil {
+		return err
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.do("push_blob", req)
 	if err != nil {
 		return fmt.Errorf("error sending request: %s", err.Error())
 	}
+	drain(resp)
 
 	if resp.StatusCode != 202 {
-		return fmt.Errorf("failed to push blob: %s", resp.Status)
+		return statusError("push_blob", resp, "failed to push blob")
+	}
+
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return fmt.Errorf("invalid upload location: %s", err.Error())
 	}
 
-	location := resp.Header.Get("Location")
-	req, err = http.NewRequest("PUT", location, bytes.NewReader(opts.File))
+	body := newProgressReader(bytes.NewReader(opts.File), int64(len(opts.File)), opts.Progress)
+	req, err = http.NewRequestWithContext(ctx, "PUT", location.String(), body)
 	if err != nil {
 		return fmt.Errorf("error uploading blob: %s", err.Error())
 	}
 
+	req.ContentLength = int64(len(opts.File))
 	req.Header.Add("Content-Type", "application/octet-stream")
 	req.Header.Add("Content-Length", fmt.Sprintf("%d", len(opts.File)))
 	query := req.URL.Query()
 	query.Add("digest", opts.Digest.Digest.String())
 	req.URL.RawQuery = query.Encode()
 
-	if c.Credentials != nil {
-		req.Header.Add("Authorization", c.Credentials.encoded)
+	if err := c.authorize(req); err != nil {
+		return err
 	}
 
-	resp, err = client.Do(req)
+	resp, err = c.do("push_blob", req)
 	if err != nil {
 		return err
 	}
+	drain(resp)
 
 	if resp.StatusCode != 201 {
-		if resp.StatusCode == http.StatusUnauthorized {
-			return fmt.Errorf("unauthorized, please use nori login to authenticate")
-		}
-		return fmt.Errorf("failed to push blob: %s", resp.Status)
+		return statusError("push_blob", resp, "failed to push blob")
 	}
+	transferred("push_blob", int64(len(opts.File)))
 	return nil
 }
 
 func (c *OciClient) PullBlob(opts PullBlobOptions) ([]byte, error) {
+	return c.PullBlobContext(context.Background(), opts)
+}
+
+// PullBlobContext is PullBlob recorded as a span of the trace in ctx.
+func (c *OciClient) PullBlobContext(ctx context.Context, opts PullBlobOptions) ([]byte, error) {
+	ctx, span := trace.Start(ctx, "oci.pull_blob", trace.String("digest", opts.Digest.Digest.String()))
+	data, err := c.pullBlob(ctx, opts)
+	span.Finish(err)
+	return data, err
+}
+
+func (c *OciClient) pullBlob(ctx context.Context, opts PullBlobOptions) ([]byte, error) {
+	defer log.Timed("pull_blob", "digest", opts.Digest.Digest.String())()
+	protocol := "https"
+	if opts.Insecure {
+		protocol = "http"
+	}
+
 	var endpoint string
 	if opts.Tag.Namespace != "" {
-		endpoint = fmt.Sprintf("https://%s/v2/%s/%s/blobs/%s", opts.Tag.Host, opts.Tag.Namespace, opts.Tag.Name, opts.Digest.Digest)
+		endpoint = fmt.Sprintf("%s://%s/v2/%s/%s/blobs/%s", protocol, opts.Tag.Host, opts.Tag.Namespace, opts.Tag.Name, opts.Digest.Digest)
 	} else {
-		endpoint = fmt.Sprintf("https://%s/v2/%s/blobs/%s", opts.Tag.Host, opts.Tag.Name, opts.Digest.Digest)
+		endpoint = fmt.Sprintf("%s://%s/v2/%s/blobs/%s", protocol, opts.Tag.Host, opts.Tag.Name, opts.Digest.Digest)
 	}
 
-	req, err := http.NewRequest("GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %s", err.Error())
 	}
 
-	if c.Credentials != nil {
-		req.Header.Add("Authorization", c.Credentials.encoded)
+	if err := c.authorize(req); err != nil {
+		return nil, err
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.do("pull_blob", req)
 	if err != nil {
 		return nil, fmt.Errorf("error sending request: %s", err.Error())
 	}
+	defer drain(resp)
 
 	if resp.StatusCode != 200 {
-		if resp.StatusCode == http.StatusUnauthorized {
-			return nil, fmt.Errorf("unauthorized, please use nori login to authenticate")
-		}
-		return nil, fmt.Errorf("failed to pull blob: %s", resp.Status)
+		return nil, statusError("pull_blob", resp, "failed to pull blob")
 	}
-	defer resp.Body.Close()
 
-	data, err := io.ReadAll(resp.Body)
+	data, err := io.ReadAll(newProgressReader(resp.Body, resp.ContentLength, opts.Progress))
 	if err != nil {
 		return nil, fmt.Errorf("error reading blob: %s", err.Error())
 	}
 
+	transferred("pull_blob", int64(len(data)))
 	return data, nil
 }
 
 func (c *OciClient) PullManifest(tag *Tag) (*spec.Manifest, error) {
+	return c.PullManifestContext(context.Background(), tag)
+}
+
+// PullManifestContext is PullManifest recorded as a span of the trace in ctx.
+func (c *OciClient) PullManifestContext(ctx context.Context, tag *Tag) (*spec.Manifest, error) {
+	ctx, span := trace.Start(ctx, "oci.pull_manifest", trace.String("tag", tag.String()))
+	manifest, err := c.pullManifest(ctx, tag)
+	span.Finish(err)
+	return manifest, err
+}
+
+func (c *OciClient) pullManifest(ctx context.Context, tag *Tag) (*spec.Manifest, error) {
+	defer log.Timed("pull_manifest", "tag", tag.String())()
 	var api_endpoint string
 	if tag.Host == "" {
-		return nil, fmt.Errorf("Host is required, but not provided")
+		return nil, log.Errorf(log.CodeInvalidArgument, "pull_manifest", "Host is required, but not provided")
 	}
 
 	if tag.Namespace != "" {
@@ -156,18 +250,17 @@ func (c *OciClient) PullManifest(tag *Tag) (*spec.Manifest, error) {
 		api_endpoint = fmt.Sprintf("https://%s/v2/%s/manifests/%s", tag.Host, tag.Name, tag.Version)
 	}
 
-	req, err := http.NewRequest("GET", api_endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", api_endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Add("Accept", spec.MediaTypeImageManifest)
-	if c.Credentials != nil {
-		req.Header.Add("Authorization", c.Credentials.encoded)
+	if err := c.authorize(req); err != nil {
+		return nil, err
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.do("pull_manifest", req)
 	if err != nil {
 		return nil, err
 	}
@@ -175,11 +268,7 @@ func (c *OciClient) PullManifest(tag *Tag) (*spec.Manifest, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		if resp.StatusCode == http.StatusUnauthorized {
-			return nil, fmt.Errorf("unauthorized, please use nori login to authenticate")
-		}
-
-		return nil, fmt.Errorf("cannot to pull manifest: %s", resp.Status)
+		return nil, statusError("pull_manifest", resp, "cannot to pull manifest")
 	}
 
 	manifestBytes, err := io.ReadAll(resp.Body)
@@ -193,10 +282,31 @@ func (c *OciClient) PullManifest(tag *Tag) (*spec.Manifest, error) {
 		return nil, err
 	}
 
+	artifact("pull_manifest", *tag, manifestBytes, manifest)
 	return manifest, nil
 }
 
 func (c *OciClient) PushManifest(opts PushManifestOptions) error {
+	return c.PushManifestContext(context.Background(), opts)
+}
+
+// PushManifestContext is PushManifest recorded as a span of the trace in ctx.
+func (c *OciClient) PushManifestContext(ctx context.Context, opts PushManifestOptions) error {
+	ctx, span := trace.Start(ctx, "oci.push_manifest")
+	if opts.Tag != nil {
+		span.SetAttributes(trace.String("tag", opts.Tag.String()))
+	}
+	err := c.pushManifest(ctx, opts)
+	span.Finish(err)
+	return err
+}
+
+func (c *OciClient) pushManifest(ctx context.Context, opts PushManifestOptions) error {
+	if err := validate.Struct(opts); err != nil {
+		return err
+	}
+
+	defer log.Timed("push_manifest", "tag", opts.Tag.String())()
 	var protocol string
 	var endpoint string
 	jsonBytes, err := json.Marshal(opts.Manifest)
@@ -216,28 +326,26 @@ func (c *OciClient) PushManifest(opts PushManifestOptions) error {
 		endpoint = fmt.Sprintf("%s://%s/v2/%s/manifests/%s", protocol, opts.Tag.Host, opts.Tag.Name, opts.Tag.Version)
 	}
 
-	req, err := http.NewRequest("HEAD", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "HEAD", endpoint, nil)
 	if err != nil {
 		return fmt.Errorf("error creating request: %s", err.Error())
 	}
 
-	if c.Credentials != nil {
-		req.Header.Add("Authorization", c.Credentials.encoded)
+	if err := c.authorize(req); err != nil {
+		return err
 	}
 
 	req.Header.Add("Content-Type", spec.MediaTypeImageManifest)
 	req.Header.Add("Content-Length", fmt.Sprintf("%d", len(jsonBytes)))
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.do("push_manifest", req)
 	if err != nil {
 		return fmt.Errorf("error sending request: %s", err.Error())
 	}
-
-	defer resp.Body.Close()
+	drain(resp)
 
 	if resp.StatusCode != 200 {
-		uploadReq, err := http.NewRequest("PUT", endpoint, bytes.NewReader(jsonBytes))
+		uploadReq, err := http.NewRequestWithContext(ctx, "PUT", endpoint, bytes.NewReader(jsonBytes))
 		if err != nil {
 			return fmt.Errorf("error creating request: %s", err.Error())
 		}
@@ -245,23 +353,22 @@ func (c *OciClient) PushManifest(opts PushManifestOptions) error {
 		uploadReq.Header.Add("Content-Type", spec.MediaTypeImageManifest)
 		uploadReq.Header.Add("Content-Length", fmt.Sprintf("%d", len(jsonBytes)))
 
-		if c.Credentials != nil {
-			uploadReq.Header.Add("Authorization", c.Credentials.encoded)
+		if err := c.authorize(uploadReq); err != nil {
+			return err
 		}
 
-		resp, err = client.Do(uploadReq)
+		resp, err = c.do("push_manifest", uploadReq)
 		if err != nil {
 			return fmt.Errorf("error sending request: %s", err.Error())
 		}
+		drain(resp)
 
 		if resp.StatusCode != 201 {
-			if resp.StatusCode == http.StatusUnauthorized {
-				return fmt.Errorf("unauthorized, please use nori login to authenticate")
-			}
-			return fmt.Errorf("failed to push manifest: %s", resp.Status)
+			return statusError("push_manifest", resp, "failed to push manifest")
 		}
 	}
 
+	artifact("push_manifest", *opts.Tag, jsonBytes, opts.Manifest)
 	return nil
 }
 
@@ -284,6 +391,33 @@ func (c *OciClient) SetCredentials(creds OciCredentials) {
 	c.Credentials = &creds
 }
 
-func NewOciClient() *OciClient {
-	return &OciClient{}
+func NewOciClient(opts ...Option) *OciClient {
+	c := &OciClient{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// authorize adds the Authorization header from Credentials, or from the
+// Keychain entry for the request host.
+func (c *OciClient) authorize(req *http.Request) error {
+	if c.Credentials != nil {
+		req.Header.Add("Authorization", c.Credentials.encoded)
+		return nil
+	}
+
+	if c.Keychain == nil {
+		return nil
+	}
+
+	username, password, err := c.Keychain.Lookup(req.URL.Host)
+	if err != nil {
+		return fmt.Errorf("error reading credentials for %s: %s", req.URL.Host, err.Error())
+	}
+
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	return nil
 }
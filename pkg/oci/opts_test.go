@@ -1,10 +1,16 @@
 package oci
 
 import (
+	"bytes"
+	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/eunanio/sdk/pkg/codec"
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/trace"
 	spec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -14,7 +20,7 @@ func TestPushBlob(t *testing.T) {
 		opts         PushBlobOptions
 		setupServer  func() *httptest.Server
 		expectError  bool
-		expectedCode int
+		expectedCode log.Code
 	}{
 		{
 			name: "Successful push blob",
@@ -37,7 +43,7 @@ func TestPushBlob(t *testing.T) {
 				mux.HandleFunc("/upload/location", func(w http.ResponseWriter, r *http.Request) {
 					w.WriteHeader(http.StatusCreated)
 				})
-				return httptest.NewServer(mux)
+				return httptest.NewUnstartedServer(mux)
 			},
 			expectError: false,
 		},
@@ -58,9 +64,10 @@ func TestPushBlob(t *testing.T) {
 				mux.HandleFunc("/v2/testblob/blobs/uploads/", func(w http.ResponseWriter, r *http.Request) {
 					w.WriteHeader(http.StatusUnauthorized)
 				})
-				return httptest.NewServer(mux)
+				return httptest.NewUnstartedServer(mux)
 			},
-			expectError: true,
+			expectError:  true,
+			expectedCode: log.CodeUnauthorized,
 		},
 		{
 			name: "Server error on upload",
@@ -82,9 +89,10 @@ func TestPushBlob(t *testing.T) {
 				mux.HandleFunc("/upload/location", func(w http.ResponseWriter, r *http.Request) {
 					w.WriteHeader(http.StatusInternalServerError)
 				})
-				return httptest.NewServer(mux)
+				return httptest.NewUnstartedServer(mux)
 			},
-			expectError: true,
+			expectError:  true,
+			expectedCode: log.CodeRemote,
 		},
 		{
 			name: "Invalid URL",
@@ -102,6 +110,22 @@ func TestPushBlob(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "Missing host",
+			opts: PushBlobOptions{
+				Digest: spec.Descriptor{
+					Digest: "sha256:1234567890abcdef",
+				},
+				File: []byte("test content"),
+				Name: "testblob",
+				Tag:  Tag{Name: "testblob", Version: "v1"},
+			},
+			setupServer: func() *httptest.Server {
+				return nil
+			},
+			expectError:  true,
+			expectedCode: log.CodeInvalidArgument,
+		},
 		{
 			name: "Insecure connection",
 			opts: PushBlobOptions{
@@ -132,25 +156,215 @@ func TestPushBlob(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup mock server
+			var opts []Option
 			server := tt.setupServer()
 			if server != nil {
 				if tt.opts.Insecure {
 					server.Start()
 				} else {
 					server.StartTLS()
+					opts = append(opts, WithTransport(server.Client().Transport))
 				}
 				defer server.Close()
 				tt.opts.Tag.Host = server.Listener.Addr().String()
 			}
 
 			// Create OciClient
-			client := NewOciClient()
+			client := NewOciClient(opts...)
 
 			// Call PushBlob
 			err := client.PushBlob(tt.opts)
 			if (err != nil) != tt.expectError {
 				t.Errorf("PushBlob() error = %v, expectError %v", err, tt.expectError)
 			}
+			if tt.expectedCode != "" && !log.IsCode(err, tt.expectedCode) {
+				t.Errorf("PushBlob() error code = %v, expected %v", log.ErrorCode(err), tt.expectedCode)
+			}
 		})
 	}
 }
+
+type spanRecorder struct{ spans []trace.SpanData }
+
+func (r *spanRecorder) Export(_ context.Context, spans []trace.SpanData) error {
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func TestPushBlobContextTrace(t *testing.T) {
+	var traceparents []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/testblob/blobs/uploads/", func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get(trace.TraceparentHeader))
+		w.Header().Set("Location", "/upload/location")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/upload/location", func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get(trace.TraceparentHeader))
+		w.WriteHeader(http.StatusCreated)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	recorder := &spanRecorder{}
+	shutdown := trace.Setup(trace.Config{Exporter: recorder})
+	ctx, pipeline := trace.Start(context.Background(), "pipeline")
+
+	err := NewOciClient().PushBlobContext(ctx, PushBlobOptions{
+		Digest:   spec.Descriptor{Digest: "sha256:1234567890abcdef"},
+		File:     []byte("test content"),
+		Insecure: true,
+		Tag:      Tag{Host: server.Listener.Addr().String(), Name: "testblob", Version: "v1"},
+	})
+	pipeline.End()
+	shutdown(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	traceID := pipeline.SpanContext().TraceID
+	for _, value := range traceparents {
+		sc, ok := trace.ParseTraceparent(value)
+		if !ok || sc.TraceID != traceID {
+			t.Errorf("Expected requests to carry trace %s, got: %q", traceID, value)
+		}
+	}
+
+	var names []string
+	for _, span := range recorder.spans {
+		if span.Context.TraceID != traceID {
+			t.Errorf("Expected span %s in trace %s, got: %s", span.Name, traceID, span.Context.TraceID)
+		}
+		names = append(names, span.Name)
+	}
+	if len(names) != 4 || names[2] != "oci.push_blob" {
+		t.Errorf("Expected two requests inside oci.push_blob, got: %v", names)
+	}
+}
+
+type hostRecorder struct {
+	hosts []string
+	err   error
+}
+
+func (r *hostRecorder) Wait(_ context.Context, host string) error {
+	r.hosts = append(r.hosts, host)
+	return r.err
+}
+
+func TestRateLimiter(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/testblob/blobs/uploads/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/upload/location")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/upload/location", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	host := server.Listener.Addr().String()
+
+	opts := PushBlobOptions{
+		Digest:   spec.Descriptor{Digest: "sha256:1234567890abcdef"},
+		File:     []byte("test content"),
+		Insecure: true,
+		Tag:      Tag{Host: host, Name: "testblob", Version: "v1"},
+	}
+
+	limiter := &hostRecorder{}
+	client := &OciClient{RateLimiter: limiter}
+	if err := client.PushBlob(opts); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(limiter.hosts) != 2 || limiter.hosts[0] != host || limiter.hosts[1] != host {
+		t.Errorf("Expected the limiter to be consulted for both requests to %s, got: %v", host, limiter.hosts)
+	}
+
+	limiter = &hostRecorder{err: context.Canceled}
+	client = &OciClient{RateLimiter: limiter}
+	if err := client.PushBlob(opts); err == nil {
+		t.Error("Expected a failed wait to abort the push")
+	}
+}
+
+func TestListTags(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/library/app/tags/list", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("last") == "" {
+			w.Header().Set("Link", `</v2/library/app/tags/list?n=2&last=v2>; rel="next"`)
+			w.Write([]byte(`{"name":"library/app","tags":["v1","v2"]}`))
+			return
+		}
+		w.Write([]byte(`{"name":"library/app","tags":["v3"]}`))
+	})
+	mux.HandleFunc("/v2/library/app/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/library/app/manifests/v1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", spec.MediaTypeImageManifest)
+		w.Header().Set("Docker-Content-Digest", "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	repo := Tag{Host: server.Listener.Addr().String(), Namespace: "library", Name: "app"}
+	client := NewOciClient()
+	tags, err := client.ListTags(context.Background(), ListTagsOptions{Tag: repo, Insecure: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(tags) != 3 || tags[2] != "v3" {
+		t.Errorf("Expected tags from both pages, got: %v", tags)
+	}
+
+	repo.Version = "v1"
+	desc, err := client.ResolveManifest(context.Background(), ManifestOptions{Tag: repo, Insecure: true})
+	if err != nil || desc.MediaType != spec.MediaTypeImageManifest || desc.Digest.Encoded()[:8] != "e3b0c442" {
+		t.Errorf("Expected the manifest descriptor, got: %+v, %v", desc, err)
+	}
+
+	repo.Version = "missing"
+	if _, err := client.ResolveManifest(context.Background(), ManifestOptions{Tag: repo, Insecure: true}); !log.IsCode(err, log.CodeNotFound) {
+		t.Errorf("Expected not found, got: %v", err)
+	}
+}
+
+func TestLayerMediaType(t *testing.T) {
+	for _, c := range []codec.Codec{codec.Gzip, codec.Zstd, codec.None} {
+		var buf bytes.Buffer
+		w, _ := c.NewWriter(&buf)
+		w.Write([]byte("layer"))
+		w.Close()
+
+		desc := spec.Descriptor{MediaType: LayerMediaType(spec.MediaTypeImageLayer, buf.Bytes())}
+		if expected := codec.MediaType(spec.MediaTypeImageLayer, c); desc.MediaType != expected {
+			t.Errorf("Expected %s, got: %s", expected, desc.MediaType)
+		}
+
+		r, err := OpenLayer(desc, &buf)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if got, _ := io.ReadAll(r); string(got) != "layer" {
+			t.Errorf("Expected layer, got: %q", got)
+		}
+		r.Close()
+	}
+
+	var buf bytes.Buffer
+	w, _ := codec.Gzip.NewWriter(&buf)
+	w.Write([]byte("docker layer"))
+	w.Close()
+
+	desc := spec.Descriptor{MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip"}
+	r, err := OpenLayer(desc, &buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer r.Close()
+	if got, _ := io.ReadAll(r); string(got) != "docker layer" {
+		t.Errorf("Expected docker layer, got: %q", got)
+	}
+}
@@ -0,0 +1,32 @@
+package oci
+
+import "io"
+
+// ProgressFunc receives the number of bytes transferred so far and the total
+// size, which is -1 when unknown.
+type ProgressFunc func(transferred, total int64)
+
+type progressReader struct {
+	r           io.Reader
+	total       int64
+	transferred int64
+	fn          ProgressFunc
+}
+
+func newProgressReader(r io.Reader, total int64, fn ProgressFunc) io.Reader {
+	if fn == nil {
+		return r
+	}
+
+	return &progressReader{r: r, total: total, fn: fn}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.transferred += int64(n)
+		p.fn(p.transferred, p.total)
+	}
+
+	return n, err
+}
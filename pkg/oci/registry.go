@@ -0,0 +1,275 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/validate"
+	"github.com/opencontainers/go-digest"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Docker media types, accepted alongside the OCI ones when reading
+// manifests.
+const (
+	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+var manifestMediaTypes = []string{
+	spec.MediaTypeImageManifest,
+	spec.MediaTypeImageIndex,
+	MediaTypeDockerManifest,
+	MediaTypeDockerManifestList,
+}
+
+type ListTagsOptions struct {
+	// Tag locates the repository; its Version is ignored.
+	Tag      Tag
+	Insecure bool
+}
+
+type ManifestOptions struct {
+	// Tag locates the manifest. Its Version is a tag or a digest.
+	Tag      Tag
+	Insecure bool
+}
+
+type PutManifestOptions struct {
+	Tag Tag
+	// Data is stored as is, so the manifest keeps its digest.
+	Data      []byte `validate:"required"`
+	MediaType string `validate:"required"`
+	Insecure  bool
+}
+
+type BlobExistsOptions struct {
+	Tag      Tag
+	Digest   digest.Digest `validate:"required"`
+	Insecure bool
+}
+
+// IsIndex reports whether mediaType is a manifest list rather than an image
+// manifest.
+func IsIndex(mediaType string) bool {
+	return mediaType == spec.MediaTypeImageIndex || mediaType == MediaTypeDockerManifestList
+}
+
+// ListTags returns every tag of a repository, following pagination.
+func (c *OciClient) ListTags(ctx context.Context, opts ListTagsOptions) ([]string, error) {
+	if err := validate.Struct(opts); err != nil {
+		return nil, err
+	}
+	defer log.Timed("list_tags", "repository", opts.Tag.NamespacedName())()
+
+	var tags []string
+	next := repositoryURL(opts.Tag, opts.Insecure) + "/tags/list"
+	for next != "" {
+		req, err := http.NewRequestWithContext(ctx, "GET", next, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.authorize(req); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.do("list_tags", req)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		err = func() error {
+			defer resp.Body.Close()
+			if resp.StatusCode != 200 {
+				return statusError("list_tags", resp, "failed to list tags")
+			}
+			return json.NewDecoder(resp.Body).Decode(&page)
+		}()
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, page.Tags...)
+
+		next = ""
+		if link := nextLink(resp.Header.Get("Link")); link != "" {
+			u, err := resp.Request.URL.Parse(link)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pagination link: %w", err)
+			}
+			next = u.String()
+		}
+	}
+
+	return tags, nil
+}
+
+// nextLink returns the target of a Link header with rel="next".
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		target, params, _ := strings.Cut(link, ";")
+		if strings.Contains(strings.ReplaceAll(params, " ", ""), `rel="next"`) {
+			return strings.Trim(strings.TrimSpace(target), "<>")
+		}
+	}
+
+	return ""
+}
+
+// ResolveManifest returns the descriptor of a manifest without downloading
+// it. A missing manifest is reported with log.CodeNotFound.
+func (c *OciClient) ResolveManifest(ctx context.Context, opts ManifestOptions) (*spec.Descriptor, error) {
+	if err := validate.Struct(opts); err != nil {
+		return nil, err
+	}
+
+	req, err := c.manifestRequest(ctx, "HEAD", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do("resolve_manifest", req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, statusError("resolve_manifest", resp, "failed to resolve manifest")
+	}
+
+	d, err := digest.Parse(resp.Header.Get("Docker-Content-Digest"))
+	if err != nil {
+		// Registries need not send the digest, so read the manifest instead.
+		_, desc, err := c.FetchManifest(ctx, opts)
+		return desc, err
+	}
+
+	return &spec.Descriptor{MediaType: resp.Header.Get("Content-Type"), Digest: d, Size: resp.ContentLength}, nil
+}
+
+// FetchManifest returns a manifest or index as stored in the registry,
+// with its descriptor.
+func (c *OciClient) FetchManifest(ctx context.Context, opts ManifestOptions) ([]byte, *spec.Descriptor, error) {
+	if err := validate.Struct(opts); err != nil {
+		return nil, nil, err
+	}
+
+	req, err := c.manifestRequest(ctx, "GET", opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := c.do("fetch_manifest", req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, nil, statusError("fetch_manifest", resp, "failed to fetch manifest")
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	desc := &spec.Descriptor{MediaType: resp.Header.Get("Content-Type"), Digest: digest.FromBytes(data), Size: int64(len(data))}
+	if want, err := digest.Parse(opts.Tag.Version); err == nil && want != desc.Digest {
+		return nil, nil, log.Errorf(log.CodeRemote, "fetch_manifest", "manifest digest mismatch: expected %s, got %s", want, desc.Digest)
+	}
+
+	return data, desc, nil
+}
+
+func (c *OciClient) manifestRequest(ctx context.Context, method string, opts ManifestOptions) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, repositoryURL(opts.Tag, opts.Insecure)+"/manifests/"+opts.Tag.Version, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// PutManifest uploads a manifest or index without re-encoding it, e.g. when
+// copying it between registries.
+func (c *OciClient) PutManifest(ctx context.Context, opts PutManifestOptions) error {
+	if err := validate.Struct(opts); err != nil {
+		return err
+	}
+	defer log.Timed("put_manifest", "tag", opts.Tag.String())()
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", repositoryURL(opts.Tag, opts.Insecure)+"/manifests/"+opts.Tag.Version, bytes.NewReader(opts.Data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", opts.MediaType)
+	if err := c.authorize(req); err != nil {
+		return err
+	}
+
+	resp, err := c.do("put_manifest", req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != 201 {
+		return statusError("put_manifest", resp, "failed to put manifest")
+	}
+
+	return nil
+}
+
+// BlobExists reports whether the repository already holds a blob.
+func (c *OciClient) BlobExists(ctx context.Context, opts BlobExistsOptions) (bool, error) {
+	if err := validate.Struct(opts); err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", repositoryURL(opts.Tag, opts.Insecure)+"/blobs/"+opts.Digest.String(), nil)
+	if err != nil {
+		return false, err
+	}
+	if err := c.authorize(req); err != nil {
+		return false, err
+	}
+
+	resp, err := c.do("blob_exists", req)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case 200:
+		return true, nil
+	case 404:
+		return false, nil
+	default:
+		return false, statusError("blob_exists", resp, "failed to check blob")
+	}
+}
+
+func repositoryURL(tag Tag, insecure bool) string {
+	protocol := "https"
+	if insecure {
+		protocol = "http"
+	}
+
+	return fmt.Sprintf("%s://%s/v2/%s", protocol, tag.Host, tag.NamespacedName())
+}
@@ -0,0 +1,33 @@
+package oci
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/eunanio/sdk/pkg/httpx"
+)
+
+var (
+	sharedOnce   sync.Once
+	sharedClient *httpx.Client
+)
+
+// httpClient returns the client built on the transport of c, or else the
+// shared registry client, so connections are reused between requests.
+func (c *OciClient) httpClient() *httpx.Client {
+	if c.transport == nil {
+		sharedOnce.Do(func() { sharedClient = httpx.New() })
+		return sharedClient
+	}
+
+	c.clientOnce.Do(func() { c.client = httpx.New(httpx.WithTransport(c.transport)) })
+	return c.client
+}
+
+// drain reads and closes the body of resp, when its status and headers are
+// all that is needed, so the connection can be reused.
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
@@ -1,8 +1,8 @@
 package oci
 
 type Tag struct {
-	Host      string
-	Name      string
+	Host      string `validate:"required"`
+	Name      string `validate:"required"`
 	Namespace string
 	Version   string
 }
@@ -0,0 +1,206 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/eunanio/sdk/pkg/jsonutil"
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+type Format string
+
+const (
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	Table Format = "table"
+	// Wide is a table that also shows columns marked Wide.
+	Wide Format = "wide"
+)
+
+// Formats lists the accepted values of an -o flag.
+var Formats = []Format{Table, Wide, JSON, YAML}
+
+// ParseFormat parses an -o flag value; an empty value is Table.
+func ParseFormat(s string) (Format, error) {
+	if s == "" {
+		return Table, nil
+	}
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(s) {
+			return format, nil
+		}
+	}
+
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return "", log.Errorf(log.CodeInvalidArgument, "output", "unknown output format %q, expected one of %s", s, strings.Join(names, ", "))
+}
+
+// Column is one table column.
+type Column[T any] struct {
+	Header string
+	Value  func(item T) string
+	// Wide columns are only shown in the wide format.
+	Wide bool
+}
+
+// Definition describes how to print a type as a table. JSON and YAML use
+// the type's json field names. Declare it once per type, e.g.
+//
+//	var tagColumns = output.Define(
+//		output.Column[Tag]{Header: "TAG", Value: func(t Tag) string { return t.Name }},
+//	)
+type Definition[T any] struct {
+	columns []Column[T]
+}
+
+func Define[T any](columns ...Column[T]) *Definition[T] {
+	return &Definition[T]{columns: columns}
+}
+
+// Render prints items as a list in format.
+func (d *Definition[T]) Render(w io.Writer, format Format, items []T) error {
+	stream := d.Stream(w, format)
+	for _, item := range items {
+		if err := stream.Write(item); err != nil {
+			return err
+		}
+	}
+	return stream.Close()
+}
+
+// RenderOne prints a single item: an object rather than a list for JSON and
+// YAML, and a one-row table otherwise.
+func (d *Definition[T]) RenderOne(w io.Writer, format Format, item T) error {
+	switch format {
+	case JSON, YAML:
+		data, err := encode(item, format)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	return d.Render(w, format, []T{item})
+}
+
+// tableFlushRows bounds how many rows are buffered to align a table. Column
+// widths are recomputed for each batch, so very long lists stay responsive
+// at the cost of occasionally shifting columns.
+const tableFlushRows = 100
+
+// Stream prints items one at a time, so long lists are not held in memory.
+// Close must be called to finish the output.
+type Stream[T any] struct {
+	w       io.Writer
+	format  Format
+	columns []Column[T]
+	tw      *tabwriter.Writer
+	rows    int
+}
+
+func (d *Definition[T]) Stream(w io.Writer, format Format) *Stream[T] {
+	s := &Stream[T]{w: w, format: format}
+	if format == Table || format == Wide {
+		s.tw = tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		for _, column := range d.columns {
+			if !column.Wide || format == Wide {
+				s.columns = append(s.columns, column)
+			}
+		}
+	}
+	return s
+}
+
+func (s *Stream[T]) Write(item T) error {
+	switch s.format {
+	case JSON:
+		data, err := encode(item, JSON)
+		if err != nil {
+			return err
+		}
+		prefix := ",\n"
+		if s.rows == 0 {
+			prefix = "[\n"
+		}
+		s.rows++
+		_, err = fmt.Fprintf(s.w, "%s  %s", prefix, bytes.ReplaceAll(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"), []byte("\n  ")))
+		return err
+	case YAML:
+		data, err := encode(item, YAML)
+		if err != nil {
+			return err
+		}
+		s.rows++
+		_, err = fmt.Fprintf(s.w, "- %s\n", bytes.ReplaceAll(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"), []byte("\n  ")))
+		return err
+	case Table, Wide:
+		if s.rows%tableFlushRows == 0 {
+			if err := s.tw.Flush(); err != nil {
+				return err
+			}
+			if s.rows == 0 {
+				s.writeRow(func(c Column[T]) string { return c.Header })
+			}
+		}
+		s.rows++
+		s.writeRow(func(c Column[T]) string { return c.Value(item) })
+		return nil
+	}
+
+	return log.Errorf(log.CodeInvalidArgument, "output", "unknown output format %q", s.format)
+}
+
+func (s *Stream[T]) writeRow(cell func(Column[T]) string) {
+	values := make([]string, len(s.columns))
+	for i, column := range s.columns {
+		values[i] = strings.ReplaceAll(cell(column), "\t", " ")
+	}
+	fmt.Fprintln(s.tw, strings.Join(values, "\t"))
+}
+
+// Close ends the list. An empty list prints "[]" for JSON and YAML and only
+// the header for tables.
+func (s *Stream[T]) Close() error {
+	switch s.format {
+	case JSON:
+		if s.rows == 0 {
+			_, err := io.WriteString(s.w, "[]\n")
+			return err
+		}
+		_, err := io.WriteString(s.w, "\n]\n")
+		return err
+	case YAML:
+		if s.rows == 0 {
+			_, err := io.WriteString(s.w, "[]\n")
+			return err
+		}
+	case Table, Wide:
+		if s.rows == 0 {
+			s.writeRow(func(c Column[T]) string { return c.Header })
+		}
+		return s.tw.Flush()
+	}
+	return nil
+}
+
+func encode(item any, format Format) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(item); err != nil {
+		return nil, fmt.Errorf("failed to encode output: %w", err)
+	}
+	if format == YAML {
+		return jsonutil.JSONToYAML(buf.Bytes())
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,125 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+type tag struct {
+	Name   string `json:"name"`
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+}
+
+var tagColumns = Define(
+	Column[tag]{Header: "TAG", Value: func(t tag) string { return t.Name }},
+	Column[tag]{Header: "SIZE", Value: func(t tag) string { return fmt.Sprint(t.Size) }},
+	Column[tag]{Header: "DIGEST", Value: func(t tag) string { return t.Digest }, Wide: true},
+)
+
+func TestRender(t *testing.T) {
+	tags := []tag{{Name: "v1", Digest: "sha256:aa", Size: 10}, {Name: "latest", Digest: "sha256:bb", Size: 2048}}
+
+	tests := []struct {
+		name     string
+		format   Format
+		items    []tag
+		expected string
+	}{
+		{
+			name:     "Table",
+			format:   Table,
+			items:    tags,
+			expected: "TAG      SIZE\nv1       10\nlatest   2048\n",
+		},
+		{
+			name:     "Wide",
+			format:   Wide,
+			items:    tags,
+			expected: "TAG      SIZE   DIGEST\nv1       10     sha256:aa\nlatest   2048   sha256:bb\n",
+		},
+		{
+			name:     "JSON",
+			format:   JSON,
+			items:    tags[:1],
+			expected: "[\n  {\n    \"name\": \"v1\",\n    \"digest\": \"sha256:aa\",\n    \"size\": 10\n  }\n]\n",
+		},
+		{
+			name:     "YAML",
+			format:   YAML,
+			items:    tags,
+			expected: "- name: v1\n  digest: sha256:aa\n  size: 10\n- name: latest\n  digest: sha256:bb\n  size: 2048\n",
+		},
+		{
+			name:     "Empty JSON",
+			format:   JSON,
+			expected: "[]\n",
+		},
+		{
+			name:     "Empty table",
+			format:   Table,
+			expected: "TAG   SIZE\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tagColumns.Render(&buf, tt.format, tt.items); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestRenderOne(t *testing.T) {
+	var buf bytes.Buffer
+	if err := tagColumns.RenderOne(&buf, YAML, tag{Name: "v1", Digest: "sha256:aa"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	expected := "name: v1\ndigest: sha256:aa\nsize: 0\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestStreamLongTable(t *testing.T) {
+	var buf bytes.Buffer
+	stream := tagColumns.Stream(&buf, Table)
+	for i := range tableFlushRows + 1 {
+		if err := stream.Write(tag{Name: fmt.Sprint("v", i)}); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+	if buf.Len() == 0 {
+		t.Error("Expected rows to be written before Close")
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != tableFlushRows+2 || !strings.HasPrefix(lines[0], "TAG") {
+		t.Errorf("Expected a header and %d rows, got %d lines", tableFlushRows+1, len(lines))
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if format, err := ParseFormat(""); err != nil || format != Table {
+		t.Errorf("Expected table by default, got: %v, %v", format, err)
+	}
+	if format, err := ParseFormat("JSON"); err != nil || format != JSON {
+		t.Errorf("Expected json, got: %v, %v", format, err)
+	}
+	if _, err := ParseFormat("xml"); !log.IsCode(err, log.CodeInvalidArgument) {
+		t.Errorf("Expected an invalid argument error, got: %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	devexec "github.com/eunanio/sdk/pkg/exec"
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/validate"
+)
+
+const (
+	// EnvHandshake is set to "1" when a plugin is asked for its metadata. The
+	// plugin should print a Metadata JSON object to stdout and exit 0.
+	EnvHandshake = "DEVKIT_PLUGIN_HANDSHAKE"
+	// EnvContext holds the Invocation JSON when a plugin is run.
+	EnvContext = "DEVKIT_PLUGIN_CONTEXT"
+
+	handshakeTimeout = 5 * time.Second
+)
+
+type Metadata struct {
+	Name        string `json:"name"`
+	Version     string `json:"version" validate:"semver"`
+	Description string `json:"description"`
+	Usage       string `json:"usage,omitempty"`
+}
+
+type Plugin struct {
+	Name string
+	Path string
+}
+
+// Invocation is passed to a plugin in EnvContext so it does not need to
+// parse the host tool's flags or configuration.
+type Invocation struct {
+	Tool    string            `json:"tool"`
+	Plugin  string            `json:"plugin"`
+	Args    []string          `json:"args"`
+	Config  map[string]string `json:"config,omitempty"`
+	WorkDir string            `json:"work_dir"`
+}
+
+type Manager struct {
+	// Tool is the prefix of plugin executables: plugins for "devkit" are
+	// named devkit-<plugin>.
+	Tool string
+	// Dirs are searched before PATH.
+	Dirs []string
+	// Config is passed to every plugin in its Invocation.
+	Config map[string]string
+	Cmd    devexec.Cmd
+}
+
+// NewManager searches dirs and then PATH for plugins of tool. With no dirs
+// the user config directory's <tool>/plugins is used.
+func NewManager(tool string, dirs ...string) *Manager {
+	if len(dirs) == 0 {
+		if config, err := os.UserConfigDir(); err == nil {
+			dirs = []string{filepath.Join(config, tool, "plugins")}
+		}
+	}
+
+	return &Manager{Tool: tool, Dirs: dirs}
+}
+
+// Discover lists plugins sorted by name. When the same plugin exists in
+// several directories the first one found wins, as with PATH lookup.
+func (m *Manager) Discover() ([]Plugin, error) {
+	dirs := append(append([]string{}, m.Dirs...), filepath.SplitList(os.Getenv("PATH"))...)
+	prefix := m.Tool + "-"
+
+	seen := map[string]bool{}
+	var plugins []Plugin
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name(), prefix)
+			if !ok || seen[name] {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+
+			seen[name] = true
+			plugins = append(plugins, Plugin{Name: name, Path: path})
+		}
+	}
+
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, nil
+}
+
+func (m *Manager) Find(name string) (*Plugin, error) {
+	plugins, err := m.Discover()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range plugins {
+		if p.Name == name {
+			return &p, nil
+		}
+	}
+
+	return nil, log.Errorf(log.CodeNotFound, "plugin_find", "plugin %s not found, expected an executable named %s-%s", name, m.Tool, name)
+}
+
+// Describe runs the plugin handshake and returns its metadata.
+func (m *Manager) Describe(ctx context.Context, p Plugin) (*Metadata, error) {
+	var stdout bytes.Buffer
+	err := m.Cmd.ExecuteWithStreamContext(ctx, devexec.CmdArgs{
+		Run:     p.Path,
+		Env:     []string{EnvHandshake + "=1"},
+		Stdout:  &stdout,
+		Stderr:  &bytes.Buffer{},
+		Timeout: handshakeTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s handshake failed: %w", p.Name, err)
+	}
+
+	meta := &Metadata{}
+	if err := json.Unmarshal(stdout.Bytes(), meta); err != nil {
+		return nil, log.Errorf(log.CodeInvalidArgument, "plugin_describe", "plugin %s returned invalid metadata: %s", p.Name, err)
+	}
+	if meta.Name == "" {
+		meta.Name = p.Name
+	}
+	if err := validate.Struct(meta); err != nil {
+		return nil, fmt.Errorf("plugin %s returned invalid metadata: %w", p.Name, err)
+	}
+
+	return meta, nil
+}
+
+// Run executes the named plugin with args, connected to the current
+// terminal streams. The plugin receives its Invocation in EnvContext and
+// DEVKIT_PLUGIN_NAME.
+func (m *Manager) Run(ctx context.Context, name string, args []string) error {
+	p, err := m.Find(name)
+	if err != nil {
+		return err
+	}
+
+	wd, _ := os.Getwd()
+	invocation, err := json.Marshal(Invocation{
+		Tool:    m.Tool,
+		Plugin:  p.Name,
+		Args:    args,
+		Config:  m.Config,
+		WorkDir: wd,
+	})
+	if err != nil {
+		return err
+	}
+
+	return m.Cmd.ExecuteWithStreamContext(ctx, devexec.CmdArgs{
+		Run:   p.Path,
+		Args:  args,
+		Env:   []string{EnvContext + "=" + string(invocation), "DEVKIT_PLUGIN_NAME=" + p.Name},
+		Stdin: os.Stdin,
+	})
+}
+
+// ReadInvocation is called by a plugin to read the context passed by Run.
+func ReadInvocation() (*Invocation, error) {
+	data := os.Getenv(EnvContext)
+	if data == "" {
+		return nil, log.Errorf(log.CodeInvalidArgument, "plugin_invocation", "%s is not set, the plugin was not started by its host tool", EnvContext)
+	}
+
+	invocation := &Invocation{}
+	if err := json.Unmarshal([]byte(data), invocation); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", EnvContext, err)
+	}
+
+	return invocation, nil
+}
+
+// IsHandshake reports whether the plugin was started to describe itself.
+func IsHandshake() bool {
+	return os.Getenv(EnvHandshake) == "1"
+}
+
+// pluginName strips prefix and, on Windows, an executable extension from a
+// file name.
+func pluginName(file, prefix string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(file))
+		if !isWindowsExecutableExt(ext) {
+			return "", false
+		}
+		file = strings.TrimSuffix(file, filepath.Ext(file))
+	}
+
+	name, ok := strings.CutPrefix(file, prefix)
+	return name, ok && name != ""
+}
+
+func isWindowsExecutableExt(ext string) bool {
+	pathext := os.Getenv("PATHEXT")
+	if pathext == "" {
+		pathext = ".COM;.EXE;.BAT;.CMD"
+	}
+
+	for _, e := range filepath.SplitList(pathext) {
+		if strings.EqualFold(e, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
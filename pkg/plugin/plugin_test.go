@@ -0,0 +1,84 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+const script = `#!/bin/sh
+if [ "$DEVKIT_PLUGIN_HANDSHAKE" = "1" ]; then
+  echo '{"name":"hello","version":"1.0.0","description":"Says hello"}'
+  exit 0
+fi
+echo "$DEVKIT_PLUGIN_CONTEXT" > "${0%/*}/context.json"
+`
+
+func writePlugin(t *testing.T, dir, name, content string, mode os.FileMode) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+
+	first, second := t.TempDir(), t.TempDir()
+	writePlugin(t, first, "devkit-hello", script, 0755)
+	writePlugin(t, second, "devkit-hello", "#!/bin/sh\nexit 1\n", 0755)
+	writePlugin(t, second, "devkit-build", "#!/bin/sh\n", 0755)
+	writePlugin(t, second, "devkit-notes", "not executable", 0644)
+	writePlugin(t, second, "other-tool", "#!/bin/sh\n", 0755)
+	t.Setenv("PATH", second)
+
+	m := NewManager("devkit", first)
+	m.Config = map[string]string{"registry": "registry.example.com"}
+
+	plugins, err := m.Discover()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range plugins {
+		names = append(names, p.Name)
+	}
+	if strings.Join(names, ",") != "build,hello" {
+		t.Fatalf("Expected plugins build,hello, got: %v", names)
+	}
+	if plugins[1].Path != filepath.Join(first, "devkit-hello") {
+		t.Errorf("Expected plugin dirs to take precedence over PATH, got: %s", plugins[1].Path)
+	}
+
+	meta, err := m.Describe(context.Background(), plugins[1])
+	if err != nil || meta.Version != "1.0.0" || meta.Description != "Says hello" {
+		t.Errorf("Unexpected metadata: %+v %v", meta, err)
+	}
+
+	if err := m.Run(context.Background(), "hello", []string{"--name", "world"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(first, "context.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvContext, string(data))
+	invocation, err := ReadInvocation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invocation.Plugin != "hello" || strings.Join(invocation.Args, " ") != "--name world" || invocation.Config["registry"] != "registry.example.com" {
+		t.Errorf("Unexpected invocation: %+v", invocation)
+	}
+
+	if err := m.Run(context.Background(), "missing", nil); !log.IsCode(err, log.CodeNotFound) {
+		t.Errorf("Expected %s error, got: %v", log.CodeNotFound, err)
+	}
+}
@@ -0,0 +1,141 @@
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+// Group runs tasks with bounded concurrency. The first task to fail cancels
+// the group's context and its error is returned by Wait.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	sem    chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+}
+
+// New returns a group running at most limit tasks at once, or any number
+// when limit is zero or negative, and the context passed to its tasks.
+func New(ctx context.Context, limit int) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	g := &Group{ctx: ctx, cancel: cancel}
+	if limit > 0 {
+		g.sem = make(chan struct{}, limit)
+	}
+
+	return g, ctx
+}
+
+// Go runs fn once a slot is free. It blocks while the group is at its limit
+// and skips fn, recording the context error, once the group is cancelled.
+func (g *Group) Go(fn func(ctx context.Context) error) {
+	if g.ctx.Err() != nil {
+		g.fail(context.Cause(g.ctx))
+		return
+	}
+
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		case <-g.ctx.Done():
+			g.fail(context.Cause(g.ctx))
+			return
+		}
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+
+		if err := fn(g.ctx); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+// Wait blocks until every started task returns and reports the first error.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(nil)
+	return g.err
+}
+
+func (g *Group) fail(err error) {
+	g.once.Do(func() {
+		g.err = err
+		g.cancel(err)
+	})
+}
+
+// Map calls fn for every item with at most limit calls running at once and
+// returns the results in item order. The first error cancels the remaining
+// calls and is returned.
+func Map[T, R any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	g, ctx := New(ctx, limit)
+	for i, item := range items {
+		g.Go(func(ctx context.Context) error {
+			result, err := fn(ctx, item)
+			results[i] = result
+			return err
+		})
+	}
+
+	return results, g.Wait()
+}
+
+// MapAll is like Map but runs every item regardless of failures, returning
+// each item's error at its index. Items not started because ctx was done get
+// ctx's error.
+func MapAll[T, R any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) (R, error)) ([]R, []error) {
+	if limit <= 0 || limit > len(items) {
+		limit = len(items)
+	}
+
+	results := make([]R, len(items))
+	errs := make([]error, len(items))
+	sem := make(chan struct{}, max(limit, 1))
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = fn(ctx, item)
+		}()
+	}
+	wg.Wait()
+
+	return results, errs
+}
+
+// ForEach calls fn for every item with at most limit calls at once, stopping
+// at the first error.
+func ForEach[T any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) error) error {
+	g, ctx := New(ctx, limit)
+	for _, item := range items {
+		g.Go(func(ctx context.Context) error {
+			return fn(ctx, item)
+		})
+	}
+
+	return g.Wait()
+}
@@ -0,0 +1,102 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMap(t *testing.T) {
+	var running, peak atomic.Int32
+	results, err := Map(context.Background(), []int{1, 2, 3, 4, 5, 6}, 2, func(ctx context.Context, n int) (int, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if current <= p || peak.CompareAndSwap(p, current) {
+				break
+			}
+		}
+		time.Sleep(time.Duration(7-n) * time.Millisecond)
+		return n * n, nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for i, expected := range []int{1, 4, 9, 16, 25, 36} {
+		if results[i] != expected {
+			t.Errorf("Expected results in order, got: %v", results)
+			break
+		}
+	}
+	if peak.Load() > 2 {
+		t.Errorf("Expected at most 2 concurrent calls, got: %d", peak.Load())
+	}
+}
+
+func TestMapFailFast(t *testing.T) {
+	failure := errors.New("failed")
+	var calls atomic.Int32
+
+	_, err := Map(context.Background(), []int{1, 2, 3, 4, 5}, 1, func(ctx context.Context, n int) (int, error) {
+		calls.Add(1)
+		if n == 2 {
+			return 0, failure
+		}
+		return n, nil
+	})
+
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected %v, got: %v", failure, err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Expected remaining items to be skipped, got %d calls", calls.Load())
+	}
+}
+
+func TestMapAll(t *testing.T) {
+	failure := errors.New("failed")
+	results, errs := MapAll(context.Background(), []int{1, 2, 3}, 2, func(ctx context.Context, n int) (int, error) {
+		if n == 2 {
+			return 0, failure
+		}
+		return n * 10, nil
+	})
+
+	if results[0] != 10 || results[2] != 30 {
+		t.Errorf("Expected every item to run, got: %v", results)
+	}
+	if errs[0] != nil || !errors.Is(errs[1], failure) || errs[2] != nil {
+		t.Errorf("Expected an error only for the second item, got: %v", errs)
+	}
+}
+
+func TestGroupCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g, _ := New(ctx, 1)
+
+	started := make(chan struct{})
+	g.Go(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+	cancel()
+
+	var ran atomic.Bool
+	g.Go(func(ctx context.Context) error {
+		ran.Store(true)
+		return nil
+	})
+
+	if err := g.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, got: %v", context.Canceled, err)
+	}
+	if ran.Load() {
+		t.Error("Expected tasks queued after cancellation to be skipped")
+	}
+}
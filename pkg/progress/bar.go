@@ -0,0 +1,131 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/style"
+)
+
+const (
+	barWidth       = 30
+	redrawInterval = 100 * time.Millisecond
+)
+
+// Bar renders byte based progress. Update matches the progress callbacks of
+// the oci client, and Bar is an io.Writer so it can count bytes through an
+// io.TeeReader or io.MultiWriter. When stderr is not a terminal, a plain
+// line is printed every 10% or few seconds instead of redrawing.
+type Bar struct {
+	w        io.Writer
+	tty      bool
+	label    string
+	mu       sync.Mutex
+	current  int64
+	total    int64
+	lastDraw time.Time
+	lastStep int64
+	lastLine string
+	finished bool
+}
+
+func NewBar(label string, total int64) *Bar {
+	return NewBarWriter(os.Stderr, label, total)
+}
+
+func NewBarWriter(w io.Writer, label string, total int64) *Bar {
+	return &Bar{w: w, tty: isTerminal(w), label: label, total: total, lastStep: -1}
+}
+
+// Update sets the transferred and total byte counts. A total of zero or less
+// keeps the previous total.
+func (b *Bar) Update(current, total int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.current = current
+	if total > 0 {
+		b.total = total
+	}
+	b.draw(false)
+}
+
+func (b *Bar) Add(n int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.current += n
+	b.draw(false)
+}
+
+func (b *Bar) Write(p []byte) (int, error) {
+	b.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// Finish draws the final state and ends the line.
+func (b *Bar) Finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.finished {
+		return
+	}
+	b.finished = true
+	b.draw(true)
+	if b.tty {
+		fmt.Fprintln(b.w)
+	}
+}
+
+func (b *Bar) draw(force bool) {
+	if b.finished && !force {
+		return
+	}
+
+	now := time.Now()
+	if b.tty {
+		if !force && now.Sub(b.lastDraw) < redrawInterval {
+			return
+		}
+		b.lastDraw = now
+		fmt.Fprintf(b.w, "\r\x1b[2K%s", b.line())
+		return
+	}
+
+	step := int64(-1)
+	if b.total > 0 {
+		step = b.current * 10 / b.total
+	}
+
+	if force || (step >= 0 && step != b.lastStep) || now.Sub(b.lastDraw) >= plainInterval {
+		line := b.line()
+		if line == b.lastLine {
+			return
+		}
+		b.lastStep = step
+		b.lastDraw = now
+		b.lastLine = line
+		fmt.Fprintln(b.w, line)
+	}
+}
+
+func (b *Bar) line() string {
+	if b.total <= 0 {
+		return fmt.Sprintf("%s %s", b.label, style.FormatBytes(uint64(b.current)))
+	}
+
+	ratio := float64(b.current) / float64(b.total)
+	ratio = min(max(ratio, 0), 1)
+	filled := int(ratio * barWidth)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+	if filled > 0 && filled < barWidth {
+		bar = bar[:filled-1] + ">" + bar[filled:]
+	}
+
+	return fmt.Sprintf("%s [%s] %3.0f%% %s/%s", b.label, bar, ratio*100, style.FormatBytes(uint64(b.current)), style.FormatBytes(uint64(b.total)))
+}
@@ -0,0 +1,56 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBarPlainOutput(t *testing.T) {
+	tests := []struct {
+		name          string
+		total         int64
+		updates       []int64
+		expectedLines []string
+	}{
+		{
+			name:    "Prints on every 10 percent step",
+			total:   100,
+			updates: []int64{1, 5, 10, 12, 55, 100},
+			expectedLines: []string{
+				"push [                              ]   1% 1 B/100 B",
+				"push [==>                           ]  10% 10 B/100 B",
+				"push [===============>              ]  55% 55 B/100 B",
+				"push [==============================] 100% 100 B/100 B",
+			},
+		},
+		{
+			name:          "Unknown total",
+			total:         0,
+			updates:       []int64{2048},
+			expectedLines: []string{"push 2.0 KiB"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			bar := NewBarWriter(&buf, "push", tt.total)
+			for _, current := range tt.updates {
+				bar.Update(current, 0)
+			}
+			bar.Finish()
+
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if len(lines) != len(tt.expectedLines) {
+				t.Fatalf("Expected %d lines, got: %q", len(tt.expectedLines), lines)
+			}
+			for i, line := range lines {
+				if line != tt.expectedLines[i] {
+					t.Errorf("Line %d: expected %q, got: %q", i, tt.expectedLines[i], line)
+				}
+			}
+		})
+	}
+}
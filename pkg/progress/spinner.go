@@ -0,0 +1,105 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const (
+	spinnerInterval = 100 * time.Millisecond
+	// plainInterval is how often status lines are repeated when output is
+	// not a terminal.
+	plainInterval = 10 * time.Second
+)
+
+// Spinner shows an animated indicator for work of unknown length. When
+// stderr is not a terminal it prints the message with the elapsed time
+// every few seconds instead.
+type Spinner struct {
+	w       io.Writer
+	tty     bool
+	mu      sync.Mutex
+	message string
+	start   time.Time
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func NewSpinner(message string) *Spinner {
+	return NewSpinnerWriter(os.Stderr, message)
+}
+
+func NewSpinnerWriter(w io.Writer, message string) *Spinner {
+	return &Spinner{w: w, tty: isTerminal(w), message: message}
+}
+
+func (s *Spinner) Start() {
+	s.start = time.Now()
+	s.done = make(chan struct{})
+
+	interval := spinnerInterval
+	if !s.tty {
+		interval = plainInterval
+		fmt.Fprintf(s.w, "%s...\n", s.getMessage())
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for frame := 0; ; frame++ {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				s.render(frame)
+			}
+		}
+	}()
+}
+
+func (s *Spinner) SetMessage(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.message = message
+}
+
+// Stop halts the spinner and prints final in its place, if not empty.
+func (s *Spinner) Stop(final string) {
+	if s.done != nil {
+		close(s.done)
+		s.wg.Wait()
+		s.done = nil
+	}
+
+	if s.tty {
+		fmt.Fprint(s.w, "\r\x1b[2K")
+	}
+
+	if final != "" {
+		fmt.Fprintln(s.w, final)
+	}
+}
+
+func (s *Spinner) render(frame int) {
+	message := s.getMessage()
+	if s.tty {
+		fmt.Fprintf(s.w, "\r\x1b[2K%s %s", spinnerFrames[frame%len(spinnerFrames)], message)
+		return
+	}
+
+	fmt.Fprintf(s.w, "%s... (%s)\n", message, time.Since(s.start).Round(time.Second))
+}
+
+func (s *Spinner) getMessage() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.message
+}
@@ -0,0 +1,280 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/cache"
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/oci"
+	"github.com/eunanio/sdk/pkg/validate"
+	"github.com/opencontainers/go-digest"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultManifestTTL is how long a tag is served from the cache before it
+// is resolved upstream again.
+const DefaultManifestTTL = 5 * time.Minute
+
+// Server is a read-only registry serving the distribution API for pulls.
+// Manifests and blobs are served from Cache, and misses are fetched from
+// Upstream and cached. Tags are revalidated after ManifestTTL; when
+// Upstream cannot be reached the cached manifest is served, so pulls keep
+// working offline.
+type Server struct {
+	// Upstream is the registry host misses are fetched from.
+	Upstream string       `validate:"required"`
+	Cache    *cache.Cache `validate:"required"`
+	Insecure bool
+	// Client fetches from Upstream with its credentials and rate limits.
+	Client *oci.OciClient
+	// ManifestTTL is DefaultManifestTTL when zero.
+	ManifestTTL time.Duration
+
+	group singleflight.Group
+	now   func() time.Time
+}
+
+// Serve runs the proxy on addr until ctx is done.
+func (s *Server) Serve(ctx context.Context, addr string) error {
+	if err := validate.Struct(s); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Component("proxy").Info("serving registry cache", "addr", listener.Addr().String(), "upstream", s.Upstream)
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", "the registry cache is read-only")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	switch {
+	case r.URL.Path == "/v2/" || r.URL.Path == "/v2":
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	case strings.Contains(path, "/manifests/"):
+		name, ref, _ := cut(path, "/manifests/")
+		s.serveManifest(w, r, name, ref)
+	case strings.Contains(path, "/blobs/"):
+		name, ref, _ := cut(path, "/blobs/")
+		s.serveBlob(w, r, name, ref)
+	default:
+		writeError(w, http.StatusNotFound, "UNSUPPORTED", "not supported by the registry cache")
+	}
+}
+
+// cut splits at the last sep, as repository names may contain it.
+func cut(s, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
+
+func (s *Server) client() *oci.OciClient {
+	if s.Client == nil {
+		s.Client = oci.NewOciClient()
+	}
+	return s.Client
+}
+
+func (s *Server) manifestTTL() time.Duration {
+	if s.ManifestTTL <= 0 {
+		return DefaultManifestTTL
+	}
+	return s.ManifestTTL
+}
+
+func (s *Server) clock() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+	return s.now()
+}
+
+func (s *Server) upstream(name, version string) oci.Tag {
+	namespace, repo, ok := cut(name, "/")
+	if !ok {
+		namespace, repo = "", name
+	}
+	return oci.Tag{Host: s.Upstream, Namespace: namespace, Name: repo, Version: version}
+}
+
+type manifest struct {
+	data      []byte
+	mediaType string
+	digest    digest.Digest
+}
+
+func (s *Server) serveManifest(w http.ResponseWriter, r *http.Request, name, ref string) {
+	m, err := s.manifest(r.Context(), name, ref)
+	if err != nil {
+		s.fail(w, "MANIFEST_UNKNOWN", name+":"+ref, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", m.mediaType)
+	w.Header().Set("Docker-Content-Digest", m.digest.String())
+	w.Header().Set("Content-Length", strconv.Itoa(len(m.data)))
+	if r.Method == http.MethodGet {
+		w.Write(m.data)
+	}
+}
+
+// manifest returns a manifest by digest from the cache, fetching it on a
+// miss, or by tag, revalidating it once it is older than ManifestTTL.
+func (s *Server) manifest(ctx context.Context, name, ref string) (*manifest, error) {
+	byDigest := digest.Digest(ref).Validate() == nil
+	key := "manifest/" + name + ":" + ref
+	if byDigest {
+		key = "manifest/" + name + "@" + ref
+	}
+
+	data, entry, err := s.Cache.Get(key)
+	if err == nil && (byDigest || s.clock().Sub(entry.Created) < s.manifestTTL()) {
+		return &manifest{data: data, mediaType: entry.Metadata["media_type"], digest: entry.Digest}, nil
+	}
+
+	// Concurrent misses share one fetch, which outlives a caller that
+	// disconnects so the others still get the manifest.
+	v, err, _ := s.group.Do(key, func() (any, error) {
+		return s.fetchManifest(context.WithoutCancel(ctx), name, ref, key)
+	})
+	if err == nil {
+		return v.(*manifest), nil
+	}
+	if entry != nil && !log.IsCode(err, log.CodeNotFound) {
+		log.Component("proxy").Warn("serving cached manifest, upstream failed", "manifest", key, log.KeyError, err.Error())
+		return &manifest{data: data, mediaType: entry.Metadata["media_type"], digest: entry.Digest}, nil
+	}
+	return nil, err
+}
+
+func (s *Server) fetchManifest(ctx context.Context, name, ref, key string) (*manifest, error) {
+	data, desc, err := s.client().FetchManifest(ctx, oci.ManifestOptions{Tag: s.upstream(name, ref), Insecure: s.Insecure})
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType := desc.MediaType
+	if mediaType == "" {
+		var probe struct {
+			MediaType string `json:"mediaType"`
+		}
+		json.Unmarshal(data, &probe)
+		mediaType = probe.MediaType
+	}
+	if mediaType == "" {
+		mediaType = spec.MediaTypeImageManifest
+	}
+
+	metadata := map[string]string{"media_type": mediaType}
+	for _, k := range []string{key, "manifest/" + name + "@" + desc.Digest.String()} {
+		if _, err := s.Cache.Put(k, bytes.NewReader(data), metadata); err != nil {
+			return nil, fmt.Errorf("failed to cache manifest: %w", err)
+		}
+	}
+
+	log.Component("proxy").Debug("cached manifest", "manifest", key, "digest", desc.Digest.String())
+	return &manifest{data: data, mediaType: mediaType, digest: desc.Digest}, nil
+}
+
+func (s *Server) serveBlob(w http.ResponseWriter, r *http.Request, name, ref string) {
+	d, err := digest.Parse(ref)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "DIGEST_INVALID", "invalid digest")
+		return
+	}
+
+	key := "blob/" + d.String()
+	f, entry, err := s.Cache.Open(key)
+	if errors.Is(err, cache.ErrNotFound) {
+		_, err, _ = s.group.Do(key, func() (any, error) {
+			return nil, s.fetchBlob(context.WithoutCancel(r.Context()), name, d, key)
+		})
+		if err == nil {
+			f, entry, err = s.Cache.Open(key)
+		}
+	}
+	if err != nil {
+		s.fail(w, "BLOB_UNKNOWN", name+"@"+ref, err)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Docker-Content-Digest", d.String())
+	w.Header().Set("Content-Length", strconv.FormatInt(entry.Size, 10))
+	if r.Method == http.MethodGet {
+		io.Copy(w, f)
+	}
+}
+
+func (s *Server) fetchBlob(ctx context.Context, name string, d digest.Digest, key string) error {
+	tag := s.upstream(name, "")
+	data, err := s.client().PullBlobContext(ctx, oci.PullBlobOptions{Digest: spec.Descriptor{Digest: d}, Name: tag.Name, Tag: &tag, Insecure: s.Insecure})
+	if err != nil {
+		return err
+	}
+	if got := d.Algorithm().FromBytes(data); got != d {
+		return log.Errorf(log.CodeRemote, "proxy_fetch_blob", "blob digest mismatch: expected %s, got %s", d, got)
+	}
+
+	if _, err := s.Cache.Put(key, bytes.NewReader(data), nil); err != nil {
+		return fmt.Errorf("failed to cache blob: %w", err)
+	}
+
+	log.Component("proxy").Debug("cached blob", "digest", d.String(), "bytes", len(data))
+	return nil
+}
+
+func (s *Server) fail(w http.ResponseWriter, code, ref string, err error) {
+	if log.IsCode(err, log.CodeNotFound) {
+		writeError(w, http.StatusNotFound, code, ref+" not found")
+		return
+	}
+
+	log.Component("proxy").Error("upstream fetch failed", "reference", ref, log.KeyError, err.Error())
+	writeError(w, http.StatusBadGateway, "UNAVAILABLE", err.Error())
+}
+
+// writeError writes an error in the distribution API format.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"errors": []map[string]string{{"code": code, "message": message}},
+	})
+}
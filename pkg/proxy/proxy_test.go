@@ -0,0 +1,156 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/cache"
+	"github.com/eunanio/sdk/pkg/fs"
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/oci"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestServer(t *testing.T) {
+	layer := []byte("layer content")
+	manifest, _ := json.Marshal(spec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: spec.MediaTypeImageManifest,
+		Layers:    []spec.Descriptor{{MediaType: spec.MediaTypeImageLayerGzip, Digest: digest.FromBytes(layer), Size: int64(len(layer))}},
+	})
+
+	var mu sync.Mutex
+	requests := map[string]int{}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/v2/library/app/manifests/v1":
+			w.Header().Set("Content-Type", spec.MediaTypeImageManifest)
+			w.Write(manifest)
+		case "/v2/library/app/blobs/" + digest.FromBytes(layer).String():
+			w.Write(layer)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer upstream.Close()
+
+	c, err := cache.New("/cache", cache.Options{FS: fs.NewMemFS()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	server := &Server{Upstream: strings.TrimPrefix(upstream.URL, "http://"), Cache: c, Insecure: true, now: func() time.Time { return now }}
+	proxy := httptest.NewServer(server)
+	defer proxy.Close()
+
+	client := oci.NewOciClient()
+	tag := oci.Tag{Host: strings.TrimPrefix(proxy.URL, "http://"), Namespace: "library", Name: "app", Version: "v1"}
+	pull := func() error {
+		data, desc, err := client.FetchManifest(context.Background(), oci.ManifestOptions{Tag: tag, Insecure: true})
+		if err != nil {
+			return err
+		}
+		if string(data) != string(manifest) || desc.Digest != digest.FromBytes(manifest) || desc.MediaType != spec.MediaTypeImageManifest {
+			t.Errorf("Expected the upstream manifest, got: %s %+v", data, desc)
+		}
+		blob, err := client.PullBlob(oci.PullBlobOptions{Digest: spec.Descriptor{Digest: digest.FromBytes(layer)}, Tag: &tag, Insecure: true})
+		if err != nil {
+			return err
+		}
+		if string(blob) != string(layer) {
+			t.Errorf("Expected the layer, got: %q", blob)
+		}
+		return nil
+	}
+
+	for i := range 2 {
+		if err := pull(); err != nil {
+			t.Fatalf("Expected pull %d to succeed, got: %v", i+1, err)
+		}
+	}
+	if len(requests) != 2 || requests["/v2/library/app/manifests/v1"] != 1 {
+		t.Errorf("Expected one upstream request per manifest and blob, got: %v", requests)
+	}
+
+	byDigest := tag
+	byDigest.Version = digest.FromBytes(manifest).String()
+	if _, err := client.ResolveManifest(context.Background(), oci.ManifestOptions{Tag: byDigest, Insecure: true}); err != nil {
+		t.Errorf("Expected the manifest to be cached by digest, got: %v", err)
+	}
+
+	now = now.Add(DefaultManifestTTL + time.Second)
+	if err := pull(); err != nil || requests["/v2/library/app/manifests/v1"] != 2 {
+		t.Errorf("Expected an expired tag to be revalidated, got: %v, %v", err, requests)
+	}
+
+	now = now.Add(DefaultManifestTTL + time.Second)
+	upstream.Close()
+	if err := pull(); err != nil {
+		t.Errorf("Expected the cached manifest to be served offline, got: %v", err)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/broken") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer upstream.Close()
+
+	c, err := cache.New("/cache", cache.Options{FS: fs.NewMemFS()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &Server{Upstream: strings.TrimPrefix(upstream.URL, "http://"), Cache: c, Insecure: true}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"ping", http.MethodGet, "/v2/", http.StatusOK, ""},
+		{"unknown manifest", http.MethodGet, "/v2/app/manifests/v1", http.StatusNotFound, "MANIFEST_UNKNOWN"},
+		{"upstream failure", http.MethodGet, "/v2/app/manifests/broken", http.StatusBadGateway, "UNAVAILABLE"},
+		{"unknown blob", http.MethodHead, "/v2/app/blobs/" + digest.FromString("x").String(), http.StatusNotFound, "BLOB_UNKNOWN"},
+		{"invalid digest", http.MethodGet, "/v2/app/blobs/sha256:nope", http.StatusBadRequest, "DIGEST_INVALID"},
+		{"push", http.MethodPut, "/v2/app/manifests/v1", http.StatusMethodNotAllowed, "UNSUPPORTED"},
+		{"catalog", http.MethodGet, "/v2/_catalog", http.StatusNotFound, "UNSUPPORTED"},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got: %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedCode != "" && !strings.Contains(w.Body.String(), `"code":"`+tt.expectedCode+`"`) {
+				t.Errorf("Expected error code %s, got: %s", tt.expectedCode, w.Body.String())
+			}
+			if w.Header().Get("Docker-Distribution-API-Version") != "registry/2.0" {
+				t.Error("Expected the API version header")
+			}
+		})
+	}
+
+	if err := (&Server{}).Serve(context.Background(), "127.0.0.1:0"); !log.IsCode(err, log.CodeInvalidArgument) {
+		t.Errorf("Expected missing upstream and cache to be rejected, got: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit allows RPS requests per second on average, with bursts of up to
+// Burst requests. An RPS of zero or less means no limit.
+type Limit struct {
+	RPS   float64
+	Burst int
+}
+
+// Limiter is a token bucket.
+type Limiter struct {
+	mu     sync.Mutex
+	limit  Limit
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewLimiter returns a limiter that starts with a full bucket. A burst
+// below one is treated as one.
+func NewLimiter(limit Limit) *Limiter {
+	limit.Burst = max(limit.Burst, 1)
+	return &Limiter{limit: limit, tokens: float64(limit.Burst), now: time.Now}
+}
+
+func (l *Limiter) unlimited() bool {
+	return l.limit.RPS <= 0
+}
+
+// advance refills the bucket for the time elapsed since the last call.
+func (l *Limiter) advance(now time.Time) {
+	if !l.last.IsZero() {
+		l.tokens = math.Min(float64(l.limit.Burst), l.tokens+now.Sub(l.last).Seconds()*l.limit.RPS)
+	}
+	l.last = now
+}
+
+// Allow takes a token if one is available without waiting.
+func (l *Limiter) Allow() bool {
+	if l.unlimited() {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.advance(l.now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Wait blocks until a token is available or ctx is done. The token is
+// returned to the bucket when ctx ends the wait.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l.unlimited() {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	l.advance(l.now())
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.limit.RPS * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Hosts keeps one limiter per host, implementing oci.RateLimiter.
+type Hosts struct {
+	mu       sync.Mutex
+	fallback Limit
+	limits   map[string]Limit
+	limiters map[string]*Limiter
+}
+
+// NewHosts limits every host to fallback unless Set gives it its own limit.
+func NewHosts(fallback Limit) *Hosts {
+	return &Hosts{fallback: fallback, limits: map[string]Limit{}, limiters: map[string]*Limiter{}}
+}
+
+// Set configures the limit of host, e.g. "registry-1.docker.io" or
+// "harbor.example.com:8443", replacing its current limiter.
+func (h *Hosts) Set(host string, limit Limit) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	host = strings.ToLower(host)
+	h.limits[host] = limit
+	delete(h.limiters, host)
+}
+
+func (h *Hosts) Limiter(host string) *Limiter {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	host = strings.ToLower(host)
+	if l, ok := h.limiters[host]; ok {
+		return l
+	}
+
+	limit, ok := h.limits[host]
+	if !ok {
+		limit = h.fallback
+	}
+	l := NewLimiter(limit)
+	h.limiters[host] = l
+	return l
+}
+
+// Wait blocks until a request to host is allowed or ctx is done.
+func (h *Hosts) Wait(ctx context.Context, host string) error {
+	return h.Limiter(host).Wait(ctx)
+}
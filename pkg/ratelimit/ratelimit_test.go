@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAllow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewLimiter(Limit{RPS: 2, Burst: 3})
+	l.now = func() time.Time { return now }
+
+	tests := []struct {
+		name     string
+		advance  time.Duration
+		expected []bool
+	}{
+		{name: "Burst", expected: []bool{true, true, true, false}},
+		{name: "Half a second refills one token", advance: 500 * time.Millisecond, expected: []bool{true, false}},
+		{name: "Refill is capped at burst", advance: time.Hour, expected: []bool{true, true, true, false}},
+	}
+
+	for _, tt := range tests {
+		now = now.Add(tt.advance)
+		for i, expected := range tt.expected {
+			if got := l.Allow(); got != expected {
+				t.Errorf("%s: expected request %d allowed: %v, got: %v", tt.name, i, expected, got)
+			}
+		}
+	}
+}
+
+func TestWait(t *testing.T) {
+	l := NewLimiter(Limit{RPS: 50, Burst: 1})
+	start := time.Now()
+	for range 3 {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Expected 3 requests at 50 rps to take about 40ms, took: %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	slow := NewLimiter(Limit{RPS: 0.1, Burst: 1})
+	slow.Wait(context.Background())
+	if err := slow.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to end with its context, got: %v", err)
+	}
+
+	if err := NewLimiter(Limit{}).Wait(context.Background()); err != nil {
+		t.Errorf("Expected no limit without RPS, got: %v", err)
+	}
+}
+
+func TestHosts(t *testing.T) {
+	h := NewHosts(Limit{RPS: 1, Burst: 1})
+	h.Set("Registry-1.Docker.io", Limit{RPS: 1, Burst: 2})
+
+	if !h.Limiter("ghcr.io").Allow() || h.Limiter("ghcr.io").Allow() {
+		t.Error("Expected ghcr.io to use the fallback burst of 1")
+	}
+	if !h.Limiter("quay.io").Allow() {
+		t.Error("Expected hosts to have separate buckets")
+	}
+	docker := h.Limiter("registry-1.docker.io")
+	if !docker.Allow() || !docker.Allow() || docker.Allow() {
+		t.Error("Expected registry-1.docker.io to use its own burst of 2")
+	}
+}
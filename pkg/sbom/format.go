@@ -0,0 +1,157 @@
+package sbom
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Media types of the documents, used as the artifactType of referrers.
+const (
+	CycloneDXMediaType = "application/vnd.cyclonedx+json"
+	SPDXMediaType      = "application/spdx+json"
+)
+
+// Metadata describes the subject of a document and how it was created.
+type Metadata struct {
+	// Name and Version identify the scanned project or image.
+	Name    string
+	Version string
+	// Tool is recorded as the creator, defaulting to "devkit".
+	Tool string
+	// Timestamp defaults to the current time.
+	Timestamp time.Time
+}
+
+func (m Metadata) tool() string {
+	if m.Tool == "" {
+		return "devkit"
+	}
+	return m.Tool
+}
+
+func (m Metadata) timestamp() string {
+	if m.Timestamp.IsZero() {
+		return time.Now().UTC().Format(time.RFC3339)
+	}
+	return m.Timestamp.UTC().Format(time.RFC3339)
+}
+
+type cdxComponent struct {
+	Type    string `json:"type"`
+	BOMRef  string `json:"bom-ref,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+}
+
+// CycloneDX returns a CycloneDX 1.5 JSON document listing components as
+// libraries of the subject described by meta.
+func CycloneDX(components []Component, meta Metadata) ([]byte, error) {
+	serial, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]cdxComponent, 0, len(components))
+	for _, c := range components {
+		list = append(list, cdxComponent{Type: "library", BOMRef: c.PURL, Name: c.Name, Version: c.Version, PURL: c.PURL})
+	}
+
+	doc := map[string]any{
+		"bomFormat":    "CycloneDX",
+		"specVersion":  "1.5",
+		"serialNumber": "urn:uuid:" + serial,
+		"version":      1,
+		"metadata": map[string]any{
+			"timestamp": meta.timestamp(),
+			"tools": map[string]any{
+				"components": []cdxComponent{{Type: "application", Name: meta.tool()}},
+			},
+			"component": cdxComponent{Type: "application", BOMRef: "root", Name: meta.Name, Version: meta.Version},
+		},
+		"components": list,
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+type spdxPackage struct {
+	Name             string    `json:"name"`
+	SPDXID           string    `json:"SPDXID"`
+	VersionInfo      string    `json:"versionInfo,omitempty"`
+	DownloadLocation string    `json:"downloadLocation"`
+	FilesAnalyzed    bool      `json:"filesAnalyzed"`
+	ExternalRefs     []spdxRef `json:"externalRefs,omitempty"`
+}
+
+type spdxRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+// SPDX returns an SPDX 2.3 JSON document in which the subject described by
+// meta depends on every component.
+func SPDX(components []Component, meta Metadata) ([]byte, error) {
+	id, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	root := spdxPackage{
+		Name:             meta.Name,
+		SPDXID:           "SPDXRef-Root",
+		VersionInfo:      meta.Version,
+		DownloadLocation: "NOASSERTION",
+	}
+	packages := []spdxPackage{root}
+	relationships := []spdxRelationship{{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: root.SPDXID}}
+
+	for i, c := range components {
+		pkg := spdxPackage{
+			Name:             c.Name,
+			SPDXID:           fmt.Sprintf("SPDXRef-Package-%d", i+1),
+			VersionInfo:      c.Version,
+			DownloadLocation: "NOASSERTION",
+			ExternalRefs:     []spdxRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: c.PURL}},
+		}
+		packages = append(packages, pkg)
+		relationships = append(relationships, spdxRelationship{Element: root.SPDXID, Type: "DEPENDS_ON", Related: pkg.SPDXID})
+	}
+
+	doc := map[string]any{
+		"spdxVersion":       "SPDX-2.3",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              meta.Name,
+		"documentNamespace": fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", meta.tool(), id),
+		"creationInfo": map[string]any{
+			"created":  meta.timestamp(),
+			"creators": []string{"Tool: " + meta.tool()},
+		},
+		"packages":      packages,
+		"relationships": relationships,
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
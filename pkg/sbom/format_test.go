@@ -0,0 +1,131 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/oci"
+	"github.com/opencontainers/go-digest"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var testComponents = []Component{
+	{Name: "github.com/foo/bar", Version: "v1.2.3", Ecosystem: EcosystemGo, PURL: "pkg:golang/github.com/foo/bar@v1.2.3"},
+	{Name: "left-pad", Version: "1.3.0", Ecosystem: EcosystemNPM, PURL: "pkg:npm/left-pad@1.3.0"},
+}
+
+var testMetadata = Metadata{Name: "app", Version: "1.0.0", Timestamp: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+
+func TestCycloneDX(t *testing.T) {
+	data, err := CycloneDX(testComponents, testMetadata)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var doc struct {
+		BOMFormat    string `json:"bomFormat"`
+		SpecVersion  string `json:"specVersion"`
+		SerialNumber string `json:"serialNumber"`
+		Metadata     struct {
+			Timestamp string       `json:"timestamp"`
+			Component cdxComponent `json:"component"`
+		} `json:"metadata"`
+		Components []cdxComponent `json:"components"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.BOMFormat != "CycloneDX" || doc.SpecVersion != "1.5" || !strings.HasPrefix(doc.SerialNumber, "urn:uuid:") {
+		t.Errorf("Unexpected header: %+v", doc)
+	}
+	if doc.Metadata.Timestamp != "2024-05-01T12:00:00Z" || doc.Metadata.Component.Name != "app" {
+		t.Errorf("Unexpected metadata: %+v", doc.Metadata)
+	}
+	if len(doc.Components) != 2 || doc.Components[1].PURL != "pkg:npm/left-pad@1.3.0" || doc.Components[1].Type != "library" {
+		t.Errorf("Unexpected components: %+v", doc.Components)
+	}
+}
+
+func TestSPDX(t *testing.T) {
+	data, err := SPDX(testComponents, testMetadata)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var doc struct {
+		SPDXVersion       string             `json:"spdxVersion"`
+		DocumentNamespace string             `json:"documentNamespace"`
+		Packages          []spdxPackage      `json:"packages"`
+		Relationships     []spdxRelationship `json:"relationships"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.SPDXVersion != "SPDX-2.3" || !strings.HasPrefix(doc.DocumentNamespace, "https://spdx.org/spdxdocs/devkit-") {
+		t.Errorf("Unexpected header: %+v", doc)
+	}
+	if len(doc.Packages) != 3 || doc.Packages[2].ExternalRefs[0].ReferenceLocator != "pkg:npm/left-pad@1.3.0" {
+		t.Errorf("Unexpected packages: %+v", doc.Packages)
+	}
+	expected := spdxRelationship{Element: "SPDXRef-Root", Type: "DEPENDS_ON", Related: "SPDXRef-Package-2"}
+	if len(doc.Relationships) != 3 || doc.Relationships[0].Type != "DESCRIBES" || doc.Relationships[2] != expected {
+		t.Errorf("Unexpected relationships: %+v", doc.Relationships)
+	}
+}
+
+func TestAttach(t *testing.T) {
+	blobs := map[string][]byte{}
+	var manifestPath string
+	var manifestBody []byte
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/app/blobs/uploads/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/upload")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		blobs[r.URL.Query().Get("digest")] = data
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("/v2/app/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		manifestPath = r.URL.Path
+		manifestBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	doc, err := CycloneDX(testComponents, testMetadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject := spec.Descriptor{MediaType: spec.MediaTypeImageManifest, Digest: digest.FromString("image"), Size: 512}
+	tag := oci.Tag{Host: strings.TrimPrefix(server.URL, "http://"), Name: "app", Version: "latest"}
+	manifest, err := Attach(oci.NewOciClient(), tag, subject, CycloneDXMediaType, doc, true)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if manifest.ArtifactType != CycloneDXMediaType || manifest.Subject.Digest != subject.Digest {
+		t.Errorf("Expected a referrer of the subject, got: %+v", manifest)
+	}
+	if string(blobs[manifest.Layers[0].Digest.String()]) != string(doc) || string(blobs[spec.DescriptorEmptyJSON.Digest.String()]) != "{}" {
+		t.Errorf("Expected the document and empty config to be uploaded, got: %v", blobs)
+	}
+	if manifestPath != "/v2/app/manifests/"+digest.FromBytes(manifestBody).String() {
+		t.Errorf("Expected the manifest to be pushed by its digest, got: %s", manifestPath)
+	}
+}
@@ -0,0 +1,149 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+func parseGoMod(data []byte) ([]Component, error) {
+	file, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replacements change which module is built, so they are reported
+	// instead of the required version.
+	replaced := map[string]modfile.Replace{}
+	for _, r := range file.Replace {
+		if r.Old.Version == "" {
+			replaced[r.Old.Path] = *r
+		} else {
+			replaced[r.Old.Path+"@"+r.Old.Version] = *r
+		}
+	}
+
+	var components []Component
+	for _, require := range file.Require {
+		mod := require.Mod
+		r, ok := replaced[mod.Path+"@"+mod.Version]
+		if !ok {
+			r, ok = replaced[mod.Path]
+		}
+		if ok {
+			// Local directory replacements have no version to report.
+			if r.New.Version == "" {
+				continue
+			}
+			mod = r.New
+		}
+
+		components = append(components, Component{Name: mod.Path, Version: mod.Version, Ecosystem: EcosystemGo})
+	}
+
+	return components, nil
+}
+
+type npmDependency struct {
+	Version      string                   `json:"version"`
+	Link         bool                     `json:"link"`
+	Dependencies map[string]npmDependency `json:"dependencies"`
+}
+
+// parsePackageLock reads the packages map of lockfile versions 2 and 3, or
+// the nested dependencies of version 1.
+func parsePackageLock(data []byte) ([]Component, error) {
+	var lock struct {
+		Packages     map[string]npmDependency `json:"packages"`
+		Dependencies map[string]npmDependency `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	var components []Component
+	if len(lock.Packages) > 0 {
+		for key, pkg := range lock.Packages {
+			// The root project has the key "" and workspace packages are
+			// links; neither is a dependency.
+			i := strings.LastIndex(key, "node_modules/")
+			if i < 0 || pkg.Link || pkg.Version == "" {
+				continue
+			}
+			name := key[i+len("node_modules/"):]
+			components = append(components, Component{Name: name, Version: pkg.Version, Ecosystem: EcosystemNPM})
+		}
+		return components, nil
+	}
+
+	var walk func(deps map[string]npmDependency)
+	walk = func(deps map[string]npmDependency) {
+		for name, dep := range deps {
+			if dep.Version != "" && !strings.HasPrefix(dep.Version, "file:") {
+				components = append(components, Component{Name: name, Version: dep.Version, Ecosystem: EcosystemNPM})
+			}
+			walk(dep.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+
+	return components, nil
+}
+
+var (
+	requirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(?:===?\s*([^\s;,]+))?`)
+	pypiSeparators     = regexp.MustCompile(`[-_.]+`)
+)
+
+// parseRequirements reads pip requirement lines. Only exact pins (== or
+// ===) have a version; options, includes, URLs and local paths are
+// skipped.
+func parseRequirements(data []byte) ([]Component, error) {
+	var components []Component
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+
+		match := requirementPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		components = append(components, Component{Name: normalizePyPI(match[1]), Version: match[2], Ecosystem: EcosystemPyPI})
+	}
+
+	return components, scanner.Err()
+}
+
+// normalizePyPI applies the PEP 503 name normalization used in package
+// URLs.
+func normalizePyPI(name string) string {
+	return pypiSeparators.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// purl formats the package URL of c, escaping each path segment. The @ of
+// npm scopes is escaped as the purl spec requires.
+func purl(c Component) string {
+	segments := strings.Split(c.Name, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "@", "%40")
+	}
+
+	p := "pkg:" + string(c.Ecosystem) + "/" + strings.Join(segments, "/")
+	if c.Version != "" {
+		p += "@" + url.PathEscape(c.Version)
+	}
+
+	return p
+}
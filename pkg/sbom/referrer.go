@@ -0,0 +1,75 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/oci"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Referrer returns an OCI 1.1 artifact manifest that attaches doc, a
+// document of mediaType, to the manifest described by subject. Registries
+// list it through the referrers API of the subject.
+func Referrer(subject spec.Descriptor, mediaType string, doc []byte) *spec.Manifest {
+	return &spec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    spec.MediaTypeImageManifest,
+		ArtifactType: mediaType,
+		Config:       spec.DescriptorEmptyJSON,
+		Layers: []spec.Descriptor{{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(doc),
+			Size:      int64(len(doc)),
+		}},
+		Subject: &spec.Descriptor{
+			MediaType: subject.MediaType,
+			Digest:    subject.Digest,
+			Size:      subject.Size,
+		},
+	}
+}
+
+// Attach pushes doc as a referrer of subject in the repository of tag and
+// returns the pushed manifest. The manifest is pushed by digest, so tag's
+// version is ignored.
+func Attach(registry *oci.OciClient, tag oci.Tag, subject spec.Descriptor, mediaType string, doc []byte, insecure bool) (*spec.Manifest, error) {
+	defer log.Timed("sbom_attach", "subject", subject.Digest.String())()
+	manifest := Referrer(subject, mediaType, doc)
+
+	blobs := [][]byte{spec.DescriptorEmptyJSON.Data, doc}
+	for i, desc := range []spec.Descriptor{manifest.Config, manifest.Layers[0]} {
+		err := registry.PushBlob(oci.PushBlobOptions{
+			Digest:   desc,
+			File:     blobs[i],
+			Name:     tag.Name,
+			Insecure: insecure,
+			Tag:      tag,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to push blob %s: %w", desc.Digest, err)
+		}
+	}
+
+	// PushManifest uploads the manifest as encoding/json marshals it, so
+	// the same encoding gives the digest to push by.
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	tag.Version = digest.FromBytes(data).String()
+
+	err = registry.PushManifest(oci.PushManifestOptions{
+		Manifest: manifest,
+		Tag:      &tag,
+		Insecure: insecure,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
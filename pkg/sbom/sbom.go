@@ -0,0 +1,223 @@
+package sbom
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/codec"
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+// maxManifestSize bounds how much of a detected manifest file is read.
+const maxManifestSize = 32 << 20
+
+type Ecosystem string
+
+const (
+	EcosystemGo   Ecosystem = "golang"
+	EcosystemNPM  Ecosystem = "npm"
+	EcosystemPyPI Ecosystem = "pypi"
+)
+
+// Component is a package found in a dependency manifest.
+type Component struct {
+	Name      string
+	Version   string
+	Ecosystem Ecosystem
+	// PURL is the package URL identifying the component, e.g.
+	// pkg:golang/golang.org/x/sync@v0.10.0.
+	PURL string
+	// Source is the manifest the component was found in, relative to the
+	// scanned directory or image root.
+	Source string
+}
+
+// parsers maps manifest file names to the functions that read them.
+var parsers = map[string]func(data []byte) ([]Component, error){
+	"go.mod":            parseGoMod,
+	"package-lock.json": parsePackageLock,
+	"requirements.txt":  parseRequirements,
+}
+
+// skipDirs are not descended into when scanning a directory.
+var skipDirs = map[string]bool{".git": true, "node_modules": true, "vendor": true}
+
+// Scan walks dir and returns the components declared in every go.mod,
+// package-lock.json and requirements.txt below it, sorted and without
+// duplicates.
+func Scan(dir string) ([]Component, error) {
+	defer log.Timed("sbom_scan", "dir", dir)()
+	var components []Component
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if file != dir && skipDirs[entry.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		parse, ok := parsers[entry.Name()]
+		if !ok {
+			return nil
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+
+		found, err := parseManifest(parse, filepath.ToSlash(rel), data)
+		if err != nil {
+			return err
+		}
+		components = append(components, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+
+	return normalize(components), nil
+}
+
+// ScanLayers reads image layers, plain or gzipped tarballs, in order from
+// the base layer up and returns the components of the manifests in the
+// resulting filesystem. Files deleted by whiteouts in later layers are not
+// reported.
+func ScanLayers(layers ...io.Reader) ([]Component, error) {
+	defer log.Timed("sbom_scan_layers", "layers", len(layers))()
+	files := map[string][]Component{}
+	for i, layer := range layers {
+		if err := scanLayer(layer, files); err != nil {
+			return nil, fmt.Errorf("failed to scan layer %d: %w", i, err)
+		}
+	}
+
+	var components []Component
+	for _, found := range files {
+		components = append(components, found...)
+	}
+
+	return normalize(components), nil
+}
+
+func scanLayer(layer io.Reader, files map[string][]Component) error {
+	reader, err := codec.NewReader(layer)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	return scanTar(tar.NewReader(reader), files)
+}
+
+func scanTar(tr *tar.Reader, files map[string][]Component) error {
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		dir, base := path.Split(name)
+
+		// Whiteouts delete a file, or with .wh..wh..opq everything in a
+		// directory, from the layers below.
+		if base == ".wh..wh..opq" {
+			for file := range files {
+				if strings.HasPrefix(file, dir) {
+					delete(files, file)
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(base, ".wh.") {
+			deleted := dir + strings.TrimPrefix(base, ".wh.")
+			for file := range files {
+				if file == deleted || strings.HasPrefix(file, deleted+"/") {
+					delete(files, file)
+				}
+			}
+			continue
+		}
+
+		parse, ok := parsers[base]
+		if !ok || header.Typeflag != tar.TypeReg || isSkipped(dir) {
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(tr, maxManifestSize))
+		if err != nil {
+			return err
+		}
+
+		found, err := parseManifest(parse, name, data)
+		if err != nil {
+			return err
+		}
+		files[name] = found
+	}
+}
+
+func isSkipped(dir string) bool {
+	for _, part := range strings.Split(dir, "/") {
+		if skipDirs[part] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseManifest(parse func([]byte) ([]Component, error), source string, data []byte) ([]Component, error) {
+	found, err := parse(data)
+	if err != nil {
+		return nil, log.Errorf(log.CodeInvalidArgument, "sbom_scan", "failed to parse %s: %s", source, err)
+	}
+
+	for i := range found {
+		found[i].Source = source
+		found[i].PURL = purl(found[i])
+	}
+	return found, nil
+}
+
+// normalize sorts components by package URL and source, keeping the first
+// occurrence of each package URL.
+func normalize(components []Component) []Component {
+	sort.Slice(components, func(i, j int) bool {
+		if components[i].PURL != components[j].PURL {
+			return components[i].PURL < components[j].PURL
+		}
+		return components[i].Source < components[j].Source
+	})
+
+	unique := components[:0]
+	for i, component := range components {
+		if i > 0 && component.PURL == components[i-1].PURL {
+			continue
+		}
+		unique = append(unique, component)
+	}
+
+	return unique
+}
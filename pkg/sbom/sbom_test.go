@@ -0,0 +1,214 @@
+package sbom
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+const goMod = `module example.com/app
+
+go 1.23
+
+require (
+	github.com/foo/bar v1.2.3
+	golang.org/x/sync v0.10.0 // indirect
+	example.com/local v0.0.0
+	example.com/old v1.0.0
+)
+
+replace example.com/local => ../local
+
+replace example.com/old v1.0.0 => example.com/new v1.1.0
+`
+
+const packageLock = `{
+  "name": "web",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "web", "version": "1.0.0"},
+    "node_modules/@scope/pkg": {"version": "2.0.0"},
+    "node_modules/left-pad": {"version": "1.3.0"},
+    "node_modules/left-pad/node_modules/nested": {"version": "0.1.0"},
+    "packages/workspace": {"version": "0.0.1"},
+    "node_modules/workspace": {"resolved": "packages/workspace", "link": true}
+  }
+}`
+
+const packageLockV1 = `{
+  "lockfileVersion": 1,
+  "dependencies": {
+    "express": {"version": "4.18.2", "dependencies": {"debug": {"version": "2.6.9"}}},
+    "local": {"version": "file:../local"}
+  }
+}`
+
+const requirements = `# runtime
+Django==4.2.1
+requests[security] == 2.31.0 ; python_version >= "3.8"
+Flask_Login>=0.6
+-r other.txt
+--index-url https://pypi.example.com
+git+https://github.com/org/repo.git#egg=repo
+numpy==1.26.0  # pinned
+`
+
+func TestParsers(t *testing.T) {
+	tests := []struct {
+		name     string
+		parse    func([]byte) ([]Component, error)
+		data     string
+		expected []string
+	}{
+		{name: "go.mod", parse: parseGoMod, data: goMod, expected: []string{
+			"pkg:golang/github.com/foo/bar@v1.2.3",
+			"pkg:golang/golang.org/x/sync@v0.10.0",
+			"pkg:golang/example.com/new@v1.1.0",
+		}},
+		{name: "package-lock v3", parse: parsePackageLock, data: packageLock, expected: []string{
+			"pkg:npm/%40scope/pkg@2.0.0",
+			"pkg:npm/left-pad@1.3.0",
+			"pkg:npm/nested@0.1.0",
+		}},
+		{name: "package-lock v1", parse: parsePackageLock, data: packageLockV1, expected: []string{
+			"pkg:npm/debug@2.6.9",
+			"pkg:npm/express@4.18.2",
+		}},
+		{name: "requirements.txt", parse: parseRequirements, data: requirements, expected: []string{
+			"pkg:pypi/django@4.2.1",
+			"pkg:pypi/requests@2.31.0",
+			"pkg:pypi/flask-login",
+			"pkg:pypi/numpy@1.26.0",
+		}},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			components, err := parseManifest(tt.parse, tt.name, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			var purls []string
+			for _, c := range normalize(components) {
+				purls = append(purls, c.PURL)
+			}
+			expected := normalize(toComponents(tt.expected))
+			var expectedPURLs []string
+			for _, c := range expected {
+				expectedPURLs = append(expectedPURLs, c.PURL)
+			}
+			if !reflect.DeepEqual(purls, expectedPURLs) {
+				t.Errorf("Expected %v, got: %v", expectedPURLs, purls)
+			}
+		})
+	}
+
+	if _, err := parseManifest(parsePackageLock, "package-lock.json", []byte("{")); !log.IsCode(err, log.CodeInvalidArgument) {
+		t.Errorf("Expected invalid argument for a malformed lockfile, got: %v", err)
+	}
+}
+
+func toComponents(purls []string) []Component {
+	components := make([]Component, len(purls))
+	for i, p := range purls {
+		components[i] = Component{PURL: p}
+	}
+	return components
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                               goMod,
+		"web/package-lock.json":                packageLock,
+		"web/node_modules/x/package-lock.json": packageLockV1,
+		"api/requirements.txt":                 requirements,
+		"api/tools/requirements.txt":           "Django==4.2.1\n",
+		".git/modules/go.mod":                  "module ignored\nrequire ignored.example v1.0.0\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	components, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(components) != 10 {
+		t.Errorf("Expected 10 unique components, got %d: %+v", len(components), components)
+	}
+	for _, c := range components {
+		if c.Name == "express" || c.Name == "ignored.example" {
+			t.Errorf("Expected skipped directories not to be scanned, found: %+v", c)
+		}
+		if c.PURL == "pkg:pypi/django@4.2.1" && c.Source != "api/requirements.txt" {
+			t.Errorf("Expected the first source of a duplicate to be kept, got: %s", c.Source)
+		}
+	}
+}
+
+func TestScanLayers(t *testing.T) {
+	base := layer(t, true, map[string]string{
+		"app/go.mod":                  goMod,
+		"srv/requirements.txt":        "numpy==1.26.0\n",
+		"opt/web/package-lock.json":   packageLock,
+		"usr/lib/node_modules/go.mod": goMod,
+	})
+	top := layer(t, false, map[string]string{
+		"srv/.wh.requirements.txt": "",
+		"opt/web/.wh..wh..opq":     "",
+		"srv/requirements.txt2":    "",
+	})
+
+	components, err := ScanLayers(bytes.NewReader(base), bytes.NewReader(top))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var purls []string
+	for _, c := range components {
+		purls = append(purls, c.PURL)
+		if c.Source != "app/go.mod" {
+			t.Errorf("Expected only app/go.mod to remain, got: %s", c.Source)
+		}
+	}
+	if len(purls) != 3 {
+		t.Errorf("Expected the go.mod components, got: %v", purls)
+	}
+}
+
+func layer(t *testing.T, compress bool, files map[string]string) []byte {
+	var buf bytes.Buffer
+	var tw *tar.Writer
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gz)
+	} else {
+		tw = tar.NewWriter(&buf)
+	}
+
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	if gz != nil {
+		gz.Close()
+	}
+
+	return buf.Bytes()
+}
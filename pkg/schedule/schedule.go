@@ -0,0 +1,135 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/validate"
+)
+
+type Task struct {
+	Name     string                          `validate:"required"`
+	Schedule Schedule                        `validate:"required"`
+	Run      func(ctx context.Context) error `validate:"required"`
+	// Jitter delays each run by a random duration up to Jitter, so
+	// processes started together do not all hit a registry at once.
+	Jitter time.Duration
+	// Timeout cancels the context of a run that takes longer.
+	Timeout time.Duration
+	// Immediate runs the task once as soon as the scheduler starts.
+	Immediate bool
+}
+
+// Scheduler runs tasks until its context is cancelled. Runs of the same
+// task never overlap: a run that overruns its next slot delays it. Errors
+// and panics are logged and do not affect other tasks or later runs.
+type Scheduler struct {
+	mu    sync.Mutex
+	tasks []Task
+	// OnError, if set, also receives every failed run. Panics are reported
+	// as errors.
+	OnError func(task string, err error)
+}
+
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add registers task. Tasks added while Run is active start on the next
+// call to Run.
+func (s *Scheduler) Add(task Task) error {
+	if err := validate.Struct(task); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks = append(s.tasks, task)
+	return nil
+}
+
+// Run schedules every task and blocks until ctx is cancelled and all
+// running tasks have returned.
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	tasks := append([]Task(nil), s.tasks...)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, task := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, task)
+		}()
+	}
+
+	<-ctx.Done()
+	wg.Wait()
+	return nil
+}
+
+func (s *Scheduler) loop(ctx context.Context, task Task) {
+	if task.Immediate {
+		s.run(ctx, task)
+	}
+
+	for {
+		next := task.Schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Component("schedule").Info("task has no further runs", "task", task.Name)
+			return
+		}
+
+		wait := time.Until(next)
+		if task.Jitter > 0 {
+			wait += rand.N(task.Jitter)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.run(ctx, task)
+	}
+}
+
+// run calls the task, turning a panic into an error.
+func (s *Scheduler) run(ctx context.Context, task Task) {
+	if task.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, task.Timeout)
+		defer cancel()
+	}
+
+	logger := log.Component("schedule")
+	start := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("task panicked", "task", task.Name, "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+				err = fmt.Errorf("task %s panicked: %v", task.Name, r)
+			}
+		}()
+		return task.Run(ctx)
+	}()
+
+	if err == nil {
+		logger.Debug("task finished", "task", task.Name, "duration_ms", time.Since(start).Milliseconds())
+		return
+	}
+
+	logger.Error("task failed", "task", task.Name, log.KeyError, err.Error())
+	if s.OnError != nil {
+		s.OnError(task.Name, err)
+	}
+}
@@ -0,0 +1,127 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+func TestParse(t *testing.T) {
+	start := time.Date(2024, 5, 15, 10, 7, 30, 0, time.UTC) // a Wednesday
+
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{expr: "* * * * *", expected: time.Date(2024, 5, 15, 10, 8, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", expected: time.Date(2024, 5, 15, 10, 15, 0, 0, time.UTC)},
+		{expr: "0 9-17 * * mon-fri", expected: time.Date(2024, 5, 15, 11, 0, 0, 0, time.UTC)},
+		{expr: "30 2 * * sat,sun", expected: time.Date(2024, 5, 18, 2, 30, 0, 0, time.UTC)},
+		{expr: "0 0 * * 7", expected: time.Date(2024, 5, 19, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 1 jan *", expected: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 13 * fri", expected: time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC)},
+		{expr: "5/20 10 * * *", expected: time.Date(2024, 5, 15, 10, 25, 0, 0, time.UTC)},
+		{expr: "@daily", expected: time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC)},
+		{expr: "@hourly", expected: time.Date(2024, 5, 15, 11, 0, 0, 0, time.UTC)},
+		{expr: "@every 90s", expected: start.Add(90 * time.Second)},
+		{expr: "0 0 30 feb *", expected: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if next := schedule.Next(start); !next.Equal(tt.expected) {
+				t.Errorf("Expected %v, got: %v", tt.expected, next)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * * 13 *", "5-1 * * * *", "*/0 * * * *", "@every", "@every -1m", "* * * * funday"} {
+		if _, err := Parse(expr); !log.IsCode(err, log.CodeInvalidArgument) {
+			t.Errorf("Expected %q to be invalid, got: %v", expr, err)
+		}
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	s := New()
+	var runs, panics atomic.Int32
+	var mu sync.Mutex
+	var failed []string
+	s.OnError = func(task string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failed = append(failed, task)
+	}
+
+	if err := s.Add(Task{Name: "invalid"}); !log.IsCode(err, log.CodeInvalidArgument) {
+		t.Fatalf("Expected a task without a schedule to be rejected, got: %v", err)
+	}
+	s.Add(Task{Name: "count", Schedule: Every(10 * time.Millisecond), Jitter: time.Millisecond, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+	s.Add(Task{Name: "panic", Schedule: Every(10 * time.Millisecond), Immediate: true, Run: func(ctx context.Context) error {
+		panics.Add(1)
+		panic("boom")
+	}})
+	s.Add(Task{Name: "timeout", Schedule: Every(time.Hour), Immediate: true, Timeout: 5 * time.Millisecond, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := s.Run(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if runs.Load() < 3 {
+		t.Errorf("Expected the counting task to run repeatedly, got: %d", runs.Load())
+	}
+	if panics.Load() < 2 {
+		t.Errorf("Expected the panicking task to keep being scheduled, got: %d", panics.Load())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var timedOut bool
+	for _, task := range failed {
+		timedOut = timedOut || task == "timeout"
+	}
+	if !timedOut {
+		t.Errorf("Expected the timeout to be reported, got: %v", failed)
+	}
+}
+
+func TestSchedulerShutdownWaits(t *testing.T) {
+	s := New()
+	finished := make(chan struct{})
+	s.Add(Task{Name: "slow", Schedule: Every(time.Hour), Immediate: true, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+		close(finished)
+		return errors.New("interrupted")
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	s.Run(ctx)
+
+	select {
+	case <-finished:
+	default:
+		t.Error("Expected Run to wait for the running task")
+	}
+}
@@ -0,0 +1,129 @@
+package secrets
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces secrets in redacted output.
+const Redacted = "REDACTED"
+
+// Redact returns data with every secret replaced by Redacted, and what was
+// replaced. Private key blocks are removed up to their END line. Binary
+// data is returned unchanged.
+func Redact(data []byte, opts Options) ([]byte, []Finding) {
+	opts = opts.withDefaults()
+	if isBinary(data) {
+		return data, nil
+	}
+	return redact(data, opts)
+}
+
+// CopyRedacted copies src to dst with secrets redacted, so files can be
+// published without the originals being changed.
+func CopyRedacted(dst io.Writer, src io.Reader, opts Options) ([]Finding, error) {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+
+	redacted, findings := Redact(data, opts)
+	_, err = dst.Write(redacted)
+	return findings, err
+}
+
+// RedactTransform returns a transform for fs.CompressOptions.Transform that
+// redacts files as they are archived. .env files are left out of archives
+// entirely by returning nil.
+func RedactTransform(opts Options) func(rel string, data []byte) ([]byte, error) {
+	return func(rel string, data []byte) ([]byte, error) {
+		if isEnvFile(rel) {
+			return nil, nil
+		}
+		redacted, _ := Redact(data, opts)
+		if redacted == nil {
+			redacted = []byte{}
+		}
+		return redacted, nil
+	}
+}
+
+type span struct {
+	start, end int
+	rule       string
+}
+
+func redact(data []byte, opts Options) ([]byte, []Finding) {
+	var out bytes.Buffer
+	var findings []Finding
+	inKey := false
+
+	lines := strings.SplitAfter(string(data), "\n")
+	for i, line := range lines {
+		if inKey {
+			if strings.Contains(line, "-----END") {
+				inKey = false
+			}
+			continue
+		}
+
+		spans := secretSpans(line, opts)
+		if len(spans) == 0 {
+			out.WriteString(line)
+			continue
+		}
+
+		last := 0
+		for _, s := range spans {
+			findings = append(findings, Finding{Line: i + 1, Rule: s.rule, Match: mask(line[s.start:s.end])})
+			if s.rule == privateKeyRule {
+				out.WriteString(line[last:s.start])
+				out.WriteString(Redacted)
+				if strings.HasSuffix(line, "\n") {
+					out.WriteByte('\n')
+				}
+				last = len(line)
+				inKey = !strings.Contains(line[s.end:], "-----END")
+				break
+			}
+			out.WriteString(line[last:s.start])
+			out.WriteString(Redacted)
+			last = s.end
+		}
+		out.WriteString(line[last:])
+	}
+
+	return out.Bytes(), findings
+}
+
+// secretSpans returns non-overlapping secret spans in line, rule matches
+// taking precedence over entropy.
+func secretSpans(line string, opts Options) []span {
+	var spans []span
+	for _, rule := range opts.Rules {
+		for _, m := range rule.Pattern.FindAllStringSubmatchIndex(line, -1) {
+			start, end := m[0], m[1]
+			if len(m) >= 4 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			spans = append(spans, span{start, end, rule.Name})
+		}
+	}
+	if opts.Entropy > 0 {
+		for _, s := range highEntropyTokens(line, opts.Entropy) {
+			spans = append(spans, span{s[0], s[1], "high-entropy"})
+		}
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var kept []span
+	for _, s := range spans {
+		if len(kept) > 0 && s.start < kept[len(kept)-1].end {
+			continue
+		}
+		kept = append(kept, s)
+	}
+	return kept
+}
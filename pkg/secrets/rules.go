@@ -0,0 +1,78 @@
+package secrets
+
+import (
+	"math"
+	"regexp"
+)
+
+// Rule matches a known credential format. When Pattern has a capture group
+// the first group is the secret, otherwise the whole match.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+const privateKeyRule = "private-key"
+
+// DefaultRules detect common credentials.
+var DefaultRules = []Rule{
+	{Name: "aws-access-key", Pattern: regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`)},
+	{Name: "github-token", Pattern: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,})\b`)},
+	{Name: "gitlab-token", Pattern: regexp.MustCompile(`\b(glpat-[A-Za-z0-9_-]{20,})\b`)},
+	{Name: "slack-token", Pattern: regexp.MustCompile(`\b(xox[abposr]-[0-9A-Za-z-]{10,})\b`)},
+	{Name: "jwt", Pattern: regexp.MustCompile(`\b(eyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})\b`)},
+	{Name: privateKeyRule, Pattern: regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY-----`)},
+	{Name: "password-assignment", Pattern: regexp.MustCompile(`(?i)\b[a-z_.-]*(?:password|passwd|secret|token|api[_-]?key)[a-z_.-]*\s*[:=]\s*["']?([^\s"'#]{8,})`)},
+}
+
+// DefaultEntropy is the Shannon entropy, in bits per character, above which
+// long tokens are reported as likely secrets. Random base64 strings score
+// above it; words, identifiers and hex digests score below.
+const DefaultEntropy = 4.0
+
+const minEntropyLength = 20
+
+var tokenPattern = regexp.MustCompile(`[A-Za-z0-9+/_-]{20,}={0,2}`)
+
+// highEntropyTokens returns the spans of tokens mixing letters and digits
+// whose entropy reaches threshold.
+func highEntropyTokens(line string, threshold float64) [][]int {
+	var spans [][]int
+	for _, span := range tokenPattern.FindAllStringIndex(line, -1) {
+		token := line[span[0]:span[1]]
+		if len(token) < minEntropyLength || !hasLettersAndDigits(token) {
+			continue
+		}
+		if entropy(token) >= threshold {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+func hasLettersAndDigits(s string) bool {
+	var letters, digits bool
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			digits = true
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			letters = true
+		}
+	}
+	return letters && digits
+}
+
+func entropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, c := range s {
+		counts[c]++
+	}
+
+	var bits float64
+	for _, n := range counts {
+		p := float64(n) / float64(len(s))
+		bits -= p * math.Log2(p)
+	}
+	return bits
+}
@@ -0,0 +1,198 @@
+package secrets
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/codec"
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+// EnvFileRule reports .env files themselves, which should never be
+// published even when no individual value looks like a secret.
+const EnvFileRule = "env-file"
+
+type Options struct {
+	// Rules default to DefaultRules.
+	Rules []Rule
+	// Entropy is the threshold for high-entropy tokens, defaulting to
+	// DefaultEntropy. A negative value disables entropy checks.
+	Entropy float64
+	// Exclude skips files, and directories with everything below them. rel
+	// uses forward slashes, as with fs.CompressOptions.Exclude.
+	Exclude func(rel string, isDir bool) bool
+	// MaxFileSize skips larger files, defaulting to 10 MiB.
+	MaxFileSize int64
+}
+
+func (o Options) withDefaults() Options {
+	if o.Rules == nil {
+		o.Rules = DefaultRules
+	}
+	if o.Entropy == 0 {
+		o.Entropy = DefaultEntropy
+	}
+	if o.MaxFileSize == 0 {
+		o.MaxFileSize = 10 << 20
+	}
+	return o
+}
+
+// Finding is a suspected secret. Match is masked so findings can be logged
+// safely.
+type Finding struct {
+	Path  string `json:"path"`
+	Line  int    `json:"line,omitempty"`
+	Rule  string `json:"rule"`
+	Match string `json:"match,omitempty"`
+}
+
+func (f Finding) String() string {
+	if f.Line == 0 {
+		return fmt.Sprintf("%s: %s", f.Path, f.Rule)
+	}
+	return fmt.Sprintf("%s:%d: %s %s", f.Path, f.Line, f.Rule, f.Match)
+}
+
+// Scan reads r, named name in findings, line by line. Binary content is
+// skipped.
+func Scan(r io.Reader, name string, opts Options) ([]Finding, error) {
+	opts = opts.withDefaults()
+	data, err := io.ReadAll(io.LimitReader(r, opts.MaxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	if isEnvFile(name) {
+		findings = append(findings, Finding{Path: name, Rule: EnvFileRule})
+	}
+	if int64(len(data)) > opts.MaxFileSize || isBinary(data) {
+		return findings, nil
+	}
+
+	_, matches := redact(data, opts)
+	for _, m := range matches {
+		m.Path = name
+		findings = append(findings, m)
+	}
+	return findings, nil
+}
+
+// ScanFile scans the file at path.
+func ScanFile(path string, opts Options) ([]Finding, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Scan(f, path, opts)
+}
+
+// ScanDir scans every file below dir, e.g. before it is compressed into an
+// artifact. Paths in findings are relative to dir. .git directories are
+// skipped.
+func ScanDir(dir string, opts Options) ([]Finding, error) {
+	defer log.Timed("secrets_scan", "dir", dir)()
+	opts = opts.withDefaults()
+
+	var findings []Finding
+	err := filepath.WalkDir(dir, func(file string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, file)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if entry.IsDir() {
+			if entry.Name() == ".git" || (opts.Exclude != nil && opts.Exclude(rel, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || (opts.Exclude != nil && opts.Exclude(rel, false)) {
+			return nil
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		found, err := Scan(f, rel, opts)
+		if err != nil {
+			return fmt.Errorf("failed to scan %s: %w", rel, err)
+		}
+		findings = append(findings, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return findings, nil
+}
+
+// ScanArchive scans the regular files of a tar archive, plain or compressed
+// with a registered codec, such as an OCI layer.
+func ScanArchive(r io.Reader, opts Options) ([]Finding, error) {
+	opts = opts.withDefaults()
+	src, err := codec.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("error creating decompressor: %w", err)
+	}
+	defer src.Close()
+
+	var findings []Finding
+	tr := tar.NewReader(src)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading tar archive: %w", err)
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if header.Typeflag != tar.TypeReg || (opts.Exclude != nil && opts.Exclude(name, false)) {
+			continue
+		}
+
+		found, err := Scan(tr, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", name, err)
+		}
+		findings = append(findings, found...)
+	}
+
+	return findings, nil
+}
+
+func isEnvFile(name string) bool {
+	base := path.Base(filepath.ToSlash(name))
+	return base == ".env" || (strings.HasPrefix(base, ".env.") && !strings.HasSuffix(base, ".example") && !strings.HasSuffix(base, ".sample"))
+}
+
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0
+}
+
+// mask keeps a short prefix of a secret for context.
+func mask(secret string) string {
+	if len(secret) <= 8 {
+		return "****"
+	}
+	return secret[:4] + "****"
+}
@@ -0,0 +1,325 @@
+package selfupdate
+
+import (
+	"context"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/checksum"
+	"github.com/eunanio/sdk/pkg/fs"
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/signing"
+	"github.com/eunanio/sdk/pkg/version"
+	"github.com/opencontainers/go-digest"
+)
+
+type Updater struct {
+	Source Source
+	// Current is the version of the running binary, defaulting to
+	// version.Get().Version.
+	Current string
+	// AssetName picks the release asset for this platform. By default the
+	// first asset whose name mentions runtime.GOOS and runtime.GOARCH is
+	// used.
+	AssetName func(release *Release) (string, error)
+	// ChecksumFile is the release asset listing sha256 sums, defaulting to
+	// checksum.DefaultFile. The binary is verified against it.
+	ChecksumFile string
+	// PublicKey requires the checksum file and its minisign signature,
+	// released as the checksum file name plus signing.SignatureExt.
+	PublicKey *signing.PublicKey
+	// AllowUnverified installs a release that has no checksum file instead
+	// of refusing it.
+	AllowUnverified bool
+	// Validate is run on the new binary before it replaces the current one,
+	// e.g. to execute it with --version.
+	Validate func(path string) error
+	// Executable defaults to the running executable.
+	Executable string
+}
+
+// Check returns the latest release and whether it is newer than Current.
+func (u *Updater) Check(ctx context.Context) (*Release, bool, error) {
+	release, err := u.Source.Latest(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	current := u.Current
+	if current == "" {
+		current = version.Get().Version
+	}
+
+	return release, version.Compare(release.Version, current) > 0, nil
+}
+
+// Latest returns the latest release version, for use as a version.Checker.
+func (u *Updater) Latest(ctx context.Context) (string, error) {
+	release, err := u.Source.Latest(ctx)
+	if err != nil {
+		return "", err
+	}
+	return release.Version, nil
+}
+
+// Update installs the latest release if it is newer, returning it, or nil
+// when already up to date. The executable is swapped with a rename and
+// restored if any later step fails.
+func (u *Updater) Update(ctx context.Context) (*Release, error) {
+	defer log.Timed("self_update", "current", u.Current)()
+	release, newer, err := u.Check(ctx)
+	if err != nil || !newer {
+		return nil, err
+	}
+
+	exe, err := u.executable()
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.MkdirTemp(filepath.Dir(exe), ".update-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create update directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	binary, err := u.fetch(ctx, release, tmp, filepath.Base(exe))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(binary, 0755); err != nil {
+		return nil, err
+	}
+
+	if u.Validate != nil {
+		if err := u.Validate(binary); err != nil {
+			return nil, fmt.Errorf("new version failed validation: %w", err)
+		}
+	}
+
+	if err := replace(exe, binary); err != nil {
+		return nil, err
+	}
+
+	return release, nil
+}
+
+func (u *Updater) executable() (string, error) {
+	if u.Executable != "" {
+		return u.Executable, nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.EvalSymlinks(exe)
+}
+
+// fetch downloads and verifies the platform asset into dir, returning the
+// path of the binary. Archives are unpacked and searched for exeName.
+func (u *Updater) fetch(ctx context.Context, release *Release, dir, exeName string) (string, error) {
+	name, err := u.assetName(release)
+	if err != nil {
+		return "", err
+	}
+
+	asset, ok := release.asset(name)
+	if !ok {
+		return "", log.Errorf(log.CodeNotFound, "self_update", "release %s has no asset %s", release.Version, name)
+	}
+
+	want, err := u.expectedDigest(ctx, release, dir, name)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, name)
+	if err := u.Source.Download(ctx, asset, path, want); err != nil {
+		return "", err
+	}
+
+	if !strings.HasSuffix(name, ".tar.gz") && !strings.HasSuffix(name, ".tgz") {
+		return path, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	out := filepath.Join(dir, "extracted")
+	if err := fs.DecompressDir(data, out); err != nil {
+		return "", err
+	}
+
+	return findBinary(out, exeName)
+}
+
+// expectedDigest reads the asset's sum from the release checksum file,
+// verifying its signature when a public key is configured. It returns ""
+// only when the release has no checksum file and AllowUnverified is set.
+func (u *Updater) expectedDigest(ctx context.Context, release *Release, dir, name string) (digest.Digest, error) {
+	sumsName := u.ChecksumFile
+	if sumsName == "" {
+		sumsName = checksum.DefaultFile
+	}
+
+	sumsAsset, ok := release.asset(sumsName)
+	if !ok {
+		if u.PublicKey != nil || !u.AllowUnverified {
+			return "", log.Errorf(log.CodeNotFound, "self_update", "release %s has no %s to verify", release.Version, sumsName)
+		}
+		return "", nil
+	}
+
+	sumsPath := filepath.Join(dir, sumsName)
+	if err := u.Source.Download(ctx, sumsAsset, sumsPath, ""); err != nil {
+		return "", err
+	}
+
+	if u.PublicKey != nil {
+		sigAsset, ok := release.asset(sumsName + signing.SignatureExt)
+		if !ok {
+			return "", log.Errorf(log.CodeNotFound, "self_update", "release %s has no signature for %s", release.Version, sumsName)
+		}
+		if err := u.Source.Download(ctx, sigAsset, sumsPath+signing.SignatureExt, ""); err != nil {
+			return "", err
+		}
+		if err := checksum.VerifySignature(sumsPath, u.PublicKey); err != nil {
+			return "", err
+		}
+	}
+
+	data, err := os.ReadFile(sumsPath)
+	if err != nil {
+		return "", err
+	}
+
+	sums, err := checksum.Parse(data)
+	if err != nil {
+		return "", err
+	}
+
+	for _, sum := range sums {
+		if sum.Name == name {
+			return digest.NewDigestFromEncoded(digest.SHA256, sum.Hex), nil
+		}
+	}
+
+	return "", log.Errorf(log.CodeNotFound, "self_update", "%s does not list %s", sumsName, name)
+}
+
+func (u *Updater) assetName(release *Release) (string, error) {
+	if u.AssetName != nil {
+		return u.AssetName(release)
+	}
+
+	for _, asset := range release.Assets {
+		name := strings.ToLower(asset.Name)
+		if strings.HasSuffix(name, signing.SignatureExt) || strings.HasSuffix(name, ".sig") || strings.HasSuffix(name, "sums") || strings.HasSuffix(name, ".txt") {
+			continue
+		}
+		if matchesPlatform(name, runtime.GOOS, runtime.GOARCH) {
+			return asset.Name, nil
+		}
+	}
+
+	return "", log.Errorf(log.CodeNotFound, "self_update", "release %s has no asset for %s/%s", release.Version, runtime.GOOS, runtime.GOARCH)
+}
+
+var platformAliases = map[string][]string{
+	"darwin": {"darwin", "macos"},
+	"amd64":  {"amd64", "x86_64"},
+	"arm64":  {"arm64", "aarch64"},
+}
+
+// matchesPlatform reports whether the "_", "-" and "." separated words of
+// name include goos and goarch, so "arm" does not match an arm64 asset.
+func matchesPlatform(name, goos, goarch string) bool {
+	words := splitName(name)
+	contains := func(value string) bool {
+		aliases, ok := platformAliases[value]
+		if !ok {
+			aliases = []string{value}
+		}
+		for _, alias := range aliases {
+			// Aliases such as x86_64 span several words.
+			want := splitName(alias)
+			for i := 0; i+len(want) <= len(words); i++ {
+				if slices.Equal(words[i:i+len(want)], want) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	return contains(goos) && contains(goarch)
+}
+
+func splitName(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.'
+	})
+}
+
+func findBinary(dir, exeName string) (string, error) {
+	var found string
+	err := filepath.WalkDir(dir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil || found != "" {
+			return err
+		}
+		if !d.IsDir() && d.Name() == exeName {
+			found = path
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if found == "" {
+		return "", log.Errorf(log.CodeNotFound, "self_update", "archive does not contain %s", exeName)
+	}
+
+	return found, nil
+}
+
+// replace moves exe aside, renames binary into its place and removes the
+// old copy. If the swap fails the original is restored. Windows cannot
+// delete a running executable, so the old copy is left for CleanupOld.
+func replace(exe, binary string) error {
+	old := exe + ".old"
+	_ = os.Remove(old)
+
+	if err := os.Rename(exe, old); err != nil {
+		return fmt.Errorf("failed to move current executable: %w", err)
+	}
+
+	if err := os.Rename(binary, exe); err != nil {
+		if rbErr := os.Rename(old, exe); rbErr != nil {
+			return fmt.Errorf("failed to install update: %w (rollback failed: %s)", err, rbErr)
+		}
+		return fmt.Errorf("failed to install update: %w", err)
+	}
+
+	_ = os.Remove(old)
+	return nil
+}
+
+// CleanupOld removes the executable left behind by an update on Windows.
+// Call it early at startup.
+func CleanupOld() {
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+
+	_ = os.Remove(exe + ".old")
+}
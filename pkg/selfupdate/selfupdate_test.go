@@ -0,0 +1,159 @@
+package selfupdate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/httpx"
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/signing"
+)
+
+func newReleaseServer(t *testing.T, binary []byte, key *signing.PrivateKey, tamper bool) *httptest.Server {
+	t.Helper()
+	asset := fmt.Sprintf("tool_%s_%s", runtime.GOOS, runtime.GOARCH)
+	sum := sha256.Sum256(binary)
+	sums := []byte(hex.EncodeToString(sum[:]) + "  " + asset + "\n")
+	signature, err := signing.Sign(key, bytes.NewReader(sums), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tamper {
+		binary = []byte("tampered")
+	}
+
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/repos/eunanio/tool/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"tag_name": "v1.1.0",
+			"assets": []map[string]string{
+				{"name": "SHA256SUMS", "browser_download_url": server.URL + "/SHA256SUMS"},
+				{"name": "SHA256SUMS.minisig", "browser_download_url": server.URL + "/SHA256SUMS.minisig"},
+				{"name": asset, "browser_download_url": server.URL + "/" + asset},
+			},
+		})
+	})
+	mux.HandleFunc("/SHA256SUMS", func(w http.ResponseWriter, r *http.Request) { w.Write(sums) })
+	mux.HandleFunc("/SHA256SUMS.minisig", func(w http.ResponseWriter, r *http.Request) { w.Write(signature.Marshal()) })
+	mux.HandleFunc("/"+asset, func(w http.ResponseWriter, r *http.Request) { w.Write(binary) })
+
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestUpdate(t *testing.T) {
+	public, private, err := signing.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		current         string
+		tamper          bool
+		noChecksums     bool
+		allowUnverified bool
+		expected        string
+		expectedCode    log.Code
+	}{
+		{name: "Installs newer release", current: "v1.0.0", expected: "new binary"},
+		{name: "Already up to date", current: "v1.1.0", expected: "old binary"},
+		{name: "Rejects tampered binary", current: "v1.0.0", tamper: true, expected: "old binary", expectedCode: log.CodeInvalidArgument},
+		{name: "Refuses release without checksums", current: "v1.0.0", noChecksums: true, expected: "old binary", expectedCode: log.CodeNotFound},
+		{name: "Installs unverified release when allowed", current: "v1.0.0", noChecksums: true, allowUnverified: true, expected: "new binary"},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			server := newReleaseServer(t, []byte("new binary"), private, tt.tamper)
+			exe := filepath.Join(t.TempDir(), "tool")
+			if err := os.WriteFile(exe, []byte("old binary"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			updater := &Updater{
+				Source:     &GitHubSource{Repo: "eunanio/tool", BaseURL: server.URL, Client: httpx.New(httpx.WithRetries(0))},
+				Current:    tt.current,
+				PublicKey:  public,
+				Executable: exe,
+			}
+			if tt.noChecksums {
+				updater.ChecksumFile = "MISSING"
+				updater.PublicKey = nil
+				updater.AllowUnverified = tt.allowUnverified
+			}
+
+			_, err := updater.Update(context.Background())
+			if tt.expectedCode != "" {
+				if !log.IsCode(err, tt.expectedCode) {
+					t.Errorf("Expected %s error, got: %v", tt.expectedCode, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			data, _ := os.ReadFile(exe)
+			if string(data) != tt.expected {
+				t.Errorf("Expected executable to contain %q, got: %q", tt.expected, data)
+			}
+
+			entries, _ := os.ReadDir(filepath.Dir(exe))
+			if len(entries) != 1 {
+				t.Errorf("Expected temporary files to be cleaned up, got: %v", entries)
+			}
+		})
+	}
+}
+
+func TestMatchesPlatform(t *testing.T) {
+	tests := []struct {
+		name     string
+		goos     string
+		goarch   string
+		expected bool
+	}{
+		{name: "tool_linux_amd64.tar.gz", goos: "linux", goarch: "amd64", expected: true},
+		{name: "tool-macos-x86_64.tar.gz", goos: "darwin", goarch: "amd64", expected: true},
+		{name: "tool_linux_arm64.tar.gz", goos: "linux", goarch: "arm", expected: false},
+		{name: "tool_linux_arm.tar.gz", goos: "linux", goarch: "arm", expected: true},
+		{name: "tool_darwinx_amd64", goos: "darwin", goarch: "amd64", expected: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesPlatform(tt.name, tt.goos, tt.goarch); got != tt.expected {
+				t.Errorf("Expected %v for %s/%s, got: %v", tt.expected, tt.goos, tt.goarch, got)
+			}
+		})
+	}
+}
+
+func TestReplaceRollback(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "tool")
+	if err := os.WriteFile(exe, []byte("old binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := replace(exe, filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("Expected an error for a missing binary")
+	}
+
+	if data, _ := os.ReadFile(exe); string(data) != "old binary" {
+		t.Errorf("Expected the original executable to be restored, got: %q", data)
+	}
+}
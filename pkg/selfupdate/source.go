@@ -0,0 +1,187 @@
+package selfupdate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/download"
+	"github.com/eunanio/sdk/pkg/httpx"
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/oci"
+	"github.com/opencontainers/go-digest"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type Release struct {
+	Version string
+	Assets  []Asset
+}
+
+type Asset struct {
+	Name string
+	// URL is set for assets downloaded over HTTP.
+	URL string
+	// Digest is set when the source already knows the asset digest.
+	Digest digest.Digest
+}
+
+func (r *Release) asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+
+	return Asset{}, false
+}
+
+// Source finds the latest release and fetches its assets.
+type Source interface {
+	Latest(ctx context.Context) (*Release, error)
+	// Download writes asset to dst, verifying want when it is set.
+	Download(ctx context.Context, asset Asset, dst string, want digest.Digest) error
+}
+
+// GitHubSource reads the latest published release of Repo ("owner/name").
+type GitHubSource struct {
+	Repo string
+	// Token is optional and raises the API rate limit.
+	Token  string
+	Client *httpx.Client
+	// BaseURL defaults to https://api.github.com.
+	BaseURL string
+}
+
+func (g *GitHubSource) client() *httpx.Client {
+	if g.Client == nil {
+		g.Client = httpx.New()
+	}
+
+	return g.Client
+}
+
+func (g *GitHubSource) Latest(ctx context.Context) (*Release, error) {
+	base := g.BaseURL
+	if base == "" {
+		base = "https://api.github.com"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/repos/%s/releases/latest", base, g.Repo), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+
+	resp, err := g.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, log.Errorf(log.CodeRemote, "selfupdate_latest", "failed to get latest release of %s: %s", g.Repo, resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+		Assets  []struct {
+			Name string `json:"name"`
+			URL  string `json:"browser_download_url"`
+		} `json:"assets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+
+	result := &Release{Version: release.TagName}
+	for _, asset := range release.Assets {
+		result.Assets = append(result.Assets, Asset{Name: asset.Name, URL: asset.URL})
+	}
+
+	return result, nil
+}
+
+func (g *GitHubSource) Download(ctx context.Context, asset Asset, dst string, want digest.Digest) error {
+	_, err := download.File(ctx, asset.URL, dst, download.Options{Client: g.client(), Digest: want})
+	return err
+}
+
+// OCISource reads releases published as an OCI artifact. The manifest of Tag
+// carries the version in the org.opencontainers.image.version annotation and
+// each layer is an asset named by org.opencontainers.image.title.
+type OCISource struct {
+	Tag    oci.Tag
+	Client *oci.OciClient
+}
+
+func (o *OCISource) client() *oci.OciClient {
+	if o.Client == nil {
+		o.Client = oci.NewOciClient()
+	}
+
+	return o.Client
+}
+
+func (o *OCISource) Latest(ctx context.Context) (*Release, error) {
+	manifest, err := o.client().PullManifest(&o.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	version := manifest.Annotations[spec.AnnotationVersion]
+	if version == "" {
+		return nil, log.Errorf(log.CodeInvalidArgument, "selfupdate_latest", "%s has no %s annotation", o.Tag.String(), spec.AnnotationVersion)
+	}
+
+	release := &Release{Version: version}
+	for _, layer := range manifest.Layers {
+		name := layer.Annotations[spec.AnnotationTitle]
+		if name == "" {
+			continue
+		}
+		release.Assets = append(release.Assets, Asset{Name: name, Digest: layer.Digest})
+	}
+
+	return release, nil
+}
+
+// Download pulls the asset blob. Blobs are always checked against their
+// layer digest; want must match it when set.
+func (o *OCISource) Download(ctx context.Context, asset Asset, dst string, want digest.Digest) error {
+	if want != "" && want.Encoded() != asset.Digest.Encoded() {
+		return log.NewError(log.CodeInvalidArgument, "selfupdate_download", fmt.Errorf("%w for %s: expected %s, got %s", download.ErrDigestMismatch, asset.Name, want, asset.Digest))
+	}
+
+	data, err := o.client().PullBlob(oci.PullBlobOptions{
+		Digest: spec.Descriptor{Digest: asset.Digest},
+		Name:   asset.Name,
+		Tag:    &o.Tag,
+	})
+	if err != nil {
+		return err
+	}
+
+	if got := asset.Digest.Algorithm().FromBytes(data); got != asset.Digest {
+		return log.NewError(log.CodeInvalidArgument, "selfupdate_download", fmt.Errorf("%w for %s: expected %s, got %s", download.ErrDigestMismatch, asset.Name, asset.Digest, got))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(dst, data, 0644)
+}
+
+// normalizeVersion strips a leading "v" so tags like v1.2.3 compare with
+// 1.2.3.
+func normalizeVersion(version string) string {
+	return strings.TrimPrefix(strings.TrimSpace(version), "v")
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+)
+
+// launchdPlist renders a property list that starts the service at load and
+// restarts it when it exits with an error.
+func launchdPlist(c Config) []byte {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	plistString(&b, "Label", c.Name, "\t")
+
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range append([]string{c.Executable}, c.Args...) {
+		b.WriteString("\t\t<string>")
+		xml.EscapeText(&b, []byte(arg))
+		b.WriteString("</string>\n")
+	}
+	b.WriteString("\t</array>\n")
+
+	if len(c.Env) > 0 {
+		b.WriteString("\t<key>EnvironmentVariables</key>\n\t<dict>\n")
+		for _, key := range sortedKeys(c.Env) {
+			plistString(&b, key, c.Env[key], "\t\t")
+		}
+		b.WriteString("\t</dict>\n")
+	}
+	if c.WorkingDir != "" {
+		plistString(&b, "WorkingDirectory", c.WorkingDir, "\t")
+	}
+	if c.LogFile != "" {
+		plistString(&b, "StandardOutPath", c.LogFile, "\t")
+		plistString(&b, "StandardErrorPath", c.LogFile, "\t")
+	}
+
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	b.WriteString("</dict>\n</plist>\n")
+
+	return b.Bytes()
+}
+
+func plistString(b *bytes.Buffer, key, value, indent string) {
+	b.WriteString(indent + "<key>")
+	xml.EscapeText(b, []byte(key))
+	b.WriteString("</key>\n" + indent + "<string>")
+	xml.EscapeText(b, []byte(value))
+	b.WriteString("</string>\n")
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package service
+
+import (
+	"context"
+
+	"github.com/eunanio/sdk/pkg/system"
+)
+
+// Run runs fn until the service is asked to stop. Under systemd and launchd
+// that is SIGTERM, which cancels ctx.
+func Run(name string, fn func(ctx context.Context) error) error {
+	ctx, stop := system.NotifyContext(context.Background())
+	defer stop()
+	return fn(ctx)
+}
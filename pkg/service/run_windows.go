@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+
+	"github.com/eunanio/sdk/pkg/system"
+	"golang.org/x/sys/windows/svc"
+)
+
+// Run runs fn until the service is asked to stop. When started by the
+// Service Control Manager it reports the service state and cancels ctx on
+// stop or shutdown requests; otherwise Ctrl+C cancels ctx.
+func Run(name string, fn func(ctx context.Context) error) error {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return err
+	}
+	if !isService {
+		ctx, stop := system.NotifyContext(context.Background())
+		defer stop()
+		return fn(ctx)
+	}
+
+	h := &handler{fn: fn}
+	if err := svc.Run(name, h); err != nil {
+		return err
+	}
+	return h.err
+}
+
+type handler struct {
+	fn  func(ctx context.Context) error
+	err error
+}
+
+func (h *handler) Execute(args []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- h.fn(ctx) }()
+
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for {
+		select {
+		case h.err = <-done:
+			return h.exit()
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				changes <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				cancel()
+				h.err = <-done
+				return h.exit()
+			}
+		}
+	}
+}
+
+// exit reports a failure as a service-specific exit code so recovery
+// actions restart the service.
+func (h *handler) exit() (bool, uint32) {
+	if h.err != nil && h.err != context.Canceled {
+		return true, 1
+	}
+	return false, 0
+}
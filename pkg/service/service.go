@@ -0,0 +1,139 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/validate"
+)
+
+var ErrUnsupported = errors.New("services are not supported on this platform")
+
+type Config struct {
+	// Name identifies the service: the systemd unit name, launchd label or
+	// Windows service name, e.g. "devkit-proxy".
+	Name        string `json:"name" validate:"required,service-name"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description"`
+	// Executable defaults to the running executable.
+	Executable string            `json:"executable" validate:"path-exists"`
+	Args       []string          `json:"args"`
+	Env        map[string]string `json:"env"`
+	// WorkingDir is not supported by Windows services.
+	WorkingDir string `json:"workingDir" validate:"path-exists"`
+	// User installs a per-user service (a systemd user unit or LaunchAgent)
+	// that needs no root privileges. It is ignored on Windows.
+	User bool `json:"user"`
+	// LogFile receives stdout and stderr under launchd. systemd services log
+	// to the journal.
+	LogFile string `json:"logFile"`
+}
+
+type Status string
+
+const (
+	StatusRunning      Status = "running"
+	StatusStopped      Status = "stopped"
+	StatusNotInstalled Status = "not installed"
+)
+
+// manager is implemented by each platform's service manager.
+type manager interface {
+	install() error
+	uninstall() error
+	start() error
+	stop() error
+	status() (Status, error)
+}
+
+type Service struct {
+	config  Config
+	manager manager
+}
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+func init() {
+	validate.Register("service-name", func(value reflect.Value, _ string) error {
+		if !namePattern.MatchString(value.String()) {
+			return errors.New("must contain only letters, digits, '.', '_' and '-'")
+		}
+		return nil
+	})
+}
+
+// New prepares a service for the platform's service manager: systemd on
+// Linux, launchd on macOS and the Service Control Manager on Windows.
+func New(config Config) (*Service, error) {
+	if config.Executable == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find executable: %w", err)
+		}
+		config.Executable = exe
+	}
+	if err := validate.Struct(config); err != nil {
+		return nil, err
+	}
+
+	exe, err := filepath.Abs(config.Executable)
+	if err != nil {
+		return nil, err
+	}
+	config.Executable = exe
+	if config.DisplayName == "" {
+		config.DisplayName = config.Name
+	}
+
+	manager, err := nativeManager(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{config: config, manager: manager}, nil
+}
+
+func (s *Service) Name() string {
+	return s.config.Name
+}
+
+// Install registers the service to start at boot, or at login for user
+// services, without starting it.
+func (s *Service) Install() error {
+	defer log.Timed("service_install", "name", s.config.Name)()
+	return s.wrap("install", s.manager.install())
+}
+
+// Uninstall stops the service and removes it.
+func (s *Service) Uninstall() error {
+	defer log.Timed("service_uninstall", "name", s.config.Name)()
+	return s.wrap("uninstall", s.manager.uninstall())
+}
+
+func (s *Service) Start() error {
+	return s.wrap("start", s.manager.start())
+}
+
+func (s *Service) Stop() error {
+	return s.wrap("stop", s.manager.stop())
+}
+
+func (s *Service) Status() (Status, error) {
+	status, err := s.manager.status()
+	return status, s.wrap("status", err)
+}
+
+func (s *Service) wrap(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, os.ErrPermission) {
+		return log.NewError(log.CodeUnauthorized, "service_"+op, fmt.Errorf("%s %s: %w (system services need root or administrator rights)", op, s.config.Name, err))
+	}
+	return fmt.Errorf("failed to %s service %s: %w", op, s.config.Name, err)
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// launchd manages a LaunchDaemon, or a LaunchAgent in the user's GUI domain
+// for user services.
+type launchd struct {
+	config    Config
+	plistPath string
+	domain    string
+}
+
+func nativeManager(config Config) (manager, error) {
+	l := &launchd{
+		config:    config,
+		plistPath: filepath.Join("/Library/LaunchDaemons", config.Name+".plist"),
+		domain:    "system",
+	}
+	if config.User {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		l.plistPath = filepath.Join(home, "Library", "LaunchAgents", config.Name+".plist")
+		l.domain = "gui/" + strconv.Itoa(os.Getuid())
+	}
+
+	return l, nil
+}
+
+func (l *launchd) target() string {
+	return l.domain + "/" + l.config.Name
+}
+
+func (l *launchd) launchctl(args ...string) (string, error) {
+	out, err := exec.Command("launchctl", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("launchctl %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+func (l *launchd) loaded() (string, bool) {
+	out, err := l.launchctl("print", l.target())
+	return out, err == nil
+}
+
+func (l *launchd) install() error {
+	if err := os.MkdirAll(filepath.Dir(l.plistPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(l.plistPath, launchdPlist(l.config), 0644); err != nil {
+		return err
+	}
+	_, err := l.launchctl("enable", l.target())
+	return err
+}
+
+func (l *launchd) uninstall() error {
+	if _, err := os.Stat(l.plistPath); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if _, ok := l.loaded(); ok {
+		if err := l.stop(); err != nil {
+			return err
+		}
+	}
+	return os.Remove(l.plistPath)
+}
+
+func (l *launchd) start() error {
+	if _, ok := l.loaded(); ok {
+		_, err := l.launchctl("kickstart", l.target())
+		return err
+	}
+	_, err := l.launchctl("bootstrap", l.domain, l.plistPath)
+	return err
+}
+
+// stop unloads the job; with KeepAlive set, killing it would only restart
+// it.
+func (l *launchd) stop() error {
+	_, err := l.launchctl("bootout", l.target())
+	return err
+}
+
+func (l *launchd) status() (Status, error) {
+	if _, err := os.Stat(l.plistPath); errors.Is(err, os.ErrNotExist) {
+		return StatusNotInstalled, nil
+	}
+
+	out, ok := l.loaded()
+	if ok && strings.Contains(out, "state = running") {
+		return StatusRunning, nil
+	}
+	return StatusStopped, nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// systemd manages a unit in /etc/systemd/system, or in the user's
+// systemd/user config directory for user services.
+type systemd struct {
+	config   Config
+	unitPath string
+}
+
+func nativeManager(config Config) (manager, error) {
+	dir := "/etc/systemd/system"
+	if config.User {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(configDir, "systemd", "user")
+	}
+
+	return &systemd{config: config, unitPath: filepath.Join(dir, config.Name+".service")}, nil
+}
+
+func (s *systemd) unit() string {
+	return s.config.Name + ".service"
+}
+
+func (s *systemd) systemctl(args ...string) (string, error) {
+	if s.config.User {
+		args = append([]string{"--user"}, args...)
+	}
+
+	out, err := exec.Command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("systemctl %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (s *systemd) install() error {
+	if err := os.MkdirAll(filepath.Dir(s.unitPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.unitPath, []byte(systemdUnit(s.config)), 0644); err != nil {
+		return err
+	}
+	if _, err := s.systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	_, err := s.systemctl("enable", s.unit())
+	return err
+}
+
+func (s *systemd) uninstall() error {
+	if _, err := os.Stat(s.unitPath); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	// The unit may already be stopped or disabled.
+	_, _ = s.systemctl("stop", s.unit())
+	_, _ = s.systemctl("disable", s.unit())
+	if err := os.Remove(s.unitPath); err != nil {
+		return err
+	}
+	_, err := s.systemctl("daemon-reload")
+	return err
+}
+
+func (s *systemd) start() error {
+	_, err := s.systemctl("start", s.unit())
+	return err
+}
+
+func (s *systemd) stop() error {
+	_, err := s.systemctl("stop", s.unit())
+	return err
+}
+
+func (s *systemd) status() (Status, error) {
+	if _, err := os.Stat(s.unitPath); errors.Is(err, os.ErrNotExist) {
+		return StatusNotInstalled, nil
+	}
+
+	args := []string{"is-active", s.unit()}
+	if s.config.User {
+		args = append([]string{"--user"}, args...)
+	}
+	// is-active exits non-zero for inactive units, so only its output is
+	// used.
+	out, _ := exec.Command("systemctl", args...).Output()
+	switch state := strings.TrimSpace(string(out)); state {
+	case "active", "activating", "reloading":
+		return StatusRunning, nil
+	case "inactive", "failed", "deactivating":
+		return StatusStopped, nil
+	default:
+		return "", fmt.Errorf("unexpected state %q from systemctl is-active", state)
+	}
+}
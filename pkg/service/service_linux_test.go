@@ -0,0 +1,27 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStatusNotInstalled(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	s, err := New(Config{Name: "devkit-test", User: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if path := s.manager.(*systemd).unitPath; path != filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "systemd", "user", "devkit-test.service") {
+		t.Errorf("Unexpected unit path: %s", path)
+	}
+
+	status, err := s.Status()
+	if err != nil || status != StatusNotInstalled {
+		t.Errorf("Expected %q, got: %q, %v", StatusNotInstalled, status, err)
+	}
+	if err := s.Uninstall(); err != nil {
+		t.Errorf("Expected uninstalling a missing service to succeed, got: %v", err)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !linux && !darwin && !windows
+
+package service
+
+func nativeManager(config Config) (manager, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+func testConfig() Config {
+	return Config{
+		Name:        "devkit-proxy",
+		Description: "Devkit registry proxy",
+		Executable:  "/opt/devkit/bin/devkit",
+		Args:        []string{"proxy", "--listen", ":5000", "--cache dir"},
+		Env:         map[string]string{"LOG_LEVEL": "debug", "TOKEN": "a$b%c"},
+		WorkingDir:  "/var/lib/devkit",
+		LogFile:     "/var/log/devkit-proxy.log",
+	}
+}
+
+func TestSystemdUnit(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     bool
+		contains []string
+		excludes []string
+	}{
+		{
+			name: "System service",
+			contains: []string{
+				"Description=Devkit registry proxy\nAfter=network-online.target",
+				`ExecStart=/opt/devkit/bin/devkit proxy --listen :5000 "--cache dir"`,
+				"WorkingDirectory=/var/lib/devkit",
+				"Environment=LOG_LEVEL=debug\nEnvironment=TOKEN=a$$b%%c\n",
+				"Restart=on-failure",
+				"WantedBy=multi-user.target",
+			},
+		},
+		{
+			name:     "User service",
+			user:     true,
+			contains: []string{"WantedBy=default.target"},
+			excludes: []string{"network-online.target"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig()
+			config.User = tt.user
+			unit := systemdUnit(config)
+
+			for _, s := range tt.contains {
+				if !strings.Contains(unit, s) {
+					t.Errorf("Expected unit to contain %q, got:\n%s", s, unit)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(unit, s) {
+					t.Errorf("Expected unit not to contain %q, got:\n%s", s, unit)
+				}
+			}
+		})
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	config := testConfig()
+	config.Args = append(config.Args, "<&>")
+	plist := string(launchdPlist(config))
+
+	for _, s := range []string{
+		"<key>Label</key>\n\t<string>devkit-proxy</string>",
+		"\t\t<string>/opt/devkit/bin/devkit</string>\n\t\t<string>proxy</string>",
+		"<string>&lt;&amp;&gt;</string>",
+		"\t\t<key>TOKEN</key>\n\t\t<string>a$b%c</string>",
+		"<key>StandardErrorPath</key>\n\t<string>/var/log/devkit-proxy.log</string>",
+		"<key>SuccessfulExit</key>\n\t\t<false/>",
+	} {
+		if !strings.Contains(plist, s) {
+			t.Errorf("Expected plist to contain %q, got:\n%s", s, plist)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		config      Config
+		expectError bool
+	}{
+		{name: "Defaults to the running executable", config: Config{Name: "devkit-proxy"}},
+		{name: "Missing name", config: Config{Executable: exe}, expectError: true},
+		{name: "Invalid name", config: Config{Name: "devkit proxy", Executable: exe}, expectError: true},
+		{name: "Missing executable", config: Config{Name: "devkit-proxy", Executable: "/nonexistent/devkit"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(tt.config)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+			if err != nil && !log.IsCode(err, log.CodeInvalidArgument) {
+				t.Errorf("Expected an invalid argument error, got: %v", err)
+			}
+			if err == nil && s.config.Executable != exe {
+				t.Errorf("Expected executable %s, got: %s", exe, s.config.Executable)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+const stopTimeout = 30 * time.Second
+
+// scm manages a service through the Windows Service Control Manager. The
+// executable must call Run so it reports its state to the SCM.
+type scm struct {
+	config Config
+}
+
+func nativeManager(config Config) (manager, error) {
+	return &scm{config: config}, nil
+}
+
+// open connects to the SCM and opens the service. close releases both.
+func (s *scm) open() (*mgr.Service, func(), error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	service, err := m.OpenService(s.config.Name)
+	if err != nil {
+		m.Disconnect()
+		return nil, nil, err
+	}
+
+	return service, func() {
+		service.Close()
+		m.Disconnect()
+	}, nil
+}
+
+func (s *scm) install() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	service, err := m.CreateService(s.config.Name, s.config.Executable, mgr.Config{
+		DisplayName: s.config.DisplayName,
+		Description: s.config.Description,
+		StartType:   mgr.StartAutomatic,
+	}, s.config.Args...)
+	if err != nil {
+		return err
+	}
+	defer service.Close()
+
+	recovery := []mgr.RecoveryAction{{Type: mgr.ServiceRestart, Delay: 5 * time.Second}}
+	if err := service.SetRecoveryActions(recovery, uint32((24 * time.Hour).Seconds())); err != nil {
+		return err
+	}
+
+	if len(s.config.Env) == 0 {
+		return nil
+	}
+
+	// Services read extra environment variables from the Environment value
+	// of their registry key.
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\`+s.config.Name, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	var env []string
+	for _, name := range sortedKeys(s.config.Env) {
+		env = append(env, name+"="+s.config.Env[name])
+	}
+	return key.SetStringsValue("Environment", env)
+}
+
+func (s *scm) uninstall() error {
+	service, release, err := s.open()
+	if errors.Is(err, windows.ERROR_SERVICE_DOES_NOT_EXIST) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if status, err := service.Query(); err == nil && status.State != svc.Stopped {
+		if err := waitForStop(service); err != nil {
+			return err
+		}
+	}
+	return service.Delete()
+}
+
+func (s *scm) start() error {
+	service, release, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return service.Start()
+}
+
+func (s *scm) stop() error {
+	service, release, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return waitForStop(service)
+}
+
+func waitForStop(service *mgr.Service) error {
+	status, err := service.Control(svc.Stop)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(stopTimeout)
+	for status.State != svc.Stopped {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for the service to stop")
+		}
+		time.Sleep(300 * time.Millisecond)
+		if status, err = service.Query(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *scm) status() (Status, error) {
+	service, release, err := s.open()
+	if errors.Is(err, windows.ERROR_SERVICE_DOES_NOT_EXIST) {
+		return StatusNotInstalled, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	status, err := service.Query()
+	if err != nil {
+		return "", err
+	}
+	switch status.State {
+	case svc.Running, svc.StartPending, svc.ContinuePending:
+		return StatusRunning, nil
+	}
+	return StatusStopped, nil
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// systemdUnit renders a unit that restarts the service when it fails.
+func systemdUnit(c Config) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s\n", firstNonEmpty(c.Description, c.DisplayName))
+	if !c.User {
+		b.WriteString("After=network-online.target\nWants=network-online.target\n")
+	}
+
+	b.WriteString("\n[Service]\nType=simple\n")
+	command := append([]string{c.Executable}, c.Args...)
+	for i, arg := range command {
+		command[i] = systemdQuote(arg)
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(command, " "))
+	if c.WorkingDir != "" {
+		fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdQuote(c.WorkingDir))
+	}
+	for _, key := range sortedKeys(c.Env) {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote(key+"="+c.Env[key]))
+	}
+	b.WriteString("Restart=on-failure\nRestartSec=5\n")
+
+	target := "multi-user.target"
+	if c.User {
+		target = "default.target"
+	}
+	fmt.Fprintf(&b, "\n[Install]\nWantedBy=%s\n", target)
+
+	return b.String()
+}
+
+// systemdQuote quotes a word for ExecStart and Environment, escaping
+// specifiers (%) and variable expansion ($).
+func systemdQuote(s string) string {
+	s = strings.NewReplacer("%", "%%", "$", "$$").Replace(s)
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\;") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
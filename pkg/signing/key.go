@@ -0,0 +1,228 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Keys and signatures use the minisign formats, so files signed here can be
+// checked with minisign -V and the other way around.
+var (
+	algEd25519   = [2]byte{'E', 'd'}
+	algPrehashed = [2]byte{'E', 'D'}
+	kdfScrypt    = [2]byte{'S', 'c'}
+	kdfNone      = [2]byte{0, 0}
+	chkBlake2b   = [2]byte{'B', '2'}
+)
+
+// Scrypt limits for encrypting secret keys, minisign's defaults. Tests
+// lower them.
+var (
+	scryptOpsLimit uint64 = 1 << 25
+	scryptMemLimit uint64 = 1 << 30
+)
+
+const (
+	commentPrefix  = "untrusted comment: "
+	saltSize       = 32
+	secretDataSize = 8 + ed25519.PrivateKeySize + blake2b.Size256
+)
+
+// KeyID identifies the key pair a signature was made with.
+type KeyID [8]byte
+
+// String formats the ID the way minisign prints it.
+func (id KeyID) String() string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(id[:]))
+}
+
+type PublicKey struct {
+	ID  KeyID
+	Key ed25519.PublicKey
+}
+
+type PrivateKey struct {
+	ID  KeyID
+	Key ed25519.PrivateKey
+}
+
+// Public returns the public half of the key pair.
+func (k *PrivateKey) Public() *PublicKey {
+	return &PublicKey{ID: k.ID, Key: k.Key.Public().(ed25519.PublicKey)}
+}
+
+// GenerateKey creates a key pair with a random ID.
+func GenerateKey() (*PublicKey, *PrivateKey, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var id KeyID
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, nil, err
+	}
+
+	return &PublicKey{ID: id, Key: public}, &PrivateKey{ID: id, Key: private}, nil
+}
+
+// Marshal encodes the key as a minisign .pub file.
+func (k *PublicKey) Marshal() []byte {
+	data := slices.Concat(algEd25519[:], k.ID[:], k.Key)
+	return []byte(fmt.Sprintf("%sminisign public key %s\n%s\n", commentPrefix, k.ID, base64.StdEncoding.EncodeToString(data)))
+}
+
+// ParsePublicKey reads a minisign .pub file or just its base64 line, as
+// passed to minisign -P.
+func ParsePublicKey(data []byte) (*PublicKey, error) {
+	encoded, err := keyLine(data)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != 2+8+ed25519.PublicKeySize || !bytes.Equal(raw[:2], algEd25519[:]) {
+		return nil, log.Errorf(log.CodeInvalidArgument, "signing_parse_key", "invalid public key")
+	}
+
+	key := &PublicKey{Key: ed25519.PublicKey(raw[10:])}
+	copy(key.ID[:], raw[2:10])
+	return key, nil
+}
+
+// Marshal encodes the key as a minisign .key file, encrypted with password
+// unless it is empty.
+func (k *PrivateKey) Marshal(password []byte) ([]byte, error) {
+	secret := slices.Concat(k.ID[:], k.Key)
+	secret = append(secret, checksum(k.ID, k.Key)...)
+
+	kdf := kdfNone
+	salt := make([]byte, saltSize)
+	if len(password) > 0 {
+		kdf = kdfScrypt
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		stream, err := keyStream(password, salt, scryptOpsLimit, scryptMemLimit)
+		if err != nil {
+			return nil, err
+		}
+		subtle.XORBytes(secret, secret, stream)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(algEd25519[:])
+	buf.Write(kdf[:])
+	buf.Write(chkBlake2b[:])
+	buf.Write(salt)
+	binary.Write(&buf, binary.LittleEndian, scryptOpsLimit)
+	binary.Write(&buf, binary.LittleEndian, scryptMemLimit)
+	buf.Write(secret)
+
+	comment := "minisign encrypted secret key"
+	if len(password) == 0 {
+		comment = "minisign secret key"
+	}
+	return []byte(fmt.Sprintf("%s%s\n%s\n", commentPrefix, comment, base64.StdEncoding.EncodeToString(buf.Bytes()))), nil
+}
+
+// ParsePrivateKey reads a minisign .key file, decrypting it with password
+// when it is encrypted.
+func ParsePrivateKey(data, password []byte) (*PrivateKey, error) {
+	encoded, err := keyLine(data)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != 6+saltSize+16+secretDataSize || !bytes.Equal(raw[:2], algEd25519[:]) || !bytes.Equal(raw[4:6], chkBlake2b[:]) {
+		return nil, log.Errorf(log.CodeInvalidArgument, "signing_parse_key", "invalid secret key")
+	}
+
+	salt := raw[6 : 6+saltSize]
+	opsLimit := binary.LittleEndian.Uint64(raw[6+saltSize:])
+	memLimit := binary.LittleEndian.Uint64(raw[6+saltSize+8:])
+	secret := bytes.Clone(raw[6+saltSize+16:])
+
+	switch [2]byte(raw[2:4]) {
+	case kdfScrypt:
+		if len(password) == 0 {
+			return nil, log.Errorf(log.CodeUnauthorized, "signing_parse_key", "secret key is encrypted and no password was given")
+		}
+		stream, err := keyStream(password, salt, opsLimit, memLimit)
+		if err != nil {
+			return nil, err
+		}
+		subtle.XORBytes(secret, secret, stream)
+	case kdfNone:
+	default:
+		return nil, log.Errorf(log.CodeInvalidArgument, "signing_parse_key", "unsupported key derivation %q", raw[2:4])
+	}
+
+	key := &PrivateKey{Key: ed25519.PrivateKey(secret[8 : 8+ed25519.PrivateKeySize])}
+	copy(key.ID[:], secret[:8])
+	if subtle.ConstantTimeCompare(checksum(key.ID, key.Key), secret[8+ed25519.PrivateKeySize:]) != 1 {
+		return nil, log.Errorf(log.CodeUnauthorized, "signing_parse_key", "wrong password or corrupt secret key")
+	}
+
+	return key, nil
+}
+
+// keyLine returns the base64 line of a key file, skipping its comment.
+func keyLine(data []byte) (string, error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, commentPrefix) {
+			return line, nil
+		}
+	}
+
+	return "", log.Errorf(log.CodeInvalidArgument, "signing_parse_key", "empty key file")
+}
+
+func checksum(id KeyID, key ed25519.PrivateKey) []byte {
+	sum := blake2b.Sum256(slices.Concat(algEd25519[:], id[:], key))
+	return sum[:]
+}
+
+// keyStream derives the bytes XORed with the secret key.
+func keyStream(password, salt []byte, opsLimit, memLimit uint64) ([]byte, error) {
+	logN, r, p := scryptParams(opsLimit, memLimit)
+	stream, err := scrypt.Key(password, salt, 1<<logN, r, p, secretDataSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return stream, nil
+}
+
+// scryptParams turns libsodium's opslimit and memlimit, which minisign
+// stores in key files, into scrypt parameters the way libsodium does.
+func scryptParams(opsLimit, memLimit uint64) (logN uint, r, p int) {
+	opsLimit = max(opsLimit, 32768)
+	r, p, logN = 8, 1, 1
+
+	maxN := memLimit / (uint64(r) * 128)
+	if opsLimit < memLimit/32 {
+		maxN = opsLimit / (uint64(r) * 4)
+	}
+	for logN < 63 && uint64(1)<<logN <= maxN/2 {
+		logN++
+	}
+	if opsLimit >= memLimit/32 {
+		maxRP := min((opsLimit/4)>>logN, 0x3fffffff)
+		p = max(int(maxRP)/r, 1)
+	}
+
+	return logN, r, p
+}
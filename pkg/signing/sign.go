@@ -0,0 +1,187 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"golang.org/x/crypto/blake2b"
+)
+
+// SignatureExt is appended to a file name for its detached signature.
+const SignatureExt = ".minisig"
+
+const trustedPrefix = "trusted comment: "
+
+// Signature is a detached minisign signature. TrustedComment is signed
+// along with the signature, UntrustedComment is not.
+type Signature struct {
+	UntrustedComment string
+	KeyID            KeyID
+	// Prehashed signatures sign the BLAKE2b-512 hash of the content, which
+	// minisign uses by default.
+	Prehashed       bool
+	Signature       []byte
+	TrustedComment  string
+	GlobalSignature []byte
+}
+
+// Sign signs the content of r. An empty trustedComment is replaced by the
+// current time.
+func Sign(key *PrivateKey, r io.Reader, trustedComment string) (*Signature, error) {
+	hash, err := blake2b.New512(nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(hash, r); err != nil {
+		return nil, fmt.Errorf("failed to read content: %w", err)
+	}
+	if trustedComment == "" {
+		trustedComment = fmt.Sprintf("timestamp:%d", time.Now().Unix())
+	}
+
+	signature := ed25519.Sign(key.Key, hash.Sum(nil))
+	return &Signature{
+		UntrustedComment: "signature from minisign secret key",
+		KeyID:            key.ID,
+		Prehashed:        true,
+		Signature:        signature,
+		TrustedComment:   trustedComment,
+		GlobalSignature:  ed25519.Sign(key.Key, slices.Concat(signature, []byte(trustedComment))),
+	}, nil
+}
+
+// Verify checks sig against the content of r and key, including the
+// trusted comment.
+func Verify(key *PublicKey, r io.Reader, sig *Signature) error {
+	if sig.KeyID != key.ID {
+		return log.Errorf(log.CodeUnauthorized, "signing_verify", "signature was made with key %s, not %s", sig.KeyID, key.ID)
+	}
+
+	var message []byte
+	if sig.Prehashed {
+		hash, err := blake2b.New512(nil)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(hash, r); err != nil {
+			return fmt.Errorf("failed to read content: %w", err)
+		}
+		message = hash.Sum(nil)
+	} else {
+		var err error
+		if message, err = io.ReadAll(r); err != nil {
+			return fmt.Errorf("failed to read content: %w", err)
+		}
+	}
+
+	if !ed25519.Verify(key.Key, message, sig.Signature) {
+		return log.Errorf(log.CodeUnauthorized, "signing_verify", "invalid signature")
+	}
+	if !ed25519.Verify(key.Key, slices.Concat(sig.Signature, []byte(sig.TrustedComment)), sig.GlobalSignature) {
+		return log.Errorf(log.CodeUnauthorized, "signing_verify", "invalid signature of trusted comment")
+	}
+
+	return nil
+}
+
+// Marshal encodes the signature as a .minisig file.
+func (s *Signature) Marshal() []byte {
+	alg := algEd25519
+	if s.Prehashed {
+		alg = algPrehashed
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s%s\n", commentPrefix, s.UntrustedComment)
+	fmt.Fprintf(&buf, "%s\n", base64.StdEncoding.EncodeToString(slices.Concat(alg[:], s.KeyID[:], s.Signature)))
+	fmt.Fprintf(&buf, "%s%s\n", trustedPrefix, s.TrustedComment)
+	fmt.Fprintf(&buf, "%s\n", base64.StdEncoding.EncodeToString(s.GlobalSignature))
+	return buf.Bytes()
+}
+
+// ParseSignature reads a .minisig file.
+func ParseSignature(data []byte) (*Signature, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[0], commentPrefix) || !strings.HasPrefix(lines[2], trustedPrefix) {
+		return nil, log.Errorf(log.CodeInvalidArgument, "signing_parse_signature", "invalid signature file")
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(raw) != 2+8+ed25519.SignatureSize {
+		return nil, log.Errorf(log.CodeInvalidArgument, "signing_parse_signature", "invalid signature")
+	}
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return nil, log.Errorf(log.CodeInvalidArgument, "signing_parse_signature", "invalid trusted comment signature")
+	}
+
+	sig := &Signature{
+		UntrustedComment: strings.TrimPrefix(lines[0], commentPrefix),
+		Signature:        raw[10:],
+		TrustedComment:   strings.TrimPrefix(lines[2], trustedPrefix),
+		GlobalSignature:  global,
+	}
+	copy(sig.KeyID[:], raw[2:10])
+
+	switch [2]byte(raw[:2]) {
+	case algPrehashed:
+		sig.Prehashed = true
+	case algEd25519:
+	default:
+		return nil, log.Errorf(log.CodeInvalidArgument, "signing_parse_signature", "unsupported signature algorithm %q", raw[:2])
+	}
+
+	return sig, nil
+}
+
+// SignFile writes a detached signature of file, such as a release tarball or
+// a SHA256SUMS manifest, to file.minisig.
+func SignFile(file string, key *PrivateKey) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	comment := fmt.Sprintf("timestamp:%d\tfile:%s\thashed", time.Now().Unix(), filepath.Base(file))
+	sig, err := Sign(key, f, comment)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file+SignatureExt, sig.Marshal(), 0644)
+}
+
+// VerifyFile checks file against its detached signature in file.minisig and
+// returns the signature, whose trusted comment can then be relied on.
+func VerifyFile(file string, key *PublicKey) (*Signature, error) {
+	data, err := os.ReadFile(file + SignatureExt)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := ParseSignature(data)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if err := Verify(key, f, sig); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+	}
+
+	return sig, nil
+}
@@ -0,0 +1,177 @@
+package signing
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+func init() {
+	scryptOpsLimit, scryptMemLimit = 1<<15, 1<<20
+}
+
+func TestScryptParams(t *testing.T) {
+	tests := []struct {
+		name         string
+		opsLimit     uint64
+		memLimit     uint64
+		expectedLogN uint
+		expectedR    int
+		expectedP    int
+	}{
+		{"minisign default", 1 << 25, 1 << 30, 20, 8, 1},
+		{"interactive", 1 << 19, 1 << 24, 14, 8, 1},
+		{"ops bound", 1 << 15, 1 << 30, 10, 8, 1},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			logN, r, p := scryptParams(tt.opsLimit, tt.memLimit)
+			if logN != tt.expectedLogN || r != tt.expectedR || p != tt.expectedP {
+				t.Errorf("Expected N=2^%d r=%d p=%d, got: N=2^%d r=%d p=%d", tt.expectedLogN, tt.expectedR, tt.expectedP, logN, r, p)
+			}
+		})
+	}
+}
+
+func TestKeys(t *testing.T) {
+	public, private, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	encoded := public.Marshal()
+	if !bytes.HasPrefix(encoded, []byte("untrusted comment: minisign public key "+public.ID.String()+"\nRW")) {
+		t.Errorf("Expected a minisign public key, got: %s", encoded)
+	}
+	for _, data := range [][]byte{encoded, bytes.Split(encoded, []byte("\n"))[1]} {
+		parsed, err := ParsePublicKey(data)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if parsed.ID != public.ID || !parsed.Key.Equal(public.Key) {
+			t.Errorf("Expected %s, got: %s", public.ID, parsed.ID)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		parseAs  string
+		code     log.Code
+	}{
+		{name: "unencrypted", password: "", parseAs: ""},
+		{name: "encrypted", password: "hunter2", parseAs: "hunter2"},
+		{name: "wrong password", password: "hunter2", parseAs: "hunter3", code: log.CodeUnauthorized},
+		{name: "missing password", password: "hunter2", parseAs: "", code: log.CodeUnauthorized},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := private.Marshal([]byte(tt.password))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			parsed, err := ParsePrivateKey(data, []byte(tt.parseAs))
+			if tt.code != "" {
+				if !log.IsCode(err, tt.code) {
+					t.Errorf("Expected %s error, got: %v", tt.code, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if parsed.ID != private.ID || !parsed.Key.Equal(private.Key) {
+				t.Error("Expected the parsed key to match")
+			}
+		})
+	}
+
+	if _, err := ParsePublicKey([]byte("untrusted comment: x\nnot base64")); !log.IsCode(err, log.CodeInvalidArgument) {
+		t.Errorf("Expected invalid argument, got: %v", err)
+	}
+}
+
+func TestSignFile(t *testing.T) {
+	public, private, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPublic, _, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "SHA256SUMS")
+	if err := os.WriteFile(file, []byte("abc  app.tar.gz\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SignFile(file, private); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	sig, err := VerifyFile(file, public)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !strings.Contains(sig.TrustedComment, "file:SHA256SUMS") {
+		t.Errorf("Expected the file name in the trusted comment, got: %q", sig.TrustedComment)
+	}
+
+	if _, err := VerifyFile(file, otherPublic); !log.IsCode(err, log.CodeUnauthorized) {
+		t.Errorf("Expected another key to be rejected, got: %v", err)
+	}
+
+	data, _ := os.ReadFile(file + SignatureExt)
+	tampered := bytes.Replace(data, []byte("file:SHA256SUMS"), []byte("file:OTHER"), 1)
+	os.WriteFile(file+SignatureExt, tampered, 0644)
+	if _, err := VerifyFile(file, public); !log.IsCode(err, log.CodeUnauthorized) {
+		t.Errorf("Expected a modified trusted comment to be rejected, got: %v", err)
+	}
+
+	os.WriteFile(file+SignatureExt, data, 0644)
+	os.WriteFile(file, []byte("def  app.tar.gz\n"), 0644)
+	if _, err := VerifyFile(file, public); !log.IsCode(err, log.CodeUnauthorized) {
+		t.Errorf("Expected modified content to be rejected, got: %v", err)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	public, private, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := Sign(private, strings.NewReader("release"), "")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !strings.HasPrefix(sig.TrustedComment, "timestamp:") {
+		t.Errorf("Expected a timestamp comment, got: %q", sig.TrustedComment)
+	}
+
+	parsed, err := ParseSignature(sig.Marshal())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(strings.Split(string(sig.Marshal()), "\n")[1])
+	if string(raw[:2]) != "ED" {
+		t.Errorf("Expected a prehashed signature, got: %q", raw[:2])
+	}
+	if err := Verify(public, strings.NewReader("release"), parsed); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+
+	if _, err := ParseSignature([]byte("garbage")); !log.IsCode(err, log.CodeInvalidArgument) {
+		t.Errorf("Expected invalid argument, got: %v", err)
+	}
+}
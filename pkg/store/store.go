@@ -0,0 +1,425 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	bolt "go.etcd.io/bbolt"
+)
+
+// lockTimeout bounds how long opening the database waits for other
+// processes.
+const lockTimeout = 30 * time.Second
+
+type Action string
+
+const (
+	ActionPush Action = "push"
+	ActionPull Action = "pull"
+)
+
+type Artifact struct {
+	Action Action `json:"action"`
+	// Reference is the full reference, e.g. "ghcr.io/org/app:v1".
+	Reference string `json:"reference"`
+	// Repository is Reference without its tag or digest. Record derives it
+	// when it is empty.
+	Repository  string            `json:"repository"`
+	Digest      digest.Digest     `json:"digest"`
+	MediaType   string            `json:"media_type,omitempty"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Time        time.Time         `json:"time"`
+}
+
+// Query selects artifacts. Zero fields match everything.
+type Query struct {
+	Repository string
+	Action     Action
+	Digest     digest.Digest
+	// Labels must all be present in the annotations. An empty value matches
+	// any value of the key.
+	Labels map[string]string
+	Since  time.Time
+	Until  time.Time
+	// Limit caps the number of results, newest first.
+	Limit int
+}
+
+func (q Query) matches(a Artifact) bool {
+	if q.Repository != "" && a.Repository != q.Repository {
+		return false
+	}
+	if q.Action != "" && a.Action != q.Action {
+		return false
+	}
+	if q.Digest != "" && a.Digest != q.Digest {
+		return false
+	}
+	if !q.Since.IsZero() && a.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !a.Time.Before(q.Until) {
+		return false
+	}
+	for key, value := range q.Labels {
+		got, ok := a.Annotations[key]
+		if !ok || value != "" && got != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Buckets of the database. artifacts holds the records keyed by sequence
+// number; the others index those keys by repository, label and time.
+var (
+	bucketArtifacts  = []byte("artifacts")
+	bucketRepository = []byte("by_repository")
+	bucketLabel      = []byte("by_label")
+	bucketTime       = []byte("by_time")
+)
+
+// Store is a local history of pushed and pulled artifacts, kept in a bbolt
+// database at dir/artifacts.db. The database is opened for each call and
+// bbolt locks the file, so several processes can share a store.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	return &Store{dir: dir, now: time.Now}, nil
+}
+
+// Default returns the store in the user config directory, e.g.
+// ~/.config/<name>/artifacts on Linux.
+func Default(name string) (*Store, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find config directory: %w", err)
+	}
+
+	return Open(filepath.Join(dir, name, "artifacts"))
+}
+
+// Record adds a to the history.
+func (s *Store) Record(a Artifact) error {
+	if a.Reference == "" || a.Digest == "" {
+		return fmt.Errorf("artifact needs a reference and digest")
+	}
+	if a.Repository == "" {
+		a.Repository = repository(a.Reference)
+	}
+	if a.Time.IsZero() {
+		a.Time = s.now()
+	}
+	a.Time = a.Time.UTC()
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	return s.update(func(tx *bolt.Tx) error {
+		artifacts := tx.Bucket(bucketArtifacts)
+		seq, err := artifacts.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		id := itob(seq)
+		if err := artifacts.Put(id, data); err != nil {
+			return err
+		}
+		return index(tx, id, a, func(b *bolt.Bucket, key []byte) error {
+			return b.Put(key, []byte{})
+		})
+	})
+}
+
+// Find returns the artifacts matching q, newest first.
+func (s *Store) Find(q Query) ([]Artifact, error) {
+	var matched []Artifact
+	err := s.view(func(tx *bolt.Tx) error {
+		candidates := candidates(tx, q)
+		return walk(tx, q.Since, q.Until, func(id []byte, a Artifact) bool {
+			if candidates != nil && !candidates[string(id)] || !q.matches(a) {
+				return true
+			}
+			matched = append(matched, a)
+			return q.Limit <= 0 || len(matched) < q.Limit
+		})
+	})
+
+	return matched, err
+}
+
+// History returns the last limit artifacts of repository, newest first.
+// A limit of zero returns all of them.
+func (s *Store) History(repository string, limit int) ([]Artifact, error) {
+	return s.Find(Query{Repository: repository, Limit: limit})
+}
+
+// Search returns artifacts whose reference, digest or annotation values
+// contain term, ignoring case, newest first. Each reference is returned
+// once, with its latest record.
+func (s *Store) Search(term string) ([]Artifact, error) {
+	term = strings.ToLower(term)
+	seen := map[string]bool{}
+	var matched []Artifact
+	err := s.view(func(tx *bolt.Tx) error {
+		return walk(tx, time.Time{}, time.Time{}, func(_ []byte, a Artifact) bool {
+			if !seen[a.Reference] && containsTerm(a, term) {
+				seen[a.Reference] = true
+				matched = append(matched, a)
+			}
+			return true
+		})
+	})
+
+	return matched, err
+}
+
+func containsTerm(a Artifact, term string) bool {
+	if strings.Contains(strings.ToLower(a.Reference), term) || strings.Contains(a.Digest.String(), term) {
+		return true
+	}
+	for _, value := range a.Annotations {
+		if strings.Contains(strings.ToLower(value), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// LastUsed returns when each digest was last pushed or pulled, so cache
+// garbage collection can keep recently used content.
+func (s *Store) LastUsed() (map[digest.Digest]time.Time, error) {
+	used := map[digest.Digest]time.Time{}
+	err := s.view(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketArtifacts).ForEach(func(_, data []byte) error {
+			var a Artifact
+			if err := json.Unmarshal(data, &a); err != nil {
+				return err
+			}
+			if a.Time.After(used[a.Digest]) {
+				used[a.Digest] = a.Time
+			}
+			return nil
+		})
+	})
+
+	return used, err
+}
+
+// Prune removes records older than before, returning how many were
+// removed.
+func (s *Store) Prune(before time.Time) (int, error) {
+	var removed int
+	err := s.update(func(tx *bolt.Tx) error {
+		var old [][]byte
+		c := tx.Bucket(bucketTime).Cursor()
+		for key, _ := c.First(); key != nil && bytes.Compare(key[:8], timeKey(before)) < 0; key, _ = c.Next() {
+			old = append(old, key[8:])
+		}
+
+		artifacts := tx.Bucket(bucketArtifacts)
+		for _, id := range old {
+			var a Artifact
+			if err := json.Unmarshal(artifacts.Get(id), &a); err != nil {
+				return err
+			}
+			err := index(tx, id, a, func(b *bolt.Bucket, key []byte) error {
+				return b.Delete(key)
+			})
+			if err != nil {
+				return err
+			}
+			if err := artifacts.Delete(id); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+
+	return removed, err
+}
+
+func (s *Store) path() string {
+	return filepath.Join(s.dir, "artifacts.db")
+}
+
+// update runs fn in a read-write transaction, creating the database and its
+// buckets on first use.
+func (s *Store) update(fn func(tx *bolt.Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db, err := bolt.Open(s.path(), 0644, &bolt.Options{Timeout: lockTimeout})
+	if err != nil {
+		return fmt.Errorf("failed to open store: %w", err)
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketArtifacts, bucketRepository, bucketLabel, bucketTime} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return fn(tx)
+	})
+}
+
+// view runs fn in a read-only transaction. A store that was never written
+// to is empty and fn is not called.
+func (s *Store) view(fn func(tx *bolt.Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := os.Stat(s.path()); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	db, err := bolt.Open(s.path(), 0644, &bolt.Options{Timeout: lockTimeout, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to open store: %w", err)
+	}
+	defer db.Close()
+
+	return db.View(fn)
+}
+
+// index calls fn with the index entry of record id in each index bucket,
+// to add or remove them.
+func index(tx *bolt.Tx, id []byte, a Artifact, fn func(b *bolt.Bucket, key []byte) error) error {
+	repo, err := tx.Bucket(bucketRepository).CreateBucketIfNotExists([]byte(a.Repository))
+	if err != nil {
+		return err
+	}
+	if err := fn(repo, id); err != nil {
+		return err
+	}
+
+	for key, value := range a.Annotations {
+		label, err := tx.Bucket(bucketLabel).CreateBucketIfNotExists([]byte(key))
+		if err != nil {
+			return err
+		}
+		if err := fn(label, labelKey(value, id)); err != nil {
+			return err
+		}
+	}
+
+	return fn(tx.Bucket(bucketTime), append(timeKey(a.Time), id...))
+}
+
+// candidates returns the ids of the records in the repository of q, or
+// else with one of its labels, or nil when q selects neither and every
+// record is a candidate.
+func candidates(tx *bolt.Tx, q Query) map[string]bool {
+	ids := map[string]bool{}
+	if q.Repository != "" {
+		if b := tx.Bucket(bucketRepository).Bucket([]byte(q.Repository)); b != nil {
+			b.ForEach(func(id, _ []byte) error {
+				ids[string(id)] = true
+				return nil
+			})
+		}
+		return ids
+	}
+
+	for key, value := range q.Labels {
+		b := tx.Bucket(bucketLabel).Bucket([]byte(key))
+		if b == nil {
+			return ids
+		}
+		c := b.Cursor()
+		prefix := []byte{}
+		if value != "" {
+			prefix = labelKey(value, nil)
+		}
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			ids[string(k[len(k)-8:])] = true
+		}
+		return ids
+	}
+
+	return nil
+}
+
+// walk calls fn with the records between since and until, newest first,
+// until it returns false. Zero times leave the range open.
+func walk(tx *bolt.Tx, since, until time.Time, fn func(id []byte, a Artifact) bool) error {
+	artifacts := tx.Bucket(bucketArtifacts)
+	c := tx.Bucket(bucketTime).Cursor()
+
+	var key []byte
+	if until.IsZero() {
+		key, _ = c.Last()
+	} else if key, _ = c.Seek(timeKey(until)); key != nil {
+		key, _ = c.Prev()
+	} else {
+		key, _ = c.Last()
+	}
+
+	for ; key != nil; key, _ = c.Prev() {
+		if !since.IsZero() && bytes.Compare(key[:8], timeKey(since)) < 0 {
+			return nil
+		}
+
+		id := key[8:]
+		var a Artifact
+		if err := json.Unmarshal(artifacts.Get(id), &a); err != nil {
+			return err
+		}
+		if !fn(id, a) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// itob encodes a sequence number so keys sort in insertion order.
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+// timeKey encodes t so keys sort chronologically, flipping the sign bit so
+// times before 1970 sort first.
+func timeKey(t time.Time) []byte {
+	return itob(uint64(t.UnixNano()) ^ 1<<63)
+}
+
+func labelKey(value string, id []byte) []byte {
+	return append([]byte(value+"\x00"), id...)
+}
+
+// repository strips the tag or digest from ref.
+func repository(ref string) string {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	return ref
+}
@@ -0,0 +1,167 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/oci"
+	"github.com/opencontainers/go-digest"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func newStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	records := []Artifact{
+		{Action: ActionPush, Reference: "ghcr.io/org/app:v1", Digest: digest.FromString("app-v1"), Size: 10, Annotations: map[string]string{"env": "prod"}, Time: day},
+		{Action: ActionPull, Reference: "ghcr.io/org/app:v1", Digest: digest.FromString("app-v1"), Size: 10, Time: day.Add(24 * time.Hour)},
+		{Action: ActionPush, Reference: "ghcr.io/org/app:v2", Digest: digest.FromString("app-v2"), Size: 12, Annotations: map[string]string{"env": "staging", "team": "Payments"}, Time: day.Add(48 * time.Hour)},
+		{Action: ActionPush, Reference: "localhost:5000/chart@" + digest.FromString("chart").String(), Digest: digest.FromString("chart"), Size: 3, Time: day.Add(72 * time.Hour)},
+	}
+	for _, r := range records {
+		if err := s.Record(r); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+
+	return s
+}
+
+func references(artifacts []Artifact) []string {
+	var refs []string
+	for _, a := range artifacts {
+		refs = append(refs, string(a.Action)+" "+a.Reference)
+	}
+	return refs
+}
+
+func TestFind(t *testing.T) {
+	s := newStore(t)
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		query    Query
+		expected []string
+	}{
+		{name: "By repository", query: Query{Repository: "ghcr.io/org/app"}, expected: []string{"push ghcr.io/org/app:v2", "pull ghcr.io/org/app:v1", "push ghcr.io/org/app:v1"}},
+		{name: "Repository of a digest reference", query: Query{Repository: "localhost:5000/chart"}, expected: []string{"push localhost:5000/chart@" + digest.FromString("chart").String()}},
+		{name: "By label value", query: Query{Labels: map[string]string{"env": "prod"}}, expected: []string{"push ghcr.io/org/app:v1"}},
+		{name: "By label key", query: Query{Labels: map[string]string{"env": ""}}, expected: []string{"push ghcr.io/org/app:v2", "push ghcr.io/org/app:v1"}},
+		{name: "By date", query: Query{Since: day.Add(24 * time.Hour), Until: day.Add(72 * time.Hour)}, expected: []string{"push ghcr.io/org/app:v2", "pull ghcr.io/org/app:v1"}},
+		{name: "By action with limit", query: Query{Action: ActionPush, Limit: 1}, expected: []string{"push localhost:5000/chart@" + digest.FromString("chart").String()}},
+		{name: "No match", query: Query{Repository: "missing"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			artifacts, err := s.Find(tt.query)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if got := references(artifacts); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got: %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	s := newStore(t)
+
+	artifacts, err := s.Search("payments")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got := references(artifacts); !slices.Equal(got, []string{"push ghcr.io/org/app:v2"}) {
+		t.Errorf("Expected a match on annotations, got: %v", got)
+	}
+
+	artifacts, _ = s.Search("APP:V1")
+	if got := references(artifacts); !slices.Equal(got, []string{"pull ghcr.io/org/app:v1"}) {
+		t.Errorf("Expected the latest record of each reference, got: %v", got)
+	}
+}
+
+func TestLastUsedAndPrune(t *testing.T) {
+	s := newStore(t)
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	used, err := s.LastUsed()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !used[digest.FromString("app-v1")].Equal(day.Add(24 * time.Hour)) {
+		t.Errorf("Expected app-v1 to be last used on its pull, got: %v", used[digest.FromString("app-v1")])
+	}
+
+	removed, err := s.Prune(day.Add(48 * time.Hour))
+	if err != nil || removed != 2 {
+		t.Fatalf("Expected 2 records removed, got: %d %v", removed, err)
+	}
+
+	artifacts, _ := s.Find(Query{})
+	if len(artifacts) != 2 {
+		t.Errorf("Expected 2 records left, got: %v", references(artifacts))
+	}
+	if err := s.Record(Artifact{Action: ActionPull, Reference: "ghcr.io/org/app:v3", Digest: digest.FromString("v3")}); err != nil {
+		t.Fatalf("Expected recording after prune to succeed, got: %v", err)
+	}
+	if history, _ := s.History("ghcr.io/org/app", 0); len(history) != 2 {
+		t.Errorf("Expected 2 app records, got: %v", references(history))
+	}
+}
+
+func TestSharedStore(t *testing.T) {
+	dir := t.TempDir()
+	first, _ := Open(dir)
+	second, _ := Open(dir)
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	for i, s := range []*Store{first, second} {
+		wg.Add(1)
+		go func(i int, s *Store) {
+			defer wg.Done()
+			err := s.Record(Artifact{Action: ActionPush, Reference: fmt.Sprintf("ghcr.io/org/app:v%d", i), Digest: digest.FromString("app"), Time: day.Add(time.Duration(i) * time.Hour)})
+			if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		}(i, s)
+	}
+	wg.Wait()
+
+	artifacts, err := first.History("ghcr.io/org/app", 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got := references(artifacts); !slices.Equal(got, []string{"push ghcr.io/org/app:v1", "push ghcr.io/org/app:v0"}) {
+		t.Errorf("Expected both records newest first, got: %v", got)
+	}
+}
+
+func TestFromManifest(t *testing.T) {
+	manifest := &spec.Manifest{
+		Config:      spec.Descriptor{Size: 100},
+		Layers:      []spec.Descriptor{{Size: 1000}, {Size: 24}},
+		Annotations: map[string]string{"org.opencontainers.image.title": "app"},
+	}
+	tag := oci.Tag{Host: "ghcr.io", Namespace: "org", Name: "app", Version: "v1"}
+
+	a := fromManifest("push_manifest", tag, digest.FromString("manifest"), manifest)
+	if a.Action != ActionPush || a.Reference != "ghcr.io/org/app:v1" || a.Size != 1124 || a.MediaType != spec.MediaTypeImageManifest {
+		t.Errorf("Expected a push of ghcr.io/org/app:v1 with size 1124, got: %+v", a)
+	}
+	if fromManifest("pull_manifest", tag, "", manifest).Action != ActionPull {
+		t.Error("Expected pull_manifest to be recorded as a pull")
+	}
+}
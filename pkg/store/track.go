@@ -0,0 +1,48 @@
+package store
+
+import (
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/oci"
+	"github.com/opencontainers/go-digest"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Track records every manifest pushed or pulled by an oci.OciClient in s.
+// Failures to record are logged rather than failing the transfer.
+func Track(s *Store) {
+	oci.OnArtifact(func(op string, tag oci.Tag, d digest.Digest, manifest *spec.Manifest) {
+		if err := s.Record(fromManifest(op, tag, d, manifest)); err != nil {
+			log.Component("store").Debug("failed to record artifact", "reference", tag.String(), log.KeyError, err.Error())
+		}
+	})
+}
+
+func fromManifest(op string, tag oci.Tag, d digest.Digest, manifest *spec.Manifest) Artifact {
+	action := ActionPull
+	if strings.HasPrefix(op, "push") {
+		action = ActionPush
+	}
+
+	size := manifest.Config.Size
+	for _, layer := range manifest.Layers {
+		size += layer.Size
+	}
+
+	mediaType := manifest.MediaType
+	if manifest.ArtifactType != "" {
+		mediaType = manifest.ArtifactType
+	} else if mediaType == "" {
+		mediaType = spec.MediaTypeImageManifest
+	}
+
+	return Artifact{
+		Action:      action,
+		Reference:   tag.String(),
+		Digest:      d,
+		MediaType:   mediaType,
+		Size:        size,
+		Annotations: manifest.Annotations,
+	}
+}
@@ -0,0 +1,108 @@
+package style
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+
+	"golang.org/x/term"
+)
+
+const (
+	codeBold   = "1"
+	codeDim    = "2"
+	codeRed    = "31"
+	codeGreen  = "32"
+	codeYellow = "33"
+	codeCyan   = "36"
+)
+
+var enabled atomic.Bool
+
+func init() {
+	enabled.Store(detect())
+}
+
+// detect disables color when NO_COLOR is set, CI is true or stdout is not a
+// terminal.
+func detect() bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	if ci, err := strconv.ParseBool(os.Getenv("CI")); err == nil && ci {
+		return false
+	}
+
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// Enabled reports whether styling functions emit ANSI escape sequences.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// SetEnabled overrides the detected color support, e.g. for a --color flag.
+func SetEnabled(on bool) {
+	enabled.Store(on)
+}
+
+func Success(s string) string {
+	return wrap(codeGreen, s)
+}
+
+func Warn(s string) string {
+	return wrap(codeYellow, s)
+}
+
+func Error(s string) string {
+	return wrap(codeRed, s)
+}
+
+func Info(s string) string {
+	return wrap(codeCyan, s)
+}
+
+func Bold(s string) string {
+	return wrap(codeBold, s)
+}
+
+func Dim(s string) string {
+	return wrap(codeDim, s)
+}
+
+func Successf(format string, args ...any) string {
+	return Success(fmt.Sprintf(format, args...))
+}
+
+func Warnf(format string, args ...any) string {
+	return Warn(fmt.Sprintf(format, args...))
+}
+
+func Errorf(format string, args ...any) string {
+	return Error(fmt.Sprintf(format, args...))
+}
+
+func wrap(code, s string) string {
+	if !Enabled() || s == "" {
+		return s
+	}
+
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// FormatBytes formats n in binary units, e.g. "1.5 KiB".
+func FormatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,59 @@
+package style
+
+import "testing"
+
+func TestStyle(t *testing.T) {
+	defer SetEnabled(Enabled())
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		fn       func(string) string
+		input    string
+		expected string
+	}{
+		{"success", true, Success, "done", "\x1b[32mdone\x1b[0m"},
+		{"error", true, Error, "failed", "\x1b[31mfailed\x1b[0m"},
+		{"bold", true, Bold, "name", "\x1b[1mname\x1b[0m"},
+		{"empty", true, Warn, "", ""},
+		{"disabled", false, Success, "done", "done"},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			SetEnabled(tt.enabled)
+			if got := tt.fn(tt.input); got != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	if detect() {
+		t.Error("Expected color to be disabled when NO_COLOR is set")
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n        uint64
+		expected string
+	}{
+		{512, "512 B"},
+		{1536, "1.5 KiB"},
+		{20 << 30, "20.0 GiB"},
+		{1<<64 - 1, "16.0 EiB"},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := FormatBytes(tt.n); got != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, got)
+			}
+		})
+	}
+}
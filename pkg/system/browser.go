@@ -0,0 +1,131 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// launchWait is how long an opener is watched for an early failure before it
+// is assumed to have handed the URL to a browser.
+const launchWait = 2 * time.Second
+
+// Opener is a command used to open a URL. A "%s" argument is replaced with
+// the URL; otherwise the URL is appended.
+type Opener []string
+
+// OpenURLError is returned when no opener could open URL, so callers can
+// print the link for the user instead.
+type OpenURLError struct {
+	URL string
+	Err error
+}
+
+func (e *OpenURLError) Error() string {
+	return fmt.Sprintf("could not open %s: %s", e.URL, e.Err.Error())
+}
+
+func (e *OpenURLError) Unwrap() error {
+	return e.Err
+}
+
+// OpenURLWith tries each opener in turn until one launches successfully.
+func OpenURLWith(url string, openers ...Opener) error {
+	if len(openers) == 0 {
+		return &OpenURLError{URL: url, Err: fmt.Errorf("no URL opener available for %s", runtime.GOOS)}
+	}
+
+	var errs []error
+	for _, opener := range openers {
+		if len(opener) == 0 {
+			continue
+		}
+
+		err := launch(opener.command(url))
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", opener[0], err))
+	}
+
+	return &OpenURLError{URL: url, Err: errors.Join(errs...)}
+}
+
+// browserOpeners parses $BROWSER, a list of commands separated like PATH.
+func browserOpeners() []Opener {
+	var openers []Opener
+	for _, entry := range strings.Split(os.Getenv("BROWSER"), string(os.PathListSeparator)) {
+		if fields := strings.Fields(entry); len(fields) > 0 {
+			openers = append(openers, Opener(fields))
+		}
+	}
+
+	return openers
+}
+
+func defaultOpeners() []Opener {
+	switch runtime.GOOS {
+	case "linux":
+		if IsWSL() {
+			return []Opener{{"wslview"}, {"cmd.exe", "/C", "start"}}
+		}
+		return []Opener{{"xdg-open"}, {"sensible-browser"}, {"x-www-browser"}}
+	case "windows":
+		return []Opener{{"rundll32", "url.dll,FileProtocolHandler"}}
+	case "darwin":
+		return []Opener{{"open"}}
+	default:
+		return []Opener{{"xdg-open"}}
+	}
+}
+
+func (o Opener) command(url string) []string {
+	// cmd.exe treats & and friends as command separators, even in URLs.
+	name := strings.ToLower(filepath.Base(o[0]))
+	if name == "cmd" || name == "cmd.exe" {
+		url = strings.NewReplacer("^", "^^", "&", "^&", "|", "^|", "<", "^<", ">", "^>").Replace(url)
+	}
+
+	args := make([]string, 0, len(o)+1)
+	substituted := false
+	for _, arg := range o {
+		if strings.Contains(arg, "%s") {
+			arg = strings.ReplaceAll(arg, "%s", url)
+			substituted = true
+		}
+		args = append(args, arg)
+	}
+
+	if !substituted {
+		args = append(args, url)
+	}
+
+	return args
+}
+
+// launch starts args and reports an error if it cannot be started or exits
+// unsuccessfully within launchWait. Openers still running after that are
+// assumed to be a browser that stays in the foreground.
+func launch(args []string) error {
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(launchWait):
+		return nil
+	}
+}
@@ -0,0 +1,64 @@
+package system
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestOpenerCommand(t *testing.T) {
+	url := "https://example.com/?a=1&b=2"
+
+	tests := []struct {
+		name     string
+		opener   Opener
+		expected []string
+	}{
+		{name: "Appends URL", opener: Opener{"xdg-open"}, expected: []string{"xdg-open", url}},
+		{name: "Substitutes placeholder", opener: Opener{"firefox", "--new-tab", "%s"}, expected: []string{"firefox", "--new-tab", url}},
+		{name: "Escapes for cmd.exe", opener: Opener{"cmd.exe", "/C", "start"}, expected: []string{"cmd.exe", "/C", "start", "https://example.com/?a=1^&b=2"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.opener.command(url)
+			if strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("command() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOpenURLWith(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires true and false binaries")
+	}
+
+	tests := []struct {
+		name        string
+		openers     []Opener
+		expectError bool
+	}{
+		{name: "First opener succeeds", openers: []Opener{{"true"}}},
+		{name: "Falls back to next opener", openers: []Opener{{"devkit-missing-browser"}, {"false"}, {"true"}}},
+		{name: "All openers fail", openers: []Opener{{"devkit-missing-browser"}, {"false"}}, expectError: true},
+		{name: "No openers", expectError: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			err := OpenURLWith("https://example.com", tt.openers...)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+
+			var openErr *OpenURLError
+			if tt.expectError && (!errors.As(err, &openErr) || openErr.URL != "https://example.com") {
+				t.Errorf("Expected OpenURLError carrying the URL, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+type clipboardTool struct {
+	copy  []string
+	paste []string
+}
+
+func clipboardTools() []clipboardTool {
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipboardTool{{copy: []string{"pbcopy"}, paste: []string{"pbpaste"}}}
+	case "windows":
+		return []clipboardTool{{
+			copy:  []string{"clip"},
+			paste: []string{"powershell", "-NoProfile", "-Command", "Get-Clipboard"},
+		}}
+	default:
+		var tools []clipboardTool
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			tools = append(tools, clipboardTool{copy: []string{"wl-copy"}, paste: []string{"wl-paste", "--no-newline"}})
+		}
+		return append(tools,
+			clipboardTool{copy: []string{"xclip", "-selection", "clipboard"}, paste: []string{"xclip", "-selection", "clipboard", "-o"}},
+			clipboardTool{copy: []string{"xsel", "--clipboard", "--input"}, paste: []string{"xsel", "--clipboard", "--output"}},
+		)
+	}
+}
+
+func findClipboardTool() (clipboardTool, error) {
+	for _, tool := range clipboardTools() {
+		if _, err := exec.LookPath(tool.copy[0]); err == nil {
+			return tool, nil
+		}
+	}
+
+	return clipboardTool{}, fmt.Errorf("no clipboard utility found for %s", runtime.GOOS)
+}
+
+func CopyToClipboard(text string) error {
+	tool, err := findClipboardTool()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(tool.copy[0], tool.copy[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to copy to clipboard: %s: %s", err.Error(), strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+func ReadClipboard() (string, error) {
+	tool, err := findClipboardTool()
+	if err != nil {
+		return "", err
+	}
+
+	out, err := exec.Command(tool.paste[0], tool.paste[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read clipboard: %s", err.Error())
+	}
+
+	if runtime.GOOS == "windows" {
+		return strings.TrimRight(string(out), "\r\n"), nil
+	}
+
+	return string(out), nil
+}
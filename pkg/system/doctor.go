@@ -0,0 +1,182 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	devexec "github.com/eunanio/sdk/pkg/exec"
+)
+
+const checkTimeout = 10 * time.Second
+
+type CheckStatus string
+
+const (
+	StatusPass CheckStatus = "pass"
+	StatusWarn CheckStatus = "warn"
+	StatusFail CheckStatus = "fail"
+)
+
+// Check is a single environment check. Run returns a short description of
+// what was found, or an error when the check fails. Failures of Optional
+// checks are reported as warnings.
+type Check struct {
+	Name     string
+	Optional bool
+	Run      func(ctx context.Context) (string, error)
+}
+
+type CheckResult struct {
+	Name     string        `json:"name"`
+	Status   CheckStatus   `json:"status"`
+	Message  string        `json:"message"`
+	Duration time.Duration `json:"duration"`
+}
+
+type Report struct {
+	Results []CheckResult `json:"results"`
+}
+
+// Passed reports whether no check failed. Warnings do not fail a report.
+func (r Report) Passed() bool {
+	for _, result := range r.Results {
+		if result.Status == StatusFail {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r Report) Print(w io.Writer) {
+	for _, result := range r.Results {
+		fmt.Fprintf(w, "[%s] %s: %s\n", strings.ToUpper(string(result.Status)), result.Name, result.Message)
+	}
+}
+
+// Doctor runs checks concurrently and returns their results in order.
+func Doctor(checks ...Check) Report {
+	return DoctorContext(context.Background(), checks...)
+}
+
+func DoctorContext(ctx context.Context, checks ...Check) Report {
+	report := Report{Results: make([]CheckResult, len(checks))}
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			report.Results[i] = runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func runCheck(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	message, err := check.Run(ctx)
+	result := CheckResult{Name: check.Name, Status: StatusPass, Message: message, Duration: time.Since(start)}
+	if err != nil {
+		result.Status = StatusFail
+		if check.Optional {
+			result.Status = StatusWarn
+		}
+		result.Message = err.Error()
+	}
+
+	return result
+}
+
+// BinaryCheck verifies that binary is on PATH and satisfies constraint, such
+// as ">=24.0". An empty constraint only checks presence.
+func BinaryCheck(binary, constraint string) Check {
+	name := binary
+	if constraint != "" {
+		name = fmt.Sprintf("%s %s", binary, constraint)
+	}
+
+	return Check{
+		Name: name,
+		Run: func(ctx context.Context) (string, error) {
+			if err := devexec.Require(binary, constraint); err != nil {
+				return "", err
+			}
+			return "found", nil
+		},
+	}
+}
+
+func EnvCheck(name string) Check {
+	return Check{
+		Name: "$" + name,
+		Run: func(ctx context.Context) (string, error) {
+			if os.Getenv(name) == "" {
+				return "", fmt.Errorf("%s is not set", name)
+			}
+			return "set", nil
+		},
+	}
+}
+
+// WritableCheck verifies that a file can be created in dir.
+func WritableCheck(dir string) Check {
+	return Check{
+		Name: dir + " writable",
+		Run: func(ctx context.Context) (string, error) {
+			file, err := os.CreateTemp(dir, ".devkit-doctor-")
+			if err != nil {
+				return "", fmt.Errorf("%s is not writable: %w", dir, err)
+			}
+			file.Close()
+			os.Remove(file.Name())
+			return "writable", nil
+		},
+	}
+}
+
+// NetworkCheck verifies that a TCP connection to address (host:port) can be
+// established.
+func NetworkCheck(address string) Check {
+	return Check{
+		Name: address + " reachable",
+		Run: func(ctx context.Context) (string, error) {
+			var dialer net.Dialer
+			conn, err := dialer.DialContext(ctx, "tcp", address)
+			if err != nil {
+				return "", fmt.Errorf("cannot reach %s: %w", address, err)
+			}
+			conn.Close()
+			return "reachable", nil
+		},
+	}
+}
+
+// DockerCheck verifies that the docker CLI can talk to a running daemon.
+func DockerCheck() Check {
+	return Check{
+		Name: "docker daemon",
+		Run: func(ctx context.Context) (string, error) {
+			out, err := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Version}}").CombinedOutput()
+			if err != nil {
+				reason := strings.TrimSpace(string(out))
+				if reason == "" {
+					reason = err.Error()
+				}
+				return "", fmt.Errorf("docker daemon is not available: %s", reason)
+			}
+			return "running " + strings.TrimSpace(string(out)), nil
+		},
+	}
+}
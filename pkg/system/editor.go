@@ -0,0 +1,71 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Editor returns the user's preferred editor command from $VISUAL or
+// $EDITOR, falling back to notepad on Windows and vi elsewhere. The result
+// may include arguments, e.g. "code --wait".
+func Editor() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.Fields(os.Getenv(name)); len(editor) > 0 {
+			return editor
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+
+	return []string{"vi"}
+}
+
+// Edit writes content to a temporary file with the given extension (such as
+// ".json"), opens it in the user's editor and returns the saved content once
+// the editor exits.
+func Edit(content string, extension string) (string, error) {
+	if extension != "" && !strings.HasPrefix(extension, ".") {
+		extension = "." + extension
+	}
+
+	dir, err := os.MkdirTemp("", "devkit-edit-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "edit"+extension)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	// The editor reads the terminal itself, so stdin is held while it runs
+	// and a line typed for an earlier timed out read is kept for later.
+	pending, unlock := lockStdin()
+	defer unlock()
+	if pending != nil {
+		defer keepPending(*pending)
+	}
+
+	editor := Editor()
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %s failed: %w", editor[0], err)
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited file: %w", err)
+	}
+
+	return string(edited), nil
+}
@@ -0,0 +1,119 @@
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"runtime"
+	"strings"
+)
+
+type HostInfo struct {
+	OS            string `json:"os"`
+	Arch          string `json:"arch"`
+	KernelVersion string `json:"kernel_version,omitempty"`
+	Distro        string `json:"distro,omitempty"`
+	DistroVersion string `json:"distro_version,omitempty"`
+	DistroName    string `json:"distro_name,omitempty"`
+	Container     bool   `json:"container"`
+	WSL           bool   `json:"wsl"`
+	CPUs          int    `json:"cpus"`
+	TotalMemory   uint64 `json:"total_memory_bytes,omitempty"`
+}
+
+// Info collects a best-effort description of the host for diagnostics.
+// Fields that cannot be determined on the current platform are left empty.
+func Info() HostInfo {
+	info := HostInfo{
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		KernelVersion: kernelVersion(),
+		Container:     IsContainer(),
+		WSL:           IsWSL(),
+		CPUs:          runtime.NumCPU(),
+		TotalMemory:   totalMemory(),
+	}
+
+	if runtime.GOOS == "linux" {
+		for _, path := range []string{"/etc/os-release", "/usr/lib/os-release"} {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+
+			release := parseOSRelease(data)
+			info.Distro = release["ID"]
+			info.DistroVersion = release["VERSION_ID"]
+			info.DistroName = release["PRETTY_NAME"]
+			break
+		}
+	}
+
+	return info
+}
+
+// IsWSL reports whether the process runs under Windows Subsystem for Linux.
+func IsWSL() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+
+	version, err := os.ReadFile("/proc/version")
+	return err == nil && bytes.Contains(bytes.ToLower(version), []byte("microsoft"))
+}
+
+// IsContainer reports whether the process appears to run inside a container
+// such as Docker, Podman or a Kubernetes pod.
+func IsContainer() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return true
+	}
+
+	cgroup, err := os.ReadFile("/proc/1/cgroup")
+	if err != nil {
+		return false
+	}
+
+	for _, name := range []string{"docker", "kubepods", "containerd", "libpod", "lxc"} {
+		if bytes.Contains(cgroup, []byte(name)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseOSRelease parses the KEY=value format of os-release(5).
+func parseOSRelease(data []byte) map[string]string {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		values[key] = strings.Trim(value, `"'`)
+	}
+
+	return values
+}
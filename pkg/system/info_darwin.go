@@ -0,0 +1,21 @@
+package system
+
+import "golang.org/x/sys/unix"
+
+func kernelVersion() string {
+	release, err := unix.Sysctl("kern.osrelease")
+	if err != nil {
+		return ""
+	}
+
+	return release
+}
+
+func totalMemory() uint64 {
+	memory, err := unix.SysctlUint64("hw.memsize")
+	if err != nil {
+		return 0
+	}
+
+	return memory
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !windows
+
+package system
+
+func kernelVersion() string {
+	return ""
+}
+
+func totalMemory() uint64 {
+	return 0
+}
@@ -0,0 +1,38 @@
+package system
+
+import "testing"
+
+func TestParseOSRelease(t *testing.T) {
+	data := []byte(`# comment
+NAME="Ubuntu"
+VERSION_ID="22.04"
+ID=ubuntu
+PRETTY_NAME='Ubuntu 22.04.4 LTS'
+
+INVALID LINE
+`)
+
+	expected := map[string]string{
+		"NAME":        "Ubuntu",
+		"VERSION_ID":  "22.04",
+		"ID":          "ubuntu",
+		"PRETTY_NAME": "Ubuntu 22.04.4 LTS",
+	}
+
+	got := parseOSRelease(data)
+	if len(got) != len(expected) {
+		t.Errorf("Expected %d values, got: %v", len(expected), got)
+	}
+	for key, value := range expected {
+		if got[key] != value {
+			t.Errorf("Expected %s = %q, got: %q", key, value, got[key])
+		}
+	}
+}
+
+func TestInfo(t *testing.T) {
+	info := Info()
+	if info.OS == "" || info.Arch == "" || info.CPUs < 1 {
+		t.Errorf("Expected OS, architecture and CPU count to be set, got: %+v", info)
+	}
+}
@@ -0,0 +1,56 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// FreePort asks the kernel for an unused TCP port on localhost. The port is
+// released before returning, so another process could claim it first.
+func FreePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free port: %w", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// PrimaryIP returns the local address used for outbound traffic. No packets
+// are sent; connecting a UDP socket only selects a route.
+func PrimaryIP() (net.IP, error) {
+	conn, err := net.Dial("udp", "192.0.2.1:80")
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine primary IP: %w", err)
+	}
+	defer conn.Close()
+
+	return conn.LocalAddr().(*net.UDPAddr).IP, nil
+}
+
+// WaitForPort polls until a TCP connection to host:port succeeds or ctx is
+// done.
+func WaitForPort(ctx context.Context, host string, port int) error {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	dialer := &net.Dialer{Timeout: time.Second}
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s: %w", address, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
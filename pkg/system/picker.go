@@ -0,0 +1,167 @@
+package system
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// PathFilter reports whether path can be picked. Directories are always
+// listed so they can be browsed, but are only selectable when the filter
+// accepts them.
+type PathFilter func(path string, entry fs.DirEntry) bool
+
+// DirsOnly accepts directories.
+func DirsOnly(path string, entry fs.DirEntry) bool {
+	return entry.IsDir()
+}
+
+// FilesWithExt accepts regular files with one of the given extensions, such
+// as ".tgz".
+func FilesWithExt(exts ...string) PathFilter {
+	return func(path string, entry fs.DirEntry) bool {
+		return !entry.IsDir() && slices.Contains(exts, filepath.Ext(path))
+	}
+}
+
+const pickerHeight = 15
+
+// PickPath lets the user browse from start and choose a path accepted by
+// filter; a nil filter accepts everything. When prompting is disabled start
+// itself is returned if the filter accepts it, so a flag value can be passed
+// straight through; otherwise ErrNonInteractive is returned. With piped input
+// a single line holding the path, relative to start, is read instead.
+func PickPath(start string, filter PathFilter) (string, error) {
+	if filter == nil {
+		filter = func(string, fs.DirEntry) bool { return true }
+	}
+
+	start, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+
+	if nonInteractive {
+		if accepts(start, filter) {
+			return start, nil
+		}
+		return "", ErrNonInteractive
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return pickFromLine(start, filter, readLine)
+	}
+
+	pending, unlock := lockStdin()
+	defer unlock()
+	if pending != nil {
+		return pickFromLine(start, filter, pending.answer)
+	}
+
+	dir := start
+	for {
+		options, paths, err := pickerOptions(dir, filter)
+		if err != nil {
+			return "", err
+		}
+
+		height := min(pickerHeight, len(options))
+		selected, err := selectRaw(fd, dir, options, height)
+		fmt.Fprintf(os.Stderr, "\x1b[%dA\x1b[J", height+1)
+		if err != nil {
+			return "", err
+		}
+
+		path := paths[selected]
+		if path == dir {
+			return path, nil
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		if !info.IsDir() {
+			return path, nil
+		}
+		dir = path
+	}
+}
+
+// pickerOptions lists dir for the picker. The first entry selects dir itself
+// when the filter accepts it, followed by the parent and the directory
+// contents with directories first.
+func pickerOptions(dir string, filter PathFilter) (options, paths []string, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	if accepts(dir, filter) {
+		options = append(options, ". (select this directory)")
+		paths = append(paths, dir)
+	}
+	if parent := filepath.Dir(dir); parent != dir {
+		options = append(options, "../")
+		paths = append(paths, parent)
+	}
+
+	slices.SortStableFunc(entries, func(a, b fs.DirEntry) int {
+		if a.IsDir() != b.IsDir() {
+			if a.IsDir() {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		switch {
+		case entry.IsDir():
+			options = append(options, entry.Name()+"/")
+		case filter(path, entry):
+			options = append(options, entry.Name())
+		default:
+			continue
+		}
+		paths = append(paths, path)
+	}
+
+	return options, paths, nil
+}
+
+func pickFromLine(start string, filter PathFilter, read func() (string, error)) (string, error) {
+	fmt.Fprintf(os.Stderr, "Path (relative to %s): ", start)
+	answer, err := read()
+	if err != nil {
+		return "", err
+	}
+
+	path := strings.TrimSpace(answer)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(start, path)
+	}
+
+	if !accepts(path, filter) {
+		return "", fmt.Errorf("invalid selection: %s", path)
+	}
+
+	return path, nil
+}
+
+func accepts(path string, filter PathFilter) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	return filter(path, fs.FileInfoToDirEntry(info))
+}
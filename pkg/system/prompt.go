@@ -0,0 +1,269 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+var ErrInterrupted = errors.New("interrupted")
+
+// PromptPassword prints label to stderr and reads a secret without echoing
+// it. Ctrl+C restores the terminal and returns ErrInterrupted. When stdin is
+// not a terminal a single line is read from it instead.
+func PromptPassword(label string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return readLine()
+	}
+
+	pending, unlock := lockStdin()
+	defer unlock()
+	if pending != nil {
+		return pending.answer()
+	}
+
+	fmt.Fprint(os.Stderr, label)
+	defer fmt.Fprintln(os.Stderr)
+
+	// Raw mode turns off echo and ISIG, so Ctrl+C arrives as a byte rather
+	// than a signal and no read is left running once it is handled.
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(fd, state)
+
+	return readPassword(stdinReader)
+}
+
+// readPassword reads a line typed in raw mode, handling the editing keys
+// the terminal would otherwise have handled.
+func readPassword(r io.Reader) (string, error) {
+	var password []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return "", err
+		}
+		if n == 0 {
+			continue
+		}
+
+		switch key := buf[0]; key {
+		case '\r', '\n':
+			return string(password), nil
+		case 0x03: // Ctrl+C
+			return "", ErrInterrupted
+		case 0x04: // Ctrl+D
+			if len(password) == 0 {
+				return "", io.EOF
+			}
+		case 0x15: // Ctrl+U
+			password = password[:0]
+		case 0x7f, 0x08: // Backspace
+			_, size := utf8.DecodeLastRune(password)
+			password = password[:len(password)-size]
+		default:
+			password = append(password, key)
+		}
+	}
+}
+
+var ErrNonInteractive = errors.New("input required but running non-interactively")
+
+var nonInteractive = envBool("DEVKIT_NONINTERACTIVE") || envBool("CI")
+
+// SetNonInteractive disables prompting, typically from a --yes or
+// --non-interactive flag. Prompts then return their default answer, or
+// ErrNonInteractive when there is none. It can also be enabled by setting
+// DEVKIT_NONINTERACTIVE or CI.
+func SetNonInteractive(enabled bool) {
+	nonInteractive = enabled
+}
+
+// IsNonInteractive reports whether prompting is disabled.
+func IsNonInteractive() bool {
+	return nonInteractive
+}
+
+// Input asks for a line of text, returning def when the answer is empty or
+// prompting is disabled. ErrNonInteractive is returned when prompting is
+// disabled and def is empty.
+func Input(label, def string) (string, error) {
+	if nonInteractive {
+		if def == "" {
+			return "", ErrNonInteractive
+		}
+		return def, nil
+	}
+
+	if def != "" {
+		fmt.Fprintf(os.Stderr, "%s [%s]: ", label, def)
+	} else {
+		fmt.Fprintf(os.Stderr, "%s: ", label)
+	}
+
+	answer, err := readLine()
+	if err != nil {
+		if err == io.EOF && def != "" {
+			return def, nil
+		}
+		return "", err
+	}
+
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// Confirm asks a yes/no question, returning def when the answer is empty or
+// prompting is disabled.
+func Confirm(question string, def bool) (bool, error) {
+	if nonInteractive {
+		return def, nil
+	}
+
+	hint := "[y/N]"
+	if def {
+		hint = "[Y/n]"
+	}
+
+	for {
+		fmt.Fprintf(os.Stderr, "%s %s: ", question, hint)
+		answer, err := readLine()
+		if err != nil {
+			if err == io.EOF {
+				return def, nil
+			}
+			return false, err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// Select asks the user to choose one of options and returns its index. On a
+// terminal the choice is made with the arrow keys (or j/k) and Enter; with
+// piped input a line holding the option number or text is read instead.
+func Select(label string, options []string) (int, error) {
+	if len(options) == 0 {
+		return -1, fmt.Errorf("no options to select from")
+	}
+
+	if nonInteractive {
+		return -1, ErrNonInteractive
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return selectFromLine(label, options, readLine)
+	}
+
+	pending, unlock := lockStdin()
+	defer unlock()
+	if pending != nil {
+		return selectFromLine(label, options, pending.answer)
+	}
+
+	return selectRaw(fd, label, options, len(options))
+}
+
+// selectRaw runs the arrow key menu, showing at most height options at a
+// time and scrolling to keep the selection visible. The caller must hold
+// stdin, see lockStdin.
+func selectRaw(fd int, label string, options []string, height int) (int, error) {
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return -1, err
+	}
+	defer term.Restore(fd, state)
+
+	height = min(height, len(options))
+	fmt.Fprintf(os.Stderr, "%s\r\n", label)
+	selected, offset := 0, 0
+	renderOptions(options, selected, offset, height, false)
+
+	buf := make([]byte, 3)
+	for {
+		n, err := stdinReader.Read(buf)
+		if err != nil {
+			return -1, err
+		}
+
+		switch key := string(buf[:n]); key {
+		case "\x1b[A", "k":
+			selected = (selected - 1 + len(options)) % len(options)
+		case "\x1b[B", "j":
+			selected = (selected + 1) % len(options)
+		case "\r", "\n":
+			return selected, nil
+		case "\x03":
+			return -1, ErrInterrupted
+		default:
+			continue
+		}
+
+		if selected < offset {
+			offset = selected
+		} else if selected >= offset+height {
+			offset = selected - height + 1
+		}
+		renderOptions(options, selected, offset, height, true)
+	}
+}
+
+func renderOptions(options []string, selected, offset, height int, redraw bool) {
+	if redraw {
+		fmt.Fprintf(os.Stderr, "\x1b[%dA", height)
+	}
+
+	for i := offset; i < offset+height; i++ {
+		cursor := "  "
+		if i == selected {
+			cursor = "> "
+		}
+		fmt.Fprintf(os.Stderr, "\x1b[2K%s%s\r\n", cursor, options[i])
+	}
+}
+
+func selectFromLine(label string, options []string, read func() (string, error)) (int, error) {
+	fmt.Fprintln(os.Stderr, label)
+	for i, option := range options {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, option)
+	}
+
+	answer, err := read()
+	if err != nil {
+		return -1, err
+	}
+
+	answer = strings.TrimSpace(answer)
+	for i, option := range options {
+		if answer == option || answer == strconv.Itoa(i+1) {
+			return i, nil
+		}
+	}
+
+	return -1, fmt.Errorf("invalid selection: %s", answer)
+}
+
+func envBool(name string) bool {
+	value, err := strconv.ParseBool(os.Getenv(name))
+	return err == nil && value
+}
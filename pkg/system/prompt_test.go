@@ -0,0 +1,269 @@
+package system
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		def            bool
+		nonInteractive bool
+		expected       bool
+	}{
+		{name: "Yes", input: "y\n", expected: true},
+		{name: "No with default yes", input: "no\n", def: true, expected: false},
+		{name: "Empty answer uses default", input: "\n", def: true, expected: true},
+		{name: "Invalid answer asks again", input: "maybe\nYES\n", expected: true},
+		{name: "End of input uses default", input: "", def: true, expected: true},
+		{name: "Non-interactive uses default", input: "y\n", nonInteractive: true, expected: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			stdinReader = bufio.NewReader(strings.NewReader(tt.input))
+			SetNonInteractive(tt.nonInteractive)
+			defer SetNonInteractive(false)
+
+			got, err := Confirm("overwrite existing tag?", tt.def)
+			if err != nil {
+				t.Fatalf("Confirm() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Confirm() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInput(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		def            string
+		nonInteractive bool
+		expected       string
+		expectError    bool
+	}{
+		{name: "Answer", input: "  my-app \n", expected: "my-app"},
+		{name: "Empty answer uses default", input: "\n", def: "demo", expected: "demo"},
+		{name: "End of input uses default", input: "", def: "demo", expected: "demo"},
+		{name: "End of input without default", input: "", expectError: true},
+		{name: "Non-interactive uses default", input: "other\n", def: "demo", nonInteractive: true, expected: "demo"},
+		{name: "Non-interactive without default", nonInteractive: true, expectError: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			stdinReader = bufio.NewReader(strings.NewReader(tt.input))
+			SetNonInteractive(tt.nonInteractive)
+			defer SetNonInteractive(false)
+
+			got, err := Input("Project name", tt.def)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Input() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSelectFromLine(t *testing.T) {
+	options := []string{"linux", "darwin", "windows"}
+
+	tests := []struct {
+		name        string
+		input       string
+		expected    int
+		expectError bool
+	}{
+		{name: "By number", input: "2\n", expected: 1},
+		{name: "By text", input: "windows\n", expected: 2},
+		{name: "Out of range", input: "4\n", expected: -1, expectError: true},
+		{name: "Unknown option", input: "plan9\n", expected: -1, expectError: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			stdinReader = bufio.NewReader(strings.NewReader(tt.input))
+
+			got, err := selectFromLine("Choose a platform", options, readLine)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+			if got != tt.expected {
+				t.Errorf("selectFromLine() = %d, expected %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadPassword(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    string
+		expectedErr error
+	}{
+		{name: "Enter", input: "s3cret\r", expected: "s3cret"},
+		{name: "Backspace", input: "s3cx\x7fret\r", expected: "s3cret"},
+		{name: "Backspace multibyte", input: "pä\x7fa\r", expected: "pa"},
+		{name: "Backspace on empty", input: "\x7fok\r", expected: "ok"},
+		{name: "Ctrl+U clears", input: "wrong\x15right\r", expected: "right"},
+		{name: "Ctrl+C", input: "s3c\x03ret\r", expectedErr: ErrInterrupted},
+		{name: "Ctrl+D on empty", input: "\x04", expectedErr: io.EOF},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			r := strings.NewReader(tt.input)
+			got, err := readPassword(r)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Expected error %v, got: %v", tt.expectedErr, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, got)
+			}
+			if tt.expectedErr == ErrInterrupted && r.Len() != 4 {
+				t.Errorf("Expected reading to stop at Ctrl+C, %d bytes left", r.Len())
+			}
+		})
+	}
+}
+
+func TestReadLineWithTimeout(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	stdinReader = bufio.NewReader(pr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := ReadLineWithTimeout(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got: %v", err)
+	}
+
+	go pw.Write([]byte("late line\r\n"))
+
+	// Prompts read stdin too and must get the abandoned read's line.
+	line, err := readLine()
+	if err != nil {
+		t.Fatalf("readLine() error = %v", err)
+	}
+	if line != "late line" {
+		t.Errorf("Expected %q, got: %q", "late line", line)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := ReadLineWithTimeout(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got: %v", err)
+	}
+
+	go pw.Write([]byte("typed late\n"))
+
+	// Raw mode prompts wait for the abandoned read before reading stdin.
+	pending, unlock := lockStdin()
+	if pending == nil {
+		t.Fatal("Expected the abandoned read's result")
+	}
+	if answer, _ := pending.answer(); answer != "typed late" {
+		t.Errorf("Expected %q, got: %q", "typed late", answer)
+	}
+
+	go pw.Write([]byte("secret\r"))
+	password, err := readPassword(stdinReader)
+	unlock()
+	if err != nil || password != "secret" {
+		t.Errorf("Expected %q, got: %q, %v", "secret", password, err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := ReadLineWithTimeout(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got: %v", err)
+	}
+
+	go func() {
+		pw.Write([]byte("first\nrest"))
+		pw.Close()
+	}()
+
+	data, err := ReadAllStdin()
+	if err != nil {
+		t.Fatalf("ReadAllStdin() error = %v", err)
+	}
+	if string(data) != "first\nrest" {
+		t.Errorf("Expected %q, got: %q", "first\nrest", data)
+	}
+}
+
+func TestReadAllStdin(t *testing.T) {
+	payload := "{\n  \"mediaType\": \"application/vnd.oci.image.manifest.v1+json\"\n}\n"
+	stdinReader = bufio.NewReader(strings.NewReader(payload))
+
+	data, err := ReadAllStdin()
+	if err != nil {
+		t.Fatalf("ReadAllStdin() error = %v", err)
+	}
+	if string(data) != payload {
+		t.Errorf("Expected %q, got: %q", payload, data)
+	}
+}
+
+func TestPickPath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "chart"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "chart.tgz"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		start          string
+		input          string
+		filter         PathFilter
+		nonInteractive bool
+		expected       string
+		expectError    bool
+	}{
+		{name: "Piped relative path", start: dir, input: "chart\n", filter: DirsOnly, expected: filepath.Join(dir, "chart")},
+		{name: "Piped path rejected by filter", start: dir, input: "chart.tgz\n", filter: DirsOnly, expectError: true},
+		{name: "Piped file with extension", start: dir, input: "chart.tgz\n", filter: FilesWithExt(".tgz"), expected: filepath.Join(dir, "chart.tgz")},
+		{name: "Non-interactive uses start", start: filepath.Join(dir, "chart"), filter: DirsOnly, nonInteractive: true, expected: filepath.Join(dir, "chart")},
+		{name: "Non-interactive rejects start", start: dir, filter: FilesWithExt(".tgz"), nonInteractive: true, expectError: true},
+	}
+
+	defer SetNonInteractive(nonInteractive)
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			stdinReader = bufio.NewReader(strings.NewReader(tt.input))
+			SetNonInteractive(tt.nonInteractive)
+
+			got, err := PickPath(tt.start, tt.filter)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package system
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+type ProxyConfig struct {
+	HTTPProxy  string   `json:"http_proxy,omitempty"`
+	HTTPSProxy string   `json:"https_proxy,omitempty"`
+	NoProxy    []string `json:"no_proxy,omitempty"`
+	// Source is where the settings were found: "env", "scutil",
+	// "internet-settings", "winhttp" or "" when no proxy is configured.
+	Source string `json:"source,omitempty"`
+}
+
+// ProxySettings returns the proxy configuration from HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY, falling back to the macOS network settings or the Windows
+// Internet and WinHTTP settings when none of the variables are set.
+func ProxySettings() *ProxyConfig {
+	if config := envProxy(); config != nil {
+		return config
+	}
+
+	if config := platformProxy(); config != nil {
+		return config
+	}
+
+	return &ProxyConfig{}
+}
+
+// ProxyFunc returns a function suitable for http.Transport.Proxy.
+func (c *ProxyConfig) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	config := httpproxy.Config{
+		HTTPProxy:  c.HTTPProxy,
+		HTTPSProxy: c.HTTPSProxy,
+		NoProxy:    strings.Join(c.NoProxy, ","),
+	}
+	proxy := config.ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
+
+// Transport returns a clone of http.DefaultTransport routed through the
+// configured proxy.
+func (c *ProxyConfig) Transport() http.RoundTripper {
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return http.DefaultTransport
+	}
+
+	transport := base.Clone()
+	transport.Proxy = c.ProxyFunc()
+	return transport
+}
+
+func envProxy() *ProxyConfig {
+	config := &ProxyConfig{
+		HTTPProxy:  normalizeProxy(getenvAny("HTTP_PROXY", "http_proxy")),
+		HTTPSProxy: normalizeProxy(getenvAny("HTTPS_PROXY", "https_proxy")),
+		NoProxy:    splitNoProxy(getenvAny("NO_PROXY", "no_proxy"), ","),
+		Source:     "env",
+	}
+
+	if config.HTTPProxy == "" && config.HTTPSProxy == "" {
+		return nil
+	}
+
+	return config
+}
+
+func getenvAny(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// normalizeProxy adds an http scheme to bare host:port proxy addresses.
+func normalizeProxy(proxy string) string {
+	proxy = strings.TrimSpace(proxy)
+	if proxy == "" || strings.Contains(proxy, "://") {
+		return proxy
+	}
+
+	return "http://" + proxy
+}
+
+func splitNoProxy(value, sep string) []string {
+	var hosts []string
+	for _, host := range strings.Split(value, sep) {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return hosts
+}
+
+// parseScutilProxy reads the dictionary printed by scutil --proxy:
+//
+//	<dictionary> {
+//	  ExceptionsList : <array> {
+//	    0 : *.local
+//	  }
+//	  HTTPEnable : 1
+//	  HTTPPort : 8080
+//	  HTTPProxy : proxy.example.com
+//	}
+func parseScutilProxy(out string) *ProxyConfig {
+	values := map[string]string{}
+	var exceptions []string
+	inExceptions := false
+
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "ExceptionsList"):
+			inExceptions = true
+			continue
+		case inExceptions && line == "}":
+			inExceptions = false
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, " : ")
+		if !ok {
+			continue
+		}
+		if inExceptions {
+			exceptions = append(exceptions, value)
+		} else {
+			values[key] = value
+		}
+	}
+
+	config := &ProxyConfig{NoProxy: exceptions, Source: "scutil"}
+	if values["HTTPEnable"] == "1" && values["HTTPProxy"] != "" {
+		config.HTTPProxy = proxyAddress(values["HTTPProxy"], values["HTTPPort"])
+	}
+	if values["HTTPSEnable"] == "1" && values["HTTPSProxy"] != "" {
+		config.HTTPSProxy = proxyAddress(values["HTTPSProxy"], values["HTTPSPort"])
+	}
+
+	if config.HTTPProxy == "" && config.HTTPSProxy == "" {
+		return nil
+	}
+
+	return config
+}
+
+func proxyAddress(host, port string) string {
+	if port == "" || port == "0" {
+		return normalizeProxy(host)
+	}
+
+	return normalizeProxy(net.JoinHostPort(host, port))
+}
+
+// parseWindowsProxy reads a ProxyServer value from the Internet settings or
+// WinHTTP, either "host:port" for all protocols or per protocol as
+// "http=host:port;https=host:port". Bypass entries are separated by
+// semicolons and "<local>" stands for plain host names.
+func parseWindowsProxy(server, bypass, source string) *ProxyConfig {
+	config := &ProxyConfig{Source: source}
+	for _, entry := range strings.Split(server, ";") {
+		scheme, address, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			if address = normalizeProxy(scheme); address != "" {
+				config.HTTPProxy, config.HTTPSProxy = address, address
+			}
+			continue
+		}
+
+		switch strings.ToLower(scheme) {
+		case "http":
+			config.HTTPProxy = normalizeProxy(address)
+		case "https":
+			config.HTTPSProxy = normalizeProxy(address)
+		}
+	}
+
+	for _, host := range splitNoProxy(bypass, ";") {
+		if host == "<local>" {
+			continue
+		}
+		config.NoProxy = append(config.NoProxy, host)
+	}
+
+	if config.HTTPProxy == "" && config.HTTPSProxy == "" {
+		return nil
+	}
+
+	return config
+}
@@ -0,0 +1,12 @@
+package system
+
+import "os/exec"
+
+func platformProxy() *ProxyConfig {
+	out, err := exec.Command("scutil", "--proxy").Output()
+	if err != nil {
+		return nil
+	}
+
+	return parseScutilProxy(string(out))
+}
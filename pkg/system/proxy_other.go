@@ -0,0 +1,9 @@
+//go:build !darwin && !windows
+
+package system
+
+// platformProxy has no desktop proxy settings to read; Linux and other
+// systems rely on the environment variables.
+func platformProxy() *ProxyConfig {
+	return nil
+}
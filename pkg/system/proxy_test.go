@@ -0,0 +1,95 @@
+package system
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseScutilProxy(t *testing.T) {
+	out := `<dictionary> {
+  ExceptionsList : <array> {
+    0 : *.local
+    1 : 169.254/16
+  }
+  FTPPassive : 1
+  HTTPEnable : 1
+  HTTPPort : 8080
+  HTTPProxy : proxy.example.com
+  HTTPSEnable : 0
+}`
+
+	expected := &ProxyConfig{
+		HTTPProxy: "http://proxy.example.com:8080",
+		NoProxy:   []string{"*.local", "169.254/16"},
+		Source:    "scutil",
+	}
+
+	if got := parseScutilProxy(out); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %+v, got: %+v", expected, got)
+	}
+	if got := parseScutilProxy("<dictionary> {\n  HTTPEnable : 0\n}"); got != nil {
+		t.Errorf("Expected no proxy, got: %+v", got)
+	}
+}
+
+func TestParseWindowsProxy(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   string
+		bypass   string
+		expected *ProxyConfig
+	}{
+		{
+			name:     "Single proxy",
+			server:   "proxy:3128",
+			bypass:   "<local>;*.corp.example.com",
+			expected: &ProxyConfig{HTTPProxy: "http://proxy:3128", HTTPSProxy: "http://proxy:3128", NoProxy: []string{"*.corp.example.com"}, Source: "winhttp"},
+		},
+		{
+			name:     "Per protocol",
+			server:   "http=web:80;https=secure:443;ftp=files:21",
+			expected: &ProxyConfig{HTTPProxy: "http://web:80", HTTPSProxy: "http://secure:443", Source: "winhttp"},
+		},
+		{
+			name:   "Empty",
+			server: "",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseWindowsProxy(tt.server, tt.bypass, "winhttp")
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %+v, got: %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestProxySettingsFromEnv(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "proxy.example.com:3128")
+	t.Setenv("NO_PROXY", "internal.example.com, .svc")
+
+	config := ProxySettings()
+	if config.Source != "env" || config.HTTPSProxy != "http://proxy.example.com:3128" {
+		t.Fatalf("Expected HTTPS proxy from env, got: %+v", config)
+	}
+
+	proxy := config.ProxyFunc()
+	for host, expected := range map[string]bool{
+		"registry.example.com": true,
+		"internal.example.com": false,
+		"api.svc":              false,
+	} {
+		req, _ := http.NewRequest("GET", "https://"+host+"/v2/", nil)
+		url, err := proxy(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (url != nil) != expected {
+			t.Errorf("Expected proxy for %s: %v, got: %v", host, expected, url)
+		}
+	}
+}
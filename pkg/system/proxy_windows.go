@@ -0,0 +1,82 @@
+package system
+
+import (
+	"encoding/binary"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const internetSettingsKey = `Software\Microsoft\Windows\CurrentVersion\Internet Settings`
+
+func platformProxy() *ProxyConfig {
+	if config := internetSettingsProxy(); config != nil {
+		return config
+	}
+
+	return winHTTPProxy()
+}
+
+// internetSettingsProxy reads the per-user proxy configured in the Windows
+// Settings app or Internet Options.
+func internetSettingsProxy() *ProxyConfig {
+	key, err := registry.OpenKey(registry.CURRENT_USER, internetSettingsKey, registry.QUERY_VALUE)
+	if err != nil {
+		return nil
+	}
+	defer key.Close()
+
+	enabled, _, err := key.GetIntegerValue("ProxyEnable")
+	if err != nil || enabled == 0 {
+		return nil
+	}
+
+	server, _, err := key.GetStringValue("ProxyServer")
+	if err != nil {
+		return nil
+	}
+	bypass, _, _ := key.GetStringValue("ProxyOverride")
+
+	return parseWindowsProxy(server, bypass, "internet-settings")
+}
+
+// winHTTPProxy reads the machine-wide proxy set with netsh winhttp. The
+// WinHttpSettings value holds a struct size, a counter and access type flags
+// followed by the length-prefixed proxy server and bypass list.
+func winHTTPProxy() *ProxyConfig {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, internetSettingsKey+`\Connections`, registry.QUERY_VALUE)
+	if err != nil {
+		return nil
+	}
+	defer key.Close()
+
+	data, _, err := key.GetBinaryValue("WinHttpSettings")
+	if err != nil || len(data) < 16 {
+		return nil
+	}
+
+	const accessTypeNamedProxy = 0x2
+	if binary.LittleEndian.Uint32(data[8:12])&accessTypeNamedProxy == 0 {
+		return nil
+	}
+
+	server, rest, ok := lengthPrefixed(data[12:])
+	if !ok {
+		return nil
+	}
+	bypass, _, _ := lengthPrefixed(rest)
+
+	return parseWindowsProxy(server, bypass, "winhttp")
+}
+
+func lengthPrefixed(data []byte) (value string, rest []byte, ok bool) {
+	if len(data) < 4 {
+		return "", nil, false
+	}
+
+	n := binary.LittleEndian.Uint32(data[:4])
+	if uint64(len(data)-4) < uint64(n) {
+		return "", nil, false
+	}
+
+	return string(data[4 : 4+n]), data[4+n:], true
+}
@@ -0,0 +1,55 @@
+package system
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/style"
+)
+
+type ResourceUsage struct {
+	Path        string `json:"path"`
+	DiskFree    uint64 `json:"disk_free_bytes"`
+	DiskTotal   uint64 `json:"disk_total_bytes"`
+	MemoryFree  uint64 `json:"memory_free_bytes"`
+	MemoryTotal uint64 `json:"memory_total_bytes"`
+	// LoadAverage is the one minute load average, or zero where the
+	// platform does not provide one.
+	LoadAverage float64 `json:"load_average"`
+	CPUs        int     `json:"cpus"`
+}
+
+// Resources reports free disk space on the filesystem holding path along
+// with free memory and CPU load.
+func Resources(path string) (*ResourceUsage, error) {
+	free, total, err := diskSpace(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disk space for %s: %w", path, err)
+	}
+
+	return &ResourceUsage{
+		Path:        path,
+		DiskFree:    free,
+		DiskTotal:   total,
+		MemoryFree:  freeMemory(),
+		MemoryTotal: totalMemory(),
+		LoadAverage: loadAverage(),
+		CPUs:        runtime.NumCPU(),
+	}, nil
+}
+
+// EnsureDiskSpace returns an error when the filesystem holding path has less
+// than required bytes available, so large extractions can fail up front.
+func EnsureDiskSpace(path string, required uint64) error {
+	free, _, err := diskSpace(path)
+	if err != nil {
+		return fmt.Errorf("failed to get disk space for %s: %w", path, err)
+	}
+
+	if free < required {
+		return log.Errorf(log.CodeIO, "disk_space", "not enough disk space in %s: %s required, %s available", path, style.FormatBytes(required), style.FormatBytes(free))
+	}
+
+	return nil
+}
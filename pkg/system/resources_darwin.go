@@ -0,0 +1,41 @@
+package system
+
+import (
+	"encoding/binary"
+
+	"golang.org/x/sys/unix"
+)
+
+func diskSpace(path string) (free, total uint64, err error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+
+	return stat.Bavail * uint64(stat.Bsize), stat.Blocks * uint64(stat.Bsize), nil
+}
+
+func freeMemory() uint64 {
+	pages, err := unix.SysctlUint32("vm.page_free_count")
+	if err != nil {
+		return 0
+	}
+
+	return uint64(pages) * uint64(unix.Getpagesize())
+}
+
+// loadAverage decodes struct loadavg { fixpt_t ldavg[3]; long fscale; }.
+func loadAverage() float64 {
+	raw, err := unix.SysctlRaw("vm.loadavg")
+	if err != nil || len(raw) < 24 {
+		return 0
+	}
+
+	load := binary.LittleEndian.Uint32(raw[0:4])
+	scale := binary.LittleEndian.Uint64(raw[16:24])
+	if scale == 0 {
+		return 0
+	}
+
+	return float64(load) / float64(scale)
+}
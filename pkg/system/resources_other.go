@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !windows
+
+package system
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func diskSpace(path string) (free, total uint64, err error) {
+	return 0, 0, fmt.Errorf("disk space is not supported on %s", runtime.GOOS)
+}
+
+func freeMemory() uint64 {
+	return 0
+}
+
+func loadAverage() float64 {
+	return 0
+}
@@ -0,0 +1,30 @@
+package system
+
+import (
+	"math"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+func TestResources(t *testing.T) {
+	usage, err := Resources(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if usage.DiskTotal == 0 || usage.DiskFree > usage.DiskTotal || usage.CPUs < 1 {
+		t.Errorf("Expected disk space and CPU count to be set, got: %+v", usage)
+	}
+}
+
+func TestEnsureDiskSpace(t *testing.T) {
+	dir := t.TempDir()
+	if err := EnsureDiskSpace(dir, 1); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+
+	err := EnsureDiskSpace(dir, math.MaxUint64)
+	if !log.IsCode(err, log.CodeIO) {
+		t.Errorf("Expected %s error, got: %v", log.CodeIO, err)
+	}
+}
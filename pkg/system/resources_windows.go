@@ -0,0 +1,30 @@
+package system
+
+import "golang.org/x/sys/windows"
+
+func diskSpace(path string) (free, total uint64, err error) {
+	dir, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := windows.GetDiskFreeSpaceEx(dir, &free, &total, nil); err != nil {
+		return 0, 0, err
+	}
+
+	return free, total, nil
+}
+
+func freeMemory() uint64 {
+	status, ok := globalMemoryStatus()
+	if !ok {
+		return 0
+	}
+
+	return status.AvailPhys
+}
+
+// loadAverage is not provided by Windows.
+func loadAverage() float64 {
+	return 0
+}
@@ -0,0 +1,62 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+// shutdownSignals covers Ctrl+C everywhere; on Windows the console close,
+// logoff and shutdown events are delivered as SIGTERM.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// NotifyContext returns a context that is cancelled on the first SIGINT or
+// SIGTERM. The cleanup callbacks then run in reverse order, like defers. A
+// second signal skips any remaining cleanup and exits immediately with status
+// 130. Calling the returned CancelFunc stops listening for signals.
+func NotifyContext(parent context.Context, cleanup ...func()) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, shutdownSignals...)
+
+	done := make(chan struct{})
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+			cancel()
+		})
+	}
+
+	go func() {
+		select {
+		case sig := <-signals:
+			log.Component("system").Info("shutdown signal received", "signal", sig.String())
+			cancel()
+		case <-done:
+			return
+		}
+
+		go func() {
+			for i := len(cleanup) - 1; i >= 0; i-- {
+				cleanup[i]()
+			}
+		}()
+
+		select {
+		case <-signals:
+			fmt.Fprintln(os.Stderr, "forced shutdown")
+			_ = log.Flush()
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+
+	return ctx, stop
+}
@@ -0,0 +1,140 @@
+package system
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// stdinReader is shared by the stdin and prompt helpers so buffered input is
+// not lost between calls. It is only read while holding stdinOwner.
+var stdinReader = bufio.NewReader(os.Stdin)
+
+type lineResult struct {
+	line string
+	err  error
+}
+
+var (
+	// stdinOwner is held by the one caller reading stdin at a time. Every
+	// reader in this package takes it, including raw mode prompts and the
+	// editor started by Edit. Code elsewhere that reads os.Stdin directly,
+	// such as exec.ExecuteWithPTY, bypasses it and must not run while a
+	// ReadLineWithTimeout read is still pending.
+	stdinOwner = make(chan struct{}, 1)
+	// pendingLine is a read a timed out caller stopped waiting for. The next
+	// caller takes its result instead of reading stdin concurrently with it.
+	pendingLine chan lineResult
+)
+
+// IsStdinPiped reports whether stdin is a pipe or file rather than a
+// terminal.
+func IsStdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
+// ReadAllStdin reads stdin until EOF, preserving binary content and newlines.
+func ReadAllStdin() ([]byte, error) {
+	pending, unlock := lockStdin()
+	defer unlock()
+
+	var head string
+	if pending != nil {
+		if pending.err != nil {
+			if errors.Is(pending.err, io.EOF) {
+				pending.err = nil
+			}
+			return []byte(pending.line), pending.err
+		}
+		head = pending.line
+	}
+
+	rest, err := io.ReadAll(stdinReader)
+	return append([]byte(head), rest...), err
+}
+
+// ReadLineWithTimeout reads one line from stdin, giving up when ctx is done.
+// A line that arrives after the timeout is returned by the next read of
+// stdin rather than being lost. Until then the read keeps running, so
+// os.Stdin must not be read outside this package, e.g. by
+// exec.ExecuteWithPTY.
+func ReadLineWithTimeout(ctx context.Context) (string, error) {
+	return trimLine(nextLine(ctx))
+}
+
+func readLine() (string, error) {
+	return trimLine(nextLine(context.Background()))
+}
+
+// nextLine returns the next raw line of stdin, including its newline. The
+// read runs in its own goroutine so the caller can stop waiting when ctx is
+// done, leaving the result to the next caller.
+func nextLine(ctx context.Context) (string, error) {
+	select {
+	case stdinOwner <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { <-stdinOwner }()
+
+	result := pendingLine
+	pendingLine = nil
+	if result == nil {
+		result = make(chan lineResult, 1)
+		go func() {
+			line, err := stdinReader.ReadString('\n')
+			result <- lineResult{line, err}
+		}()
+	}
+
+	select {
+	case r := <-result:
+		return r.line, r.err
+	case <-ctx.Done():
+		pendingLine = result
+		return "", ctx.Err()
+	}
+}
+
+// lockStdin takes ownership of stdin for a caller that reads stdinReader
+// itself, such as a raw mode prompt. It first waits for any read a timed out
+// caller left running, so nothing else is reading once it returns; that
+// read's result, if any, is the caller's next input.
+func lockStdin() (pending *lineResult, unlock func()) {
+	stdinOwner <- struct{}{}
+	if pendingLine != nil {
+		r := <-pendingLine
+		pendingLine = nil
+		pending = &r
+	}
+
+	return pending, func() { <-stdinOwner }
+}
+
+// keepPending leaves r for the next read of stdin. The caller must hold
+// stdin.
+func keepPending(r lineResult) {
+	pendingLine = make(chan lineResult, 1)
+	pendingLine <- r
+}
+
+// answer returns the line as a prompt answer.
+func (r lineResult) answer() (string, error) {
+	return trimLine(r.line, r.err)
+}
+
+func trimLine(line string, err error) (string, error) {
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
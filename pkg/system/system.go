@@ -1,38 +1,23 @@
 package system
 
 import (
-	"bufio"
-	"fmt"
-	"os"
-	"os/exec"
-	"runtime"
+	"io"
 
 	"github.com/eunanio/sdk/pkg/log"
 )
 
+// OpenURL opens url in the user's browser. Commands listed in $BROWSER are
+// tried before the platform defaults. If nothing could open the URL an
+// *OpenURLError is returned so the caller can print the link instead.
 func OpenURL(url string) error {
-	var err error
-
-	switch runtime.GOOS {
-	case "linux":
-		err = exec.Command("xdg-open", url).Start()
-	case "windows":
-		err = exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
-	case "darwin":
-		err = exec.Command("open", url).Start()
-	default:
-		err = fmt.Errorf("unsupported platform")
-	}
-
-	return err
+	return OpenURLWith(url, append(browserOpeners(), defaultOpeners()...)...)
 }
 
 func GetStdin() (msg string) {
-	scanner := bufio.NewScanner(os.Stdin)
-	if scanner.Scan() {
-		msg = scanner.Text()
+	msg, err := readLine()
+	if err == io.EOF {
+		return ""
 	}
-	err := scanner.Err()
 	log.NoError(err, "Error reading from stdin")
 
 	return msg
@@ -0,0 +1,315 @@
+package telemetry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/httpx"
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+const maxBatch = 500
+
+var disabled atomic.Bool
+
+// Disable turns telemetry off for the rest of the process: nothing is
+// recorded or sent. Setting DO_NOT_TRACK=1 or DEVKIT_TELEMETRY=0 has the same
+// effect.
+func Disable() {
+	disabled.Store(true)
+}
+
+func isDisabled() bool {
+	if disabled.Load() {
+		return true
+	}
+
+	if value, err := strconv.ParseBool(os.Getenv("DO_NOT_TRACK")); err == nil && value {
+		return true
+	}
+	if value, err := strconv.ParseBool(os.Getenv("DEVKIT_TELEMETRY")); err == nil && !value {
+		return true
+	}
+
+	return false
+}
+
+// Event is one command invocation. It deliberately carries no arguments,
+// paths or error messages.
+type Event struct {
+	Command    string    `json:"command"`
+	DurationMs int64     `json:"duration_ms"`
+	Error      log.Code  `json:"error,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// Payload is the exact document sent to the endpoint.
+type Payload struct {
+	InstallID string  `json:"install_id"`
+	Tool      string  `json:"tool"`
+	Version   string  `json:"version"`
+	OS        string  `json:"os"`
+	Arch      string  `json:"arch"`
+	Events    []Event `json:"events"`
+}
+
+type Client struct {
+	Tool     string
+	Version  string
+	Endpoint string
+	// Dir holds the event queue, install ID and consent.
+	Dir  string
+	HTTP *httpx.Client
+
+	mu sync.Mutex
+}
+
+// New stores telemetry for tool under the user config directory.
+func New(tool, version, endpoint string) (*Client, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find config directory: %w", err)
+	}
+
+	return &Client{Tool: tool, Version: version, Endpoint: endpoint, Dir: filepath.Join(dir, tool, "telemetry")}, nil
+}
+
+// SetOptIn records the user's consent to send telemetry. Events are only
+// ever sent after SetOptIn(true), or with DEVKIT_TELEMETRY=1.
+func (c *Client) SetOptIn(enabled bool) error {
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(c.Dir, "consent"), []byte(strconv.FormatBool(enabled)), 0600)
+}
+
+func (c *Client) OptedIn() bool {
+	if isDisabled() {
+		return false
+	}
+
+	if value, err := strconv.ParseBool(os.Getenv("DEVKIT_TELEMETRY")); err == nil {
+		return value
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.Dir, "consent"))
+	if err != nil {
+		return false
+	}
+
+	value, _ := strconv.ParseBool(strings.TrimSpace(string(data)))
+	return value
+}
+
+// Track records command when the returned function is called with the
+// command's result:
+//
+//	done := client.Track("push")
+//	err := push()
+//	done(err)
+func (c *Client) Track(command string) func(err error) {
+	start := time.Now()
+	return func(err error) {
+		event := Event{Command: command, DurationMs: time.Since(start).Milliseconds(), Time: start.UTC()}
+		if err != nil {
+			event.Error = log.ErrorCode(err)
+		}
+		_ = c.Record(event)
+	}
+}
+
+// Record appends event to the local queue.
+func (c *Client) Record(event Event) error {
+	if isDisabled() {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(c.queuePath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// Payload returns the next batch exactly as Flush would send it, so users
+// can audit what is collected.
+func (c *Client) Payload() (*Payload, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.payload()
+}
+
+func (c *Client) payload() (*Payload, error) {
+	events, err := c.readQueue()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := c.installID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Payload{
+		InstallID: id,
+		Tool:      c.Tool,
+		Version:   c.Version,
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		Events:    events[:min(len(events), maxBatch)],
+	}, nil
+}
+
+// Flush sends queued events in batches when the user has opted in,
+// removing them once the endpoint accepts them. Without consent it does
+// nothing.
+func (c *Client) Flush(ctx context.Context) error {
+	if !c.OptedIn() || c.Endpoint == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for {
+		payload, err := c.payload()
+		if err != nil || len(payload.Events) == 0 {
+			return err
+		}
+
+		if err := c.send(ctx, payload); err != nil {
+			return err
+		}
+
+		if err := c.dropEvents(len(payload.Events)); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, payload *Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := c.HTTP
+	if client == nil {
+		client = httpx.New(httpx.WithTimeout(10 * time.Second))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return log.Errorf(log.CodeRemote, "telemetry_flush", "telemetry endpoint returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (c *Client) queuePath() string {
+	return filepath.Join(c.Dir, "events.jsonl")
+}
+
+func (c *Client) readQueue() ([]Event, error) {
+	f, err := os.Open(c.queuePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event Event
+		if json.Unmarshal(scanner.Bytes(), &event) == nil {
+			events = append(events, event)
+		}
+	}
+
+	return events, scanner.Err()
+}
+
+// dropEvents removes the first n events from the queue.
+func (c *Client) dropEvents(n int) error {
+	events, err := c.readQueue()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, event := range events[min(n, len(events)):] {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(data, '\n'))
+	}
+
+	return os.WriteFile(c.queuePath(), buf.Bytes(), 0600)
+}
+
+// installID returns a random identifier created on first use. It is not
+// derived from the machine or user.
+func (c *Client) installID() (string, error) {
+	path := filepath.Join(c.Dir, "id")
+	if data, err := os.ReadFile(path); err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return "", err
+	}
+	encoded := hex.EncodeToString(id)
+	return encoded, os.WriteFile(path, []byte(encoded), 0600)
+}
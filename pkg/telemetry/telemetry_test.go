@@ -0,0 +1,99 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+func newTestClient(t *testing.T, endpoint string) *Client {
+	t.Helper()
+	t.Setenv("DEVKIT_TELEMETRY", "")
+	t.Setenv("DO_NOT_TRACK", "")
+	return &Client{Tool: "devkit", Version: "1.0.0", Endpoint: endpoint, Dir: t.TempDir()}
+}
+
+func TestFlush(t *testing.T) {
+	var received []Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload Payload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		received = append(received, payload)
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server.URL)
+	c.Track("push")(log.Errorf(log.CodeUnauthorized, "push", "secret detail"))
+	c.Track("pull")(nil)
+
+	payload, err := c.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Events) != 2 || payload.Events[0].Error != log.CodeUnauthorized || payload.InstallID == "" {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+
+	if err := c.Flush(context.Background()); err != nil || len(received) != 0 {
+		t.Fatalf("Expected nothing to be sent without consent, got: %d %v", len(received), err)
+	}
+
+	if err := c.SetOptIn(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Flush(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(received) != 1 || len(received[0].Events) != 2 || received[0].InstallID != payload.InstallID {
+		t.Errorf("Expected one batch with two events, got: %+v", received)
+	}
+
+	if payload, _ := c.Payload(); len(payload.Events) != 0 {
+		t.Errorf("Expected the queue to be empty after flushing, got: %+v", payload.Events)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		disable bool
+	}{
+		{name: "DO_NOT_TRACK", env: map[string]string{"DO_NOT_TRACK": "1"}},
+		{name: "DEVKIT_TELEMETRY", env: map[string]string{"DEVKIT_TELEMETRY": "0"}},
+		{name: "Disable", disable: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, "http://127.0.0.1:0")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if tt.disable {
+				Disable()
+				defer disabled.Store(false)
+			}
+
+			if err := c.SetOptIn(true); err != nil {
+				t.Fatal(err)
+			}
+			c.Track("push")(errors.New("failed"))
+
+			if c.OptedIn() {
+				t.Error("Expected telemetry to be disabled")
+			}
+			if payload, _ := c.Payload(); len(payload.Events) != 0 {
+				t.Errorf("Expected no events to be recorded, got: %+v", payload.Events)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package template
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ext is stripped from file names when rendering a directory.
+const Ext = ".tmpl"
+
+type Option func(*options)
+
+type options struct {
+	funcs   template.FuncMap
+	lenient bool
+	left    string
+	right   string
+}
+
+// WithFuncs adds functions to the template, overriding the built-in ones of
+// the same name.
+func WithFuncs(funcs template.FuncMap) Option {
+	return func(o *options) {
+		for name, fn := range funcs {
+			o.funcs[name] = fn
+		}
+	}
+}
+
+// WithLenient renders missing map keys as "<no value>" instead of failing.
+func WithLenient() Option {
+	return func(o *options) {
+		o.lenient = true
+	}
+}
+
+func WithDelims(left, right string) Option {
+	return func(o *options) {
+		o.left = left
+		o.right = right
+	}
+}
+
+// Funcs returns the built-in template functions.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"env":      os.Getenv,
+		"default":  defaultValue,
+		"required": required,
+		"toJson":   toJSON,
+		"toYaml":   toYAML,
+		"sha256":   sha256Sum,
+		"indent":   indent,
+		"nindent":  nindent,
+		"quote":    quote,
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+		"trim":     strings.TrimSpace,
+		"replace":  replace,
+		"join":     join,
+		"split":    split,
+	}
+}
+
+func parse(name, text string, opts []Option) (*template.Template, error) {
+	o := &options{funcs: Funcs()}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	tmpl := template.New(name).Funcs(o.funcs).Delims(o.left, o.right)
+	if !o.lenient {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+
+	tmpl, err := tmpl.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	return tmpl, nil
+}
+
+// Render executes text with data. Missing map keys are an error unless
+// WithLenient is given.
+func Render(text string, data any, opts ...Option) (string, error) {
+	return render("template", text, data, opts)
+}
+
+func render(name, text string, data any, opts []Option) (string, error) {
+	tmpl, err := parse(name, text, opts)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// RenderFile renders the template at src into dst, keeping the file mode.
+func RenderFile(src, dst string, data any, opts ...Option) error {
+	text, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	out, err := render(src, string(text), data, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(dst, []byte(out), info.Mode().Perm())
+}
+
+// RenderDir renders every file under src into the same layout under dst.
+// Files ending in .tmpl are rendered with the extension removed; all other
+// files are copied unchanged.
+func RenderDir(src, dst string, data any, opts ...Option) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		if strings.HasSuffix(path, Ext) {
+			return RenderFile(path, strings.TrimSuffix(target, Ext), data, opts...)
+		}
+
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(dst, data, info.Mode().Perm())
+}
+
+func defaultValue(def, value any) any {
+	if value == nil {
+		return def
+	}
+
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return def
+		}
+	case bool:
+		if !v {
+			return def
+		}
+	case int:
+		if v == 0 {
+			return def
+		}
+	}
+
+	return value
+}
+
+func required(msg string, value any) (any, error) {
+	if value == nil || value == "" {
+		return nil, fmt.Errorf("%s", msg)
+	}
+
+	return value, nil
+}
+
+func toJSON(value any) (string, error) {
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+func toYAML(value any) (string, error) {
+	data, err := yaml.Marshal(value)
+	return strings.TrimSuffix(string(data), "\n"), err
+}
+
+func sha256Sum(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func indent(spaces int, value string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(value, "\n", "\n"+pad)
+}
+
+func nindent(spaces int, value string) string {
+	return "\n" + indent(spaces, value)
+}
+
+func quote(value any) string {
+	return fmt.Sprintf("%q", fmt.Sprint(value))
+}
+
+func replace(old, new, value string) string {
+	return strings.ReplaceAll(value, old, new)
+}
+
+func join(sep string, values []string) string {
+	return strings.Join(values, sep)
+}
+
+func split(sep, value string) []string {
+	return strings.Split(value, sep)
+}
@@ -0,0 +1,81 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	t.Setenv("DEVKIT_TEMPLATE_TEST", "from-env")
+
+	data := map[string]any{
+		"name":   "web",
+		"empty":  "",
+		"labels": map[string]string{"app": "web", "tier": "frontend"},
+	}
+
+	tests := []struct {
+		name        string
+		text        string
+		opts        []Option
+		expected    string
+		expectError bool
+	}{
+		{name: "Value", text: "name: {{ .name }}", expected: "name: web"},
+		{name: "Env", text: `{{ env "DEVKIT_TEMPLATE_TEST" }}`, expected: "from-env"},
+		{name: "Default", text: `{{ .empty | default "fallback" }}`, expected: "fallback"},
+		{name: "ToJson", text: `{{ toJson .labels }}`, expected: `{"app":"web","tier":"frontend"}`},
+		{name: "ToYaml indented", text: "labels:{{ toYaml .labels | nindent 2 }}", expected: "labels:\n  app: web\n  tier: frontend"},
+		{name: "Sha256", text: `{{ sha256 "abc" }}`, expected: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{name: "Required", text: `{{ required "name is required" .empty }}`, expectError: true},
+		{name: "Missing key is an error", text: "{{ .missing }}", expectError: true},
+		{name: "Missing key when lenient", text: "{{ .missing }}", opts: []Option{WithLenient()}, expected: "<no value>"},
+		{name: "Custom delimiters", text: "[[ .name ]] {{ x }}", opts: []Option{WithDelims("[[", "]]")}, expected: "web {{ x }}"},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.text, data, tt.opts...)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Expected error: %v, got: %v", tt.expectError, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRenderDir(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "templates"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "templates", "deployment.yaml.tmpl"), []byte("name: {{ .name }}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "README.md"), []byte("{{ not rendered }}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RenderDir(src, dst, map[string]string{"name": "web"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := map[string]string{
+		"templates/deployment.yaml": "name: web\n",
+		"README.md":                 "{{ not rendered }}",
+	}
+	for path, content := range expected {
+		data, err := os.ReadFile(filepath.Join(dst, path))
+		if err != nil {
+			t.Errorf("Expected %s to exist, got: %v", path, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("Expected %s to contain %q, got: %q", path, content, data)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around changes.
+const DefaultContext = 3
+
+type Op byte
+
+const (
+	OpEqual  Op = ' '
+	OpDelete Op = '-'
+	OpInsert Op = '+'
+)
+
+// Line is one line of a hunk. Text keeps its trailing newline, which only
+// the last line of a file may lack.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []Line
+}
+
+func (h Hunk) header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines))
+}
+
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// Unified returns a unified diff of a and b with DefaultContext lines of
+// context, or "" when they are equal.
+func Unified(oldName, newName, a, b string) string {
+	return UnifiedContext(oldName, newName, a, b, DefaultContext)
+}
+
+func UnifiedContext(oldName, newName, a, b string, context int) string {
+	hunks := Hunks(a, b, context)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks {
+		sb.WriteString(h.header())
+		sb.WriteByte('\n')
+		for _, line := range h.Lines {
+			sb.WriteByte(byte(line.Op))
+			sb.WriteString(line.Text)
+			if !strings.HasSuffix(line.Text, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// Hunks groups the changes between a and b, with context unchanged lines
+// around each. Changes closer than twice the context share a hunk.
+func Hunks(a, b string, context int) []Hunk {
+	edits := diffLines(splitLines(a), splitLines(b))
+	context = max(context, 0)
+
+	// Line numbers before each edit.
+	oldPos := make([]int, len(edits)+1)
+	newPos := make([]int, len(edits)+1)
+	for i, e := range edits {
+		oldPos[i+1], newPos[i+1] = oldPos[i], newPos[i]
+		if e.Op != OpInsert {
+			oldPos[i+1]++
+		}
+		if e.Op != OpDelete {
+			newPos[i+1]++
+		}
+	}
+
+	var hunks []Hunk
+	var current *Hunk
+	lastChange := -1
+	closeHunk := func(end int) {
+		current.Lines = append(current.Lines, edits[lastChange+1:end]...)
+		for _, line := range current.Lines {
+			if line.Op != OpInsert {
+				current.OldLines++
+			}
+			if line.Op != OpDelete {
+				current.NewLines++
+			}
+		}
+		// An empty side is numbered by the line before it.
+		if current.OldLines == 0 {
+			current.OldStart--
+		}
+		if current.NewLines == 0 {
+			current.NewStart--
+		}
+		hunks = append(hunks, *current)
+		current = nil
+	}
+
+	for i, e := range edits {
+		if e.Op == OpEqual {
+			continue
+		}
+		if current != nil && i-lastChange > 2*context+1 {
+			closeHunk(lastChange + 1 + context)
+		}
+		if current == nil {
+			start := max(i-context, 0)
+			current = &Hunk{OldStart: oldPos[start] + 1, NewStart: newPos[start] + 1}
+			current.Lines = append(current.Lines, edits[start:i]...)
+		} else {
+			current.Lines = append(current.Lines, edits[lastChange+1:i]...)
+		}
+		current.Lines = append(current.Lines, e)
+		lastChange = i
+	}
+	if current != nil {
+		closeHunk(min(lastChange+1+context, len(edits)))
+	}
+
+	return hunks
+}
+
+// splitLines splits s after each newline, keeping them.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the shortest edit script from a to b using Myers'
+// algorithm, after trimming the common prefix and suffix.
+func diffLines(a, b []string) []Line {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]Line, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		edits = append(edits, Line{OpEqual, line})
+	}
+	edits = append(edits, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, Line{OpEqual, line})
+	}
+	return edits
+}
+
+func myers(a, b []string) []Line {
+	n, m := len(a), len(b)
+	limit := n + m
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+
+	// trace[d] holds v[-d-1..d+1] as it was before step d.
+	var trace [][]int
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	var edits []Line
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		snapshot := trace[d]
+		at := func(k int) int { return snapshot[k+d+1] }
+
+		k := x - y
+		prevK := k - 1
+		if k == -d || k != d && at(k-1) < at(k+1) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			edits = append(edits, Line{OpEqual, a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			edits = append(edits, Line{OpInsert, b[y-1]})
+			y--
+		} else {
+			edits = append(edits, Line{OpDelete, a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		edits = append(edits, Line{OpEqual, a[x-1]})
+		x--
+		y--
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
@@ -0,0 +1,226 @@
+package textdiff
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+// ErrConflict is returned when a hunk's context or removed lines are not
+// found in the text being patched.
+var ErrConflict = errors.New("patch does not apply")
+
+// File is the diff of one file in a patch.
+type File struct {
+	OldName string
+	NewName string
+	Hunks   []Hunk
+}
+
+// Parse reads a unified diff of one or more files. Text outside of file
+// headers and hunks, such as "diff --git" lines, is ignored.
+func Parse(patch string) ([]File, error) {
+	var files []File
+	var hunk *Hunk
+	var oldLeft, newLeft int
+
+	lines := splitLines(patch)
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r\n")
+
+		if hunk != nil && (oldLeft > 0 || newLeft > 0) {
+			if line == "" {
+				// Some editors strip the space of empty context lines.
+				line = " "
+			}
+			if line[0] == '\\' {
+				if n := len(hunk.Lines); n > 0 {
+					hunk.Lines[n-1].Text = trimNewline(hunk.Lines[n-1].Text)
+				}
+				continue
+			}
+			op := Op(line[0])
+			switch op {
+			case OpEqual, OpDelete, OpInsert:
+			default:
+				return nil, log.Errorf(log.CodeInvalidArgument, "parse_patch", "line %d: unexpected %q in hunk", i+1, line)
+			}
+			if op != OpInsert {
+				oldLeft--
+			}
+			if op != OpDelete {
+				newLeft--
+			}
+			if oldLeft < 0 || newLeft < 0 {
+				return nil, log.Errorf(log.CodeInvalidArgument, "parse_patch", "line %d: hunk is longer than its header", i+1)
+			}
+			hunk.Lines = append(hunk.Lines, Line{Op: op, Text: lines[i][1:]})
+			if oldLeft == 0 && newLeft == 0 {
+				files[len(files)-1].Hunks = append(files[len(files)-1].Hunks, *hunk)
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file" applies to the previous line.
+			if last := lastLine(files); last != nil {
+				last.Text = trimNewline(last.Text)
+			}
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			files = append(files, File{OldName: headerName(line[4:]), NewName: headerName(strings.TrimRight(lines[i+1][4:], "\r\n"))})
+			i++
+		case strings.HasPrefix(line, "@@ "):
+			if len(files) == 0 {
+				return nil, log.Errorf(log.CodeInvalidArgument, "parse_patch", "line %d: hunk before file header", i+1)
+			}
+			h, err := parseHeader(line)
+			if err != nil {
+				return nil, log.Errorf(log.CodeInvalidArgument, "parse_patch", "line %d: %v", i+1, err)
+			}
+			hunk, oldLeft, newLeft = &h, h.OldLines, h.NewLines
+			if oldLeft == 0 && newLeft == 0 {
+				files[len(files)-1].Hunks = append(files[len(files)-1].Hunks, h)
+			}
+		}
+	}
+
+	if oldLeft > 0 || newLeft > 0 {
+		return nil, log.Errorf(log.CodeInvalidArgument, "parse_patch", "patch ends inside a hunk")
+	}
+	return files, nil
+}
+
+func lastLine(files []File) *Line {
+	if len(files) == 0 {
+		return nil
+	}
+	hunks := files[len(files)-1].Hunks
+	if len(hunks) == 0 || len(hunks[len(hunks)-1].Lines) == 0 {
+		return nil
+	}
+	lines := hunks[len(hunks)-1].Lines
+	return &lines[len(lines)-1]
+}
+
+func trimNewline(s string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(s, "\n"), "\r")
+}
+
+// headerName drops the timestamp some tools append after a tab.
+func headerName(s string) string {
+	name, _, _ := strings.Cut(s, "\t")
+	return name
+}
+
+func parseHeader(line string) (Hunk, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[3] != "@@" || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return Hunk{}, fmt.Errorf("invalid hunk header %q", line)
+	}
+
+	var h Hunk
+	var err error
+	if h.OldStart, h.OldLines, err = parseRange(fields[1][1:]); err != nil {
+		return Hunk{}, err
+	}
+	if h.NewStart, h.NewLines, err = parseRange(fields[2][1:]); err != nil {
+		return Hunk{}, err
+	}
+	return h, nil
+}
+
+func parseRange(s string) (int, int, error) {
+	startText, countText, hasCount := strings.Cut(s, ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q", s)
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countText); err != nil {
+			return 0, 0, fmt.Errorf("invalid range %q", s)
+		}
+	}
+	return start, count, nil
+}
+
+// Apply applies hunks to text. A hunk whose lines moved, e.g. because the
+// text was edited since the diff was made, is applied at the nearest
+// position where its context matches.
+func Apply(text string, hunks []Hunk) (string, error) {
+	lines := splitLines(text)
+	var out []string
+	pos := 0
+
+	for i, h := range hunks {
+		var old []string
+		for _, line := range h.Lines {
+			if line.Op != OpInsert {
+				old = append(old, line.Text)
+			}
+		}
+
+		expected := h.OldStart - 1
+		if h.OldLines == 0 {
+			expected = h.OldStart
+		}
+		at, ok := find(lines, old, pos, expected)
+		if !ok {
+			return "", fmt.Errorf("hunk %d (%s): %w", i+1, h.header(), ErrConflict)
+		}
+
+		out = append(out, lines[pos:at]...)
+		for _, line := range h.Lines {
+			if line.Op != OpDelete {
+				out = append(out, line.Text)
+			}
+		}
+		pos = at + len(old)
+	}
+
+	out = append(out, lines[pos:]...)
+	return strings.Join(out, ""), nil
+}
+
+// ApplyPatch parses a single-file patch and applies it to text.
+func ApplyPatch(text, patch string) (string, error) {
+	files, err := Parse(patch)
+	if err != nil {
+		return "", err
+	}
+	if len(files) != 1 {
+		return "", log.Errorf(log.CodeInvalidArgument, "apply_patch", "expected a patch of one file, got %d", len(files))
+	}
+
+	return Apply(text, files[0].Hunks)
+}
+
+// find returns the index at or after min where old occurs in lines,
+// preferring the position closest to expected.
+func find(lines, old []string, min, expected int) (int, bool) {
+	matches := func(at int) bool {
+		if at < min || at+len(old) > len(lines) {
+			return false
+		}
+		for i, line := range old {
+			if lines[at+i] != line {
+				return false
+			}
+		}
+		return true
+	}
+
+	for distance := 0; distance <= len(lines); distance++ {
+		if matches(expected - distance) {
+			return expected - distance, true
+		}
+		if matches(expected + distance) {
+			return expected + distance, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,39 @@
+package textdiff
+
+import (
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/style"
+)
+
+// Colorize styles a unified diff for the terminal: file headers bold, hunk
+// headers cyan, removed lines red and added lines green. Without color
+// support it returns diff unchanged.
+func Colorize(diff string) string {
+	if !style.Enabled() {
+		return diff
+	}
+
+	var sb strings.Builder
+	for _, line := range splitLines(diff) {
+		text := strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(text, "--- ") || strings.HasPrefix(text, "+++ "):
+			text = style.Bold(text)
+		case strings.HasPrefix(text, "@@"):
+			text = style.Info(text)
+		case strings.HasPrefix(text, "-"):
+			text = style.Error(text)
+		case strings.HasPrefix(text, "+"):
+			text = style.Success(text)
+		case strings.HasPrefix(text, `\`):
+			text = style.Dim(text)
+		}
+		sb.WriteString(text)
+		if strings.HasSuffix(line, "\n") {
+			sb.WriteByte('\n')
+		}
+	}
+
+	return sb.String()
+}
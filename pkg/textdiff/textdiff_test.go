@@ -0,0 +1,181 @@
+package textdiff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/style"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{
+			"change",
+			"a\nb\nc\n", "a\nB\nc\n",
+			"--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			"from empty",
+			"", "a\nb\n",
+			"--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			"to empty",
+			"a\n", "",
+			"--- old\n+++ new\n@@ -1 +0,0 @@\n-a\n",
+		},
+		{
+			"no newline at end",
+			"a\nb", "a\nc",
+			"--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+		{
+			"newline added",
+			"a", "a\n",
+			"--- old\n+++ new\n@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+a\n",
+		},
+		{
+			"separate hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n", "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			"--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("old", "new", tt.a, tt.b); got != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var long []string
+	for i := range 40 {
+		long = append(long, fmt.Sprintf("line %d\n", i))
+	}
+	edited := append([]string{"header\n"}, long[:10]...)
+	edited = append(edited, long[12:30]...)
+	edited = append(edited, "inserted\n")
+	edited = append(edited, long[30:]...)
+
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"empty", "", ""},
+		{"create", "", "a\nb\n"},
+		{"delete", "a\nb\n", ""},
+		{"replace all", "a\nb\n", "c\nd\n"},
+		{"no newline", "a\nb", "a\nb\nc"},
+		{"long", strings.Join(long, ""), strings.Join(edited, "")},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(tt.a, Hunks(tt.a, tt.b, DefaultContext))
+			if err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+			if got != tt.b {
+				t.Errorf("Expected %q, got: %q", tt.b, got)
+			}
+
+			got, err = ApplyPatch(tt.a, Unified("a", "b", tt.a, tt.b))
+			if tt.a == tt.b {
+				// An empty patch has no file to apply.
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyPatch failed: %v", err)
+			}
+			if got != tt.b {
+				t.Errorf("Expected %q after parsing, got: %q", tt.b, got)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	patch := "--- a/config.yaml\n+++ b/config.yaml\n@@ -2,3 +2,3 @@\n name: app\n-replicas: 1\n+replicas: 3\n port: 80\n"
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		err      error
+	}{
+		{"exact", "kind: App\nname: app\nreplicas: 1\nport: 80\n", "kind: App\nname: app\nreplicas: 3\nport: 80\n", nil},
+		{"offset", "# comment\n\nkind: App\nname: app\nreplicas: 1\nport: 80\n", "# comment\n\nkind: App\nname: app\nreplicas: 3\nport: 80\n", nil},
+		{"conflict", "kind: App\nname: app\nreplicas: 2\nport: 80\n", "", ErrConflict},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyPatch(tt.text, patch)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got: %v", tt.err, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	patch := "diff --git a/x b/x\n--- a/x\t2024-01-01\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n--- a/y\n+++ b/y\n@@ -1,2 +1 @@\n a\n-b\n\\ No newline at end of file\n"
+
+	files, err := Parse(patch)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got: %d", len(files))
+	}
+	if files[0].OldName != "a/x" || files[0].NewName != "b/x" {
+		t.Errorf("Expected names a/x and b/x, got: %s and %s", files[0].OldName, files[0].NewName)
+	}
+	if got := files[1].Hunks[0].Lines[1].Text; got != "b" {
+		t.Errorf("Expected last line without newline, got: %q", got)
+	}
+
+	for _, invalid := range []string{
+		"--- a\n+++ b\n@@ -1 +1 @@\n-a\n",
+		"--- a\n+++ b\n@@ -x +1 @@\n",
+		"@@ -1 +1 @@\n-a\n+b\n",
+	} {
+		if _, err := Parse(invalid); err == nil {
+			t.Errorf("Expected error parsing %q", invalid)
+		}
+	}
+}
+
+func TestColorize(t *testing.T) {
+	defer style.SetEnabled(style.Enabled())
+	diff := Unified("old", "new", "a\nb\n", "a\nc\n")
+
+	style.SetEnabled(false)
+	if got := Colorize(diff); got != diff {
+		t.Errorf("Expected diff unchanged without color, got: %q", got)
+	}
+
+	style.SetEnabled(true)
+	got := Colorize(diff)
+	for _, expected := range []string{style.Bold("--- old"), style.Info("@@ -1,2 +1,2 @@"), style.Error("-b"), style.Success("+c"), "\n a\n"} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("Expected %q in output, got: %q", expected, got)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+// Exporter sends finished spans to a tracing backend.
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+const (
+	queueSize     = 2048
+	batchSize     = 512
+	flushInterval = 5 * time.Second
+)
+
+// tracer batches finished spans for its exporter.
+type tracer struct {
+	exporter Exporter
+	queue    chan SpanData
+	flush    chan chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+var (
+	tracerMu     sync.RWMutex
+	activeTracer *tracer
+)
+
+func current() *tracer {
+	tracerMu.RLock()
+	defer tracerMu.RUnlock()
+	return activeTracer
+}
+
+func newTracer(exporter Exporter) *tracer {
+	t := &tracer{
+		exporter: exporter,
+		queue:    make(chan SpanData, queueSize),
+		flush:    make(chan chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// enqueue drops the span when the queue is full rather than blocking the
+// traced operation.
+func (t *tracer) enqueue(span SpanData) {
+	select {
+	case t.queue <- span:
+	default:
+		log.Component("trace").Debug("span queue full, dropping span", "name", span.Name)
+	}
+}
+
+func (t *tracer) run() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var batch []SpanData
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := t.exporter.Export(ctx, batch); err != nil {
+			log.Component("trace").Debug("span export failed", "spans", len(batch), "error", err)
+		}
+		batch = nil
+	}
+
+	for {
+		select {
+		case span := <-t.queue:
+			batch = append(batch, span)
+			if len(batch) >= batchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case ack := <-t.flush:
+			for len(t.queue) > 0 {
+				batch = append(batch, <-t.queue)
+			}
+			export()
+			close(ack)
+		case <-t.done:
+			return
+		}
+	}
+}
+
+// shutdown exports every queued span and stops the tracer.
+func (t *tracer) shutdown(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case t.flush <- ack:
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-ack:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	t.once.Do(func() { close(t.done) })
+	return nil
+}
+
+// OTLPExporter posts spans as OTLP/HTTP JSON to an OpenTelemetry collector.
+type OTLPExporter struct {
+	// URL is the traces endpoint, e.g. http://localhost:4318/v1/traces.
+	URL         string
+	Headers     map[string]string
+	ServiceName string
+	Client      *http.Client
+}
+
+func (e *OTLPExporter) Export(ctx context.Context, spans []SpanData) error {
+	body, err := json.Marshal(e.payload(spans))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("otlp export failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+type otlpValue map[string]any
+
+type otlpAttr struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpSpan struct {
+	TraceID      string     `json:"traceId"`
+	SpanID       string     `json:"spanId"`
+	ParentSpanID string     `json:"parentSpanId,omitempty"`
+	Name         string     `json:"name"`
+	Kind         Kind       `json:"kind"`
+	Start        string     `json:"startTimeUnixNano"`
+	End          string     `json:"endTimeUnixNano"`
+	Attributes   []otlpAttr `json:"attributes,omitempty"`
+	Status       otlpStatus `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Status codes as in OTLP.
+const statusError = 2
+
+func (e *OTLPExporter) payload(spans []SpanData) map[string]any {
+	converted := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		s := otlpSpan{
+			TraceID:    span.Context.TraceID.String(),
+			SpanID:     span.Context.SpanID.String(),
+			Name:       span.Name,
+			Kind:       span.Kind,
+			Start:      strconv.FormatInt(span.Start.UnixNano(), 10),
+			End:        strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes: convertAttrs(span.Attrs),
+		}
+		if span.Parent.IsValid() {
+			s.ParentSpanID = span.Parent.String()
+		}
+		if span.Err != nil {
+			s.Status = otlpStatus{Code: statusError, Message: span.Err.Error()}
+		}
+		converted[i] = s
+	}
+
+	return map[string]any{
+		"resourceSpans": []any{map[string]any{
+			"resource": map[string]any{"attributes": convertAttrs([]Attr{String("service.name", e.ServiceName)})},
+			"scopeSpans": []any{map[string]any{
+				"scope": map[string]any{"name": "github.com/eunanio/sdk"},
+				"spans": converted,
+			}},
+		}},
+	}
+}
+
+func convertAttrs(attrs []Attr) []otlpAttr {
+	converted := make([]otlpAttr, 0, len(attrs))
+	for _, attr := range attrs {
+		var value otlpValue
+		switch v := attr.Value.(type) {
+		case string:
+			value = otlpValue{"stringValue": v}
+		case bool:
+			value = otlpValue{"boolValue": v}
+		case int:
+			value = otlpValue{"intValue": strconv.Itoa(v)}
+		case int64:
+			value = otlpValue{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			value = otlpValue{"doubleValue": v}
+		default:
+			value = otlpValue{"stringValue": fmt.Sprint(v)}
+		}
+		converted = append(converted, otlpAttr{Key: attr.Key, Value: value})
+	}
+	return converted
+}
@@ -0,0 +1,78 @@
+package trace
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TraceparentHeader is the W3C Trace Context header. The same value is
+// passed to child processes in the TRACEPARENT environment variable.
+const (
+	TraceparentHeader = "traceparent"
+	TraceparentEnv    = "TRACEPARENT"
+)
+
+// Traceparent formats the active span of ctx as a traceparent value, or
+// returns "" when there is none.
+func Traceparent(ctx context.Context) string {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ""
+	}
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent parses a version 00 traceparent value.
+func ParseTraceparent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+
+	return sc, sc.IsValid()
+}
+
+// Inject sets the traceparent header for the active span of ctx.
+func Inject(ctx context.Context, header http.Header) {
+	if value := Traceparent(ctx); value != "" {
+		header.Set(TraceparentHeader, value)
+	}
+}
+
+// Extract returns ctx with the remote parent from a traceparent header, if
+// present and valid.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	if sc, ok := ParseTraceparent(header.Get(TraceparentHeader)); ok {
+		return ContextWithRemote(ctx, sc)
+	}
+	return ctx
+}
+
+// Env returns the TRACEPARENT variable for child processes, or nil when
+// ctx has no span.
+func Env(ctx context.Context) []string {
+	if value := Traceparent(ctx); value != "" {
+		return []string{TraceparentEnv + "=" + value}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package trace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+type Config struct {
+	ServiceName string
+	// Endpoint is the OTLP/HTTP base URL, e.g. http://localhost:4318.
+	// Spans are posted to Endpoint + "/v1/traces".
+	Endpoint string
+	Headers  map[string]string
+	// Exporter replaces the OTLP exporter, e.g. to collect spans in tests.
+	Exporter Exporter
+}
+
+// ConfigFromEnv reads the standard OpenTelemetry variables:
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT,
+// OTEL_EXPORTER_OTLP_HEADERS (comma-separated key=value pairs) and
+// OTEL_SERVICE_NAME. It reports false when no endpoint is set or
+// OTEL_SDK_DISABLED is true.
+func ConfigFromEnv() (Config, bool) {
+	if disabled, err := strconv.ParseBool(os.Getenv("OTEL_SDK_DISABLED")); err == nil && disabled {
+		return Config{}, false
+	}
+
+	config := Config{ServiceName: os.Getenv("OTEL_SERVICE_NAME"), Headers: map[string]string{}}
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); endpoint != "" {
+		config.Endpoint = strings.TrimSuffix(strings.TrimSuffix(endpoint, "/"), "/v1/traces")
+	} else {
+		config.Endpoint = strings.TrimSuffix(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "/")
+	}
+	for _, pair := range strings.Split(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), ",") {
+		if key, value, ok := strings.Cut(pair, "="); ok {
+			config.Headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	return config, config.Endpoint != ""
+}
+
+// Setup enables tracing for the process. The returned shutdown exports any
+// queued spans and must be called before exiting. Calling Setup again
+// replaces the previous configuration.
+func Setup(config Config) (shutdown func(context.Context) error) {
+	if config.ServiceName == "" {
+		exe, _ := os.Executable()
+		config.ServiceName = strings.TrimSuffix(filepath.Base(exe), ".exe")
+	}
+
+	exporter := config.Exporter
+	if exporter == nil {
+		exporter = &OTLPExporter{
+			URL:         strings.TrimSuffix(config.Endpoint, "/") + "/v1/traces",
+			Headers:     config.Headers,
+			ServiceName: config.ServiceName,
+		}
+	}
+
+	t := newTracer(exporter)
+	tracerMu.Lock()
+	previous := activeTracer
+	activeTracer = t
+	tracerMu.Unlock()
+	if previous != nil {
+		go previous.shutdown(context.Background())
+	}
+	log.Component("trace").Debug("tracing enabled", "service", config.ServiceName, "endpoint", config.Endpoint)
+
+	return func(ctx context.Context) error {
+		tracerMu.Lock()
+		if activeTracer == t {
+			activeTracer = nil
+		}
+		tracerMu.Unlock()
+		return t.shutdown(ctx)
+	}
+}
+
+// SetupFromEnv calls Setup with ConfigFromEnv when an endpoint is
+// configured, and otherwise returns a no-op shutdown.
+func SetupFromEnv() (shutdown func(context.Context) error) {
+	config, ok := ConfigFromEnv()
+	if !ok {
+		return func(context.Context) error { return nil }
+	}
+	return Setup(config)
+}
@@ -0,0 +1,183 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type memoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (e *memoryExporter) Export(_ context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func TestStartWithoutSetup(t *testing.T) {
+	ctx, span := Start(context.Background(), "noop")
+	if span != nil {
+		t.Fatalf("Expected a nil span without Setup, got: %+v", span)
+	}
+	span.SetAttributes(String("key", "value"))
+	span.Finish(errors.New("ignored"))
+	if Traceparent(ctx) != "" {
+		t.Errorf("Expected no traceparent, got: %s", Traceparent(ctx))
+	}
+}
+
+func TestSpans(t *testing.T) {
+	exporter := &memoryExporter{}
+	shutdown := Setup(Config{ServiceName: "test", Exporter: exporter})
+
+	ctx, root := Start(context.Background(), "pipeline")
+	_, child := Start(ctx, "push", Int64("bytes", 42))
+	child.Finish(errors.New("denied"))
+	root.End()
+	root.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(exporter.spans) != 2 {
+		t.Fatalf("Expected 2 spans, got: %d", len(exporter.spans))
+	}
+	pushed, pipeline := exporter.spans[0], exporter.spans[1]
+	if pushed.Context.TraceID != pipeline.Context.TraceID {
+		t.Errorf("Expected spans to share a trace, got: %s and %s", pushed.Context.TraceID, pipeline.Context.TraceID)
+	}
+	if pushed.Parent != pipeline.Context.SpanID {
+		t.Errorf("Expected push to be a child of pipeline, got parent: %s", pushed.Parent)
+	}
+	if pipeline.Parent.IsValid() {
+		t.Errorf("Expected pipeline to be a root span, got parent: %s", pipeline.Parent)
+	}
+	if pushed.Err == nil || len(pushed.Attrs) != 1 {
+		t.Errorf("Expected push to record its error and attributes, got: %+v", pushed)
+	}
+
+	if _, span := Start(context.Background(), "after"); span != nil {
+		t.Error("Expected tracing to stop after shutdown")
+	}
+}
+
+func TestTraceparent(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{name: "Sampled", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", valid: true},
+		{name: "Not sampled", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", valid: true},
+		{name: "Zero trace ID", value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{name: "Unknown version", value: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "Malformed", value: "not-a-traceparent"},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			sc, ok := ParseTraceparent(tt.value)
+			if ok != tt.valid {
+				t.Fatalf("Expected valid: %v, got: %v", tt.valid, ok)
+			}
+			if !ok {
+				return
+			}
+
+			header := http.Header{}
+			Inject(ContextWithRemote(context.Background(), sc), header)
+			if header.Get(TraceparentHeader) != tt.value {
+				t.Errorf("Expected %s, got: %s", tt.value, header.Get(TraceparentHeader))
+			}
+		})
+	}
+}
+
+func TestRemoteParent(t *testing.T) {
+	exporter := &memoryExporter{}
+	shutdown := Setup(Config{Exporter: exporter})
+
+	header := http.Header{}
+	header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := Start(Extract(context.Background(), header), "handler")
+	span.End()
+	shutdown(context.Background())
+
+	if len(exporter.spans) != 1 {
+		t.Fatalf("Expected 1 span, got: %d", len(exporter.spans))
+	}
+	if got := exporter.spans[0]; got.Context.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || got.Parent.String() != "00f067aa0ba902b7" {
+		t.Errorf("Expected the span to join the remote trace, got: %s/%s", got.Context.TraceID, got.Parent)
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var received map[string]any
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			http.NotFound(w, r)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Bearer abc")
+	t.Setenv("OTEL_SERVICE_NAME", "devkit-test")
+	shutdown := SetupFromEnv()
+
+	_, span := Start(context.Background(), "compress", String("src", "chart"))
+	span.Finish(errors.New("boom"))
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if auth != "Bearer abc" {
+		t.Errorf("Expected configured headers to be sent, got: %q", auth)
+	}
+
+	resourceSpans, _ := received["resourceSpans"].([]any)
+	if len(resourceSpans) != 1 {
+		t.Fatalf("Expected 1 resource, got: %v", received)
+	}
+	resource := resourceSpans[0].(map[string]any)
+	serviceName := resource["resource"].(map[string]any)["attributes"].([]any)[0].(map[string]any)["value"].(map[string]any)["stringValue"]
+	if serviceName != "devkit-test" {
+		t.Errorf("Expected service name devkit-test, got: %v", serviceName)
+	}
+	spans := resource["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)
+	exported := spans[0].(map[string]any)
+	if exported["name"] != "compress" || exported["status"].(map[string]any)["message"] != "boom" {
+		t.Errorf("Expected the compress span with its error, got: %v", exported)
+	}
+	if len(exported["traceId"].(string)) != 32 {
+		t.Errorf("Expected a hex trace ID, got: %v", exported["traceId"])
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://traces:4318/v1/traces")
+	config, ok := ConfigFromEnv()
+	if !ok || config.Endpoint != "http://traces:4318" {
+		t.Errorf("Expected the traces endpoint to win, got: %q", config.Endpoint)
+	}
+
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+	if _, ok := ConfigFromEnv(); ok {
+		t.Error("Expected OTEL_SDK_DISABLED to disable tracing")
+	}
+}
@@ -0,0 +1,77 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// semverPattern is the semver 2.0 grammar with an optional "v" prefix.
+var semverPattern = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+func isURL(value reflect.Value, _ string) error {
+	s, err := stringValue(value)
+	if err != nil {
+		return err
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("must be a valid URL, got %q", s)
+	}
+	return nil
+}
+
+func isSemver(value reflect.Value, _ string) error {
+	s, err := stringValue(value)
+	if err != nil {
+		return err
+	}
+
+	if !semverPattern.MatchString(s) {
+		return fmt.Errorf("must be a semantic version such as 1.2.3, got %q", s)
+	}
+	return nil
+}
+
+// oneOf accepts values listed in param, separated by spaces.
+func oneOf(value reflect.Value, param string) error {
+	allowed := strings.Fields(param)
+	s := fmt.Sprint(value.Interface())
+	for _, option := range allowed {
+		if s == option {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("must be one of %s, got %q", strings.Join(allowed, ", "), s)
+}
+
+func pathExists(value reflect.Value, _ string) error {
+	s, err := stringValue(value)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(s); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("must be an existing path, %s does not exist", s)
+		}
+		return fmt.Errorf("must be an accessible path: %w", err)
+	}
+	return nil
+}
+
+func stringValue(value reflect.Value) (string, error) {
+	if value.Kind() != reflect.String {
+		return "", fmt.Errorf("must be a string, got %s", value.Kind())
+	}
+
+	return value.String(), nil
+}
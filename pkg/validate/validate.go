@@ -0,0 +1,208 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+// TagName is the struct tag holding a field's rules, e.g.
+// `validate:"required,oneof=debug info warn"`.
+const TagName = "validate"
+
+// Rule checks a non-zero field value against param, the text after "=" in
+// the tag. The returned error's message is shown after the field name.
+type Rule func(value reflect.Value, param string) error
+
+var (
+	mu    sync.RWMutex
+	rules = map[string]Rule{
+		"url":         isURL,
+		"semver":      isSemver,
+		"oneof":       oneOf,
+		"path-exists": pathExists,
+	}
+)
+
+// Register adds a rule, replacing any existing rule with the same name.
+// "required" cannot be replaced.
+func Register(name string, rule Rule) {
+	mu.Lock()
+	defer mu.Unlock()
+	rules[name] = rule
+}
+
+func lookup(name string) (Rule, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	rule, ok := rules[name]
+	return rule, ok
+}
+
+type FieldError struct {
+	// Field is the path of the field, using json names where they are set,
+	// e.g. "tag.host" or "items[1].name".
+	Field   string
+	Rule    string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// Errors holds every failed rule of a struct.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d validation errors:", len(e))
+	for _, err := range e {
+		b.WriteString("\n  - ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Struct checks the rules in the validate tags of v, which must be a struct
+// or a pointer to one, and of nested structs and slices of structs. Rules
+// other than required are skipped for zero values. All failures are
+// returned together as Errors wrapped in a log.CodeInvalidArgument error.
+func Struct(v any) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return log.Errorf(log.CodeInvalidArgument, "", "cannot validate a nil %T", v)
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return log.Errorf(log.CodeInvalidArgument, "", "cannot validate %T: not a struct", v)
+	}
+
+	var errs Errors
+	if err := validateStruct(value, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return log.NewError(log.CodeInvalidArgument, "", errs)
+	}
+
+	return nil
+}
+
+func validateStruct(value reflect.Value, prefix string, errs *Errors) error {
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get(TagName)
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+
+		name := prefix + fieldName(field)
+		fieldValue := value.Field(i)
+		if err := validateField(fieldValue, name, tag, errs); err != nil {
+			return err
+		}
+
+		if err := validateNested(fieldValue, name, errs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateField(value reflect.Value, name, tag string, errs *Errors) error {
+	if tag == "" {
+		return nil
+	}
+
+	for _, spec := range strings.Split(tag, ",") {
+		ruleName, param, _ := strings.Cut(strings.TrimSpace(spec), "=")
+		if ruleName == "required" {
+			if isZero(value) {
+				*errs = append(*errs, FieldError{Field: name, Rule: ruleName, Message: "is required"})
+				return nil
+			}
+			continue
+		}
+
+		rule, ok := lookup(ruleName)
+		if !ok {
+			return fmt.Errorf("unknown validation rule %q on %s", ruleName, name)
+		}
+
+		if isZero(value) {
+			continue
+		}
+		if err := rule(indirect(value), param); err != nil {
+			*errs = append(*errs, FieldError{Field: name, Rule: ruleName, Message: err.Error()})
+		}
+	}
+
+	return nil
+}
+
+func validateNested(value reflect.Value, name string, errs *Errors) error {
+	value = indirect(value)
+	switch value.Kind() {
+	case reflect.Struct:
+		return validateStruct(value, name+".", errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			item := indirect(value.Index(i))
+			if item.Kind() != reflect.Struct {
+				continue
+			}
+			if err := validateStruct(item, fmt.Sprintf("%s[%d].", name, i), errs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "yaml"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return field.Name
+}
+
+func isZero(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(value.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	case reflect.Invalid:
+		return true
+	default:
+		return value.IsZero()
+	}
+}
+
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+
+	return value
+}
@@ -0,0 +1,154 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+type target struct {
+	Host  string `json:"host" validate:"required"`
+	Ports []int  `validate:"required"`
+}
+
+type config struct {
+	Name     string   `json:"name" validate:"required"`
+	Endpoint string   `json:"endpoint,omitempty" validate:"url"`
+	Version  string   `yaml:"version" validate:"semver"`
+	Level    string   `validate:"oneof=debug info warn"`
+	Retries  int      `validate:"oneof=1 2 3"`
+	Path     string   `validate:"path-exists"`
+	Target   *target  `json:"target" validate:"required"`
+	Targets  []target `json:"targets"`
+	Ignored  string   `validate:"-"`
+	internal string   `validate:"required"`
+	Optional *target  `json:"optional"`
+}
+
+func TestStruct(t *testing.T) {
+	valid := func() config {
+		return config{
+			Name:     "app",
+			Endpoint: "https://example.com/v2",
+			Version:  "v1.2.3-rc.1+build.5",
+			Level:    "info",
+			Retries:  2,
+			Path:     t.TempDir(),
+			Target:   &target{Host: "localhost", Ports: []int{80}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(c *config)
+		expected []string
+	}{
+		{name: "valid", modify: func(c *config) {}},
+		{name: "zero values skip rules", modify: func(c *config) {
+			c.Endpoint, c.Version, c.Level, c.Retries, c.Path = "", "", "", 0, ""
+		}},
+		{name: "required", modify: func(c *config) { c.Name = "  " }, expected: []string{"name is required"}},
+		{name: "required pointer", modify: func(c *config) { c.Target = nil }, expected: []string{"target is required"}},
+		{name: "url", modify: func(c *config) { c.Endpoint = "example.com" }, expected: []string{`endpoint must be a valid URL, got "example.com"`}},
+		{name: "semver", modify: func(c *config) { c.Version = "1.2" }, expected: []string{`version must be a semantic version such as 1.2.3, got "1.2"`}},
+		{name: "oneof", modify: func(c *config) { c.Level = "trace" }, expected: []string{`Level must be one of debug, info, warn, got "trace"`}},
+		{name: "oneof int", modify: func(c *config) { c.Retries = 5 }, expected: []string{`Retries must be one of 1, 2, 3, got "5"`}},
+		{name: "path-exists", modify: func(c *config) { c.Path = "/does/not/exist" }, expected: []string{"Path must be an existing path, /does/not/exist does not exist"}},
+		{name: "nested", modify: func(c *config) { c.Target.Ports = nil }, expected: []string{"target.Ports is required"}},
+		{name: "slices", modify: func(c *config) {
+			c.Targets = []target{{Host: "a", Ports: []int{1}}, {Ports: []int{2}}}
+		}, expected: []string{"targets[1].host is required"}},
+		{name: "nil optional struct", modify: func(c *config) { c.Optional = nil }},
+		{name: "aggregates", modify: func(c *config) {
+			c.Name = ""
+			c.Level = "trace"
+			c.Optional = &target{}
+		}, expected: []string{"name is required", `Level must be one of debug, info, warn, got "trace"`, "optional.host is required", "optional.Ports is required"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.modify(&c)
+
+			err := Struct(&c)
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Fatalf("Expected no error, got: %v", err)
+				}
+				return
+			}
+
+			if !log.IsCode(err, log.CodeInvalidArgument) {
+				t.Errorf("Expected an invalid argument error, got: %v", err)
+			}
+
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Expected Errors, got: %T", err)
+			}
+
+			var messages []string
+			for _, fieldErr := range errs {
+				messages = append(messages, fieldErr.Error())
+			}
+			if !reflect.DeepEqual(messages, tt.expected) {
+				t.Errorf("Expected %q, got: %q", tt.expected, messages)
+			}
+		})
+	}
+}
+
+func TestErrorsMessage(t *testing.T) {
+	single := Errors{{Field: "name", Rule: "required", Message: "is required"}}
+	if single.Error() != "name is required" {
+		t.Errorf("Expected a single line, got: %q", single.Error())
+	}
+
+	multiple := append(single, FieldError{Field: "tag.host", Rule: "required", Message: "is required"})
+	expected := "2 validation errors:\n  - name is required\n  - tag.host is required"
+	if multiple.Error() != expected {
+		t.Errorf("Expected %q, got: %q", expected, multiple.Error())
+	}
+}
+
+func TestStructInvalidInput(t *testing.T) {
+	var nilConfig *config
+	for _, v := range []any{nilConfig, "string", 42} {
+		if err := Struct(v); !log.IsCode(err, log.CodeInvalidArgument) {
+			t.Errorf("Expected an invalid argument error for %T, got: %v", v, err)
+		}
+	}
+
+	type unknown struct {
+		Name string `validate:"palindrome"`
+	}
+	if err := Struct(unknown{Name: "x"}); err == nil || !strings.Contains(err.Error(), "unknown validation rule") {
+		t.Errorf("Expected an unknown rule error, got: %v", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("even", func(value reflect.Value, param string) error {
+		if value.Int()%2 != 0 {
+			return fmt.Errorf("must be even")
+		}
+		return nil
+	})
+
+	type counter struct {
+		Count int `json:"count" validate:"even"`
+	}
+
+	if err := Struct(counter{Count: 4}); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if err := Struct(counter{Count: 3}); err == nil || err.Error() != "count must be even" {
+		t.Errorf("Expected count must be even, got: %v", err)
+	}
+}
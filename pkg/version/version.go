@@ -0,0 +1,179 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+// Build metadata, set at link time with e.g.
+//
+//	-ldflags "-X github.com/eunanio/sdk/pkg/version.Version=v1.2.3"
+//
+// Values left empty are filled from the build info embedded by the Go
+// toolchain.
+var (
+	Version   string
+	Commit    string
+	BuildDate string
+)
+
+// Dev is reported as the version of binaries built without a version, e.g.
+// with go run or go build from a checkout.
+const Dev = "dev"
+
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"buildDate,omitempty"`
+	// Modified is set when the binary was built from a dirty checkout.
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+}
+
+var buildInfo = sync.OnceValue(func() *debug.BuildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	return info
+})
+
+// Get returns the build metadata of the running binary.
+func Get() Info {
+	return resolve(buildInfo())
+}
+
+func resolve(build *debug.BuildInfo) Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if build != nil {
+		if info.Version == "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+		if build.GoVersion != "" {
+			info.GoVersion = build.GoVersion
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = Dev
+	}
+
+	return info
+}
+
+func (i Info) String() string {
+	return i.Version
+}
+
+// FormatFull describes the build on one line, e.g.
+// "v1.2.3 (commit 1a2b3c4, built 2024-05-01T10:00:00Z, go1.23.2 linux/amd64)".
+func (i Info) FormatFull() string {
+	details := []string{}
+	if i.Commit != "" {
+		commit := i.Commit[:min(len(i.Commit), 7)]
+		if i.Modified {
+			commit += "-dirty"
+		}
+		details = append(details, "commit "+commit)
+	}
+	if i.BuildDate != "" {
+		details = append(details, "built "+i.BuildDate)
+	}
+	details = append(details, i.GoVersion+" "+i.Platform)
+
+	return fmt.Sprintf("%s (%s)", i.Version, strings.Join(details, ", "))
+}
+
+// FormatFull describes the running binary, for --version output.
+func FormatFull() string {
+	return Get().FormatFull()
+}
+
+// Checker returns the latest released version, e.g. from
+// selfupdate.Updater.Latest.
+type Checker func(ctx context.Context) (string, error)
+
+// UpdateAvailable reports the latest version when it is newer than the
+// running one. Dev builds never report updates, and check failures are only
+// logged so a version check can't break the command it runs alongside.
+func UpdateAvailable(ctx context.Context, check Checker) (string, bool) {
+	current := Get().Version
+	if current == Dev {
+		return "", false
+	}
+
+	latest, err := check(ctx)
+	if err != nil {
+		log.Component("version").Debug("update check failed", "error", err)
+		return "", false
+	}
+
+	return latest, Compare(latest, current) > 0
+}
+
+// Compare orders semantic versions, ignoring a leading "v" and build
+// metadata. A pre-release sorts before its release.
+func Compare(a, b string) int {
+	a, b = strings.TrimPrefix(strings.TrimSpace(a), "v"), strings.TrimPrefix(strings.TrimSpace(b), "v")
+	coreA, preA, _ := strings.Cut(strings.SplitN(a, "+", 2)[0], "-")
+	coreB, preB, _ := strings.Cut(strings.SplitN(b, "+", 2)[0], "-")
+
+	partsA, partsB := strings.Split(coreA, "."), strings.Split(coreB, ".")
+	for i := 0; i < max(len(partsA), len(partsB)); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			y, _ = strconv.Atoi(partsB[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	case preA < preB:
+		return -1
+	default:
+		return 1
+	}
+}
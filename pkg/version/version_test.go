@@ -0,0 +1,109 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"runtime/debug"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	build := &debug.BuildInfo{
+		GoVersion: "go1.23.2",
+		Main:      debug.Module{Version: "v1.4.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "1a2b3c4d5e6f"},
+			{Key: "vcs.time", Value: "2024-05-01T10:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+
+	tests := []struct {
+		name     string
+		ldflags  [3]string
+		build    *debug.BuildInfo
+		expected string
+	}{
+		{
+			name:     "Build info",
+			build:    build,
+			expected: "v1.4.0 (commit 1a2b3c4-dirty, built 2024-05-01T10:00:00Z, go1.23.2 " + platform + ")",
+		},
+		{
+			name:     "Ldflags take precedence",
+			ldflags:  [3]string{"v2.0.0", "ffffffffff", "2024-06-01"},
+			build:    build,
+			expected: "v2.0.0 (commit fffffff-dirty, built 2024-06-01, go1.23.2 " + platform + ")",
+		},
+		{
+			name:     "Development build",
+			build:    &debug.BuildInfo{GoVersion: "go1.23.2", Main: debug.Module{Version: "(devel)"}},
+			expected: "dev (go1.23.2 " + platform + ")",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			Version, Commit, BuildDate = tt.ldflags[0], tt.ldflags[1], tt.ldflags[2]
+			defer func() { Version, Commit, BuildDate = "", "", "" }()
+
+			if got := resolve(tt.build).FormatFull(); got != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestUpdateAvailable(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		latest   string
+		err      error
+		expected bool
+	}{
+		{name: "Newer release", current: "v1.2.0", latest: "v1.3.0", expected: true},
+		{name: "Up to date", current: "v1.3.0", latest: "1.3.0"},
+		{name: "Dev build", current: "", latest: "v1.3.0"},
+		{name: "Check failure", current: "v1.2.0", err: errors.New("offline")},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			Version = tt.current
+			defer func() { Version = "" }()
+
+			check := func(ctx context.Context) (string, error) { return tt.latest, tt.err }
+			if _, ok := UpdateAvailable(context.Background(), check); ok != tt.expected {
+				t.Errorf("Expected %v, got: %v", tt.expected, ok)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"v1.2.0", "1.1.9", 1},
+		{"1.2", "v1.2.0", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.1", 1},
+		{"1.0.0+build.5", "1.0.0", 0},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := Compare(tt.a, tt.b); got != tt.expected {
+				t.Errorf("Expected %d, got: %d", tt.expected, got)
+			}
+		})
+	}
+}
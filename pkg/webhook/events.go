@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/exec"
+	"github.com/eunanio/sdk/pkg/mirror"
+	"github.com/eunanio/sdk/pkg/oci"
+	"github.com/opencontainers/go-digest"
+	spec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	EventArtifactPushed = "artifact.pushed"
+	EventSyncCompleted  = "sync.completed"
+	EventCommandFailed  = "command.failed"
+)
+
+type ArtifactPushed struct {
+	Reference   string            `json:"reference"`
+	Digest      digest.Digest     `json:"digest"`
+	MediaType   string            `json:"media_type"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type SyncCompleted struct {
+	Copied     int             `json:"copied"`
+	Unchanged  int             `json:"unchanged"`
+	Failed     int             `json:"failed"`
+	DurationMS int64           `json:"duration_ms"`
+	Results    []mirror.Result `json:"results"`
+}
+
+type CommandFailed struct {
+	Command    string `json:"command"`
+	ExitCode   int    `json:"exit_code"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+func NewArtifactPushed(tag oci.Tag, d digest.Digest, manifest *spec.Manifest) Event {
+	mediaType := manifest.MediaType
+	if manifest.ArtifactType != "" {
+		mediaType = manifest.ArtifactType
+	}
+
+	data := ArtifactPushed{Reference: tag.String(), Digest: d, MediaType: mediaType, Annotations: manifest.Annotations}
+	return NewEvent(EventArtifactPushed, fmt.Sprintf("Pushed %s (%s)", data.Reference, d), data)
+}
+
+func NewSyncCompleted(report *mirror.Report) Event {
+	data := SyncCompleted{
+		Copied:     report.Count(mirror.StatusCopied),
+		Unchanged:  report.Count(mirror.StatusUnchanged),
+		Failed:     report.Count(mirror.StatusFailed),
+		DurationMS: report.Finished.Sub(report.Started).Milliseconds(),
+		Results:    report.Results,
+	}
+
+	summary := fmt.Sprintf("Mirror sync completed: %d copied, %d unchanged, %d failed", data.Copied, data.Unchanged, data.Failed)
+	return NewEvent(EventSyncCompleted, summary, data)
+}
+
+func NewCommandFailed(command string, exitCode int, elapsed time.Duration) Event {
+	data := CommandFailed{Command: command, ExitCode: exitCode, DurationMS: elapsed.Milliseconds()}
+	return NewEvent(EventCommandFailed, fmt.Sprintf("Command %s failed with exit code %d", command, exitCode), data)
+}
+
+// Track sends artifact.pushed for every manifest an oci.OciClient pushes and
+// command.failed for every exec command that fails, in the background.
+func Track(n *Notifier) {
+	oci.OnArtifact(func(op string, tag oci.Tag, d digest.Digest, manifest *spec.Manifest) {
+		if strings.HasPrefix(op, "push") {
+			n.Go(NewArtifactPushed(tag, d, manifest))
+		}
+	})
+	exec.OnCommand(func(command string, exitCode int, elapsed time.Duration) {
+		if exitCode != 0 {
+			n.Go(NewCommandFailed(command, exitCode, elapsed))
+		}
+	})
+}
+
+// SyncReporter returns a mirror.Mirror OnReport function sending
+// sync.completed in the background.
+func (n *Notifier) SyncReporter() func(*mirror.Report) {
+	return func(report *mirror.Report) {
+		n.Go(NewSyncCompleted(report))
+	}
+}
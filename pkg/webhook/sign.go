@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/log"
+)
+
+// DefaultTolerance is how old a delivery may be when it is verified.
+const DefaultTolerance = 5 * time.Minute
+
+// Sign returns the signature header value for body sent at timestamp:
+// "sha256=" and the hex HMAC-SHA256 of "<unix timestamp>.<body>" keyed with
+// secret. Covering the timestamp lets receivers reject replayed deliveries.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of a received delivery.
+// Deliveries older than tolerance are rejected; zero means
+// DefaultTolerance.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	if tolerance == 0 {
+		tolerance = DefaultTolerance
+	}
+
+	seconds, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return log.Errorf(log.CodeUnauthorized, "webhook_verify", "missing or invalid %s header", HeaderTimestamp)
+	}
+	timestamp := time.Unix(seconds, 0)
+	if age := time.Since(timestamp); age > tolerance || age < -tolerance {
+		return log.Errorf(log.CodeUnauthorized, "webhook_verify", "delivery timestamp is outside the tolerance of %s", tolerance)
+	}
+
+	signature := header.Get(HeaderSignature)
+	if !strings.HasPrefix(signature, "sha256=") || !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return log.Errorf(log.CodeUnauthorized, "webhook_verify", "invalid signature")
+	}
+
+	return nil
+}
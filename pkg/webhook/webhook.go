@@ -0,0 +1,258 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/httpx"
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/validate"
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Devkit-Event"
+	HeaderDelivery  = "X-Devkit-Delivery"
+	HeaderTimestamp = "X-Devkit-Timestamp"
+	HeaderSignature = "X-Devkit-Signature-256"
+)
+
+// DefaultRetries is the number of times a failed delivery is retried.
+const DefaultRetries = 3
+
+// retryBackoff is the wait before the first retry, doubled for each
+// further one. Tests shorten it.
+var retryBackoff = time.Second
+
+type Format string
+
+const (
+	// FormatJSON posts the Event as JSON, signed when the endpoint has a
+	// secret.
+	FormatJSON Format = "json"
+	// FormatSlack posts the event summary as a Slack incoming webhook
+	// message.
+	FormatSlack Format = "slack"
+)
+
+type Endpoint struct {
+	URL string `validate:"required,url"`
+	// Secret signs deliveries with HMAC-SHA256; see Verify.
+	Secret string
+	// Events lists the event types delivered, all when empty.
+	Events []string
+	Format Format `validate:"oneof=json slack"`
+	// Headers are added to every request, e.g. an Authorization header.
+	Headers map[string]string
+}
+
+func (e Endpoint) wants(eventType string) bool {
+	return len(e.Events) == 0 || slices.Contains(e.Events, eventType)
+}
+
+// Event is the JSON payload of a delivery.
+type Event struct {
+	ID   string    `json:"id"`
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	// Summary is a one-line description for chat messages.
+	Summary string `json:"summary"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// Delivery is the outcome of one attempt to deliver an event.
+type Delivery struct {
+	URL      string
+	Event    string
+	ID       string
+	Attempt  int
+	Status   int
+	Duration time.Duration
+	Err      error
+}
+
+type Notifier struct {
+	Endpoints []Endpoint
+	Client    *httpx.Client
+	// Retries is DefaultRetries when zero; use a negative value to disable
+	// retries.
+	Retries int
+	// OnDelivery observes every attempt, after it has been logged.
+	OnDelivery func(Delivery)
+
+	wg sync.WaitGroup
+}
+
+func New(endpoints ...Endpoint) *Notifier {
+	return &Notifier{Endpoints: endpoints}
+}
+
+func (n *Notifier) client() *httpx.Client {
+	if n.Client == nil {
+		// Deliveries are retried here, with a fresh timestamp and signature.
+		n.Client = httpx.New(httpx.WithRetries(0), httpx.WithTimeout(30*time.Second))
+	}
+	return n.Client
+}
+
+// NewEvent returns an event with a new ID and the current time.
+func NewEvent(eventType, summary string, data any) Event {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return Event{ID: hex.EncodeToString(id), Type: eventType, Time: time.Now().UTC(), Summary: summary, Data: data}
+}
+
+// Send delivers event to every endpoint subscribed to its type, retrying
+// failures, and returns the errors of the endpoints that never accepted it.
+func (n *Notifier) Send(ctx context.Context, event Event) error {
+	var errs []error
+	for _, endpoint := range n.Endpoints {
+		if !endpoint.wants(event.Type) {
+			continue
+		}
+		if err := n.deliver(ctx, endpoint, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Go sends event in the background, for hooks that must not block. Failures
+// are logged. Call Wait before exiting.
+func (n *Notifier) Go(event Event) {
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		_ = n.Send(context.Background(), event)
+	}()
+}
+
+// Wait blocks until every event passed to Go has been delivered or has
+// failed.
+func (n *Notifier) Wait() {
+	n.wg.Wait()
+}
+
+func (n *Notifier) deliver(ctx context.Context, endpoint Endpoint, event Event) error {
+	if err := validate.Struct(endpoint); err != nil {
+		return err
+	}
+
+	body, err := encode(endpoint.Format, event)
+	if err != nil {
+		return err
+	}
+
+	retries := n.Retries
+	if retries == 0 {
+		retries = DefaultRetries
+	}
+
+	for attempt := 1; ; attempt++ {
+		delivery := n.attempt(ctx, endpoint, event, body)
+		delivery.Attempt = attempt
+		n.report(delivery)
+
+		if delivery.Err == nil {
+			return nil
+		}
+		if attempt > retries || !retryable(delivery) {
+			return fmt.Errorf("webhook %s: %w", event.Type, delivery.Err)
+		}
+
+		select {
+		case <-time.After(retryBackoff << (attempt - 1)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (n *Notifier) attempt(ctx context.Context, endpoint Endpoint, event Event, body []byte) Delivery {
+	delivery := Delivery{URL: endpoint.URL, Event: event.Type, ID: event.ID}
+	start := time.Now()
+	defer func() { delivery.Duration = time.Since(start) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Err = err
+		return delivery
+	}
+
+	timestamp := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "devkit-webhook")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderDelivery, event.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	if endpoint.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, body))
+	}
+	for key, value := range endpoint.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.client().Do(req)
+	if err != nil {
+		delivery.Err = err
+		return delivery
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	delivery.Status = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		delivery.Err = log.Errorf(log.CodeRemote, "webhook_deliver", "endpoint returned %s", resp.Status)
+	}
+	return delivery
+}
+
+// retryable reports whether a failed attempt may succeed later: network
+// errors, rate limiting and server errors.
+func retryable(d Delivery) bool {
+	return d.Status == 0 || d.Status == http.StatusTooManyRequests || d.Status >= 500
+}
+
+func (n *Notifier) report(d Delivery) {
+	logger := log.Component("webhook")
+	attrs := []any{"url", redact(d.URL), "event", d.Event, "delivery", d.ID, "attempt", d.Attempt, "status", d.Status, "duration_ms", d.Duration.Milliseconds()}
+	if d.Err != nil {
+		logger.Error("delivery failed", append(attrs, log.KeyError, d.Err.Error())...)
+	} else {
+		logger.Info("delivered", attrs...)
+	}
+
+	if n.OnDelivery != nil {
+		n.OnDelivery(d)
+	}
+}
+
+func encode(format Format, event Event) ([]byte, error) {
+	if format == FormatSlack {
+		return json.Marshal(map[string]string{"text": event.Summary})
+	}
+	return json.Marshal(event)
+}
+
+// redact keeps only the scheme and host of url for logs, as webhook URLs
+// such as Slack's carry their secret in the path.
+func redact(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "invalid URL"
+	}
+	return u.Scheme + "://" + u.Host + "/..."
+}
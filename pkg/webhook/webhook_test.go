@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/mirror"
+)
+
+func init() {
+	retryBackoff = time.Millisecond
+}
+
+// receiver records deliveries, answering with statuses in turn and 200
+// once they run out.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header.Clone())
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name          string
+		statuses      []int
+		events        []string
+		expectedCalls int
+		expectError   bool
+	}{
+		{name: "delivered", expectedCalls: 1},
+		{name: "retried", statuses: []int{503, 429}, expectedCalls: 3},
+		{name: "retries exhausted", statuses: []int{500, 500, 500, 500}, expectedCalls: 4, expectError: true},
+		{name: "client error", statuses: []int{400}, expectedCalls: 1, expectError: true},
+		{name: "not subscribed", events: []string{EventSyncCompleted}, expectedCalls: 0},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			r := &receiver{statuses: tt.statuses}
+			server := httptest.NewServer(r)
+			defer server.Close()
+
+			var deliveries []Delivery
+			n := New(Endpoint{URL: server.URL, Secret: "s3cret", Events: tt.events, Headers: map[string]string{"Authorization": "Bearer token"}})
+			n.OnDelivery = func(d Delivery) { deliveries = append(deliveries, d) }
+
+			event := NewCommandFailed("terraform", 1, time.Second)
+			err := n.Send(context.Background(), event)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Expected error %v, got: %v", tt.expectError, err)
+			}
+			if len(r.bodies) != tt.expectedCalls || len(deliveries) != tt.expectedCalls {
+				t.Fatalf("Expected %d calls, got: %d (%d reported)", tt.expectedCalls, len(r.bodies), len(deliveries))
+			}
+
+			for i, header := range r.headers {
+				if err := Verify("s3cret", header, r.bodies[i], 0); err != nil {
+					t.Errorf("Expected a valid signature, got: %v", err)
+				}
+				if header.Get(HeaderDelivery) != event.ID || header.Get(HeaderEvent) != EventCommandFailed || header.Get("Authorization") != "Bearer token" {
+					t.Errorf("Expected delivery headers, got: %v", header)
+				}
+				if deliveries[i].Attempt != i+1 {
+					t.Errorf("Expected attempt %d, got: %d", i+1, deliveries[i].Attempt)
+				}
+			}
+		})
+	}
+}
+
+func TestSlackFormat(t *testing.T) {
+	r := &receiver{}
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	n := New(Endpoint{URL: server.URL, Format: FormatSlack})
+	n.SyncReporter()(&mirror.Report{Results: []mirror.Result{{Status: mirror.StatusCopied}, {Status: mirror.StatusFailed}}})
+	n.Wait()
+
+	var message map[string]string
+	if len(r.bodies) != 1 || json.Unmarshal(r.bodies[0], &message) != nil {
+		t.Fatalf("Expected one JSON message, got: %q", r.bodies)
+	}
+	if expected := "Mirror sync completed: 1 copied, 0 unchanged, 1 failed"; message["text"] != expected {
+		t.Errorf("Expected %q, got: %q", expected, message["text"])
+	}
+	if r.headers[0].Get(HeaderSignature) != "" {
+		t.Error("Expected no signature without a secret")
+	}
+
+	if err := New(Endpoint{URL: server.URL, Format: "xml"}).Send(context.Background(), NewEvent("test", "", nil)); !log.IsCode(err, log.CodeInvalidArgument) {
+		t.Errorf("Expected an invalid format to be rejected, got: %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"type":"test"}`)
+	now := time.Now()
+	header := func(timestamp time.Time, signature string) http.Header {
+		h := http.Header{}
+		h.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+		h.Set(HeaderSignature, signature)
+		return h
+	}
+
+	tests := []struct {
+		name        string
+		header      http.Header
+		body        []byte
+		expectError bool
+	}{
+		{"valid", header(now, Sign("key", now, body)), body, false},
+		{"wrong secret", header(now, Sign("other", now, body)), body, true},
+		{"modified body", header(now, Sign("key", now, body)), []byte(`{"type":"other"}`), true},
+		{"replayed", header(now.Add(-time.Hour), Sign("key", now.Add(-time.Hour), body)), body, true},
+		{"missing", http.Header{}, body, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify("key", tt.header, tt.body, 0)
+			if (err != nil) != tt.expectError {
+				t.Errorf("Expected error %v, got: %v", tt.expectError, err)
+			}
+			if err != nil && !log.IsCode(err, log.CodeUnauthorized) {
+				t.Errorf("Expected an unauthorized error, got: %v", err)
+			}
+		})
+	}
+}
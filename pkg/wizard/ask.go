@@ -0,0 +1,58 @@
+package wizard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/eunanio/sdk/pkg/system"
+)
+
+// errRetry asks the same step again, e.g. after an unparsable number.
+var errRetry = errors.New("retry")
+
+func ask(step Step, def any) (any, error) {
+	label := step.Prompt
+	if label == "" {
+		label = step.Name
+	}
+
+	switch step.Kind {
+	case Password:
+		return system.PromptPassword(label + ": ")
+	case Confirm:
+		b, _ := def.(bool)
+		return system.Confirm(label, b)
+	case Select:
+		i, err := system.Select(label, step.Options)
+		if err != nil {
+			return nil, err
+		}
+		return step.Options[i], nil
+	case Path:
+		start, _ := def.(string)
+		if start == "" {
+			start = "."
+		}
+		return system.PickPath(start, step.Filter)
+	case Int:
+		var text string
+		if n, ok := def.(int); ok {
+			text = strconv.Itoa(n)
+		}
+		answer, err := system.Input(label, text)
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(answer)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%q is not a whole number\n", answer)
+			return nil, errRetry
+		}
+		return n, nil
+	default:
+		text, _ := def.(string)
+		return system.Input(label, text)
+	}
+}
@@ -0,0 +1,283 @@
+package wizard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/system"
+	"github.com/eunanio/sdk/pkg/validate"
+)
+
+type Kind int
+
+const (
+	// Text answers are strings.
+	Text Kind = iota
+	// Password answers are strings read without echo.
+	Password
+	// Int answers are ints.
+	Int
+	// Confirm answers are bools.
+	Confirm
+	// Select answers are one of Step.Options.
+	Select
+	// Path answers are absolute paths picked with system.PickPath.
+	Path
+)
+
+type Step struct {
+	// Name is the answer key, also used for presets and defaults.
+	Name   string
+	Prompt string
+	Kind   Kind
+	// Options are the choices of a Select step.
+	Options []string
+	// Filter restricts the paths a Path step accepts.
+	Filter system.PathFilter
+	// Default is used for an empty answer, or when running
+	// non-interactively, unless a config default is set.
+	Default any
+	// Required rejects empty text answers.
+	Required bool
+	// Validate checks the typed answer. Interactively the step is asked
+	// again with the error shown.
+	Validate func(value any) error
+	// When skips the step unless it returns true for the answers so far.
+	When func(answers Answers) bool
+}
+
+// Answers maps step names to typed values.
+type Answers map[string]any
+
+func (a Answers) String(name string) string {
+	value, _ := a[name].(string)
+	return value
+}
+
+func (a Answers) Bool(name string) bool {
+	value, _ := a[name].(bool)
+	return value
+}
+
+func (a Answers) Int(name string) int {
+	value, _ := a[name].(int)
+	return value
+}
+
+// Decode stores the answers in v, a pointer to a struct, matching step
+// names against json field names.
+func (a Answers) Decode(v any) error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+type Wizard struct {
+	steps    []Step
+	defaults map[string]any
+	presets  map[string]any
+	// ask prompts for a step; replaced in tests.
+	ask func(step Step, def any) (any, error)
+}
+
+func New(steps ...Step) *Wizard {
+	return &Wizard{
+		steps:    steps,
+		defaults: map[string]any{},
+		presets:  map[string]any{},
+		ask:      ask,
+	}
+}
+
+// Defaults sets defaults, typically from a config file, that take
+// precedence over Step.Default.
+func (w *Wizard) Defaults(values map[string]any) *Wizard {
+	for name, value := range values {
+		w.defaults[name] = value
+	}
+	return w
+}
+
+// Preset answers steps up front, typically from flags; those steps are not
+// asked. String values are converted to the step's kind.
+func (w *Wizard) Preset(values map[string]any) *Wizard {
+	for name, value := range values {
+		w.presets[name] = value
+	}
+	return w
+}
+
+// PresetJSON reads presets from a JSON object, e.g. an --answers file.
+func (w *Wizard) PresetJSON(r io.Reader) error {
+	var values map[string]any
+	if err := json.NewDecoder(r).Decode(&values); err != nil {
+		return log.Errorf(log.CodeInvalidArgument, "wizard_preset", "invalid answers: %v", err)
+	}
+	w.Preset(values)
+	return nil
+}
+
+// Run walks the steps in order. Preset steps are not asked; when prompting
+// is disabled (see system.SetNonInteractive) the remaining steps take their
+// defaults. Every invalid or missing answer that cannot be asked is
+// reported together as validate.Errors.
+func (w *Wizard) Run() (Answers, error) {
+	answers := Answers{}
+	var errs validate.Errors
+
+	for _, step := range w.steps {
+		if step.When != nil && !step.When(answers) {
+			continue
+		}
+
+		def, err := w.defaultFor(step)
+		if err != nil {
+			errs = append(errs, fieldError(step, "default", err))
+			continue
+		}
+
+		if preset, ok := w.presets[step.Name]; ok {
+			value, err := w.accept(step, preset)
+			if err != nil {
+				errs = append(errs, fieldError(step, "preset", err))
+				continue
+			}
+			answers[step.Name] = value
+			continue
+		}
+
+		if system.IsNonInteractive() {
+			if def == nil {
+				errs = append(errs, fieldError(step, "required", system.ErrNonInteractive))
+				continue
+			}
+			value, err := w.accept(step, def)
+			if err != nil {
+				errs = append(errs, fieldError(step, "default", err))
+				continue
+			}
+			answers[step.Name] = value
+			continue
+		}
+
+		value, err := w.prompt(step, def)
+		if err != nil {
+			return nil, err
+		}
+		answers[step.Name] = value
+	}
+
+	if len(errs) > 0 {
+		return nil, log.NewError(log.CodeInvalidArgument, "wizard", errs)
+	}
+
+	return answers, nil
+}
+
+func (w *Wizard) defaultFor(step Step) (any, error) {
+	if def, ok := w.defaults[step.Name]; ok {
+		return convert(step, def)
+	}
+	if step.Default == nil {
+		return nil, nil
+	}
+	return convert(step, step.Default)
+}
+
+// prompt asks until the answer is valid.
+func (w *Wizard) prompt(step Step, def any) (any, error) {
+	for {
+		value, err := w.ask(step, def)
+		if errors.Is(err, errRetry) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		value, err = w.accept(step, value)
+		if err == nil {
+			return value, nil
+		}
+		fmt.Fprintf(os.Stderr, "%s %s\n", step.Name, err)
+	}
+}
+
+func (w *Wizard) accept(step Step, value any) (any, error) {
+	value, err := convert(step, value)
+	if err != nil {
+		return nil, err
+	}
+	if step.Required && value == "" {
+		return nil, errors.New("is required")
+	}
+	if step.Validate != nil {
+		if err := step.Validate(value); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// convert turns a preset, default or answer into the step's type. Strings
+// are parsed so flag values can be passed as presets.
+func convert(step Step, value any) (any, error) {
+	switch step.Kind {
+	case Confirm:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("must be true or false, got %q", v)
+			}
+			return b, nil
+		}
+	case Int:
+		switch v := value.(type) {
+		case int:
+			return v, nil
+		case float64:
+			if v == float64(int(v)) {
+				return int(v), nil
+			}
+		case json.Number:
+			if n, err := strconv.Atoi(v.String()); err == nil {
+				return n, nil
+			}
+		case string:
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("must be a whole number, got %q", v)
+			}
+			return n, nil
+		}
+		return nil, fmt.Errorf("must be a whole number, got %v", value)
+	case Select:
+		v, ok := value.(string)
+		if ok && slices.Contains(step.Options, v) {
+			return v, nil
+		}
+		return nil, fmt.Errorf("must be one of %s, got %v", strings.Join(step.Options, ", "), value)
+	default:
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected value %v (%T)", value, value)
+}
+
+func fieldError(step Step, rule string, err error) validate.FieldError {
+	return validate.FieldError{Field: step.Name, Rule: rule, Message: err.Error()}
+}
@@ -0,0 +1,149 @@
+package wizard
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/eunanio/sdk/pkg/log"
+	"github.com/eunanio/sdk/pkg/system"
+	"github.com/eunanio/sdk/pkg/validate"
+)
+
+func initSteps() []Step {
+	return []Step{
+		{Name: "name", Prompt: "Project name", Required: true, Validate: func(value any) error {
+			if strings.Contains(value.(string), " ") {
+				return errors.New("must not contain spaces")
+			}
+			return nil
+		}},
+		{Name: "language", Kind: Select, Options: []string{"go", "node"}, Default: "go"},
+		{Name: "goVersion", Kind: Text, Default: "1.23", When: func(a Answers) bool { return a.String("language") == "go" }},
+		{Name: "replicas", Kind: Int, Default: 1},
+		{Name: "ci", Kind: Confirm, Default: false},
+	}
+}
+
+func TestRunNonInteractive(t *testing.T) {
+	defer system.SetNonInteractive(system.IsNonInteractive())
+	system.SetNonInteractive(true)
+
+	tests := []struct {
+		name     string
+		presets  map[string]any
+		json     string
+		defaults map[string]any
+		expected Answers
+		failed   []string
+	}{
+		{
+			name:     "Presets from flags",
+			presets:  map[string]any{"name": "demo", "replicas": "3", "ci": "true"},
+			expected: Answers{"name": "demo", "language": "go", "goVersion": "1.23", "replicas": 3, "ci": true},
+		},
+		{
+			name:     "Presets from JSON and config defaults",
+			json:     `{"name": "demo", "language": "node", "replicas": 2}`,
+			defaults: map[string]any{"ci": true},
+			expected: Answers{"name": "demo", "language": "node", "replicas": 2, "ci": true},
+		},
+		{
+			name:    "Missing and invalid answers",
+			presets: map[string]any{"language": "rust", "replicas": "many"},
+			failed:  []string{"name", "language", "replicas"},
+		},
+		{
+			name:    "Validation",
+			presets: map[string]any{"name": "my app"},
+			failed:  []string{"name"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // capture range variable
+		t.Run(tt.name, func(t *testing.T) {
+			w := New(initSteps()...).Defaults(tt.defaults).Preset(tt.presets)
+			if tt.json != "" {
+				if err := w.PresetJSON(strings.NewReader(tt.json)); err != nil {
+					t.Fatalf("Expected no error, got: %v", err)
+				}
+			}
+
+			answers, err := w.Run()
+			if len(tt.failed) > 0 {
+				var errs validate.Errors
+				if !errors.As(err, &errs) || !log.IsCode(err, log.CodeInvalidArgument) {
+					t.Fatalf("Expected validation errors, got: %v", err)
+				}
+				var fields []string
+				for _, e := range errs {
+					fields = append(fields, e.Field)
+				}
+				if strings.Join(fields, ",") != strings.Join(tt.failed, ",") {
+					t.Errorf("Expected failures for %v, got: %v", tt.failed, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if len(answers) != len(tt.expected) {
+				t.Errorf("Expected %v, got: %v", tt.expected, answers)
+			}
+			for name, expected := range tt.expected {
+				if answers[name] != expected {
+					t.Errorf("Expected %s=%v, got: %v", name, expected, answers[name])
+				}
+			}
+		})
+	}
+}
+
+func TestRunInteractive(t *testing.T) {
+	defer system.SetNonInteractive(system.IsNonInteractive())
+	system.SetNonInteractive(false)
+
+	replies := map[string][]any{
+		"name":     {"my app", "demo"},
+		"language": {"node"},
+		"replicas": {5},
+		"ci":       {true},
+	}
+	var asked []string
+
+	w := New(initSteps()...)
+	w.ask = func(step Step, def any) (any, error) {
+		asked = append(asked, step.Name)
+		if step.Name == "replicas" && def != 1 {
+			t.Errorf("Expected the step default, got: %v", def)
+		}
+		reply := replies[step.Name][0]
+		replies[step.Name] = replies[step.Name][1:]
+		return reply, nil
+	}
+
+	answers, err := w.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := "name,name,language,replicas,ci"
+	if strings.Join(asked, ",") != expected {
+		t.Errorf("Expected steps %s, got: %v", expected, asked)
+	}
+
+	var project struct {
+		Name     string `json:"name"`
+		Language string `json:"language"`
+		Replicas int    `json:"replicas"`
+		CI       bool   `json:"ci"`
+	}
+	if err := answers.Decode(&project); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if project.Name != "demo" || project.Language != "node" || project.Replicas != 5 || !project.CI {
+		t.Errorf("Unexpected answers: %+v", project)
+	}
+}